@@ -0,0 +1,353 @@
+// Package bankstatement parses bank statement exports (CSV or Excel) from Vietnamese banks
+// into transactions. Banks lay out their exports differently, so columns are located by
+// their header rather than by position.
+package bankstatement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Transaction is one line of a bank statement. Amount is positive for money received.
+type Transaction struct {
+	Date                time.Time
+	Amount              float64
+	Description         string
+	Reference           string
+	CounterpartyName    string
+	CounterpartyAccount string
+}
+
+// Column roles, in the order they are matched against headers. Roles whose keywords
+// contain those of another role come first, e.g. "ten tai khoan doi ung" before
+// "tai khoan doi ung".
+const (
+	colDate = iota
+	colCredit
+	colDebit
+	colAmount
+	colDescription
+	colReference
+	colCounterpartyName
+	colCounterpartyAccount
+	colCount
+)
+
+var columnOrder = []int{colCounterpartyName, colCounterpartyAccount, colCredit, colDebit, colReference, colDate, colDescription, colAmount}
+
+// columnKeywords are matched against headers with diacritics removed
+var columnKeywords = map[int][]string{
+	colDate:                {"ngay giao dich", "ngay gd", "ngay hieu luc", "ngay hach toan", "transaction date", "posting date", "ngay", "date"},
+	colCredit:              {"ghi co", "so tien co", "phat sinh co", "so tien vao", "credit", "co"},
+	colDebit:               {"ghi no", "so tien no", "phat sinh no", "so tien ra", "debit", "no"},
+	colAmount:              {"so tien", "amount"},
+	colDescription:         {"noi dung", "mo ta", "dien giai", "chi tiet", "description", "remark"},
+	colReference:           {"so tham chieu", "so but toan", "ma giao dich", "so giao dich", "so ct", "reference", "transaction id", "ref"},
+	colCounterpartyName:    {"ten tai khoan doi ung", "ten doi ung", "ten nguoi chuyen", "nguoi chuyen", "ten don vi chuyen", "remitter", "counterparty name"},
+	colCounterpartyAccount: {"tai khoan doi ung", "so tai khoan doi ung", "tk doi ung", "tai khoan nguoi chuyen", "counterparty account"},
+}
+
+// shortKeywords only match a header exactly, as they appear inside unrelated headers
+var shortKeywords = map[string]bool{"co": true, "no": true, "ngay": true, "date": true, "ref": true}
+
+var dateLayouts = []string{
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02/01/2006",
+	"2/1/2006",
+	"02-01-2006 15:04:05",
+	"02-01-2006",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"01-02-06",
+}
+
+// headerSearchRows is how far down the header row is looked for; exports usually start
+// with the account holder and period
+const headerSearchRows = 30
+
+// Parse reads the transactions of a CSV or Excel (.xlsx) statement
+func Parse(fileName string, r io.Reader) ([]*Transaction, error) {
+	var rows [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv", ".txt":
+		rows, err = readCSV(r)
+	case ".xlsx", ".xlsm":
+		rows, err = readExcel(r)
+	case ".xls":
+		return nil, errors.New("legacy .xls files are not supported, export the statement as .xlsx or .csv")
+	default:
+		return nil, fmt.Errorf("unsupported statement file type %q", filepath.Ext(fileName))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	headerRow, columns, err := findHeader(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []*Transaction
+	for i := headerRow + 1; i < len(rows); i++ {
+		row := rows[i]
+		dateValue := cell(row, columns[colDate])
+		if dateValue == "" {
+			// Blank and summary rows
+			continue
+		}
+
+		date, err := parseDate(dateValue)
+		if err != nil {
+			// Footer rows such as "Tổng cộng" have no date
+			continue
+		}
+
+		amount, err := rowAmount(row, columns)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+
+		transactions = append(transactions, &Transaction{
+			Date:                date,
+			Amount:              amount,
+			Description:         cell(row, columns[colDescription]),
+			Reference:           cell(row, columns[colReference]),
+			CounterpartyName:    cell(row, columns[colCounterpartyName]),
+			CounterpartyAccount: cell(row, columns[colCounterpartyAccount]),
+		})
+	}
+
+	return transactions, nil
+}
+
+// ParseAmount parses an amount written with Vietnamese or English separators,
+// e.g. "1.650.000", "1,650,000", "1,650,000.00" or "+1.650.000 VND"
+func ParseAmount(value string) (float64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	for _, suffix := range []string{"VND", "VNĐ", "Đ"} {
+		s = strings.TrimSpace(strings.TrimSuffix(s, suffix))
+	}
+	s = strings.ReplaceAll(s, " ", "")
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+	if strings.HasPrefix(s, "-") {
+		negative = true
+	}
+	s = strings.TrimLeft(s, "+-")
+
+	if s == "" {
+		return 0, nil
+	}
+
+	lastDot := strings.LastIndex(s, ".")
+	lastComma := strings.LastIndex(s, ",")
+
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// The separator that comes last is the decimal separator
+		if lastDot > lastComma {
+			s = strings.ReplaceAll(s, ",", "")
+		} else {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		}
+	case lastDot >= 0:
+		s = normalizeSingleSeparator(s, ".")
+	case lastComma >= 0:
+		s = normalizeSingleSeparator(s, ",")
+	}
+
+	amount, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// Normalize lowercases text and removes Vietnamese diacritics, e.g. "Ghi Có" -> "ghi co"
+func Normalize(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r == 'đ':
+			b.WriteRune('d')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Helper methods
+func readCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 BOM added by Excel
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	// Some banks export with semicolons
+	if firstLine, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV file: %w", err)
+	}
+	return rows, nil
+}
+
+func readExcel(r io.Reader) ([][]string, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Excel file: %w", err)
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("Excel file has no sheets")
+	}
+
+	return file.GetRows(sheets[0])
+}
+
+// findHeader locates the header row and the index of each column role, -1 when absent
+func findHeader(rows [][]string) (int, []int, error) {
+	for i := 0; i < len(rows) && i < headerSearchRows; i++ {
+		columns := matchColumns(rows[i])
+		hasAmount := columns[colCredit] >= 0 || columns[colAmount] >= 0
+		if columns[colDate] >= 0 && hasAmount {
+			return i, columns, nil
+		}
+	}
+
+	return 0, nil, errors.New("could not find the transaction table, expected a date column and a credit or amount column")
+}
+
+func matchColumns(header []string) []int {
+	columns := make([]int, colCount)
+	for i := range columns {
+		columns[i] = -1
+	}
+
+	assigned := make([]bool, len(header))
+	for _, role := range columnOrder {
+		for i, title := range header {
+			if assigned[i] {
+				continue
+			}
+			if headerMatches(Normalize(title), columnKeywords[role]) {
+				columns[role] = i
+				assigned[i] = true
+				break
+			}
+		}
+	}
+
+	return columns
+}
+
+func headerMatches(title string, keywords []string) bool {
+	// Bilingual headers such as "Ghi có/Credit" match on either part
+	for _, part := range append([]string{title}, strings.Split(title, "/")...) {
+		part = strings.TrimSpace(strings.Trim(strings.TrimSpace(part), "()"))
+		for _, keyword := range keywords {
+			if shortKeywords[keyword] {
+				if part == keyword {
+					return true
+				}
+			} else if strings.Contains(part, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rowAmount returns the credited amount of a row; debits are negative
+func rowAmount(row []string, columns []int) (float64, error) {
+	if columns[colCredit] >= 0 || columns[colDebit] >= 0 {
+		credit, err := ParseAmount(cell(row, columns[colCredit]))
+		if err != nil {
+			return 0, err
+		}
+		if credit != 0 {
+			return credit, nil
+		}
+
+		debit, err := ParseAmount(cell(row, columns[colDebit]))
+		if err != nil {
+			return 0, err
+		}
+		if debit > 0 {
+			debit = -debit
+		}
+		return debit, nil
+	}
+
+	return ParseAmount(cell(row, columns[colAmount]))
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if date, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return date, nil
+		}
+	}
+
+	// Excel serial dates that were not formatted
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 1 {
+		return excelize.ExcelDateToTime(serial, false)
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// normalizeSingleSeparator decides whether a lone separator kind groups thousands or
+// marks decimals. VND amounts rarely have decimals, so a separator followed by exactly
+// three digits, or appearing more than once, groups thousands.
+func normalizeSingleSeparator(s, sep string) string {
+	parts := strings.Split(s, sep)
+	if len(parts) > 2 || len(parts[len(parts)-1]) == 3 {
+		return strings.ReplaceAll(s, sep, "")
+	}
+	return strings.Replace(s, sep, ".", 1)
+}
+
+func cell(row []string, index int) string {
+	if index < 0 || index >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[index])
+}
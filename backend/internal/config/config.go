@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	_ "github.com/lib/pq"
 )
@@ -13,7 +14,12 @@ type Config struct {
 	Database DatabaseConfig
 	Server   ServerConfig
 	JWT      JWTConfig
+	Login    LoginConfig
+	Password PasswordConfig
 	Redis    RedisConfig
+	EInvoice EInvoiceConfig
+	VietQR   VietQRConfig
+	Receipt  ReceiptConfig
 }
 
 type DatabaseConfig struct {
@@ -26,14 +32,37 @@ type DatabaseConfig struct {
 }
 
 type ServerConfig struct {
-	Port string
-	Host string
+	Port           string
+	Host           string
+	TrustedProxies []string // proxies whose X-Forwarded-For is believed, e.g. "10.0.0.1,172.18.0.0/16"; none when empty
 }
 
 type JWTConfig struct {
 	Secret             string
 	AccessTokenExpiry  string
 	RefreshTokenExpiry string
+	RefreshTokenStore  string // "postgres" or "memory"
+	SignedURLExpiry    string // lifetime of signed print URLs, e.g. "5m"
+}
+
+// LoginConfig holds the limits applied to failed logins
+type LoginConfig struct {
+	AttemptStore    string // where failed login counters are kept: "memory"
+	MaxFailures     int    // failed logins of a username before it is locked
+	MaxIPFailures   int    // failed logins from an IP address before it is locked
+	LockoutDuration string // how long a lock lasts, and how long failures are remembered, e.g. "15m"
+	BackoffBase     string // delay required after the first failure, doubled after each further one
+	BackoffMax      string // longest delay required between attempts
+}
+
+// PasswordConfig holds the rules new passwords must follow and the lifetime of reset tokens
+type PasswordConfig struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	ResetTokenExpiry string // lifetime of a password reset token issued by an admin, e.g. "24h"
 }
 
 type RedisConfig struct {
@@ -43,6 +72,33 @@ type RedisConfig struct {
 	DB       int
 }
 
+// EInvoiceConfig holds the seller details and provider settings used to issue e-invoices
+type EInvoiceConfig struct {
+	Provider      string // "file" writes submitted XML to OutputDir
+	OutputDir     string
+	TemplateCode  string // Ký hiệu mẫu số hóa đơn, e.g. "1" for VAT invoices
+	Series        string // Ký hiệu hóa đơn, e.g. "C24TKP"
+	SellerName    string
+	SellerTaxCode string
+	SellerAddress string
+	SellerPhone   string
+}
+
+// VietQRConfig holds the bank account customers transfer to when paying by VietQR
+type VietQRConfig struct {
+	BankBIN       string // NAPAS bank identification number, e.g. "970436" for Vietcombank
+	BankName      string
+	AccountNumber string
+	AccountName   string
+}
+
+// ReceiptConfig holds the thermal printer receipts are sent to
+type ReceiptConfig struct {
+	PrinterAddress string // host:port of a raw TCP printer, e.g. "192.168.1.50:9100"; empty disables printing
+	PrinterTimeout int    // seconds to wait for the printer
+	PaperWidth     int    // printable width in dots, 576 for 80mm paper at 203 dpi
+}
+
 func Load() *Config {
 	return &Config{
 		Database: DatabaseConfig{
@@ -54,13 +110,32 @@ func Load() *Config {
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		},
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Host: getEnv("SERVER_HOST", "0.0.0.0"),
+			Port:           getEnv("SERVER_PORT", "8080"),
+			Host:           getEnv("SERVER_HOST", "0.0.0.0"),
+			TrustedProxies: getEnvAsList("TRUSTED_PROXIES"),
 		},
 		JWT: JWTConfig{
 			Secret:             getEnv("JWT_SECRET", "your-secret-key-here"),
 			AccessTokenExpiry:  getEnv("JWT_ACCESS_TOKEN_EXPIRY", "24h"),
 			RefreshTokenExpiry: getEnv("JWT_REFRESH_TOKEN_EXPIRY", "720h"),
+			RefreshTokenStore:  getEnv("JWT_REFRESH_TOKEN_STORE", "postgres"),
+			SignedURLExpiry:    getEnv("JWT_SIGNED_URL_EXPIRY", "5m"),
+		},
+		Login: LoginConfig{
+			AttemptStore:    getEnv("LOGIN_ATTEMPT_STORE", "memory"),
+			MaxFailures:     getEnvAsInt("LOGIN_MAX_FAILURES", 5),
+			MaxIPFailures:   getEnvAsInt("LOGIN_MAX_IP_FAILURES", 20),
+			LockoutDuration: getEnv("LOGIN_LOCKOUT_DURATION", "15m"),
+			BackoffBase:     getEnv("LOGIN_BACKOFF_BASE", "1s"),
+			BackoffMax:      getEnv("LOGIN_BACKOFF_MAX", "1m"),
+		},
+		Password: PasswordConfig{
+			MinLength:        getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+			RequireUppercase: getEnvAsBool("PASSWORD_REQUIRE_UPPERCASE", false),
+			RequireLowercase: getEnvAsBool("PASSWORD_REQUIRE_LOWERCASE", false),
+			RequireDigit:     getEnvAsBool("PASSWORD_REQUIRE_DIGIT", true),
+			RequireSymbol:    getEnvAsBool("PASSWORD_REQUIRE_SYMBOL", false),
+			ResetTokenExpiry: getEnv("PASSWORD_RESET_TOKEN_EXPIRY", "24h"),
 		},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
@@ -68,6 +143,27 @@ func Load() *Config {
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getEnvAsInt("REDIS_DB", 0),
 		},
+		EInvoice: EInvoiceConfig{
+			Provider:      getEnv("EINVOICE_PROVIDER", "file"),
+			OutputDir:     getEnv("EINVOICE_OUTPUT_DIR", "data/einvoices"),
+			TemplateCode:  getEnv("EINVOICE_TEMPLATE_CODE", "1"),
+			Series:        getEnv("EINVOICE_SERIES", "C24TKP"),
+			SellerName:    getEnv("EINVOICE_SELLER_NAME", "ĐẠI LÝ SẮT THÉP KIÊN PHƯỚC"),
+			SellerTaxCode: getEnv("EINVOICE_SELLER_TAX_CODE", ""),
+			SellerAddress: getEnv("EINVOICE_SELLER_ADDRESS", "Trường Sơn Đức Thọ Hà Tĩnh"),
+			SellerPhone:   getEnv("EINVOICE_SELLER_PHONE", "0972851015"),
+		},
+		VietQR: VietQRConfig{
+			BankBIN:       getEnv("VIETQR_BANK_BIN", ""),
+			BankName:      getEnv("VIETQR_BANK_NAME", ""),
+			AccountNumber: getEnv("VIETQR_ACCOUNT_NUMBER", ""),
+			AccountName:   getEnv("VIETQR_ACCOUNT_NAME", ""),
+		},
+		Receipt: ReceiptConfig{
+			PrinterAddress: getEnv("RECEIPT_PRINTER_ADDRESS", ""),
+			PrinterTimeout: getEnvAsInt("RECEIPT_PRINTER_TIMEOUT", 10),
+			PaperWidth:     getEnvAsInt("RECEIPT_PAPER_WIDTH", 576),
+		},
 	}
 }
 
@@ -78,6 +174,17 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvAsList splits a comma-separated variable, nil when it is not set
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvAsInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -87,6 +194,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func InitDB(cfg *Config) (*sql.DB, error) {
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Database.Host,
@@ -0,0 +1,256 @@
+// Package einvoice renders invoices into the e-invoice XML format of the tax authority
+// (Thông tư 78/2021/TT-BTC), validates them against the bundled schema and submits them
+// through a pluggable provider.
+package einvoice
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"steel-pos-backend/internal/models"
+)
+
+const (
+	formatVersion = "2.0.1"
+	invoiceTitle  = "Hóa đơn giá trị gia tăng"
+	currency      = "VND"
+	paymentMethod = "TM/CK" // Tiền mặt hoặc chuyển khoản
+)
+
+// Party identifies the seller or the buyer of an invoice
+type Party struct {
+	Name    string
+	TaxCode string
+	Address string
+	Phone   string
+}
+
+// Header holds the numbering and parties of an e-invoice
+type Header struct {
+	TemplateCode string // Ký hiệu mẫu số
+	Series       string // Ký hiệu hóa đơn
+	Number       int
+	IssueDate    time.Time
+	Seller       Party
+	Buyer        Party
+}
+
+// HDon is the root element of an e-invoice
+type HDon struct {
+	XMLName xml.Name `xml:"HDon"`
+	DLHDon  DLHDon   `xml:"DLHDon"`
+	DSCKS   DSCKS    `xml:"DSCKS"`
+}
+
+// DLHDon holds the invoice data covered by the seller's signature
+type DLHDon struct {
+	ID      string  `xml:"Id,attr"`
+	TTChung TTChung `xml:"TTChung"`
+	NDHDon  NDHDon  `xml:"NDHDon"`
+}
+
+// TTChung holds the general invoice information
+type TTChung struct {
+	PBan     string  `xml:"PBan"`
+	THDon    string  `xml:"THDon"`
+	KHMSHDon string  `xml:"KHMSHDon"`
+	KHHDon   string  `xml:"KHHDon"`
+	SHDon    int     `xml:"SHDon"`
+	NLap     string  `xml:"NLap"`
+	DVTTe    string  `xml:"DVTTe"`
+	TGia     Decimal `xml:"TGia"`
+	HTTToan  string  `xml:"HTTToan"`
+}
+
+// NDHDon holds the parties, line items and totals
+type NDHDon struct {
+	NBan    NBan    `xml:"NBan"`
+	NMua    NMua    `xml:"NMua"`
+	DSHHDVu DSHHDVu `xml:"DSHHDVu"`
+	TToan   TToan   `xml:"TToan"`
+}
+
+// NBan is the seller
+type NBan struct {
+	Ten     string `xml:"Ten"`
+	MST     string `xml:"MST"`
+	DChi    string `xml:"DChi"`
+	SDThoai string `xml:"SDThoai,omitempty"`
+}
+
+// NMua is the buyer; the tax code is only given for business buyers
+type NMua struct {
+	Ten     string `xml:"Ten"`
+	MST     string `xml:"MST,omitempty"`
+	DChi    string `xml:"DChi,omitempty"`
+	SDThoai string `xml:"SDThoai,omitempty"`
+}
+
+// DSHHDVu is the list of goods and services
+type DSHHDVu struct {
+	HHDVu []HHDVu `xml:"HHDVu"`
+}
+
+// HHDVu is a line item. Amounts are before VAT.
+type HHDVu struct {
+	TChat   int     `xml:"TChat"`
+	STT     int     `xml:"STT"`
+	THHDVu  string  `xml:"THHDVu"`
+	DVTinh  string  `xml:"DVTinh"`
+	SLuong  Decimal `xml:"SLuong"`
+	DGia    Decimal `xml:"DGia"`
+	STCKhau Decimal `xml:"STCKhau,omitempty"`
+	ThTien  Decimal `xml:"ThTien"`
+	TSuat   string  `xml:"TSuat"`
+}
+
+// TToan holds the VAT breakdown and invoice totals
+type TToan struct {
+	THTTLTSuat THTTLTSuat `xml:"THTTLTSuat"`
+	TgTCThue   Decimal    `xml:"TgTCThue"`
+	TgTThue    Decimal    `xml:"TgTThue"`
+	TTCKTMai   Decimal    `xml:"TTCKTMai,omitempty"`
+	TgTTTBSo   Decimal    `xml:"TgTTTBSo"`
+	TgTTTBChu  string     `xml:"TgTTTBChu"`
+}
+
+// THTTLTSuat is the VAT breakdown per rate
+type THTTLTSuat struct {
+	LTSuat []LTSuat `xml:"LTSuat"`
+}
+
+// LTSuat is the taxable amount and VAT at one rate
+type LTSuat struct {
+	TSuat  string  `xml:"TSuat"`
+	ThTien Decimal `xml:"ThTien"`
+	TThue  Decimal `xml:"TThue"`
+}
+
+// DSCKS holds the digital signatures. The seller's signature is left as a placeholder
+// for the provider to fill in.
+type DSCKS struct {
+	NBan string `xml:"NBan"`
+}
+
+// Build renders a confirmed invoice into e-invoice XML. Line amounts are converted to
+// amounts before VAT when the invoice prices include tax.
+func Build(invoice *models.Invoice, header Header) ([]byte, error) {
+	if len(invoice.Items) == 0 {
+		return nil, errors.New("invoice has no items")
+	}
+
+	doc := HDon{
+		DLHDon: DLHDon{
+			ID: "data",
+			TTChung: TTChung{
+				PBan:     formatVersion,
+				THDon:    invoiceTitle,
+				KHMSHDon: header.TemplateCode,
+				KHHDon:   header.Series,
+				SHDon:    header.Number,
+				NLap:     header.IssueDate.Format("2006-01-02"),
+				DVTTe:    currency,
+				TGia:     1,
+				HTTToan:  paymentMethod,
+			},
+			NDHDon: NDHDon{
+				NBan: NBan{
+					Ten:     header.Seller.Name,
+					MST:     header.Seller.TaxCode,
+					DChi:    header.Seller.Address,
+					SDThoai: header.Seller.Phone,
+				},
+				NMua: NMua{
+					Ten:     header.Buyer.Name,
+					MST:     header.Buyer.TaxCode,
+					DChi:    header.Buyer.Address,
+					SDThoai: header.Buyer.Phone,
+				},
+			},
+		},
+	}
+
+	linesTotal := 0.0
+	taxableTotal := 0.0
+	for i, item := range invoice.Items {
+		name := item.ProductName
+		if item.VariantName != "" {
+			name += " - " + item.VariantName
+		}
+
+		unitPrice := item.UnitPrice
+		discount := item.PromotionDiscount + item.DiscountAmount
+		amount := item.TotalPrice
+		if invoice.PriceMode == models.PriceModeInclusive {
+			unitPrice = netOfTax(unitPrice, item.TaxRate)
+			discount = netOfTax(discount, item.TaxRate)
+			amount = netOfTax(amount, item.TaxRate)
+		}
+
+		doc.DLHDon.NDHDon.DSHHDVu.HHDVu = append(doc.DLHDon.NDHDon.DSHHDVu.HHDVu, HHDVu{
+			TChat:   1,
+			STT:     i + 1,
+			THHDVu:  name,
+			DVTinh:  item.Unit,
+			SLuong:  Decimal(item.Quantity),
+			DGia:    Decimal(unitPrice),
+			STCKhau: Decimal(discount),
+			ThTien:  Decimal(amount),
+			TSuat:   FormatTaxRate(item.TaxRate),
+		})
+
+		linesTotal += amount
+		taxableTotal += item.TaxableAmount
+	}
+
+	totals := &doc.DLHDon.NDHDon.TToan
+	taxTotal := 0.0
+	for _, line := range models.SummarizeVAT(invoice.Items) {
+		totals.THTTLTSuat.LTSuat = append(totals.THTTLTSuat.LTSuat, LTSuat{
+			TSuat:  FormatTaxRate(line.TaxRate),
+			ThTien: Decimal(line.TaxableAmount),
+			TThue:  Decimal(line.TaxAmount),
+		})
+		taxTotal += line.TaxAmount
+	}
+
+	// The invoice discount was allocated to the taxable amounts of the lines
+	totals.TgTCThue = Decimal(round(taxableTotal))
+	totals.TgTThue = Decimal(round(taxTotal))
+	totals.TTCKTMai = Decimal(round(linesTotal - taxableTotal))
+	totals.TgTTTBSo = Decimal(round(invoice.TotalAmount))
+	totals.TgTTTBChu = AmountInWords(int64(math.Round(invoice.TotalAmount)))
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render e-invoice: %w", err)
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
+
+// Decimal is an amount rendered in plain decimal notation, as xs:decimal requires
+type Decimal float64
+
+// MarshalText implements encoding.TextMarshaler
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatFloat(float64(d), 'f', -1, 64)), nil
+}
+
+// FormatTaxRate formats a VAT rate the way the tax authority expects, e.g. "10%" or "8%"
+func FormatTaxRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', -1, 64) + "%"
+}
+
+// Helper methods
+func netOfTax(amount, rate float64) float64 {
+	return round(amount / (1 + rate/100))
+}
+
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,161 @@
+package einvoice
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"steel-pos-backend/internal/models"
+)
+
+func testHeader() Header {
+	return Header{
+		TemplateCode: "1",
+		Series:       "C24TKP",
+		Number:       42,
+		IssueDate:    time.Date(2024, 2, 28, 15, 4, 0, 0, time.UTC),
+		Seller: Party{
+			Name:    "Công ty TNHH Thép Minh Phát",
+			TaxCode: "0312345678",
+			Address: "12 Quốc lộ 1A, Bình Chánh, TP.HCM",
+			Phone:   "0281234567",
+		},
+		Buyer: Party{
+			Name:    "Công ty Xây dựng An Khang",
+			TaxCode: "0109876543-001",
+			Address: "45 Lê Lợi, Quận 1, TP.HCM",
+		},
+	}
+}
+
+// testInvoice is an invoice with tax-exclusive prices: a 10% line with a promotion and an 8% line
+func testInvoice() *models.Invoice {
+	return &models.Invoice{
+		InvoiceCode: "HD0001",
+		PriceMode:   models.PriceModeExclusive,
+		TotalAmount: 3170000,
+		Items: []*models.InvoiceItem{
+			{
+				ProductName:       "Thép hộp 40x80",
+				VariantName:       "1.4mm",
+				Unit:              "cây",
+				Quantity:          10,
+				UnitPrice:         200000,
+				PromotionDiscount: 100000,
+				TotalPrice:        1900000,
+				TaxRate:           10,
+				TaxableAmount:     1900000,
+				TaxAmount:         190000,
+			},
+			{
+				ProductName:   "Tôn lạnh",
+				Unit:          "m",
+				Quantity:      20,
+				UnitPrice:     50000,
+				TotalPrice:    1000000,
+				TaxRate:       8,
+				TaxableAmount: 1000000,
+				TaxAmount:     80000,
+			},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	out, err := Build(testInvoice(), testHeader())
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	if err := Validate(out); err != nil {
+		t.Fatalf("built e-invoice does not validate: %v", err)
+	}
+
+	var doc HDon
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	general := doc.DLHDon.TTChung
+	if general.KHMSHDon != "1" || general.KHHDon != "C24TKP" || general.SHDon != 42 || general.NLap != "2024-02-28" {
+		t.Fatalf("general information %+v", general)
+	}
+
+	lines := doc.DLHDon.NDHDon.DSHHDVu.HHDVu
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	if lines[0].THHDVu != "Thép hộp 40x80 - 1.4mm" || lines[0].STCKhau != 100000 || lines[0].ThTien != 1900000 || lines[0].TSuat != "10%" {
+		t.Fatalf("first line %+v", lines[0])
+	}
+	if lines[1].STT != 2 || lines[1].TSuat != "8%" {
+		t.Fatalf("second line %+v", lines[1])
+	}
+
+	totals := doc.DLHDon.NDHDon.TToan
+	rates := totals.THTTLTSuat.LTSuat
+	if len(rates) != 2 || rates[0].TSuat != "8%" || rates[0].TThue != 80000 || rates[1].TSuat != "10%" || rates[1].TThue != 190000 {
+		t.Fatalf("VAT breakdown %+v", rates)
+	}
+	if totals.TgTCThue != 2900000 || totals.TgTThue != 270000 || totals.TgTTTBSo != 3170000 {
+		t.Fatalf("totals %+v", totals)
+	}
+	if totals.TgTTTBChu != "Ba triệu một trăm bảy mươi nghìn đồng" {
+		t.Fatalf("amount in words %q", totals.TgTTTBChu)
+	}
+}
+
+func TestBuildInclusivePrices(t *testing.T) {
+	invoice := &models.Invoice{
+		PriceMode:   models.PriceModeInclusive,
+		TotalAmount: 1100000,
+		Items: []*models.InvoiceItem{
+			{
+				ProductName:   "Thép tấm",
+				Unit:          "kg",
+				Quantity:      50,
+				UnitPrice:     22000,
+				TotalPrice:    1100000,
+				TaxRate:       10,
+				TaxableAmount: 1000000,
+				TaxAmount:     100000,
+			},
+		},
+	}
+
+	out, err := Build(invoice, testHeader())
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	var doc HDon
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	// Line amounts are shown before VAT
+	line := doc.DLHDon.NDHDon.DSHHDVu.HHDVu[0]
+	if line.DGia != 20000 || line.ThTien != 1000000 {
+		t.Fatalf("line %+v, want unit price 20000 and amount 1000000", line)
+	}
+	if totals := doc.DLHDon.NDHDon.TToan; totals.TgTCThue != 1000000 || totals.TgTThue != 100000 || totals.TgTTTBSo != 1100000 {
+		t.Fatalf("totals %+v", totals)
+	}
+}
+
+func TestBuildWithoutItems(t *testing.T) {
+	_, err := Build(&models.Invoice{}, testHeader())
+	if err == nil || !strings.Contains(err.Error(), "invoice has no items") {
+		t.Fatalf("got %v, want no items error", err)
+	}
+}
+
+func TestFormatTaxRate(t *testing.T) {
+	tests := map[float64]string{0: "0%", 5: "5%", 8: "8%", 10: "10%", 3.5: "3.5%"}
+	for rate, want := range tests {
+		if got := FormatTaxRate(rate); got != want {
+			t.Errorf("FormatTaxRate(%v) = %q, want %q", rate, got, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package einvoice
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"steel-pos-backend/internal/config"
+)
+
+// Submission is a validated e-invoice ready to be sent to the tax authority
+type Submission struct {
+	InvoiceCode  string
+	TemplateCode string
+	Series       string
+	Number       int
+	XML          []byte
+}
+
+// SubmissionResult is what a provider returns for an accepted e-invoice
+type SubmissionResult struct {
+	Reference  string // provider's identifier of the e-invoice
+	LookupCode string // mã tra cứu given to the buyer
+}
+
+// Provider signs e-invoices and transmits them to the tax authority
+type Provider interface {
+	Name() string
+	Submit(submission *Submission) (*SubmissionResult, error)
+}
+
+// NewProvider creates the provider selected in the configuration
+func NewProvider(cfg config.EInvoiceConfig) (Provider, error) {
+	switch cfg.Provider {
+	case "", "file":
+		return NewFileProvider(cfg.OutputDir), nil
+	default:
+		return nil, fmt.Errorf("unknown e-invoice provider %q", cfg.Provider)
+	}
+}
+
+// FileProvider is a local stand-in for a real provider. It writes each submitted
+// e-invoice to a directory instead of transmitting it.
+type FileProvider struct {
+	dir string
+}
+
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+func (p *FileProvider) Name() string {
+	return "file"
+}
+
+func (p *FileProvider) Submit(submission *Submission) (*SubmissionResult, error) {
+	if err := os.MkdirAll(p.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create e-invoice directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s-%08d.xml", submission.TemplateCode, submission.Series, submission.Number)
+	path := filepath.Join(p.dir, name)
+	if err := os.WriteFile(path, submission.XML, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write e-invoice: %w", err)
+	}
+
+	lookup := make([]byte, 6)
+	if _, err := rand.Read(lookup); err != nil {
+		return nil, err
+	}
+
+	return &SubmissionResult{
+		Reference:  path,
+		LookupCode: strings.ToUpper(hex.EncodeToString(lookup)),
+	}, nil
+}
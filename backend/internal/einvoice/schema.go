@@ -0,0 +1,295 @@
+package einvoice
+
+import (
+	_ "embed"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+//go:embed schema/hdon.xsd
+var invoiceSchema []byte
+
+var (
+	schemaOnce   sync.Once
+	loadedSchema *Schema
+	schemaErr    error
+)
+
+// Schema is a parsed XML schema. Only the subset of XSD used by the bundled invoice
+// schema is supported: nested elements in sequences with minOccurs/maxOccurs, required
+// attributes, and named simple types restricting xs:string, xs:decimal, xs:integer or
+// xs:date with length, pattern and enumeration facets.
+type Schema struct {
+	elements    map[string]*xsdElement
+	simpleTypes map[string]*xsdSimpleType
+	patterns    map[string]*regexp.Regexp
+}
+
+// ValidationError lists every problem found in a document
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "e-invoice does not match schema: " + strings.Join(e.Problems, "; ")
+}
+
+type xsdSchema struct {
+	Elements    []*xsdElement    `xml:"element"`
+	SimpleTypes []*xsdSimpleType `xml:"simpleType"`
+}
+
+type xsdElement struct {
+	Name        string          `xml:"name,attr"`
+	Type        string          `xml:"type,attr"`
+	MinOccurs   string          `xml:"minOccurs,attr"`
+	MaxOccurs   string          `xml:"maxOccurs,attr"`
+	ComplexType *xsdComplexType `xml:"complexType"`
+}
+
+type xsdComplexType struct {
+	Sequence   []*xsdElement  `xml:"sequence>element"`
+	Attributes []xsdAttribute `xml:"attribute"`
+}
+
+type xsdAttribute struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+	Use  string `xml:"use,attr"`
+}
+
+type xsdSimpleType struct {
+	Name        string `xml:"name,attr"`
+	Restriction struct {
+		Base         string     `xml:"base,attr"`
+		MinLength    *xsdFacet  `xml:"minLength"`
+		MaxLength    *xsdFacet  `xml:"maxLength"`
+		Patterns     []xsdFacet `xml:"pattern"`
+		Enumerations []xsdFacet `xml:"enumeration"`
+	} `xml:"restriction"`
+}
+
+type xsdFacet struct {
+	Value string `xml:"value,attr"`
+}
+
+// xmlNode is a generic XML element
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []*xmlNode `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+var (
+	decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+	integerPattern = regexp.MustCompile(`^[+-]?\d+$`)
+)
+
+// LoadSchema parses an XML schema
+func LoadSchema(data []byte) (*Schema, error) {
+	var doc xsdSchema
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	schema := &Schema{
+		elements:    make(map[string]*xsdElement),
+		simpleTypes: make(map[string]*xsdSimpleType),
+		patterns:    make(map[string]*regexp.Regexp),
+	}
+
+	for _, element := range doc.Elements {
+		schema.elements[element.Name] = element
+	}
+
+	for _, simpleType := range doc.SimpleTypes {
+		schema.simpleTypes[simpleType.Name] = simpleType
+		for _, pattern := range simpleType.Restriction.Patterns {
+			// XSD patterns always match the whole value
+			re, err := regexp.Compile("^(?:" + pattern.Value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern in type %s: %w", simpleType.Name, err)
+			}
+			schema.patterns[pattern.Value] = re
+		}
+	}
+
+	return schema, nil
+}
+
+// Validate checks an e-invoice document against the bundled invoice schema
+func Validate(doc []byte) error {
+	schemaOnce.Do(func() {
+		loadedSchema, schemaErr = LoadSchema(invoiceSchema)
+	})
+	if schemaErr != nil {
+		return schemaErr
+	}
+
+	return loadedSchema.Validate(doc)
+}
+
+// Validate checks a document against the schema
+func (s *Schema) Validate(doc []byte) error {
+	var root xmlNode
+	if err := xml.Unmarshal(doc, &root); err != nil {
+		return &ValidationError{Problems: []string{"malformed XML: " + err.Error()}}
+	}
+
+	element, ok := s.elements[root.XMLName.Local]
+	if !ok {
+		return &ValidationError{Problems: []string{"unexpected root element " + root.XMLName.Local}}
+	}
+
+	var problems []string
+	s.validateElement(element, &root, "/"+root.XMLName.Local, &problems)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// Helper methods
+func (s *Schema) validateElement(element *xsdElement, node *xmlNode, path string, problems *[]string) {
+	if element.ComplexType == nil {
+		if len(node.Nodes) > 0 {
+			*problems = append(*problems, path+": unexpected child elements")
+			return
+		}
+		if err := s.checkValue(element.Type, node.Text); err != nil {
+			*problems = append(*problems, path+": "+err.Error())
+		}
+		return
+	}
+
+	for _, attribute := range element.ComplexType.Attributes {
+		value, found := attributeValue(node, attribute.Name)
+		if !found {
+			if attribute.Use == "required" {
+				*problems = append(*problems, path+": missing attribute "+attribute.Name)
+			}
+			continue
+		}
+		if err := s.checkValue(attribute.Type, value); err != nil {
+			*problems = append(*problems, path+"/@"+attribute.Name+": "+err.Error())
+		}
+	}
+
+	if strings.TrimSpace(node.Text) != "" {
+		*problems = append(*problems, path+": unexpected text content")
+	}
+
+	i := 0
+	for _, child := range element.ComplexType.Sequence {
+		minOccurs, maxOccurs := occurs(child)
+
+		count := 0
+		for i < len(node.Nodes) && node.Nodes[i].XMLName.Local == child.Name && (maxOccurs < 0 || count < maxOccurs) {
+			childPath := path + "/" + child.Name
+			if maxOccurs != 1 {
+				childPath += "[" + strconv.Itoa(count+1) + "]"
+			}
+			s.validateElement(child, node.Nodes[i], childPath, problems)
+			count++
+			i++
+		}
+
+		if count < minOccurs {
+			*problems = append(*problems, path+": missing element "+child.Name)
+		}
+	}
+
+	for ; i < len(node.Nodes); i++ {
+		*problems = append(*problems, path+": unexpected element "+node.Nodes[i].XMLName.Local)
+	}
+}
+
+func (s *Schema) checkValue(typeName, value string) error {
+	simpleType, ok := s.simpleTypes[typeName]
+	if !ok {
+		return checkBuiltinValue(typeName, value)
+	}
+
+	restriction := simpleType.Restriction
+	if err := checkBuiltinValue(restriction.Base, value); err != nil {
+		return err
+	}
+
+	length := utf8.RuneCountInString(value)
+	if restriction.MinLength != nil {
+		if min, _ := strconv.Atoi(restriction.MinLength.Value); length < min {
+			return fmt.Errorf("value must be at least %d characters", min)
+		}
+	}
+	if restriction.MaxLength != nil {
+		if max, _ := strconv.Atoi(restriction.MaxLength.Value); length > max {
+			return fmt.Errorf("value must be at most %d characters", max)
+		}
+	}
+
+	for _, pattern := range restriction.Patterns {
+		if !s.patterns[pattern.Value].MatchString(value) {
+			return fmt.Errorf("value %q is not a valid %s", value, simpleType.Name)
+		}
+	}
+
+	if len(restriction.Enumerations) > 0 {
+		for _, enumeration := range restriction.Enumerations {
+			if value == enumeration.Value {
+				return nil
+			}
+		}
+		return fmt.Errorf("value %q is not a valid %s", value, simpleType.Name)
+	}
+
+	return nil
+}
+
+func checkBuiltinValue(typeName, value string) error {
+	switch strings.TrimPrefix(typeName, "xs:") {
+	case "decimal":
+		if !decimalPattern.MatchString(value) {
+			return fmt.Errorf("value %q is not a decimal", value)
+		}
+	case "integer":
+		if !integerPattern.MatchString(value) {
+			return fmt.Errorf("value %q is not an integer", value)
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("value %q is not a date", value)
+		}
+	}
+	return nil
+}
+
+func attributeValue(node *xmlNode, name string) (string, bool) {
+	for _, attr := range node.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// occurs returns the minimum and maximum occurrences of an element, -1 meaning unbounded
+func occurs(element *xsdElement) (int, int) {
+	minOccurs, maxOccurs := 1, 1
+	if element.MinOccurs != "" {
+		minOccurs, _ = strconv.Atoi(element.MinOccurs)
+	}
+	if element.MaxOccurs == "unbounded" {
+		maxOccurs = -1
+	} else if element.MaxOccurs != "" {
+		maxOccurs, _ = strconv.Atoi(element.MaxOccurs)
+	}
+	return minOccurs, maxOccurs
+}
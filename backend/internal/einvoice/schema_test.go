@@ -0,0 +1,157 @@
+package einvoice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid, err := Build(testInvoice(), testHeader())
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	doc := string(valid)
+
+	tests := []struct {
+		name    string
+		old     string // replaced by new in the valid document
+		new     string
+		problem string // empty when the document stays valid
+	}{
+		{name: "valid"},
+		{
+			name: "buyer without tax code",
+			old:  "<MST>0109876543-001</MST>",
+		},
+		{
+			name:    "invalid seller tax code",
+			old:     "<MST>0312345678</MST>",
+			new:     "<MST>031234</MST>",
+			problem: `/HDon/DLHDon/NDHDon/NBan/MST: value "031234" is not a valid TaxCode`,
+		},
+		{
+			name:    "invalid series",
+			old:     "<KHHDon>C24TKP</KHHDon>",
+			new:     "<KHHDon>X24TKP</KHHDon>",
+			problem: "is not a valid Series",
+		},
+		{
+			name:    "invalid date",
+			old:     "<NLap>2024-02-28</NLap>",
+			new:     "<NLap>28/02/2024</NLap>",
+			problem: `value "28/02/2024" is not a date`,
+		},
+		{
+			name:    "invalid number",
+			old:     "<SHDon>42</SHDon>",
+			new:     "<SHDon>4x2</SHDon>",
+			problem: "is not an integer",
+		},
+		{
+			name:    "decimal in exponent notation",
+			old:     "<TgTThue>270000</TgTThue>",
+			new:     "<TgTThue>2.7e+05</TgTThue>",
+			problem: "is not a decimal",
+		},
+		{
+			name:    "invalid tax rate",
+			old:     "<TSuat>8%</TSuat>",
+			new:     "<TSuat>8</TSuat>",
+			problem: "is not a valid TaxRate",
+		},
+		{
+			name:    "invalid item kind",
+			old:     "<TChat>1</TChat>",
+			new:     "<TChat>7</TChat>",
+			problem: "is not a valid ItemKind",
+		},
+		{
+			name:    "empty seller name",
+			old:     "<Ten>Công ty TNHH Thép Minh Phát</Ten>",
+			new:     "<Ten></Ten>",
+			problem: "value must be at least 1 characters",
+		},
+		{
+			name:    "phone too long",
+			old:     "<SDThoai>0281234567</SDThoai>",
+			new:     "<SDThoai>028123456789012345678</SDThoai>",
+			problem: "value must be at most 20 characters",
+		},
+		{
+			name:    "missing element",
+			old:     "<DVTTe>VND</DVTTe>",
+			problem: "/HDon/DLHDon/TTChung: missing element DVTTe",
+		},
+		{
+			name:    "unexpected element",
+			old:     "<DVTTe>VND</DVTTe>",
+			new:     "<DVTTe>VND</DVTTe><Extra>1</Extra>",
+			problem: "unexpected element Extra",
+		},
+		{
+			name:    "missing attribute",
+			old:     `<DLHDon Id="data">`,
+			new:     `<DLHDon>`,
+			problem: "/HDon/DLHDon: missing attribute Id",
+		},
+		{
+			name:    "malformed",
+			old:     "</HDon>",
+			problem: "malformed XML",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := doc
+			if tt.old != "" {
+				if !strings.Contains(doc, tt.old) {
+					t.Fatalf("document does not contain %q", tt.old)
+				}
+				input = strings.Replace(doc, tt.old, tt.new, 1)
+			}
+
+			err := Validate([]byte(input))
+			if tt.problem == "" {
+				if err != nil {
+					t.Fatalf("got %v, want a valid document", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("got %v, want a validation error", err)
+			}
+			if !strings.Contains(err.Error(), tt.problem) {
+				t.Fatalf("got %v, want a problem containing %q", err, tt.problem)
+			}
+		})
+	}
+}
+
+func TestValidateWrongRoot(t *testing.T) {
+	err := Validate([]byte(`<?xml version="1.0" encoding="UTF-8"?><Invoice></Invoice>`))
+	if err == nil || !strings.Contains(err.Error(), "unexpected root element Invoice") {
+		t.Fatalf("got %v, want unexpected root element", err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	valid, err := Build(testInvoice(), testHeader())
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	doc := strings.Replace(string(valid), "<KHHDon>C24TKP</KHHDon>", "<KHHDon>bad</KHHDon>", 1)
+	doc = strings.Replace(doc, "<MST>0312345678</MST>", "<MST>bad</MST>", 1)
+
+	var validationErr *ValidationError
+	if !errors.As(Validate([]byte(doc)), &validationErr) {
+		t.Fatal("invalid document accepted")
+	}
+	if len(validationErr.Problems) != 2 {
+		t.Fatalf("got problems %q, want 2", validationErr.Problems)
+	}
+}
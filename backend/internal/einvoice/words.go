@@ -0,0 +1,89 @@
+package einvoice
+
+import "strings"
+
+var digitWords = []string{"không", "một", "hai", "ba", "bốn", "năm", "sáu", "bảy", "tám", "chín"}
+
+// scaleWords name the groups of three digits, from the lowest
+var scaleWords = []string{"", "nghìn", "triệu", "tỷ", "nghìn tỷ", "triệu tỷ", "tỷ tỷ"}
+
+// AmountInWords spells out an amount of Vietnamese dong, e.g.
+// 1250000 -> "Một triệu hai trăm năm mươi nghìn đồng"
+func AmountInWords(amount int64) string {
+	if amount == 0 {
+		return "Không đồng"
+	}
+
+	negative := amount < 0
+	if negative {
+		amount = -amount
+	}
+
+	var groups []int
+	for amount > 0 {
+		groups = append(groups, int(amount%1000))
+		amount /= 1000
+	}
+
+	var parts []string
+	for i := len(groups) - 1; i >= 0; i-- {
+		if groups[i] == 0 {
+			continue
+		}
+
+		// Inner groups always read their hundreds, e.g. "một nghìn không trăm linh năm"
+		parts = append(parts, readGroup(groups[i], i < len(groups)-1))
+		if scaleWords[i] != "" {
+			parts = append(parts, scaleWords[i])
+		}
+	}
+
+	words := strings.Join(parts, " ")
+	if negative {
+		words = "âm " + words
+	}
+
+	return capitalize(words) + " đồng"
+}
+
+// Helper methods
+
+// readGroup reads a number below one thousand
+func readGroup(n int, full bool) string {
+	hundreds, tens, units := n/100, n/10%10, n%10
+
+	var parts []string
+	if hundreds > 0 || full {
+		parts = append(parts, digitWords[hundreds], "trăm")
+	}
+
+	switch {
+	case tens == 0 && units == 0:
+		return strings.Join(parts, " ")
+	case tens == 0:
+		if len(parts) > 0 {
+			parts = append(parts, "linh")
+		}
+	case tens == 1:
+		parts = append(parts, "mười")
+	default:
+		parts = append(parts, digitWords[tens], "mươi")
+	}
+
+	switch {
+	case units == 0:
+	case units == 1 && tens > 1:
+		parts = append(parts, "mốt")
+	case units == 5 && tens > 0:
+		parts = append(parts, "lăm")
+	default:
+		parts = append(parts, digitWords[units])
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func capitalize(s string) string {
+	r := []rune(s)
+	return strings.ToUpper(string(r[0])) + string(r[1:])
+}
@@ -0,0 +1,39 @@
+package einvoice
+
+import "testing"
+
+func TestAmountInWords(t *testing.T) {
+	tests := []struct {
+		amount int64
+		want   string
+	}{
+		{0, "Không đồng"},
+		{5, "Năm đồng"},
+		{10, "Mười đồng"},
+		{11, "Mười một đồng"},
+		{14, "Mười bốn đồng"},
+		{15, "Mười lăm đồng"},
+		{20, "Hai mươi đồng"},
+		{21, "Hai mươi mốt đồng"},
+		{25, "Hai mươi lăm đồng"},
+		{45, "Bốn mươi lăm đồng"},
+		{101, "Một trăm linh một đồng"},
+		{105, "Một trăm linh năm đồng"},
+		{115, "Một trăm mười lăm đồng"},
+		{1005, "Một nghìn không trăm linh năm đồng"},
+		{10500, "Mười nghìn năm trăm đồng"},
+		{21000, "Hai mươi mốt nghìn đồng"},
+		{1250000, "Một triệu hai trăm năm mươi nghìn đồng"},
+		{3015000, "Ba triệu không trăm mười lăm nghìn đồng"},
+		{2000000000, "Hai tỷ đồng"},
+		{1000000001, "Một tỷ không trăm linh một đồng"},
+		{5021000000, "Năm tỷ không trăm hai mươi mốt triệu đồng"},
+		{-15000, "Âm mười lăm nghìn đồng"},
+	}
+
+	for _, tt := range tests {
+		if got := AmountInWords(tt.amount); got != tt.want {
+			t.Errorf("AmountInWords(%d) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package escpos
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Printer sends ESC/POS data to a network printer over a raw TCP connection,
+// usually port 9100 (JetDirect)
+type Printer struct {
+	address string
+	timeout time.Duration
+}
+
+// NewPrinter returns a printer at address (host:port)
+func NewPrinter(address string, timeout time.Duration) *Printer {
+	return &Printer{
+		address: address,
+		timeout: timeout,
+	}
+}
+
+// Print sends the data and closes the connection. Raw printers do not report print
+// errors, so a nil error only means the printer accepted the data.
+func (p *Printer) Print(data []byte) error {
+	if p.address == "" {
+		return errors.New("no printer address configured")
+	}
+
+	conn, err := net.DialTimeout("tcp", p.address, p.timeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to printer %s: %w", p.address, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(p.timeout)); err != nil {
+		return err
+	}
+
+	if _, err := conn.Write(data); err != nil {
+		return fmt.Errorf("failed to send receipt to printer %s: %w", p.address, err)
+	}
+
+	return nil
+}
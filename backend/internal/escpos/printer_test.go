@@ -0,0 +1,88 @@
+package escpos
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrinterPrintSendsStream(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	// 10x1 image with the first and last dots printed
+	img := image.NewGray(image.Rect(0, 0, 10, 1))
+	for x := 0; x < 10; x++ {
+		img.SetGray(x, 0, color.Gray{Y: 255})
+	}
+	img.SetGray(0, 0, color.Gray{Y: 0})
+	img.SetGray(9, 0, color.Gray{Y: 0})
+
+	b := NewBuilder()
+	b.Image(img)
+	b.Feed(2)
+	b.Cut()
+
+	if err := NewPrinter(listener.Addr().String(), time.Second).Print(b.Bytes()); err != nil {
+		t.Fatalf("Print: %v", err)
+	}
+
+	want := []byte{
+		0x1b, '@', // reset
+		0x1d, 'v', '0', 0, 2, 0, 1, 0, 0x80, 0x40, // raster band of 2 bytes x 1 row
+		0x1b, 'd', 2, // feed 2 lines
+		0x1d, 'V', 66, 0, // cut
+	}
+
+	select {
+	case got := <-received:
+		if !bytes.Equal(got, want) {
+			t.Fatalf("printer received % x, want % x", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("printer received nothing")
+	}
+}
+
+func TestPrinterPrintWithoutAddress(t *testing.T) {
+	err := NewPrinter("", time.Second).Print(NewBuilder().Bytes())
+	if err == nil || !strings.Contains(err.Error(), "no printer address") {
+		t.Fatalf("Print without address: got %v, want no printer address error", err)
+	}
+}
+
+func TestPrinterPrintDialFailure(t *testing.T) {
+	// Take a free port and close it so that nothing listens there
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	err = NewPrinter(address, time.Second).Print(NewBuilder().Bytes())
+	if err == nil || !strings.Contains(err.Error(), "failed to connect to printer "+address) {
+		t.Fatalf("Print to closed port: got %v, want connection error", err)
+	}
+}
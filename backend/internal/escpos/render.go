@@ -0,0 +1,212 @@
+package escpos
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Align is the horizontal alignment of a receipt line
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+const (
+	textSize  = 22 // pixel height of regular text, about 2.8mm at 203 dpi
+	largeSize = 32
+	margin    = 4
+)
+
+// Line is one line of a receipt. Right is printed flush right on the last row of Text.
+// Text that does not fit is wrapped.
+type Line struct {
+	Text      string
+	Right     string
+	Align     Align
+	Bold      bool
+	Large     bool
+	Separator bool // a dashed rule instead of text
+}
+
+// Renderer rasterizes receipt lines to a bitmap as wide as the paper. Font faces cannot be
+// used concurrently, so receipts are rendered one at a time.
+type Renderer struct {
+	mu    sync.Mutex
+	width int
+	faces map[bool]map[bool]font.Face // bold, large
+}
+
+// NewRenderer loads the regular and bold TrueType fonts used to draw receipts
+// on paper that is width dots wide (576 for 80mm printers at 203 dpi)
+func NewRenderer(regularFontPath, boldFontPath string, width int) (*Renderer, error) {
+	r := &Renderer{
+		width: width,
+		faces: map[bool]map[bool]font.Face{},
+	}
+
+	for bold, path := range map[bool]string{false: regularFontPath, true: boldFontPath} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read receipt font: %w", err)
+		}
+
+		parsed, err := opentype.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse receipt font %s: %w", path, err)
+		}
+
+		r.faces[bold] = map[bool]font.Face{}
+		for large, size := range map[bool]float64{false: textSize, true: largeSize} {
+			face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+			if err != nil {
+				return nil, err
+			}
+			r.faces[bold][large] = face
+		}
+	}
+
+	return r, nil
+}
+
+// Render draws the lines black on white, one below the other
+func (r *Renderer) Render(lines []Line) *image.Gray {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type row struct {
+		text, right string
+		align       Align
+		face        font.Face
+		separator   bool
+	}
+
+	var rows []row
+	height := 0
+	for _, line := range lines {
+		face := r.faces[line.Bold][line.Large]
+		lineHeight := face.Metrics().Height.Ceil()
+
+		if line.Separator {
+			rows = append(rows, row{face: face, separator: true})
+			height += lineHeight
+			continue
+		}
+
+		available := r.width - 2*margin
+		if line.Right != "" {
+			// The last row leaves room for the right-hand text
+			available -= font.MeasureString(face, line.Right+"  ").Ceil()
+		}
+
+		wrapped := wrap(face, line.Text, r.width-2*margin, available)
+		for i, text := range wrapped {
+			current := row{text: text, align: line.Align, face: face}
+			if i == len(wrapped)-1 {
+				current.right = line.Right
+			}
+			rows = append(rows, current)
+			height += lineHeight
+		}
+	}
+
+	img := image.NewGray(image.Rect(0, 0, r.width, height+2*margin))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	y := margin
+	for _, current := range rows {
+		metrics := current.face.Metrics()
+		baseline := y + metrics.Ascent.Ceil()
+
+		if current.separator {
+			middle := y + metrics.Height.Ceil()/2
+			for x := margin; x < r.width-margin; x++ {
+				if (x/6)%2 == 0 {
+					img.SetGray(x, middle, color.Gray{Y: 0})
+				}
+			}
+			y += metrics.Height.Ceil()
+			continue
+		}
+
+		drawer := &font.Drawer{Dst: img, Src: image.Black, Face: current.face}
+
+		textWidth := drawer.MeasureString(current.text).Ceil()
+		x := margin
+		switch current.align {
+		case AlignCenter:
+			x = (r.width - textWidth) / 2
+		case AlignRight:
+			x = r.width - margin - textWidth
+		}
+		drawer.Dot = fixed.P(x, baseline)
+		drawer.DrawString(current.text)
+
+		if current.right != "" {
+			drawer.Dot = fixed.P(r.width-margin-drawer.MeasureString(current.right).Ceil(), baseline)
+			drawer.DrawString(current.right)
+		}
+
+		y += metrics.Height.Ceil()
+	}
+
+	return img
+}
+
+// wrap splits text into rows at most width wide, the last one at most lastWidth wide
+func wrap(face font.Face, text string, width, lastWidth int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var rows []string
+	current := ""
+	for _, word := range words {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+
+		if font.MeasureString(face, candidate).Ceil() <= width {
+			current = candidate
+			continue
+		}
+
+		if current != "" {
+			rows = append(rows, current)
+		}
+		current = word
+
+		// Break words longer than a whole row
+		for font.MeasureString(face, current).Ceil() > width {
+			runes := []rune(current)
+			cut := len(runes) - 1
+			for cut > 1 && font.MeasureString(face, string(runes[:cut])).Ceil() > width {
+				cut--
+			}
+			rows = append(rows, string(runes[:cut]))
+			current = string(runes[cut:])
+		}
+	}
+
+	// Give the right-hand text a row of its own when it does not fit beside the last row
+	if font.MeasureString(face, current).Ceil() > lastWidth {
+		rows = append(rows, current)
+		current = ""
+	}
+	rows = append(rows, current)
+
+	return rows
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"strconv"
+
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// GetAPIKeys gets every API key with its permissions and last use
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.GetAPIKeys()
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, keys, "API keys retrieved successfully")
+}
+
+// GetAPIKey gets an API key by ID
+func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+
+	key, err := h.apiKeyService.GetAPIKey(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, key, "API key retrieved successfully")
+}
+
+// CreateAPIKey creates an API key; the key is only returned in this response
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	key, err := h.apiKeyService.CreateAPIKey(&req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, key, "API key created successfully")
+}
+
+// UpdateAPIKey updates the name, permissions and expiry of an API key
+func (h *APIKeyHandler) UpdateAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+
+	var req models.UpdateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	key, err := h.apiKeyService.UpdateAPIKey(id, &req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, key, "API key updated successfully")
+}
+
+// RevokeAPIKey revokes an API key
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	if err := h.apiKeyService.RevokeAPIKey(id, userID, userName); err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, nil, "API key revoked successfully")
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
 
@@ -32,15 +34,85 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.Login(&req)
+	response, err := h.authService.Login(&req, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		h.writeLoginError(c, err)
+		return
+	}
+
+	// Khi response có two_factor, client phải gọi /auth/login/2fa để lấy tokens
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// LoginTwoFactor hoàn tất đăng nhập bằng mã 2FA hoặc mã khôi phục
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.authService.LoginTwoFactor(&req, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		h.writeLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// BeginTwoFactorSetupLogin tạo secret 2FA trong lúc đăng nhập, khi vai trò bắt buộc 2FA
+func (h *AuthHandler) BeginTwoFactorSetupLogin(c *gin.Context) {
+	var req models.TwoFactorSetupLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	setup, err := h.authService.BeginTwoFactorSetupLogin(req.ChallengeToken)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Login failed",
+			"error":   "Two-factor setup failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    setup,
+	})
+}
+
+// EnableTwoFactorLogin xác nhận cài đặt 2FA trong lúc đăng nhập và trả về tokens cùng mã khôi phục
+func (h *AuthHandler) EnableTwoFactorLogin(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
 			"message": err.Error(),
 		})
 		return
 	}
 
+	response, err := h.authService.EnableTwoFactorLogin(&req, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		h.writeLoginError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    response,
@@ -59,6 +131,13 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	}
 
 	response, err := h.authService.RefreshToken(req.RefreshToken)
+	if errors.Is(err, services.ErrRefreshTokenExchanged) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Token refresh failed",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error":   "Token refresh failed",
@@ -73,8 +152,33 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	})
 }
 
-// ChangePassword xử lý đổi mật khẩu
-func (h *AuthHandler) ChangePassword(c *gin.Context) {
+// Logout thu hồi refresh token của phiên đăng nhập hiện tại
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := h.authService.Logout(req.RefreshToken); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Logout failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Logged out successfully",
+	})
+}
+
+// LogoutAll thu hồi refresh token của tất cả phiên đăng nhập của user hiện tại
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
 	userID, exists := middleware.GetCurrentUserID(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{
@@ -84,7 +188,214 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	var req models.ChangePasswordRequest
+	if err := h.authService.LogoutAll(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Logout failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Logged out of all sessions successfully",
+	})
+}
+
+// GetMySessions lấy danh sách phiên đăng nhập của user hiện tại
+func (h *AuthHandler) GetMySessions(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Authentication required",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	sessionID, _ := middleware.GetCurrentSessionID(c)
+	h.writeSessions(c, userID, sessionID)
+}
+
+// RevokeMySession đăng xuất một thiết bị của user hiện tại
+func (h *AuthHandler) RevokeMySession(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Authentication required",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	h.revokeSession(c, userID, c.Param("sessionId"))
+}
+
+// GetUserSessions lấy danh sách phiên đăng nhập của một user (chỉ admin)
+func (h *AuthHandler) GetUserSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a number",
+		})
+		return
+	}
+
+	sessionID, _ := middleware.GetCurrentSessionID(c)
+	h.writeSessions(c, userID, sessionID)
+}
+
+// RevokeUserSession đăng xuất một thiết bị của một user (chỉ admin)
+func (h *AuthHandler) RevokeUserSession(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a number",
+		})
+		return
+	}
+
+	h.revokeSession(c, userID, c.Param("sessionId"))
+}
+
+// RevokeAllUserSessions đăng xuất một user khỏi mọi thiết bị (chỉ admin)
+func (h *AuthHandler) RevokeAllUserSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a number",
+		})
+		return
+	}
+
+	if err := h.authService.LogoutAll(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Session revocation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "All sessions revoked successfully",
+	})
+}
+
+// UnlockUser mở khoá đăng nhập của user bị khoá do sai mật khẩu nhiều lần
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a number",
+		})
+		return
+	}
+
+	adminID, _ := middleware.GetCurrentUserID(c)
+	adminName, _ := middleware.GetCurrentUsername(c)
+
+	if err := h.authService.UnlockUser(userID, adminID, adminName); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Unlock failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "User unlocked successfully",
+	})
+}
+
+// UnlockIP mở khoá đăng nhập từ một địa chỉ IP bị khoá do sai mật khẩu nhiều lần
+func (h *AuthHandler) UnlockIP(c *gin.Context) {
+	adminID, _ := middleware.GetCurrentUserID(c)
+	adminName, _ := middleware.GetCurrentUsername(c)
+
+	if err := h.authService.UnlockIP(c.Param("ip"), adminID, adminName); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Unlock failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "IP address unlocked successfully",
+	})
+}
+
+// GetTwoFactorStatus lấy trạng thái 2FA của user hiện tại
+func (h *AuthHandler) GetTwoFactorStatus(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Authentication required",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	status, err := h.authService.GetTwoFactorStatus(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get two-factor status",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// BeginTwoFactorSetup tạo secret và mã QR để thêm vào ứng dụng xác thực
+func (h *AuthHandler) BeginTwoFactorSetup(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Authentication required",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	setup, err := h.authService.BeginTwoFactorSetup(userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Two-factor setup failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    setup,
+	})
+}
+
+// EnableTwoFactor bật 2FA và trả về mã khôi phục (chỉ hiển thị một lần)
+func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Authentication required",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
@@ -93,10 +404,10 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	err := h.authService.ChangePassword(userID, &req)
+	recoveryCodes, err := h.authService.EnableTwoFactor(userID, req.Code)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Password change failed",
+			"error":   "Enabling two-factor authentication failed",
 			"message": err.Error(),
 		})
 		return
@@ -104,7 +415,384 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Password changed successfully",
+		"data":    models.RecoveryCodesResponse{RecoveryCodes: recoveryCodes},
+	})
+}
+
+// DisableTwoFactor tắt 2FA của user hiện tại
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Authentication required",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	var req models.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := h.authService.DisableTwoFactor(userID, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Disabling two-factor authentication failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Two-factor authentication disabled successfully",
+	})
+}
+
+// RegenerateRecoveryCodes tạo lại mã khôi phục 2FA
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Authentication required",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	recoveryCodes, err := h.authService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Regenerating recovery codes failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    models.RecoveryCodesResponse{RecoveryCodes: recoveryCodes},
+	})
+}
+
+// ResetUserTwoFactor xoá 2FA của một user bị mất ứng dụng xác thực và đăng xuất user đó
+func (h *AuthHandler) ResetUserTwoFactor(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a number",
+		})
+		return
+	}
+
+	adminID, _ := middleware.GetCurrentUserID(c)
+	adminName, _ := middleware.GetCurrentUsername(c)
+
+	if err := h.authService.ResetTwoFactor(userID, adminID, adminName); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Two-factor reset failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Two-factor authentication reset successfully",
+	})
+}
+
+// SetPin đặt mã PIN chuyển user của user hiện tại
+func (h *AuthHandler) SetPin(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Authentication required",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SetPinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := h.authService.SetPin(userID, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Setting PIN failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "PIN set successfully",
+	})
+}
+
+// RemovePin xoá mã PIN của user hiện tại
+func (h *AuthHandler) RemovePin(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Authentication required",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.authService.RemovePin(userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Removing PIN failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "PIN removed successfully",
+	})
+}
+
+// ResetUserPin xoá mã PIN của một user, ví dụ khi PIN bị lộ
+func (h *AuthHandler) ResetUserPin(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a number",
+		})
+		return
+	}
+
+	adminID, _ := middleware.GetCurrentUserID(c)
+	adminName, _ := middleware.GetCurrentUsername(c)
+
+	if err := h.authService.ResetUserPin(userID, adminID, adminName); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "PIN reset failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "PIN reset successfully",
+	})
+}
+
+// SwitchUser chuyển user đang làm việc trên máy dùng chung bằng username và PIN.
+// Response giống đăng nhập, client thay tokens cũ bằng tokens mới.
+func (h *AuthHandler) SwitchUser(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Authentication required",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	sessionID, _ := middleware.GetCurrentSessionID(c)
+
+	var req models.SwitchUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.authService.SwitchUser(sessionID, userID, &req, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			h.writeLoginError(c, err)
+			return
+		}
+
+		// Không trả 401 để client không hiểu nhầm là phiên hiện tại đã hết hạn
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Switching user failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// writeLoginError trả 429 kèm Retry-After khi đăng nhập sai quá nhiều lần, còn lại trả 401
+func (h *AuthHandler) writeLoginError(c *gin.Context, err error) {
+	var throttled *services.LoginThrottledError
+	if errors.As(err, &throttled) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":   "Too many failed logins",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error":   "Login failed",
+		"message": err.Error(),
+	})
+}
+
+func (h *AuthHandler) writeSessions(c *gin.Context, userID int, currentSessionID string) {
+	sessions, err := h.authService.GetSessions(userID, currentSessionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Failed to get sessions",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sessions,
+	})
+}
+
+func (h *AuthHandler) revokeSession(c *gin.Context, userID int, sessionID string) {
+	if err := h.authService.RevokeSession(userID, sessionID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Session revocation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Session revoked successfully",
+	})
+}
+
+// ChangePassword xử lý đổi mật khẩu
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Authentication required",
+			"message": "User not authenticated",
+		})
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	sessionID, _ := middleware.GetCurrentSessionID(c)
+
+	err := h.authService.ChangePassword(userID, sessionID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Password change failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password changed successfully",
+	})
+}
+
+// GetPasswordPolicy lấy chính sách mật khẩu để hiển thị khi đặt mật khẩu
+func (h *AuthHandler) GetPasswordPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    h.authService.GetPasswordPolicy(),
+	})
+}
+
+// IssuePasswordReset tạo token đặt lại mật khẩu dùng một lần cho user quên mật khẩu
+func (h *AuthHandler) IssuePasswordReset(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a number",
+		})
+		return
+	}
+
+	adminID, _ := middleware.GetCurrentUserID(c)
+	adminName, _ := middleware.GetCurrentUsername(c)
+
+	reset, err := h.authService.IssuePasswordReset(userID, adminID, adminName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Password reset failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    reset,
+	})
+}
+
+// ResetPassword đặt mật khẩu mới bằng token do admin cấp
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := h.authService.ResetPassword(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Password reset failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password reset successfully, please log in with the new password",
 	})
 }
 
@@ -155,7 +843,7 @@ func (h *AuthHandler) GetUserByID(c *gin.Context) {
 		return
 	}
 
-	user, err := h.authService.GetUserByID(userID)
+	user, err := h.authService.GetCurrentUser(userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "User not found",
@@ -297,7 +985,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	user, err := h.authService.GetUserByID(userID)
+	user, err := h.authService.GetCurrentUser(userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "User not found",
@@ -312,7 +1000,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	})
 }
 
-// WhoAmI verify token và lấy thông tin user hiện tại
+// WhoAmI verify token và lấy thông tin user hiện tại kèm các quyền của vai trò
 func (h *AuthHandler) WhoAmI(c *gin.Context) {
 	userID, exists := middleware.GetCurrentUserID(c)
 	if !exists {
@@ -323,7 +1011,7 @@ func (h *AuthHandler) WhoAmI(c *gin.Context) {
 		return
 	}
 
-	user, err := h.authService.GetUserByID(userID)
+	user, err := h.authService.GetCurrentUser(userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "User not found",
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"strconv"
+
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxStatementSize limits uploaded bank statement files to 10 MB
+const maxStatementSize = 10 << 20
+
+type BankStatementHandler struct {
+	reconciliationService *services.BankReconciliationService
+}
+
+func NewBankStatementHandler(reconciliationService *services.BankReconciliationService) *BankStatementHandler {
+	return &BankStatementHandler{
+		reconciliationService: reconciliationService,
+	}
+}
+
+// UploadStatement imports a CSV or Excel bank statement sent as the "file" form field
+func (h *BankStatementHandler) UploadStatement(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "Statement file is required")
+		return
+	}
+
+	if fileHeader.Size > maxStatementSize {
+		response.BadRequest(c, "Statement file must not exceed 10 MB")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.BadRequest(c, "Could not read statement file")
+		return
+	}
+	defer file.Close()
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	statement, err := h.reconciliationService.ImportStatement(fileHeader.Filename, file, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, statement, "Bank statement imported successfully")
+}
+
+// GetStatements gets uploaded bank statements with pagination
+func (h *BankStatementHandler) GetStatements(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	result, err := h.reconciliationService.GetStatements(page, limit)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, result, "Bank statements retrieved successfully")
+}
+
+// GetStatement gets a bank statement with its transactions
+func (h *BankStatementHandler) GetStatement(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid bank statement ID")
+		return
+	}
+
+	statement, err := h.reconciliationService.GetStatement(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, statement, "Bank statement retrieved successfully")
+}
+
+// GetTransactions gets bank transactions, filtered by statement_id and status
+func (h *BankStatementHandler) GetTransactions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	statementID, _ := strconv.Atoi(c.Query("statement_id"))
+	status := c.Query("status")
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	result, err := h.reconciliationService.GetTransactions(page, limit, statementID, status)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, result, "Bank transactions retrieved successfully")
+}
+
+// ConfirmTransaction records a bank transaction as a payment of its matched invoice
+func (h *BankStatementHandler) ConfirmTransaction(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid bank transaction ID")
+		return
+	}
+
+	var req models.ConfirmBankTransactionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BindingError(c, err)
+			return
+		}
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	transaction, err := h.reconciliationService.ConfirmTransaction(id, &req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, transaction, "Bank transaction confirmed successfully")
+}
+
+// IgnoreTransaction marks a bank transaction that does not pay an invoice
+func (h *BankStatementHandler) IgnoreTransaction(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid bank transaction ID")
+		return
+	}
+
+	transaction, err := h.reconciliationService.IgnoreTransaction(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, transaction, "Bank transaction ignored successfully")
+}
+
+// RestoreTransaction brings an ignored bank transaction back to reconciliation
+func (h *BankStatementHandler) RestoreTransaction(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid bank transaction ID")
+		return
+	}
+
+	transaction, err := h.reconciliationService.RestoreTransaction(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, transaction, "Bank transaction restored successfully")
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BranchHandler struct {
+	branchService *services.BranchService
+}
+
+func NewBranchHandler(branchService *services.BranchService) *BranchHandler {
+	return &BranchHandler{
+		branchService: branchService,
+	}
+}
+
+// GetBranches gets every branch, or only the active ones with active=true
+func (h *BranchHandler) GetBranches(c *gin.Context) {
+	activeOnly := c.Query("active") == "true"
+
+	branches, err := h.branchService.GetBranches(activeOnly)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, branches, "Branches retrieved successfully")
+}
+
+// GetBranch gets a branch by ID
+func (h *BranchHandler) GetBranch(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid branch ID")
+		return
+	}
+
+	branch, err := h.branchService.GetBranch(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, branch, "Branch retrieved successfully")
+}
+
+func (h *BranchHandler) CreateBranch(c *gin.Context) {
+	var req models.CreateBranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	branch, err := h.branchService.CreateBranch(&req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, branch, "Branch created successfully")
+}
+
+func (h *BranchHandler) UpdateBranch(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid branch ID")
+		return
+	}
+
+	var req models.UpdateBranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	branch, err := h.branchService.UpdateBranch(id, &req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, branch, "Branch updated successfully")
+}
+
+// GetMyBranches gets the branches the current user can work at and the branch the request acted on
+func (h *BranchHandler) GetMyBranches(c *gin.Context) {
+	branches, exists := middleware.GetCurrentBranches(c)
+	if !exists {
+		response.Success(c, &models.UserBranches{Branches: []*models.Branch{}}, "Branches retrieved successfully")
+		return
+	}
+
+	response.Success(c, branches, "Branches retrieved successfully")
+}
+
+// GetUserBranches gets the branches a user is assigned to
+func (h *BranchHandler) GetUserBranches(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	branches, err := h.branchService.GetUserBranches(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, branches, "User branches retrieved successfully")
+}
+
+// AssignUserBranches replaces the branches a user is assigned to
+func (h *BranchHandler) AssignUserBranches(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	var req models.UpdateUserBranchesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	branches, err := h.branchService.AssignUserBranches(id, &req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, branches, "User branches updated successfully")
+}
+
+// GetConsolidatedReport compares every branch over a date range (YYYY-MM-DD, both inclusive)
+func (h *BranchHandler) GetConsolidatedReport(c *gin.Context) {
+	from, err := time.ParseInLocation("2006-01-02", c.Query("date_from"), time.Local)
+	if err != nil {
+		response.BadRequest(c, "Invalid date_from, expected YYYY-MM-DD")
+		return
+	}
+
+	to, err := time.ParseInLocation("2006-01-02", c.Query("date_to"), time.Local)
+	if err != nil {
+		response.BadRequest(c, "Invalid date_to, expected YYYY-MM-DD")
+		return
+	}
+
+	report, err := h.branchService.GetConsolidatedReport(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, report, "Consolidated branch report retrieved successfully")
+}
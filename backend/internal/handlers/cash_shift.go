@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CashShiftHandler struct {
+	cashShiftService *services.CashShiftService
+	pdfService       *services.PDFService
+}
+
+func NewCashShiftHandler(cashShiftService *services.CashShiftService, pdfService *services.PDFService) *CashShiftHandler {
+	return &CashShiftHandler{
+		cashShiftService: cashShiftService,
+		pdfService:       pdfService,
+	}
+}
+
+// ShiftBranchID gets the branch of a shift for RequireBranchAccess
+func (h *CashShiftHandler) ShiftBranchID(id int) (int, error) {
+	return h.cashShiftService.GetShiftBranchID(id)
+}
+
+// OpenShift opens a cash drawer shift for the current user at the current branch
+func (h *CashShiftHandler) OpenShift(c *gin.Context) {
+	var req models.OpenCashShiftRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BindingError(c, err)
+			return
+		}
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+	branchID, _ := middleware.GetCurrentBranchID(c)
+
+	shift, err := h.cashShiftService.OpenShift(&req, userID, userName, branchID)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, shift, "Cash shift opened successfully")
+}
+
+// GetCurrentShift gets the open shift of the current user
+func (h *CashShiftHandler) GetCurrentShift(c *gin.Context) {
+	userID, _ := middleware.GetCurrentUserID(c)
+
+	shift, err := h.cashShiftService.GetCurrentShift(userID)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	if shift == nil {
+		response.NotFound(c, "No open cash shift")
+		return
+	}
+
+	response.Success(c, shift, "Cash shift retrieved successfully")
+}
+
+// AddMovement records cash in or cash out of the drawer during a shift
+func (h *CashShiftHandler) AddMovement(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid cash shift ID")
+		return
+	}
+
+	var req models.CreateCashMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	movement, err := h.cashShiftService.AddMovement(id, &req, userID, userName, isManager(c))
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, movement, "Cash movement recorded successfully")
+}
+
+// CloseShift closes a shift with the cash counted in the drawer
+func (h *CashShiftHandler) CloseShift(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid cash shift ID")
+		return
+	}
+
+	var req models.CloseCashShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	shift, err := h.cashShiftService.CloseShift(id, &req, userID, userName, isManager(c))
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, shift, "Cash shift closed successfully")
+}
+
+// GetShifts gets cash shifts of the current branch with pagination, filtered by cashier_id and status
+func (h *CashShiftHandler) GetShifts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	cashierID, _ := strconv.Atoi(c.Query("cashier_id"))
+	status := c.Query("status")
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	branchID, _ := middleware.GetCurrentBranchID(c)
+
+	result, err := h.cashShiftService.GetShifts(page, limit, branchID, cashierID, status)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, result, "Cash shifts retrieved successfully")
+}
+
+// GetShift gets a cash shift with its cash movements
+func (h *CashShiftHandler) GetShift(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid cash shift ID")
+		return
+	}
+
+	shift, err := h.cashShiftService.GetShift(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, shift, "Cash shift retrieved successfully")
+}
+
+// GetShiftZReport gets the Z report of a shift
+func (h *CashShiftHandler) GetShiftZReport(c *gin.Context) {
+	report, ok := h.shiftZReport(c)
+	if !ok {
+		return
+	}
+
+	response.Success(c, report, "Z report retrieved successfully")
+}
+
+// PrintShiftZReport renders the Z report of a shift as PDF
+func (h *CashShiftHandler) PrintShiftZReport(c *gin.Context) {
+	report, ok := h.shiftZReport(c)
+	if !ok {
+		return
+	}
+
+	h.writeZReportPDF(c, report, "z-report-shift-"+c.Param("id"))
+}
+
+// GetDailyZReport gets the end-of-day Z report of the current branch, or of branch_id, for the day
+// given as date=YYYY-MM-DD, today by default
+func (h *CashShiftHandler) GetDailyZReport(c *gin.Context) {
+	report, ok := h.dailyZReport(c)
+	if !ok {
+		return
+	}
+
+	response.Success(c, report, "Z report retrieved successfully")
+}
+
+// PrintDailyZReport renders the end-of-day Z report as PDF
+func (h *CashShiftHandler) PrintDailyZReport(c *gin.Context) {
+	report, ok := h.dailyZReport(c)
+	if !ok {
+		return
+	}
+
+	h.writeZReportPDF(c, report, "z-report-"+report.From.Format("2006-01-02"))
+}
+
+// Helper methods
+func (h *CashShiftHandler) shiftZReport(c *gin.Context) (*models.ZReport, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid cash shift ID")
+		return nil, false
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+
+	report, err := h.cashShiftService.GetShiftZReport(id, userID, isManager(c))
+	if err != nil {
+		response.ServiceError(c, err)
+		return nil, false
+	}
+
+	return report, true
+}
+
+func (h *CashShiftHandler) dailyZReport(c *gin.Context) (*models.ZReport, bool) {
+	date := time.Now()
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
+		if err != nil {
+			response.BadRequest(c, "Invalid date format, expected YYYY-MM-DD")
+			return nil, false
+		}
+		date = parsed
+	}
+
+	// Signed URLs of the PDF carry the branch as branch_id since they cannot send X-Branch-ID
+	branchID, _ := middleware.GetCurrentBranchID(c)
+	if branchStr := c.Query("branch_id"); branchStr != "" {
+		parsed, err := strconv.Atoi(branchStr)
+		if err != nil || !middleware.CanAccessBranch(c, parsed) {
+			response.BadRequest(c, "Invalid branch_id")
+			return nil, false
+		}
+		branchID = parsed
+	}
+
+	report, err := h.cashShiftService.GetDailyZReport(date, branchID)
+	if err != nil {
+		response.ServiceError(c, err)
+		return nil, false
+	}
+
+	return report, true
+}
+
+func (h *CashShiftHandler) writeZReportPDF(c *gin.Context, report *models.ZReport, fileName string) {
+	pdfBytes, err := h.pdfService.GenerateZReportPDF(report)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "inline; filename="+fileName+".pdf")
+	c.Header("Content-Length", strconv.Itoa(len(pdfBytes)))
+
+	// Add CORS headers for iframe access
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+	c.Data(200, "application/pdf", pdfBytes)
+}
+
+// isManager reports whether the current user may act on other cashiers' shifts
+func isManager(c *gin.Context) bool {
+	return middleware.HasPermission(c, models.PermissionShiftManage)
+}
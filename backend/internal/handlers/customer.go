@@ -36,6 +36,7 @@ func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
 		Name:      req.Name,
 		Phone:     req.Phone,
 		Address:   req.Address,
+		TaxCode:   req.TaxCode,
 		IsActive:  true,
 		CreatedBy: &userID,
 	}
@@ -150,6 +151,9 @@ func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
 		"phone":   req.Phone,
 		"address": req.Address,
 	}
+	if req.TaxCode != nil {
+		updateData["tax_code"] = req.TaxCode
+	}
 	
 	customer, err := h.customerService.UpdateCustomer(id, updateData, userID)
 	if err != nil {
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"strconv"
+
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CustomerDepositHandler struct {
+	depositService *services.CustomerDepositService
+}
+
+func NewCustomerDepositHandler(depositService *services.CustomerDepositService) *CustomerDepositHandler {
+	return &CustomerDepositHandler{
+		depositService: depositService,
+	}
+}
+
+// ReceiveDeposit records an advance payment of a customer
+func (h *CustomerDepositHandler) ReceiveDeposit(c *gin.Context) {
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid customer ID")
+		return
+	}
+
+	var req models.CreateCustomerDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	deposit, err := h.depositService.ReceiveDeposit(customerID, &req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, deposit, "Deposit received successfully")
+}
+
+// GetCustomerDeposits gets the deposits and deposit balance of a customer
+func (h *CustomerDepositHandler) GetCustomerDeposits(c *gin.Context) {
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid customer ID")
+		return
+	}
+
+	deposits, err := h.depositService.GetDeposits(customerID)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, deposits, "Customer deposits retrieved successfully")
+}
+
+// ApplyDeposits pays an invoice of the customer from the customer's deposits
+func (h *CustomerDepositHandler) ApplyDeposits(c *gin.Context) {
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid customer ID")
+		return
+	}
+
+	var req models.ApplyCustomerDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	movements, err := h.depositService.ApplyDeposits(customerID, &req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, movements, "Deposits applied successfully")
+}
+
+// GetCustomerLedger gets the invoices, payments and deposits of a customer
+func (h *CustomerDepositHandler) GetCustomerLedger(c *gin.Context) {
+	customerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid customer ID")
+		return
+	}
+
+	ledger, err := h.depositService.GetCustomerLedger(customerID)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, ledger, "Customer ledger retrieved successfully")
+}
+
+// GetDeposit gets a deposit with its applications and refunds
+func (h *CustomerDepositHandler) GetDeposit(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid deposit ID")
+		return
+	}
+
+	deposit, err := h.depositService.GetDeposit(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, deposit, "Deposit retrieved successfully")
+}
+
+// RefundDeposit pays back what is left of a deposit
+func (h *CustomerDepositHandler) RefundDeposit(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid deposit ID")
+		return
+	}
+
+	var req models.RefundCustomerDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	movement, err := h.depositService.RefundDeposit(id, &req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, movement, "Deposit refunded successfully")
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"strconv"
+
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EInvoiceHandler struct {
+	einvoiceService *services.EInvoiceService
+}
+
+func NewEInvoiceHandler(einvoiceService *services.EInvoiceService) *EInvoiceHandler {
+	return &EInvoiceHandler{
+		einvoiceService: einvoiceService,
+	}
+}
+
+// GenerateEInvoice renders and validates the e-invoice of a confirmed invoice
+func (h *EInvoiceHandler) GenerateEInvoice(c *gin.Context) {
+	idStr := c.Param("id")
+	invoiceID, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid invoice ID")
+		return
+	}
+
+	var req models.GenerateEInvoiceRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BindingError(c, err)
+			return
+		}
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	einvoice, err := h.einvoiceService.GenerateEInvoice(invoiceID, &req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, einvoice, "E-invoice generated successfully")
+}
+
+// GetInvoiceEInvoice gets the e-invoice of an invoice
+func (h *EInvoiceHandler) GetInvoiceEInvoice(c *gin.Context) {
+	idStr := c.Param("id")
+	invoiceID, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid invoice ID")
+		return
+	}
+
+	einvoice, err := h.einvoiceService.GetEInvoiceByInvoiceID(invoiceID)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, einvoice, "E-invoice retrieved successfully")
+}
+
+// GetEInvoiceXML downloads the XML document of an e-invoice
+func (h *EInvoiceHandler) GetEInvoiceXML(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid e-invoice ID")
+		return
+	}
+
+	einvoice, err := h.einvoiceService.GetEInvoiceByID(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	filename := "einvoice-" + einvoice.Series + "-" + strconv.Itoa(einvoice.InvoiceNumber) + ".xml"
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(200, "application/xml; charset=utf-8", []byte(einvoice.XMLContent))
+}
+
+// SubmitEInvoice submits an e-invoice through the configured provider
+func (h *EInvoiceHandler) SubmitEInvoice(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid e-invoice ID")
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	einvoice, err := h.einvoiceService.SubmitEInvoice(id, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, einvoice, "E-invoice submitted successfully")
+}
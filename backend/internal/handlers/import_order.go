@@ -21,7 +21,12 @@ func NewImportOrderHandler(importOrderService *services.ImportOrderService) *Imp
 	}
 }
 
-// CreateImportOrder creates a new import order
+// ImportOrderBranchID gets the branch of an import order for RequireBranchAccess
+func (h *ImportOrderHandler) ImportOrderBranchID(id int) (int, error) {
+	return h.importOrderService.GetImportOrderBranchID(id)
+}
+
+// CreateImportOrder creates a new import order at the current branch
 func (h *ImportOrderHandler) CreateImportOrder(c *gin.Context) {
 	var req models.CreateImportOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -30,7 +35,8 @@ func (h *ImportOrderHandler) CreateImportOrder(c *gin.Context) {
 	}
 
 	userID, _ := middleware.GetCurrentUserID(c)
-	importOrder, err := h.importOrderService.CreateImportOrder(&req, userID)
+	branchID, _ := middleware.GetCurrentBranchID(c)
+	importOrder, err := h.importOrderService.CreateImportOrder(&req, userID, branchID)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
@@ -77,7 +83,9 @@ func (h *ImportOrderHandler) GetAllImportOrders(c *gin.Context) {
 		limit = 10
 	}
 
-	result, err := h.importOrderService.GetAllImportOrders(page, limit, status, supplierName, search)
+	branchID, _ := middleware.GetCurrentBranchID(c)
+
+	result, err := h.importOrderService.GetAllImportOrders(page, limit, branchID, status, supplierName, search)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
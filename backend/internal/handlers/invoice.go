@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"strconv"
+	"time"
 
 	"steel-pos-backend/internal/middleware"
 	"steel-pos-backend/internal/models"
@@ -12,17 +13,31 @@ import (
 )
 
 type InvoiceHandler struct {
-	invoiceService *services.InvoiceService
-	pdfService     *services.PDFService
+	invoiceService   *services.InvoiceService
+	pdfService       *services.PDFService
+	paymentQRService *services.PaymentQRService
+	receiptService   *services.ReceiptService
 }
 
-func NewInvoiceHandler(invoiceService *services.InvoiceService, pdfService *services.PDFService) *InvoiceHandler {
+func NewInvoiceHandler(invoiceService *services.InvoiceService, pdfService *services.PDFService, paymentQRService *services.PaymentQRService, receiptService *services.ReceiptService) *InvoiceHandler {
 	return &InvoiceHandler{
-		invoiceService: invoiceService,
-		pdfService:     pdfService,
+		invoiceService:   invoiceService,
+		pdfService:       pdfService,
+		paymentQRService: paymentQRService,
+		receiptService:   receiptService,
 	}
 }
 
+// InvoiceBranchID gets the branch of an invoice for RequireBranchAccess
+func (h *InvoiceHandler) InvoiceBranchID(id int) (int, error) {
+	return h.invoiceService.GetInvoiceBranchID(id)
+}
+
+// PaymentBranchID gets the branch of the invoice of a payment for RequireBranchAccess
+func (h *InvoiceHandler) PaymentBranchID(paymentID int) (int, error) {
+	return h.invoiceService.GetPaymentBranchID(paymentID)
+}
+
 // Invoice endpoints
 func (h *InvoiceHandler) CreateInvoice(c *gin.Context) {
 	var req models.CreateInvoiceRequest
@@ -33,8 +48,10 @@ func (h *InvoiceHandler) CreateInvoice(c *gin.Context) {
 
 	userID, _ := middleware.GetCurrentUserID(c)
 	username, _ := middleware.GetCurrentUsername(c)
+	role, _ := middleware.GetCurrentUserRole(c)
+	branchID, _ := middleware.GetCurrentBranchID(c)
 
-	invoice, err := h.invoiceService.CreateInvoice(&req, userID, username)
+	invoice, err := h.invoiceService.CreateInvoice(&req, userID, username, role, branchID)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
@@ -78,7 +95,7 @@ func (h *InvoiceHandler) GetInvoiceByCode(c *gin.Context) {
 		return
 	}
 
-	if invoice == nil {
+	if invoice == nil || !middleware.CanAccessBranch(c, invoice.BranchID) {
 		response.NotFound(c, "Invoice not found")
 		return
 	}
@@ -100,7 +117,9 @@ func (h *InvoiceHandler) GetAllInvoices(c *gin.Context) {
 		limit = 10
 	}
 
-	result, err := h.invoiceService.GetAllInvoices(page, limit, search, status, paymentStatus)
+	branchID, _ := middleware.GetCurrentBranchID(c)
+
+	result, err := h.invoiceService.GetAllInvoices(page, limit, branchID, search, status, paymentStatus)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
@@ -125,8 +144,9 @@ func (h *InvoiceHandler) UpdateInvoice(c *gin.Context) {
 
 	userID, _ := middleware.GetCurrentUserID(c)
 	username, _ := middleware.GetCurrentUsername(c)
+	role, _ := middleware.GetCurrentUserRole(c)
 
-	invoice, err := h.invoiceService.UpdateInvoice(id, &req, userID, username)
+	invoice, err := h.invoiceService.UpdateInvoice(id, &req, userID, username, role)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
@@ -143,7 +163,10 @@ func (h *InvoiceHandler) DeleteInvoice(c *gin.Context) {
 		return
 	}
 
-	err = h.invoiceService.DeleteInvoice(id)
+	userID, _ := middleware.GetCurrentUserID(c)
+	username, _ := middleware.GetCurrentUsername(c)
+
+	err = h.invoiceService.DeleteInvoice(id, userID, username)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
@@ -178,7 +201,8 @@ func (h *InvoiceHandler) CreateInvoicePayment(c *gin.Context) {
 	response.Created(c, payment, "Payment created successfully")
 }
 
-func (h *InvoiceHandler) UpdateInvoicePayment(c *gin.Context) {
+// CorrectInvoicePayment corrects a payment with a reversal and a replacement payment
+func (h *InvoiceHandler) CorrectInvoicePayment(c *gin.Context) {
 	paymentIDStr := c.Param("paymentId")
 	paymentID, err := strconv.Atoi(paymentIDStr)
 	if err != nil {
@@ -193,17 +217,19 @@ func (h *InvoiceHandler) UpdateInvoicePayment(c *gin.Context) {
 	}
 
 	userID, _ := middleware.GetCurrentUserID(c)
+	username, _ := middleware.GetCurrentUsername(c)
 
-	payment, err := h.invoiceService.UpdateInvoicePayment(paymentID, &req, userID)
+	result, err := h.invoiceService.CorrectInvoicePayment(paymentID, &req, userID, username)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
 	}
 
-	response.Success(c, payment, "Payment updated successfully")
+	response.Success(c, result, "Payment corrected successfully")
 }
 
-func (h *InvoiceHandler) DeleteInvoicePayment(c *gin.Context) {
+// ReverseInvoicePayment cancels a payment with a reversal record
+func (h *InvoiceHandler) ReverseInvoicePayment(c *gin.Context) {
 	paymentIDStr := c.Param("paymentId")
 	paymentID, err := strconv.Atoi(paymentIDStr)
 	if err != nil {
@@ -211,18 +237,54 @@ func (h *InvoiceHandler) DeleteInvoicePayment(c *gin.Context) {
 		return
 	}
 
-	err = h.invoiceService.DeleteInvoicePayment(paymentID)
+	var req models.ReverseInvoicePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	username, _ := middleware.GetCurrentUsername(c)
+
+	result, err := h.invoiceService.ReverseInvoicePayment(paymentID, &req, userID, username)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
 	}
 
-	response.Success(c, nil, "Payment deleted successfully")
+	response.Success(c, result, "Payment reversed successfully")
+}
+
+// GetPaymentCorrectionReport gets the payment corrections over a date range (YYYY-MM-DD, both inclusive)
+func (h *InvoiceHandler) GetPaymentCorrectionReport(c *gin.Context) {
+	from, err := time.ParseInLocation("2006-01-02", c.Query("date_from"), time.Local)
+	if err != nil {
+		response.BadRequest(c, "Invalid date_from, expected YYYY-MM-DD")
+		return
+	}
+
+	to, err := time.ParseInLocation("2006-01-02", c.Query("date_to"), time.Local)
+	if err != nil {
+		response.BadRequest(c, "Invalid date_to, expected YYYY-MM-DD")
+		return
+	}
+
+	branchID, _ := middleware.GetCurrentBranchID(c)
+
+	report, err := h.invoiceService.GetPaymentCorrectionReport(from, to.AddDate(0, 0, 1), branchID)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, report, "Payment correction report retrieved successfully")
 }
 
 // Dashboard/Summary endpoints
 func (h *InvoiceHandler) GetInvoiceSummary(c *gin.Context) {
-	summary, err := h.invoiceService.GetInvoiceSummary()
+	branchID, _ := middleware.GetCurrentBranchID(c)
+
+	summary, err := h.invoiceService.GetInvoiceSummary(branchID)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
@@ -249,7 +311,9 @@ func (h *InvoiceHandler) SearchInvoices(c *gin.Context) {
 		limit = 10
 	}
 
-	result, err := h.invoiceService.GetAllInvoices(page, limit, query, "", "")
+	branchID, _ := middleware.GetCurrentBranchID(c)
+
+	result, err := h.invoiceService.GetAllInvoices(page, limit, branchID, query, "", "")
 	if err != nil {
 		response.ServiceError(c, err)
 		return
@@ -288,8 +352,15 @@ func (h *InvoiceHandler) PrintInvoice(c *gin.Context) {
 		return
 	}
 
+	// Print template, the store default when not given
+	template := c.Query("template")
+	if template != "" && !models.IsValidInvoiceTemplate(template) {
+		response.BadRequest(c, "Invalid invoice template")
+		return
+	}
+
 	// Generate PDF
-	pdfBytes, err := h.pdfService.GenerateInvoicePDF(invoice)
+	pdfBytes, err := h.pdfService.GenerateInvoicePDF(invoice, template)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
@@ -309,6 +380,128 @@ func (h *InvoiceHandler) PrintInvoice(c *gin.Context) {
 	c.Data(200, "application/pdf", pdfBytes)
 }
 
+// GetInvoiceReceipt returns the ESC/POS byte stream of an 80mm thermal receipt for an invoice,
+// for print agents that forward it to a locally attached printer
+func (h *InvoiceHandler) GetInvoiceReceipt(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid invoice ID")
+		return
+	}
+
+	invoice, err := h.invoiceService.GetInvoiceByID(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	if invoice == nil {
+		response.NotFound(c, "Invoice not found")
+		return
+	}
+
+	receipt, err := h.receiptService.GenerateInvoiceReceipt(invoice)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=receipt-"+invoice.InvoiceCode+".bin")
+	c.Data(200, "application/octet-stream", receipt)
+}
+
+// PrintInvoiceReceipt sends the thermal receipt of an invoice to the configured counter printer
+func (h *InvoiceHandler) PrintInvoiceReceipt(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid invoice ID")
+		return
+	}
+
+	invoice, err := h.invoiceService.GetInvoiceByID(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	if invoice == nil {
+		response.NotFound(c, "Invoice not found")
+		return
+	}
+
+	if err := h.receiptService.PrintInvoiceReceipt(invoice); err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, nil, "Receipt sent to printer")
+}
+
+// GetInvoicePaymentQR gets the VietQR payload for the amount still due on an invoice
+func (h *InvoiceHandler) GetInvoicePaymentQR(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid invoice ID")
+		return
+	}
+
+	invoice, err := h.invoiceService.GetInvoiceByID(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	if invoice == nil {
+		response.NotFound(c, "Invoice not found")
+		return
+	}
+
+	qr, err := h.paymentQRService.GetInvoicePaymentQR(invoice)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, qr, "Payment QR retrieved successfully")
+}
+
+// GetInvoicePaymentQRImage renders the VietQR code of an invoice as a PNG for the POS screen
+func (h *InvoiceHandler) GetInvoicePaymentQRImage(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid invoice ID")
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", "320"))
+	if err != nil || size < 128 || size > 1024 {
+		response.BadRequest(c, "Invalid size, expected 128 to 1024 pixels")
+		return
+	}
+
+	invoice, err := h.invoiceService.GetInvoiceByID(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	if invoice == nil {
+		response.NotFound(c, "Invoice not found")
+		return
+	}
+
+	png, err := h.paymentQRService.GetInvoicePaymentQRImage(invoice, size)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(200, "image/png", png)
+}
+
 // GetInvoiceAuditLogs gets audit logs for a specific invoice
 func (h *InvoiceHandler) GetInvoiceAuditLogs(c *gin.Context) {
 	idStr := c.Param("id")
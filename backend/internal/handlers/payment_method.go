@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentMethodHandler struct {
+	paymentMethodService *services.PaymentMethodService
+}
+
+func NewPaymentMethodHandler(paymentMethodService *services.PaymentMethodService) *PaymentMethodHandler {
+	return &PaymentMethodHandler{
+		paymentMethodService: paymentMethodService,
+	}
+}
+
+// CreatePaymentMethod creates a new payment method
+func (h *PaymentMethodHandler) CreatePaymentMethod(c *gin.Context) {
+	var req models.CreatePaymentMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	method, err := h.paymentMethodService.CreatePaymentMethod(&req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, method, "Payment method created successfully")
+}
+
+// GetPaymentMethods gets all payment methods, only the accepted ones with active=true
+func (h *PaymentMethodHandler) GetPaymentMethods(c *gin.Context) {
+	activeOnly := c.Query("active") == "true"
+
+	methods, err := h.paymentMethodService.GetPaymentMethods(activeOnly)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, methods, "Payment methods retrieved successfully")
+}
+
+// GetPaymentMethod gets a payment method by ID
+func (h *PaymentMethodHandler) GetPaymentMethod(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid payment method ID")
+		return
+	}
+
+	method, err := h.paymentMethodService.GetPaymentMethod(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, method, "Payment method retrieved successfully")
+}
+
+// UpdatePaymentMethod updates the settings of a payment method
+func (h *PaymentMethodHandler) UpdatePaymentMethod(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid payment method ID")
+		return
+	}
+
+	var req models.UpdatePaymentMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	method, err := h.paymentMethodService.UpdatePaymentMethod(id, &req)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, method, "Payment method updated successfully")
+}
+
+// GetCollectionReport gets the payments collected per payment method over a date range (YYYY-MM-DD, both inclusive)
+func (h *PaymentMethodHandler) GetCollectionReport(c *gin.Context) {
+	from, err := time.ParseInLocation("2006-01-02", c.Query("date_from"), time.Local)
+	if err != nil {
+		response.BadRequest(c, "Invalid date_from, expected YYYY-MM-DD")
+		return
+	}
+
+	to, err := time.ParseInLocation("2006-01-02", c.Query("date_to"), time.Local)
+	if err != nil {
+		response.BadRequest(c, "Invalid date_to, expected YYYY-MM-DD")
+		return
+	}
+
+	report, err := h.paymentMethodService.GetCollectionReport(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, report, "Collection report retrieved successfully")
+}
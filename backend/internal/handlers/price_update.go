@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"strconv"
+
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PriceUpdateHandler struct {
+	priceUpdateService *services.PriceUpdateService
+}
+
+func NewPriceUpdateHandler(priceUpdateService *services.PriceUpdateService) *PriceUpdateHandler {
+	return &PriceUpdateHandler{
+		priceUpdateService: priceUpdateService,
+	}
+}
+
+// PreviewPriceUpdate previews a bulk price update without applying it
+func (h *PriceUpdateHandler) PreviewPriceUpdate(c *gin.Context) {
+	var req models.BulkPriceUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	preview, err := h.priceUpdateService.PreviewPriceUpdate(&req)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, preview, "Price update preview generated successfully")
+}
+
+// ApplyPriceUpdate applies a bulk price update as one batch
+func (h *PriceUpdateHandler) ApplyPriceUpdate(c *gin.Context) {
+	var req models.BulkPriceUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	batch, err := h.priceUpdateService.ApplyPriceUpdate(&req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, batch, "Price update applied successfully")
+}
+
+// GetPriceUpdateBatches gets all price update batches with pagination
+func (h *PriceUpdateHandler) GetPriceUpdateBatches(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	result, err := h.priceUpdateService.GetPriceUpdateBatches(page, limit)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, result, "Price update batches retrieved successfully")
+}
+
+// GetPriceUpdateBatch gets a price update batch with its price changes
+func (h *PriceUpdateHandler) GetPriceUpdateBatch(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid price update batch ID")
+		return
+	}
+
+	batch, err := h.priceUpdateService.GetPriceUpdateBatch(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, batch, "Price update batch retrieved successfully")
+}
+
+// RollbackPriceUpdate rolls back the prices changed by a batch
+func (h *PriceUpdateHandler) RollbackPriceUpdate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid price update batch ID")
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	result, err := h.priceUpdateService.RollbackPriceUpdate(id, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, result, "Price update rolled back successfully")
+}
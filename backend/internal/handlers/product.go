@@ -32,8 +32,9 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 
 	userID, _ := middleware.GetCurrentUserID(c)
 	userName, _ := middleware.GetCurrentUsername(c)
+	branchID, _ := middleware.GetCurrentBranchID(c)
 
-	product, err := h.productService.CreateProduct(&req, userID, userName)
+	product, err := h.productService.CreateProduct(&req, userID, userName, branchID)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
@@ -103,7 +104,9 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	}
 
 	userID, _ := middleware.GetCurrentUserID(c)
-	product, err := h.productService.UpdateProduct(id, &req, userID)
+	userName, _ := middleware.GetCurrentUsername(c)
+	branchID, _ := middleware.GetCurrentBranchID(c)
+	product, err := h.productService.UpdateProduct(id, &req, userID, userName, branchID)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
@@ -146,7 +149,8 @@ func (h *ProductHandler) CreateVariant(c *gin.Context) {
 	}
 
 	userID, _ := middleware.GetCurrentUserID(c)
-	variant, err := h.productService.CreateVariant(productID, &req, userID)
+	branchID, _ := middleware.GetCurrentBranchID(c)
+	variant, err := h.productService.CreateVariant(productID, &req, userID, branchID)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
@@ -212,7 +216,9 @@ func (h *ProductHandler) UpdateVariant(c *gin.Context) {
 	}
 
 	userID, _ := middleware.GetCurrentUserID(c)
-	variant, err := h.productService.UpdateVariant(id, &req, userID)
+	userName, _ := middleware.GetCurrentUsername(c)
+	branchID, _ := middleware.GetCurrentBranchID(c)
+	variant, err := h.productService.UpdateVariant(id, &req, userID, userName, branchID)
 	if err != nil {
 		response.ServiceError(c, err)
 		return
@@ -239,6 +245,122 @@ func (h *ProductHandler) DeleteVariant(c *gin.Context) {
 	response.Success(c, nil, "Product variant deleted successfully")
 }
 
+// GetVariantBranchStock gets the stock of a variant at each branch
+func (h *ProductHandler) GetVariantBranchStock(c *gin.Context) {
+	idStr := c.Param("variantId")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid variant ID")
+		return
+	}
+
+	stock, err := h.productService.GetVariantBranchStock(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, stock, "Branch stock retrieved successfully")
+}
+
+// GetVariantPriceHistory gets the price change history of a variant
+func (h *ProductHandler) GetVariantPriceHistory(c *gin.Context) {
+	idStr := c.Param("variantId")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid variant ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	result, err := h.productService.GetVariantPriceHistory(id, page, limit)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, result, "Price history retrieved successfully")
+}
+
+// SchedulePriceChange schedules a future price change for a variant
+func (h *ProductHandler) SchedulePriceChange(c *gin.Context) {
+	idStr := c.Param("variantId")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid variant ID")
+		return
+	}
+
+	var req models.CreateScheduledPriceChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	change, err := h.productService.SchedulePriceChange(id, &req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, change, "Price change scheduled successfully")
+}
+
+// GetScheduledPriceChanges gets scheduled price changes of a variant
+func (h *ProductHandler) GetScheduledPriceChanges(c *gin.Context) {
+	idStr := c.Param("variantId")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid variant ID")
+		return
+	}
+
+	changes, err := h.productService.GetScheduledPriceChanges(id, c.Query("status"))
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, changes, "Scheduled price changes retrieved successfully")
+}
+
+// CancelScheduledPriceChange cancels a pending scheduled price change
+func (h *ProductHandler) CancelScheduledPriceChange(c *gin.Context) {
+	idStr := c.Param("variantId")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid variant ID")
+		return
+	}
+
+	changeIDStr := c.Param("changeId")
+	changeID, err := strconv.Atoi(changeIDStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid scheduled price change ID")
+		return
+	}
+
+	err = h.productService.CancelScheduledPriceChange(id, changeID)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, nil, "Scheduled price change cancelled successfully")
+}
+
 // SearchProducts searches products using hybrid approach
 func (h *ProductHandler) SearchProducts(c *gin.Context) {
 	query := c.Query("q")
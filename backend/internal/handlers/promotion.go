@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"strconv"
+
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionHandler struct {
+	promotionService *services.PromotionService
+}
+
+func NewPromotionHandler(promotionService *services.PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+	}
+}
+
+// CreatePromotion creates a new promotion
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var req models.CreatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	promotion, err := h.promotionService.CreatePromotion(&req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, promotion, "Promotion created successfully")
+}
+
+// GetPromotions gets all promotions with pagination
+func (h *PromotionHandler) GetPromotions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	activeOnly := c.Query("active") == "true"
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	result, err := h.promotionService.GetPromotions(page, limit, activeOnly)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, result, "Promotions retrieved successfully")
+}
+
+// GetPromotion gets a promotion by ID
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid promotion ID")
+		return
+	}
+
+	promotion, err := h.promotionService.GetPromotion(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, promotion, "Promotion retrieved successfully")
+}
+
+// UpdatePromotion updates a promotion
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid promotion ID")
+		return
+	}
+
+	var req models.UpdatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	promotion, err := h.promotionService.UpdatePromotion(id, &req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, promotion, "Promotion updated successfully")
+}
+
+// DeletePromotion deactivates a promotion
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid promotion ID")
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	err = h.promotionService.DeletePromotion(id, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, nil, "Promotion deleted successfully")
+}
+
+// GetDiscountRoleCaps gets the manual discount cap of every role
+func (h *PromotionHandler) GetDiscountRoleCaps(c *gin.Context) {
+	caps, err := h.promotionService.GetDiscountRoleCaps()
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, caps, "Discount role caps retrieved successfully")
+}
+
+// SetDiscountRoleCap sets the manual discount cap of a role
+func (h *PromotionHandler) SetDiscountRoleCap(c *gin.Context) {
+	role := c.Param("role")
+
+	var req models.UpdateDiscountRoleCapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	roleCap, err := h.promotionService.SetDiscountRoleCap(role, &req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, roleCap, "Discount role cap updated successfully")
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	roleService *services.RoleService
+}
+
+func NewRoleHandler(roleService *services.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
+
+// GetPermissions gets the catalog of permissions that can be granted to roles
+func (h *RoleHandler) GetPermissions(c *gin.Context) {
+	response.Success(c, h.roleService.GetPermissionCatalog(), "Permissions retrieved successfully")
+}
+
+// GetRoles gets all roles with their permissions
+func (h *RoleHandler) GetRoles(c *gin.Context) {
+	roles, err := h.roleService.GetRoles()
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, roles, "Roles retrieved successfully")
+}
+
+// GetRole gets a role by name
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, err := h.roleService.GetRole(c.Param("name"))
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, role, "Role retrieved successfully")
+}
+
+// CreateRole creates a role with a bundle of permissions
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req models.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	role, err := h.roleService.CreateRole(&req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, role, "Role created successfully")
+}
+
+// UpdateRole updates the description and permissions of a role
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	role, err := h.roleService.UpdateRole(c.Param("name"), &req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, role, "Role updated successfully")
+}
+
+// DeleteRole deletes a role no user is assigned to
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	if err := h.roleService.DeleteRole(c.Param("name"), userID, userName); err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, nil, "Role deleted successfully")
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SignedURLHandler struct {
+	signedURLService *services.SignedURLService
+}
+
+func NewSignedURLHandler(signedURLService *services.SignedURLService) *SignedURLHandler {
+	return &SignedURLHandler{
+		signedURLService: signedURLService,
+	}
+}
+
+// CreateSignedURL issues a short-lived URL for a printable document that can be opened
+// in an iframe or a new tab without sending the access token
+func (h *SignedURLHandler) CreateSignedURL(c *gin.Context) {
+	var req models.CreateSignedURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	sessionID, _ := middleware.GetCurrentSessionID(c)
+	userID, _ := middleware.GetCurrentUserID(c)
+
+	signedURL, err := h.signedURLService.SignURL(req.Path, sessionID, userID)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Created(c, signedURL, "Signed URL created successfully")
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"io"
+
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxLogoSize limits uploaded store logos to 1 MB
+const maxLogoSize = 1 << 20
+
+type StoreSettingsHandler struct {
+	storeSettingsService *services.StoreSettingsService
+}
+
+func NewStoreSettingsHandler(storeSettingsService *services.StoreSettingsService) *StoreSettingsHandler {
+	return &StoreSettingsHandler{
+		storeSettingsService: storeSettingsService,
+	}
+}
+
+// GetSettings gets the store profile
+func (h *StoreSettingsHandler) GetSettings(c *gin.Context) {
+	settings, err := h.storeSettingsService.GetSettings()
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, settings, "Store settings retrieved successfully")
+}
+
+// UpdateSettings updates the store profile
+func (h *StoreSettingsHandler) UpdateSettings(c *gin.Context) {
+	var req models.UpdateStoreSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	settings, err := h.storeSettingsService.UpdateSettings(&req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, settings, "Store settings updated successfully")
+}
+
+// GetLogo returns the logo image of the store
+func (h *StoreSettingsHandler) GetLogo(c *gin.Context) {
+	logo, err := h.storeSettingsService.GetLogo()
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	if logo == nil {
+		response.NotFound(c, "Store has no logo")
+		return
+	}
+
+	c.Data(200, logo.ContentType, logo.Data)
+}
+
+// UploadLogo replaces the store logo with the PNG or JPEG image sent as the "file" form field
+func (h *StoreSettingsHandler) UploadLogo(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "Logo file is required")
+		return
+	}
+
+	if fileHeader.Size > maxLogoSize {
+		response.BadRequest(c, "Logo file must not exceed 1 MB")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.BadRequest(c, "Could not read logo file")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.BadRequest(c, "Could not read logo file")
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	if err := h.storeSettingsService.UploadLogo(data, userID, userName); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, nil, "Store logo uploaded successfully")
+}
+
+// DeleteLogo removes the store logo
+func (h *StoreSettingsHandler) DeleteLogo(c *gin.Context) {
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	if err := h.storeSettingsService.DeleteLogo(userID, userName); err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, nil, "Store logo removed successfully")
+}
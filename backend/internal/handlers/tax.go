@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TaxHandler struct {
+	taxService *services.TaxService
+}
+
+func NewTaxHandler(taxService *services.TaxService) *TaxHandler {
+	return &TaxHandler{
+		taxService: taxService,
+	}
+}
+
+// CreateTaxCategory creates a new tax category
+func (h *TaxHandler) CreateTaxCategory(c *gin.Context) {
+	var req models.CreateTaxCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	category, err := h.taxService.CreateTaxCategory(&req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, category, "Tax category created successfully")
+}
+
+// GetTaxCategories gets all tax categories with their current rate
+func (h *TaxHandler) GetTaxCategories(c *gin.Context) {
+	activeOnly := c.Query("active") == "true"
+
+	categories, err := h.taxService.GetTaxCategories(activeOnly)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, categories, "Tax categories retrieved successfully")
+}
+
+// GetTaxCategory gets a tax category with its rate history
+func (h *TaxHandler) GetTaxCategory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid tax category ID")
+		return
+	}
+
+	category, err := h.taxService.GetTaxCategory(id)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, category, "Tax category retrieved successfully")
+}
+
+// UpdateTaxCategory updates a tax category
+func (h *TaxHandler) UpdateTaxCategory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid tax category ID")
+		return
+	}
+
+	var req models.UpdateTaxCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	category, err := h.taxService.UpdateTaxCategory(id, &req)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, category, "Tax category updated successfully")
+}
+
+// AddTaxRate adds an effective-dated rate to a tax category
+func (h *TaxHandler) AddTaxRate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid tax category ID")
+		return
+	}
+
+	var req models.CreateTaxRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindingError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	userName, _ := middleware.GetCurrentUsername(c)
+
+	category, err := h.taxService.AddTaxRate(id, &req, userID, userName)
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Created(c, category, "Tax rate added successfully")
+}
+
+// GetVATReport gets the VAT collected per rate over a date range (YYYY-MM-DD, both inclusive)
+func (h *TaxHandler) GetVATReport(c *gin.Context) {
+	from, err := time.ParseInLocation("2006-01-02", c.Query("date_from"), time.Local)
+	if err != nil {
+		response.BadRequest(c, "Invalid date_from, expected YYYY-MM-DD")
+		return
+	}
+
+	to, err := time.ParseInLocation("2006-01-02", c.Query("date_to"), time.Local)
+	if err != nil {
+		response.BadRequest(c, "Invalid date_to, expected YYYY-MM-DD")
+		return
+	}
+
+	report, err := h.taxService.GetVATReport(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		response.ServiceError(c, err)
+		return
+	}
+
+	response.Success(c, report, "VAT report retrieved successfully")
+}
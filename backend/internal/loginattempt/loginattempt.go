@@ -0,0 +1,92 @@
+// Package loginattempt counts failed logins per username and per IP address, so that repeated
+// guessing is slowed down and eventually locked out.
+package loginattempt
+
+import (
+	"fmt"
+	"time"
+
+	"steel-pos-backend/internal/config"
+)
+
+// pendingTimeout is how long an attempt started with Begin counts as in progress when it is
+// neither recorded as a failure nor ended
+const pendingTimeout = 30 * time.Second
+
+// Attempts is the failed login record of a username or IP address
+type Attempts struct {
+	Key           string      // "user:<username>" or "ip:<address>"
+	Failures      int         // failures since the counter was last reset
+	Pending       []time.Time // start of the attempts in progress
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// Policy is how failures of a key slow down and lock out further attempts
+type Policy struct {
+	Window      time.Duration // how long failures are remembered, and how long a lock lasts
+	BackoffBase time.Duration // delay required after the first failure, doubled after each further one
+	BackoffMax  time.Duration // longest delay required between attempts
+	MaxFailures int           // failures that lock the key
+}
+
+// Delay is the wait required after the given number of consecutive failures
+func (p Policy) Delay(failures int) time.Duration {
+	delay := p.BackoffBase
+	for i := 1; i < failures && delay < p.BackoffMax; i++ {
+		delay *= 2
+	}
+
+	if delay > p.BackoffMax {
+		delay = p.BackoffMax
+	}
+	return delay
+}
+
+// Refusal is why an attempt may not be made yet
+type Refusal struct {
+	RetryAfter time.Duration
+	Locked     bool // locked out after too many failures, rather than waiting between attempts
+}
+
+// Store persists failed login counters
+type Store interface {
+	// Get gets the record of a key, nil when it has no failures
+	Get(key string) (*Attempts, error)
+	// Begin checks whether a login may be attempted for a key at the given time and, when it
+	// may, counts the attempt as in progress, in one step, so that concurrent attempts cannot
+	// all pass the check before any of them fails. Attempts in progress count towards the
+	// limit of the policy. It returns nil when the attempt may go ahead.
+	Begin(key string, at time.Time, policy Policy) (*Refusal, error)
+	// End ends an attempt in progress that did not fail
+	End(key string) error
+	// RecordFailure ends an attempt in progress as a failure at the given time, locks the key
+	// once it reaches the limit of the policy and returns the updated record. Failures older
+	// than the window of the policy are forgotten and counting starts again.
+	RecordFailure(key string, at time.Time, policy Policy) (*Attempts, error)
+	// Reset clears the failures, attempts in progress and any lock of a key
+	Reset(key string) error
+	// DeleteExpired removes records whose last failure and lock both ended before the given
+	// time and returns how many were removed
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+// NewStore creates the store selected in the configuration
+func NewStore(cfg config.LoginConfig) (Store, error) {
+	switch cfg.AttemptStore {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown login attempt store %q", cfg.AttemptStore)
+	}
+}
+
+// UserKey is the key failures of a username are counted under
+func UserKey(username string) string {
+	return "user:" + username
+}
+
+// IPKey is the key failures from an IP address are counted under
+func IPKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
@@ -0,0 +1,141 @@
+package loginattempt
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps failed login counters in memory. They are lost on restart and not shared
+// between instances, which is acceptable for a single server.
+type MemoryStore struct {
+	mu       sync.Mutex
+	attempts map[string]*Attempts
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		attempts: map[string]*Attempts{},
+	}
+}
+
+func (s *MemoryStore) Get(key string) (*Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts, ok := s.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+
+	return attempts.copy(), nil
+}
+
+func (s *MemoryStore) Begin(key string, at time.Time, policy Policy) (*Refusal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := s.current(key, at, policy.Window)
+
+	if attempts.LockedUntil != nil && at.Before(*attempts.LockedUntil) {
+		return &Refusal{RetryAfter: attempts.LockedUntil.Sub(at), Locked: true}, nil
+	}
+
+	if attempts.Failures > 0 {
+		if retryAt := attempts.LastFailureAt.Add(policy.Delay(attempts.Failures)); at.Before(retryAt) {
+			return &Refusal{RetryAfter: retryAt.Sub(at)}, nil
+		}
+	}
+
+	// Attempts in progress may all fail, so together with the failures they stay under the limit
+	if attempts.Failures+len(attempts.Pending) >= policy.MaxFailures {
+		return &Refusal{RetryAfter: policy.BackoffBase}, nil
+	}
+
+	attempts.Pending = append(attempts.Pending, at)
+	return nil, nil
+}
+
+func (s *MemoryStore) End(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attempts, ok := s.attempts[key]; ok && len(attempts.Pending) > 0 {
+		attempts.Pending = attempts.Pending[1:]
+	}
+	return nil
+}
+
+func (s *MemoryStore) RecordFailure(key string, at time.Time, policy Policy) (*Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := s.current(key, at, policy.Window)
+	if len(attempts.Pending) > 0 {
+		attempts.Pending = attempts.Pending[1:]
+	}
+
+	attempts.Failures++
+	attempts.LastFailureAt = at
+
+	if attempts.Failures >= policy.MaxFailures {
+		lockedUntil := at.Add(policy.Window)
+		attempts.LockedUntil = &lockedUntil
+	}
+
+	return attempts.copy(), nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+func (s *MemoryStore) DeleteExpired(before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for key, attempts := range s.attempts {
+		if attempts.LastFailureAt.Before(before) && (attempts.LockedUntil == nil || attempts.LockedUntil.Before(before)) &&
+			(len(attempts.Pending) == 0 || attempts.Pending[len(attempts.Pending)-1].Before(before)) {
+			delete(s.attempts, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// current gets the record of a key for an attempt at the given time, creating it when there is
+// none. Failures older than the window and attempts in progress that timed out are forgotten.
+func (s *MemoryStore) current(key string, at time.Time, window time.Duration) *Attempts {
+	attempts, ok := s.attempts[key]
+	if !ok {
+		attempts = &Attempts{Key: key}
+		s.attempts[key] = attempts
+	}
+
+	if attempts.Failures > 0 && attempts.LastFailureAt.Before(at.Add(-window)) {
+		attempts.Failures = 0
+	}
+
+	pending := []time.Time{}
+	for _, started := range attempts.Pending {
+		if started.After(at.Add(-pendingTimeout)) {
+			pending = append(pending, started)
+		}
+	}
+	attempts.Pending = pending
+
+	return attempts
+}
+
+// copy copies a record so that it can be returned from the store
+func (a *Attempts) copy() *Attempts {
+	found := *a
+	found.Pending = append([]time.Time(nil), a.Pending...)
+	return &found
+}
@@ -0,0 +1,153 @@
+package loginattempt
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func testPolicy() Policy {
+	return Policy{Window: 15 * time.Minute, BackoffBase: time.Second, BackoffMax: time.Minute, MaxFailures: 3}
+}
+
+func TestPolicyDelay(t *testing.T) {
+	policy := testPolicy()
+	tests := map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		6:  32 * time.Second,
+		7:  time.Minute,
+		20: time.Minute,
+	}
+
+	for failures, want := range tests {
+		if got := policy.Delay(failures); got != want {
+			t.Errorf("Delay(%d) = %s, want %s", failures, got, want)
+		}
+	}
+}
+
+func TestBeginCountsAttemptsInProgress(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+
+	// Attempts in progress may all fail, so no more than the limit are let through
+	for i := 0; i < 3; i++ {
+		if refusal, err := store.Begin("user:an", now, testPolicy()); err != nil || refusal != nil {
+			t.Fatalf("attempt %d refused: %v %v", i+1, refusal, err)
+		}
+	}
+	if refusal, _ := store.Begin("user:an", now, testPolicy()); refusal == nil {
+		t.Fatal("attempt over the limit let through")
+	}
+
+	// An attempt that did not fail makes room for another
+	if err := store.End("user:an"); err != nil {
+		t.Fatalf("End: %v", err)
+	}
+	if refusal, _ := store.Begin("user:an", now, testPolicy()); refusal != nil {
+		t.Fatalf("attempt refused after one ended: %+v", refusal)
+	}
+}
+
+func TestBeginConcurrent(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	admitted := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if refusal, err := store.Begin("ip:10.0.0.1", now, testPolicy()); err == nil && refusal == nil {
+				mu.Lock()
+				admitted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if admitted != 3 {
+		t.Fatalf("admitted %d concurrent attempts, want 3", admitted)
+	}
+}
+
+func TestRecordFailureBacksOffAndLocks(t *testing.T) {
+	store := NewMemoryStore()
+	policy := testPolicy()
+	now := time.Now()
+
+	fail := func(at time.Time) *Attempts {
+		t.Helper()
+		if refusal, err := store.Begin("user:an", at, policy); err != nil || refusal != nil {
+			t.Fatalf("attempt at %s refused: %v %v", at.Sub(now), refusal, err)
+		}
+		attempts, err := store.RecordFailure("user:an", at, policy)
+		if err != nil {
+			t.Fatalf("RecordFailure: %v", err)
+		}
+		return attempts
+	}
+
+	fail(now)
+
+	// The delay after a failure has to pass
+	refusal, _ := store.Begin("user:an", now.Add(500*time.Millisecond), policy)
+	if refusal == nil || refusal.Locked || refusal.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("got %+v, want to wait 500ms", refusal)
+	}
+
+	fail(now.Add(time.Second))
+	attempts := fail(now.Add(3 * time.Second))
+	if attempts.Failures != 3 || attempts.LockedUntil == nil || !attempts.LockedUntil.Equal(now.Add(3*time.Second+policy.Window)) {
+		t.Fatalf("got %+v, want 3 failures and locked for the window", attempts)
+	}
+	if len(attempts.Pending) != 0 {
+		t.Fatalf("failures left %d attempts in progress", len(attempts.Pending))
+	}
+
+	refusal, _ = store.Begin("user:an", now.Add(10*time.Minute), policy)
+	if refusal == nil || !refusal.Locked {
+		t.Fatalf("got %+v, want locked", refusal)
+	}
+
+	// Failures and the lock end with the window
+	if refusal, _ := store.Begin("user:an", now.Add(time.Hour), policy); refusal != nil {
+		t.Fatalf("got %+v after the window, want the attempt let through", refusal)
+	}
+}
+
+func TestAttemptsInProgressTimeOut(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		store.Begin("user:an", now, testPolicy())
+	}
+
+	if refusal, _ := store.Begin("user:an", now.Add(pendingTimeout+time.Second), testPolicy()); refusal != nil {
+		t.Fatalf("got %+v, want attempts that never ended forgotten", refusal)
+	}
+}
+
+func TestDeleteExpired(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+
+	store.Begin("user:old", now.Add(-time.Hour), testPolicy())
+	store.RecordFailure("user:old", now.Add(-time.Hour), testPolicy())
+	store.Begin("user:new", now, testPolicy())
+	store.RecordFailure("user:new", now, testPolicy())
+
+	deleted, err := store.DeleteExpired(now.Add(-testPolicy().Window))
+	if err != nil || deleted != 1 {
+		t.Fatalf("deleted %d: %v, want 1", deleted, err)
+	}
+	if attempts, _ := store.Get("user:new"); attempts == nil || attempts.Failures != 1 {
+		t.Fatalf("recent record %+v was removed", attempts)
+	}
+}
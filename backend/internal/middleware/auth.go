@@ -2,26 +2,67 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
+	"steel-pos-backend/internal/models"
 	"steel-pos-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+// passwordChangeRoutes are the routes a user who must change their password can still use
+var passwordChangeRoutes = map[string]bool{
+	"/api/auth/change-password": true,
+	"/api/auth/whoami":          true,
+	"/api/auth/logout-all":      true,
+}
+
+// apiKeyAuthRoutes are the /api/auth routes an API key can use; the others manage the password,
+// two-factor authentication and sessions of a logged-in user
+var apiKeyAuthRoutes = map[string]bool{
+	"/api/auth/whoami": true,
+}
+
+// branchFallbackRoutes bỏ qua header X-Branch-ID không hợp lệ thay vì chặn request, để client còn
+// lấy lại được danh sách chi nhánh khi chi nhánh đã chọn bị gỡ hoặc ngừng hoạt động
+var branchFallbackRoutes = map[string]bool{
+	"/api/auth/whoami":   true,
+	"/api/branches/mine": true,
+}
+
+// BranchHeader chọn chi nhánh mà request thao tác trên đó
+const BranchHeader = "X-Branch-ID"
+
 type AuthMiddleware struct {
-	jwtService *services.JWTService
+	jwtService       *services.JWTService
+	signedURLService *services.SignedURLService
+	roleService      *services.RoleService
+	authService      *services.AuthService
+	apiKeyService    *services.APIKeyService
+	branchService    *services.BranchService
 }
 
-func NewAuthMiddleware(jwtService *services.JWTService) *AuthMiddleware {
+func NewAuthMiddleware(jwtService *services.JWTService, signedURLService *services.SignedURLService, roleService *services.RoleService, authService *services.AuthService, apiKeyService *services.APIKeyService, branchService *services.BranchService) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtService: jwtService,
+		jwtService:       jwtService,
+		signedURLService: signedURLService,
+		roleService:      roleService,
+		authService:      authService,
+		apiKeyService:    apiKeyService,
+		branchService:    branchService,
 	}
 }
 
-// Authenticate middleware để verify JWT token
+// Authenticate middleware để verify JWT token hoặc API key
 func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Script và tích hợp dùng API key thay cho JWT
+		if apiKey := APIKeyFromRequest(c); apiKey != "" {
+			m.authenticateAPIKey(c, apiKey)
+			return
+		}
+
 		// Lấy token từ header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -47,7 +88,7 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 		token := tokenParts[1]
 
 		// Validate token
-		claims, err := m.jwtService.ValidateToken(token)
+		claims, err := m.jwtService.ValidateAccessToken(token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Invalid token",
@@ -61,49 +102,43 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
+		c.Set("session_id", claims.SessionID)
 		c.Set("claims", claims)
 
+		if !m.setPermissions(c, claims.Role) {
+			return
+		}
+
+		if !m.setBranches(c, claims.UserID) {
+			return
+		}
+
+		// User phải đổi mật khẩu (do admin đặt lại) chỉ được gọi các route đổi mật khẩu
+		if !m.checkPasswordChange(c, claims.UserID) {
+			return
+		}
+
 		c.Next()
 	}
 }
 
-// AuthenticateWithQueryParam middleware để support cả header và query param
-func (m *AuthMiddleware) AuthenticateWithQueryParam() gin.HandlerFunc {
+// AuthenticateSignedURL middleware cho các tài liệu in (PDF, ảnh): chấp nhận header Authorization
+// hoặc signed URL ngắn hạn do POST /api/signed-urls cấp, để access token không nằm trong URL
+func (m *AuthMiddleware) AuthenticateSignedURL() gin.HandlerFunc {
+	authenticate := m.Authenticate()
+
 	return func(c *gin.Context) {
-		// Lấy token từ header hoặc query param
-		authHeader := c.GetHeader("Authorization")
-		tokenParam := c.Query("token")
-
-		var token string
-
-		if authHeader != "" {
-			// Kiểm tra format "Bearer <token>"
-			tokenParts := strings.Split(authHeader, " ")
-			if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-				c.JSON(http.StatusUnauthorized, gin.H{
-					"error":   "Invalid authorization header format",
-					"message": "Use 'Bearer <token>' format",
-				})
-				c.Abort()
-				return
-			}
-			token = tokenParts[1]
-		} else if tokenParam != "" {
-			token = tokenParam
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Authorization required",
-				"message": "Authorization header or token parameter required",
-			})
-			c.Abort()
+		query := c.Request.URL.Query()
+		if c.GetHeader("Authorization") != "" || !m.signedURLService.IsSignedRequest(query) {
+			authenticate(c)
 			return
 		}
 
-		// Validate token
-		claims, err := m.jwtService.ValidateToken(token)
+		// Verify chữ ký, thời hạn và session của signed URL
+		user, sessionID, err := m.signedURLService.VerifyRequest(c.Request.URL.Path, query)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Invalid token",
+				"error":   "Invalid signed URL",
 				"message": err.Error(),
 			})
 			c.Abort()
@@ -111,21 +146,68 @@ func (m *AuthMiddleware) AuthenticateWithQueryParam() gin.HandlerFunc {
 		}
 
 		// Lưu thông tin user vào context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
-		c.Set("claims", claims)
+		c.Set("user_id", user.ID)
+		c.Set("username", user.Username)
+		c.Set("role", user.Role)
+		c.Set("session_id", sessionID)
+
+		if !m.setPermissions(c, user.Role) {
+			return
+		}
+
+		if !m.setBranches(c, user.ID) {
+			return
+		}
+
+		if !m.checkPasswordChange(c, user.ID) {
+			return
+		}
 
 		c.Next()
 	}
 }
 
-// RequireRole middleware để kiểm tra role cụ thể
-func (m *AuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
+// authenticateAPIKey xác thực request bằng API key. Request có quyền của API key mà vai trò của
+// user vẫn còn, không có session và không bị chặn khi user phải đổi mật khẩu.
+func (m *AuthMiddleware) authenticateAPIKey(c *gin.Context, apiKey string) {
+	user, key, permissions, err := m.apiKeyService.Authenticate(apiKey, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Invalid API key",
+			"message": err.Error(),
+		})
+		c.Abort()
+		return
+	}
+
+	if strings.HasPrefix(c.FullPath(), "/api/auth/") && !apiKeyAuthRoutes[c.FullPath()] {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "API key not allowed",
+			"message": "This route requires logging in as the user",
+		})
+		c.Abort()
+		return
+	}
+
+	// Lưu thông tin user vào context
+	c.Set("user_id", user.ID)
+	c.Set("username", user.Username)
+	c.Set("role", user.Role)
+	c.Set("api_key_id", key.ID)
+	c.Set("permissions", permissions)
+
+	if !m.setBranches(c, user.ID) {
+		return
+	}
+
+	c.Next()
+}
+
+// RequirePermission middleware để kiểm tra vai trò của user có quyền cụ thể
+func (m *AuthMiddleware) RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Đảm bảo đã authenticate trước
-		userRole, exists := c.Get("role")
-		if !exists {
+		if _, exists := c.Get("permissions"); !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Authentication required",
 				"message": "User not authenticated",
@@ -134,21 +216,63 @@ func (m *AuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
 			return
 		}
 
-		role := userRole.(string)
+		if !HasPermission(c, permission) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Insufficient permissions",
+				"message": "You don't have permission to access this resource",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireBranch middleware cho các route ghi nhận chứng từ vào chi nhánh hiện tại hoặc liệt kê, thống
+// kê chứng từ của chi nhánh hiện tại: chặn user chưa được phân công chi nhánh nào, vì repository coi
+// chi nhánh 0 là mọi chi nhánh
+func (m *AuthMiddleware) RequireBranch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if branchID, _ := GetCurrentBranchID(c); branchID == 0 {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Branch required",
+				"message": "You are not assigned to any active branch",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireBranchAccess middleware cho các route thao tác trên một chứng từ theo ID: chặn khi chứng
+// từ thuộc chi nhánh mà user không làm việc. branchOf trả về chi nhánh của chứng từ, 0 nếu không
+// tìm thấy (handler sẽ trả về 404).
+func (m *AuthMiddleware) RequireBranchAccess(param string, branchOf func(id int) (int, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param(param))
+		if err != nil {
+			// Handler trả về lỗi ID không hợp lệ
+			c.Next()
+			return
+		}
 
-		// Kiểm tra role có trong danh sách được phép không
-		hasPermission := false
-		for _, allowedRole := range roles {
-			if role == allowedRole {
-				hasPermission = true
-				break
-			}
+		branchID, err := branchOf(id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to load branch",
+				"message": err.Error(),
+			})
+			c.Abort()
+			return
 		}
 
-		if !hasPermission {
+		if branchID != 0 && !CanAccessBranch(c, branchID) {
 			c.JSON(http.StatusForbidden, gin.H{
-				"error":   "Insufficient permissions",
-				"message": "You don't have permission to access this resource",
+				"error":   "Branch access denied",
+				"message": "This record belongs to a branch you do not work at",
 			})
 			c.Abort()
 			return
@@ -158,19 +282,86 @@ func (m *AuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
 	}
 }
 
-// RequireAdmin middleware để kiểm tra quyền admin
-func (m *AuthMiddleware) RequireAdmin() gin.HandlerFunc {
-	return m.RequireRole("admin")
+// setPermissions lưu quyền của vai trò vào context, trả về false nếu không lấy được
+func (m *AuthMiddleware) setPermissions(c *gin.Context, role string) bool {
+	permissions, err := m.roleService.GetRolePermissions(role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load permissions",
+			"message": err.Error(),
+		})
+		c.Abort()
+		return false
+	}
+
+	c.Set("permissions", permissions)
+	return true
 }
 
-// RequireManager middleware để kiểm tra quyền manager trở lên
-func (m *AuthMiddleware) RequireManager() gin.HandlerFunc {
-	return m.RequireRole("admin", "manager")
+// setBranches lưu các chi nhánh user làm việc và chi nhánh hiện tại (header X-Branch-ID hoặc chi
+// nhánh đầu tiên) vào context, trả về false nếu đã chặn request. Phải gọi sau setPermissions vì
+// user có quyền branch.manage làm việc ở mọi chi nhánh.
+func (m *AuthMiddleware) setBranches(c *gin.Context, userID int) bool {
+	requestedID := 0
+	if header := strings.TrimSpace(c.GetHeader(BranchHeader)); header != "" {
+		id, err := strconv.Atoi(header)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid branch",
+				"message": "X-Branch-ID must be a branch ID",
+			})
+			c.Abort()
+			return false
+		}
+		requestedID = id
+	}
+
+	allBranches := HasPermission(c, models.PermissionBranchManage)
+
+	branches, err := m.branchService.ResolveBranches(userID, allBranches, requestedID)
+	if err != nil && requestedID != 0 && branchFallbackRoutes[c.FullPath()] {
+		branches, err = m.branchService.ResolveBranches(userID, allBranches, 0)
+	}
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Branch access denied",
+			"message": err.Error(),
+		})
+		c.Abort()
+		return false
+	}
+
+	c.Set("branches", branches)
+	c.Set("branch_id", branches.ActiveBranchID)
+	return true
 }
 
-// RequireAccountant middleware để kiểm tra quyền accountant trở lên
-func (m *AuthMiddleware) RequireAccountant() gin.HandlerFunc {
-	return m.RequireRole("admin", "manager", "accountant")
+// checkPasswordChange chặn request khi user bị bắt buộc đổi mật khẩu, trả về false nếu đã chặn
+func (m *AuthMiddleware) checkPasswordChange(c *gin.Context, userID int) bool {
+	if passwordChangeRoutes[c.FullPath()] {
+		return true
+	}
+
+	mustChange, err := m.authService.MustChangePassword(userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Invalid token",
+			"message": err.Error(),
+		})
+		c.Abort()
+		return false
+	}
+
+	if mustChange {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Password change required",
+			"message": "You must change your password before continuing",
+		})
+		c.Abort()
+		return false
+	}
+
+	return true
 }
 
 // GetCurrentUserID helper function để lấy user ID từ context
@@ -199,3 +390,90 @@ func GetCurrentUsername(c *gin.Context) (string, bool) {
 	}
 	return username.(string), true
 }
+
+// APIKeyFromRequest lấy API key từ header X-API-Key hoặc "Bearer <key>", trả về "" nếu request
+// dùng JWT
+func APIKeyFromRequest(c *gin.Context) string {
+	if apiKey := strings.TrimSpace(c.GetHeader("X-API-Key")); apiKey != "" {
+		return apiKey
+	}
+
+	tokenParts := strings.Split(c.GetHeader("Authorization"), " ")
+	if len(tokenParts) == 2 && tokenParts[0] == "Bearer" && services.IsAPIKey(tokenParts[1]) {
+		return tokenParts[1]
+	}
+
+	return ""
+}
+
+// GetCurrentAPIKeyID helper function để lấy ID của API key khi request dùng API key
+func GetCurrentAPIKeyID(c *gin.Context) (int, bool) {
+	keyID, exists := c.Get("api_key_id")
+	if !exists {
+		return 0, false
+	}
+	return keyID.(int), true
+}
+
+// GetCurrentSessionID helper function để lấy session ID từ context
+func GetCurrentSessionID(c *gin.Context) (string, bool) {
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		return "", false
+	}
+	return sessionID.(string), true
+}
+
+// GetCurrentBranchID helper function để lấy chi nhánh hiện tại của request, 0 nếu user không có
+// chi nhánh nào
+func GetCurrentBranchID(c *gin.Context) (int, bool) {
+	branchID, exists := c.Get("branch_id")
+	if !exists {
+		return 0, false
+	}
+	return branchID.(int), true
+}
+
+// GetCurrentBranches helper function để lấy các chi nhánh user hiện tại làm việc
+func GetCurrentBranches(c *gin.Context) (*models.UserBranches, bool) {
+	branches, exists := c.Get("branches")
+	if !exists {
+		return nil, false
+	}
+	return branches.(*models.UserBranches), true
+}
+
+// CanAccessBranch helper function để kiểm tra user hiện tại có làm việc ở chi nhánh không. User có
+// quyền branch.manage truy cập được mọi chi nhánh, kể cả chi nhánh đã ngừng hoạt động.
+func CanAccessBranch(c *gin.Context, branchID int) bool {
+	branches, exists := GetCurrentBranches(c)
+	if !exists {
+		return false
+	}
+
+	if branches.AllBranches {
+		return true
+	}
+
+	for _, branch := range branches.Branches {
+		if branch.ID == branchID {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission helper function để kiểm tra user hiện tại có quyền cụ thể không
+func HasPermission(c *gin.Context, permission string) bool {
+	permissions, exists := c.Get("permissions")
+	if !exists {
+		return false
+	}
+
+	for _, granted := range permissions.([]string) {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -28,6 +28,12 @@ func (m *TokenRefreshMiddleware) TokenRefresh() gin.HandlerFunc {
 			return
 		}
 
+		// API key không hết hạn theo access token, Authenticate sẽ xác thực
+		if APIKeyFromRequest(c) != "" {
+			c.Next()
+			return
+		}
+
 		// Lấy access token từ header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -47,12 +53,13 @@ func (m *TokenRefreshMiddleware) TokenRefresh() gin.HandlerFunc {
 		accessToken := tokenParts[1]
 
 		// Validate access token
-		claims, err := m.jwtService.ValidateToken(accessToken)
+		claims, err := m.jwtService.ValidateAccessToken(accessToken)
 		if err == nil {
 			// Token hợp lệ, tiếp tục
 			c.Set("user_id", claims.UserID)
 			c.Set("username", claims.Username)
 			c.Set("role", claims.Role)
+			c.Set("session_id", claims.SessionID)
 			c.Next()
 			return
 		}
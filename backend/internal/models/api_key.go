@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// APIKey lets an integration or script call the API as a user without logging in. Requests made
+// with it only get the permissions of the key that the role of the user still grants.
+type APIKey struct {
+	ID          int        `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	Prefix      string     `json:"prefix" db:"key_prefix"` // start of the key, to tell keys apart
+	UserID      int        `json:"user_id" db:"user_id"`
+	Username    string     `json:"username"`
+	Permissions []string   `json:"permissions" db:"permissions"`
+	ExpiresAt   *time.Time `json:"expires_at" db:"expires_at"` // nil for keys that do not expire
+	LastUsedAt  *time.Time `json:"last_used_at" db:"last_used_at"`
+	LastUsedIP  *string    `json:"last_used_ip" db:"last_used_ip"`
+	RevokedAt   *time.Time `json:"revoked_at" db:"revoked_at"`
+	CreatedBy   *int       `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// IsActive reports whether the key has not been revoked and has not expired
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// CreatedAPIKey is a newly created key together with its secret, which is not shown again
+type CreatedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKeyRequest represents the request to create an API key
+type CreateAPIKeyRequest struct {
+	Name        string     `json:"name" binding:"required,max=100"`
+	UserID      int        `json:"user_id" binding:"required"`
+	Permissions []string   `json:"permissions" binding:"required,min=1"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// UpdateAPIKeyRequest represents the request to update an API key; omitted fields are left unchanged
+type UpdateAPIKeyRequest struct {
+	Name        *string    `json:"name" binding:"omitempty,max=100"`
+	Permissions *[]string  `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
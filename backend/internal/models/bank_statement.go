@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+// Bank transaction statuses
+const (
+	BankTransactionStatusUnmatched = "unmatched"
+	BankTransactionStatusSuggested = "suggested" // matched automatically, waiting for an accountant
+	BankTransactionStatusConfirmed = "confirmed" // recorded as an invoice payment
+	BankTransactionStatusIgnored   = "ignored"   // not an invoice payment
+)
+
+// Bank transaction match methods
+const (
+	MatchMethodInvoiceCode    = "invoice_code"    // the transfer description contains the invoice code
+	MatchMethodAmountCustomer = "amount_customer" // the amount and customer identify a single open invoice
+	MatchMethodAmount         = "amount"          // only the amount points to a single open invoice, left unmatched as a candidate
+	MatchMethodManual         = "manual"
+)
+
+// BankStatement represents an uploaded bank statement file
+type BankStatement struct {
+	ID               int       `json:"id" db:"id"`
+	FileName         string    `json:"file_name" db:"file_name"`
+	TransactionCount int       `json:"transaction_count" db:"transaction_count"`
+	SkippedCount     int       `json:"skipped_count" db:"skipped_count"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	CreatedBy        *int      `json:"created_by" db:"created_by"`
+	CreatedByName    *string   `json:"created_by_name" db:"created_by_name"`
+
+	// Relations
+	Transactions []*BankTransaction `json:"transactions,omitempty"`
+}
+
+// BankTransaction represents an incoming transfer from a bank statement
+type BankTransaction struct {
+	ID                  int        `json:"id" db:"id"`
+	StatementID         int        `json:"statement_id" db:"statement_id"`
+	TransactionDate     time.Time  `json:"transaction_date" db:"transaction_date"`
+	Amount              float64    `json:"amount" db:"amount"`
+	Description         *string    `json:"description" db:"description"`
+	BankReference       *string    `json:"bank_reference" db:"bank_reference"`
+	CounterpartyName    *string    `json:"counterparty_name" db:"counterparty_name"`
+	CounterpartyAccount *string    `json:"counterparty_account" db:"counterparty_account"`
+	Status              string     `json:"status" db:"status"`
+	InvoiceID           *int       `json:"invoice_id" db:"invoice_id"`
+	MatchMethod         *string    `json:"match_method" db:"match_method"`
+	PaymentID           *int       `json:"payment_id" db:"payment_id"`
+	ConfirmedBy         *int       `json:"confirmed_by" db:"confirmed_by"`
+	ConfirmedByName     *string    `json:"confirmed_by_name" db:"confirmed_by_name"`
+	ConfirmedAt         *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Relations
+	InvoiceCode *string `json:"invoice_code,omitempty"`
+}
+
+// OpenInvoice is an invoice still awaiting payment, as considered for matching transfers
+type OpenInvoice struct {
+	ID            int     `json:"id"`
+	InvoiceCode   string  `json:"invoice_code"`
+	CustomerName  string  `json:"customer_name"`
+	CustomerPhone string  `json:"customer_phone"`
+	Outstanding   float64 `json:"outstanding"`
+}
+
+// Request/Response structs
+
+// ConfirmBankTransactionRequest confirms the suggested match of a transaction, or matches
+// it to another invoice
+type ConfirmBankTransactionRequest struct {
+	InvoiceID *int `json:"invoice_id"`
+}
+
+// BankStatementListResponse represents a paginated list of bank statements
+type BankStatementListResponse struct {
+	Statements []*BankStatement `json:"statements"`
+	Total      int              `json:"total"`
+	Page       int              `json:"page"`
+	Limit      int              `json:"limit"`
+}
+
+// BankTransactionListResponse represents a paginated list of bank transactions
+type BankTransactionListResponse struct {
+	Transactions []*BankTransaction `json:"transactions"`
+	Total        int                `json:"total"`
+	Page         int                `json:"page"`
+	Limit        int                `json:"limit"`
+}
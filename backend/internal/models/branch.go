@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Branch is a store of the business. Invoices, import orders, cash shifts and stock belong to a
+// branch; users work at the branches they are assigned to.
+type Branch struct {
+	ID        int       `json:"id" db:"id"`
+	Code      string    `json:"code" db:"code"`
+	Name      string    `json:"name" db:"name"`
+	Address   *string   `json:"address" db:"address"`
+	Phone     *string   `json:"phone" db:"phone"`
+	IsActive  bool      `json:"is_active" db:"is_active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateBranchRequest represents the request to create a branch
+type CreateBranchRequest struct {
+	Code    string  `json:"code" binding:"required,max=20"`
+	Name    string  `json:"name" binding:"required,max=200"`
+	Address *string `json:"address"`
+	Phone   *string `json:"phone" binding:"omitempty,max=20"`
+}
+
+// UpdateBranchRequest represents the request to update a branch; omitted fields are left unchanged
+type UpdateBranchRequest struct {
+	Name     *string `json:"name" binding:"omitempty,max=200"`
+	Address  *string `json:"address"`
+	Phone    *string `json:"phone" binding:"omitempty,max=20"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// UpdateUserBranchesRequest sets the branches a user works at
+type UpdateUserBranchesRequest struct {
+	BranchIDs []int `json:"branch_ids" binding:"required,min=1"`
+}
+
+// UserBranches are the branches the current user can work at and the one the request acts on
+type UserBranches struct {
+	Branches       []*Branch `json:"branches"`
+	ActiveBranchID int       `json:"active_branch_id"`
+	AllBranches    bool      `json:"all_branches"` // user has branch.manage
+}
+
+// BranchStock is the stock of a variant at one branch
+type BranchStock struct {
+	BranchID   int       `json:"branch_id" db:"branch_id"`
+	BranchCode string    `json:"branch_code" db:"branch_code"`
+	BranchName string    `json:"branch_name" db:"branch_name"`
+	VariantID  int       `json:"variant_id" db:"variant_id"`
+	Stock      int       `json:"stock" db:"stock"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// BranchReportLine sums the activity of one branch, or of all branches for the total line
+type BranchReportLine struct {
+	BranchID          int     `json:"branch_id,omitempty"`
+	BranchCode        string  `json:"branch_code,omitempty"`
+	BranchName        string  `json:"branch_name"`
+	InvoiceCount      int     `json:"invoice_count"`
+	CancelledCount    int     `json:"cancelled_count"`
+	TotalAmount       float64 `json:"total_amount"`
+	PaidAmount        float64 `json:"paid_amount"`
+	OutstandingAmount float64 `json:"outstanding_amount"`
+	ImportOrderCount  int     `json:"import_order_count"` // approved import orders
+	ImportValue       float64 `json:"import_value"`
+	ShiftCount        int     `json:"shift_count"`
+	CashDifference    float64 `json:"cash_difference"` // of the closed shifts
+	StockUnits        int     `json:"stock_units"`     // stock on hand now
+}
+
+// ConsolidatedBranchReport compares branches over [From, To) and sums them up
+type ConsolidatedBranchReport struct {
+	From        time.Time           `json:"from"`
+	To          time.Time           `json:"to"`
+	Branches    []*BranchReportLine `json:"branches"`
+	Total       *BranchReportLine   `json:"total"`
+	GeneratedAt time.Time           `json:"generated_at"`
+}
@@ -0,0 +1,118 @@
+package models
+
+import "time"
+
+// Cash shift statuses
+const (
+	CashShiftStatusOpen   = "open"
+	CashShiftStatusClosed = "closed"
+)
+
+// Cash movement types
+const (
+	CashMovementIn  = "cash_in"  // cash added to the drawer, e.g. change from the bank
+	CashMovementOut = "cash_out" // cash taken from the drawer, e.g. paying a supplier
+)
+
+// CashShift represents a cashier session on the cash drawer
+type CashShift struct {
+	ID             int        `json:"id" db:"id"`
+	CashierID      int        `json:"cashier_id" db:"cashier_id"`
+	CashierName    string     `json:"cashier_name" db:"cashier_name"`
+	BranchID       int        `json:"branch_id" db:"branch_id"`
+	Status         string     `json:"status" db:"status"`
+	OpeningFloat   float64    `json:"opening_float" db:"opening_float"`
+	OpeningNotes   *string    `json:"opening_notes" db:"opening_notes"`
+	OpenedAt       time.Time  `json:"opened_at" db:"opened_at"`
+	ExpectedCash   *float64   `json:"expected_cash" db:"expected_cash"`
+	CountedCash    *float64   `json:"counted_cash" db:"counted_cash"`
+	CashDifference *float64   `json:"cash_difference" db:"cash_difference"`
+	ClosingNotes   *string    `json:"closing_notes" db:"closing_notes"`
+	ClosedAt       *time.Time `json:"closed_at" db:"closed_at"`
+	ClosedBy       *int       `json:"closed_by" db:"closed_by"`
+	ClosedByName   *string    `json:"closed_by_name" db:"closed_by_name"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Relations
+	Movements []*CashMovement `json:"movements,omitempty"`
+}
+
+// CashMovement represents cash added to or taken from the drawer outside of sales
+type CashMovement struct {
+	ID            int       `json:"id" db:"id"`
+	ShiftID       int       `json:"shift_id" db:"shift_id"`
+	MovementType  string    `json:"movement_type" db:"movement_type"`
+	Amount        float64   `json:"amount" db:"amount"`
+	Reason        string    `json:"reason" db:"reason"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	CreatedBy     *int      `json:"created_by" db:"created_by"`
+	CreatedByName *string   `json:"created_by_name" db:"created_by_name"`
+}
+
+// PaymentMethodTotal represents the payments received with one payment method
+type PaymentMethodTotal struct {
+	PaymentMethod string  `json:"payment_method"`
+	Name          string  `json:"name"`
+	IsCash        bool    `json:"is_cash"`
+	Count         int     `json:"count"`
+	Amount        float64 `json:"amount"`
+}
+
+// SalesTotals represents the invoices created over a period
+type SalesTotals struct {
+	InvoiceCount   int     `json:"invoice_count"`
+	CancelledCount int     `json:"cancelled_count"`
+	Subtotal       float64 `json:"subtotal"`
+	DiscountAmount float64 `json:"discount_amount"`
+	TaxAmount      float64 `json:"tax_amount"`
+	TotalAmount    float64 `json:"total_amount"`
+}
+
+// ZReport represents the end-of-shift or end-of-day report of the cash drawer
+type ZReport struct {
+	Title            string                `json:"title"`
+	BranchID         int                   `json:"branch_id,omitempty"` // 0 for a report of all branches
+	From             time.Time             `json:"from"`
+	To               time.Time             `json:"to"`
+	Shifts           []*CashShift          `json:"shifts"`
+	Sales            *SalesTotals          `json:"sales"`
+	PaymentsByMethod []*PaymentMethodTotal `json:"payments_by_method"`
+	OpeningFloat     float64               `json:"opening_float"`
+	CashPayments     float64               `json:"cash_payments"`
+	CashIn           float64               `json:"cash_in"`
+	CashOut          float64               `json:"cash_out"`
+	ExpectedCash     float64               `json:"expected_cash"`
+	CountedCash      *float64              `json:"counted_cash"`    // nil until every shift is closed
+	CashDifference   *float64              `json:"cash_difference"` // counted - expected
+	GeneratedAt      time.Time             `json:"generated_at"`
+}
+
+// Request/Response structs
+
+// OpenCashShiftRequest represents a request to open a shift
+type OpenCashShiftRequest struct {
+	OpeningFloat float64 `json:"opening_float" binding:"gte=0"`
+	Notes        *string `json:"notes"`
+}
+
+// CloseCashShiftRequest represents a request to close a shift with the cash counted in the drawer
+type CloseCashShiftRequest struct {
+	CountedCash *float64 `json:"counted_cash" binding:"required,gte=0"`
+	Notes       *string  `json:"notes"`
+}
+
+// CreateCashMovementRequest represents a request to record cash in or out of the drawer
+type CreateCashMovementRequest struct {
+	MovementType string  `json:"movement_type" binding:"required,oneof=cash_in cash_out"`
+	Amount       float64 `json:"amount" binding:"required,gt=0"`
+	Reason       string  `json:"reason" binding:"required"`
+}
+
+// CashShiftListResponse represents a paginated list of cash shifts
+type CashShiftListResponse struct {
+	Shifts []*CashShift `json:"shifts"`
+	Total  int          `json:"total"`
+	Page   int          `json:"page"`
+	Limit  int          `json:"limit"`
+}
@@ -8,6 +8,7 @@ type Customer struct {
 	Phone     string    `json:"phone" db:"phone"`
 	Name      string    `json:"name" db:"name"`
 	Address   *string   `json:"address" db:"address"`
+	TaxCode   *string   `json:"tax_code" db:"tax_code"` // mã số thuế, for business buyers
 	IsActive  bool      `json:"is_active" db:"is_active"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
@@ -22,12 +23,14 @@ type CreateCustomerRequest struct {
 	Phone   string  `json:"phone" binding:"required"`
 	Name    string  `json:"name" binding:"required"`
 	Address *string `json:"address"`
+	TaxCode *string `json:"tax_code"`
 }
 
 type UpdateCustomerRequest struct {
 	Phone   *string `json:"phone"`
 	Name    *string `json:"name"`
 	Address *string `json:"address"`
+	TaxCode *string `json:"tax_code"`
 	IsActive *bool  `json:"is_active"`
 }
 
@@ -0,0 +1,99 @@
+package models
+
+import "time"
+
+// Customer deposit movement types
+const (
+	DepositMovementApplied  = "applied"  // used to pay an invoice
+	DepositMovementReleased = "released" // given back from a cancelled invoice or reversed payment
+	DepositMovementRefunded = "refunded" // paid back to the customer
+)
+
+// CustomerDeposit represents money a customer paid in advance, held as credit
+type CustomerDeposit struct {
+	ID                   int       `json:"id" db:"id"`
+	CustomerID           int       `json:"customer_id" db:"customer_id"`
+	Amount               float64   `json:"amount" db:"amount"`
+	RemainingAmount      float64   `json:"remaining_amount" db:"remaining_amount"`
+	PaymentMethod        string    `json:"payment_method" db:"payment_method"`
+	TransactionReference *string   `json:"transaction_reference" db:"transaction_reference"`
+	Notes                *string   `json:"notes" db:"notes"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+	CreatedBy            *int      `json:"created_by" db:"created_by"`
+	CreatedByName        *string   `json:"created_by_name" db:"created_by_name"`
+
+	// Relations
+	Movements []*CustomerDepositMovement `json:"movements,omitempty"`
+}
+
+// CustomerDepositMovement represents part of a deposit applied to an invoice, released from it or refunded
+type CustomerDepositMovement struct {
+	ID                   int       `json:"id" db:"id"`
+	DepositID            int       `json:"deposit_id" db:"deposit_id"`
+	MovementType         string    `json:"movement_type" db:"movement_type"`
+	Amount               float64   `json:"amount" db:"amount"`
+	InvoiceID            *int      `json:"invoice_id" db:"invoice_id"`
+	InvoicePaymentID     *int      `json:"invoice_payment_id" db:"invoice_payment_id"`
+	PaymentMethod        *string   `json:"payment_method" db:"payment_method"`
+	TransactionReference *string   `json:"transaction_reference" db:"transaction_reference"`
+	Reason               *string   `json:"reason" db:"reason"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	CreatedBy            *int      `json:"created_by" db:"created_by"`
+	CreatedByName        *string   `json:"created_by_name" db:"created_by_name"`
+}
+
+// CustomerDepositBalance represents the deposits of a customer and the credit still available
+type CustomerDepositBalance struct {
+	CustomerID int                `json:"customer_id"`
+	Balance    float64            `json:"balance"`
+	Deposits   []*CustomerDeposit `json:"deposits"`
+}
+
+// CustomerLedgerEntry represents one line of a customer ledger. Debits increase what the
+// customer owes; credits decrease it. Deposit applications appear as both.
+type CustomerLedgerEntry struct {
+	Date           time.Time `json:"date"`
+	EntryType      string    `json:"entry_type"` // invoice, payment, deposit, deposit_applied, deposit_released, deposit_refunded
+	Reference      string    `json:"reference"`
+	Description    string    `json:"description"`
+	InvoiceID      *int      `json:"invoice_id"`
+	DepositID      *int      `json:"deposit_id"`
+	Debit          float64   `json:"debit"`
+	Credit         float64   `json:"credit"`
+	Balance        float64   `json:"balance"`         // owed by the customer, negative when in credit
+	DepositBalance float64   `json:"deposit_balance"` // unused deposits
+}
+
+// CustomerLedger represents the account history of a customer
+type CustomerLedger struct {
+	CustomerID     int                    `json:"customer_id"`
+	CustomerName   string                 `json:"customer_name"`
+	Entries        []*CustomerLedgerEntry `json:"entries"`
+	TotalDebit     float64                `json:"total_debit"`
+	TotalCredit    float64                `json:"total_credit"`
+	Balance        float64                `json:"balance"`
+	DepositBalance float64                `json:"deposit_balance"`
+}
+
+// Request structs
+type CreateCustomerDepositRequest struct {
+	Amount               float64 `json:"amount" binding:"required,gt=0"`
+	PaymentMethod        string  `json:"payment_method" binding:"required"`
+	TransactionReference *string `json:"transaction_reference"`
+	Notes                *string `json:"notes"`
+}
+
+// ApplyCustomerDepositRequest applies deposits to an invoice, as much as is due when no amount is given
+type ApplyCustomerDepositRequest struct {
+	InvoiceID int      `json:"invoice_id" binding:"required"`
+	Amount    *float64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// RefundCustomerDepositRequest refunds a deposit, its whole remaining amount when no amount is given
+type RefundCustomerDepositRequest struct {
+	Amount               *float64 `json:"amount" binding:"omitempty,gt=0"`
+	PaymentMethod        string   `json:"payment_method" binding:"required"`
+	TransactionReference *string  `json:"transaction_reference"`
+	Reason               string   `json:"reason" binding:"required"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// E-invoice statuses
+const (
+	EInvoiceStatusValidated = "validated" // rendered and valid against the schema, not yet submitted
+	EInvoiceStatusSubmitted = "submitted"
+	EInvoiceStatusFailed    = "failed" // rejected by the provider, can be submitted again
+)
+
+// EInvoice represents the electronic invoice issued for a confirmed invoice
+type EInvoice struct {
+	ID                int        `json:"id" db:"id"`
+	InvoiceID         int        `json:"invoice_id" db:"invoice_id"`
+	TemplateCode      string     `json:"template_code" db:"template_code"`
+	Series            string     `json:"series" db:"series"`
+	InvoiceNumber     int        `json:"invoice_number" db:"invoice_number"`
+	IssueDate         time.Time  `json:"issue_date" db:"issue_date"`
+	BuyerTaxCode      *string    `json:"buyer_tax_code" db:"buyer_tax_code"`
+	XMLContent        string     `json:"-" db:"xml_content"`
+	Status            string     `json:"status" db:"status"`
+	Provider          *string    `json:"provider" db:"provider"`
+	ProviderReference *string    `json:"provider_reference" db:"provider_reference"`
+	LookupCode        *string    `json:"lookup_code" db:"lookup_code"`
+	ErrorMessage      *string    `json:"error_message" db:"error_message"`
+	SubmittedAt       *time.Time `json:"submitted_at" db:"submitted_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy         *int       `json:"created_by" db:"created_by"`
+	CreatedByName     *string    `json:"created_by_name" db:"created_by_name"`
+}
+
+// Request/Response structs
+
+// GenerateEInvoiceRequest represents a request to issue the e-invoice of an invoice.
+// The buyer tax code defaults to the tax code of the invoice's customer.
+type GenerateEInvoiceRequest struct {
+	BuyerTaxCode *string `json:"buyer_tax_code"`
+}
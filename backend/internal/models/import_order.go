@@ -6,6 +6,7 @@ import "time"
 type ImportOrder struct {
 	ID             int        `json:"id" db:"id"`
 	ImportCode     string     `json:"import_code" db:"import_code"`
+	BranchID       int        `json:"branch_id" db:"branch_id"`
 	SupplierName   string     `json:"supplier_name" db:"supplier_name"`
 	ImportDate     time.Time  `json:"import_date" db:"import_date"`
 	TotalAmount    float64    `json:"total_amount" db:"total_amount"`
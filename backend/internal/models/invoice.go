@@ -2,10 +2,17 @@ package models
 
 import "time"
 
+// Invoice payment types
+const (
+	PaymentTypePayment  = "payment"
+	PaymentTypeReversal = "reversal" // negative amount cancelling a corrected payment
+)
+
 // Invoice represents an invoice in the system
 type Invoice struct {
 	ID                 int       `json:"id" db:"id"`
 	InvoiceCode        string    `json:"invoice_code" db:"invoice_code"`
+	BranchID           int       `json:"branch_id" db:"branch_id"`
 	CustomerID         *int      `json:"customer_id" db:"customer_id"`
 	CustomerPhone      string    `json:"customer_phone" db:"customer_phone"`
 	CustomerName       string    `json:"customer_name" db:"customer_name"`
@@ -15,8 +22,10 @@ type Invoice struct {
 	DiscountPercentage float64   `json:"discount_percentage" db:"discount_percentage"`
 	TaxAmount          float64   `json:"tax_amount" db:"tax_amount"`
 	TaxPercentage      float64   `json:"tax_percentage" db:"tax_percentage"`
+	PriceMode          string    `json:"price_mode" db:"price_mode"` // exclusive or inclusive of VAT
 	TotalAmount        float64   `json:"total_amount" db:"total_amount"`
 	PaidAmount         float64   `json:"paid_amount" db:"paid_amount"`
+	ChangeAmount       float64   `json:"change_amount" db:"-"` // cash given back at checkout, from payments
 	PaymentStatus      string    `json:"payment_status" db:"payment_status"`
 	Status             string    `json:"status" db:"status"`
 	Notes              *string   `json:"notes" db:"notes"`
@@ -26,35 +35,57 @@ type Invoice struct {
 	CreatedByUsername  *string   `json:"created_by_username" db:"created_by_username"`
 
 	// Relations
-	Items         []*InvoiceItem   `json:"items,omitempty"`
-	Payments      []*InvoicePayment `json:"payments,omitempty"`
+	Items      []*InvoiceItem    `json:"items,omitempty"`
+	Payments   []*InvoicePayment `json:"payments,omitempty"`
+	VATSummary []*VATSummaryLine `json:"vat_summary,omitempty"`
 }
 
 // InvoiceItem represents an item in an invoice
 type InvoiceItem struct {
-	ID           int     `json:"id" db:"id"`
-	InvoiceID    int     `json:"invoice_id" db:"invoice_id"`
-	ProductID    *int    `json:"product_id" db:"product_id"`
-	VariantID    *int    `json:"variant_id" db:"variant_id"`
-	ProductName  string  `json:"product_name" db:"product_name"`
-	VariantName  string  `json:"variant_name" db:"variant_name"`
-	Unit         string  `json:"unit" db:"unit"`
-	Quantity     float64 `json:"quantity" db:"quantity"`
-	UnitPrice    float64 `json:"unit_price" db:"unit_price"`
-	TotalPrice   float64 `json:"total_price" db:"total_price"`
-	ProductNotes *string `json:"product_notes" db:"product_notes"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID                 int       `json:"id" db:"id"`
+	InvoiceID          int       `json:"invoice_id" db:"invoice_id"`
+	ProductID          *int      `json:"product_id" db:"product_id"`
+	VariantID          *int      `json:"variant_id" db:"variant_id"`
+	ProductName        string    `json:"product_name" db:"product_name"`
+	VariantName        string    `json:"variant_name" db:"variant_name"`
+	Unit               string    `json:"unit" db:"unit"`
+	Quantity           float64   `json:"quantity" db:"quantity"`
+	UnitPrice          float64   `json:"unit_price" db:"unit_price"`
+	TotalPrice         float64   `json:"total_price" db:"total_price"`
+	ProductNotes       *string   `json:"product_notes" db:"product_notes"`
+	PromotionID        *int      `json:"promotion_id" db:"promotion_id"`
+	PromotionName      *string   `json:"promotion_name" db:"promotion_name"`
+	PromotionDiscount  float64   `json:"promotion_discount" db:"promotion_discount"`
+	DiscountAmount     float64   `json:"discount_amount" db:"discount_amount"` // line discount, applied after promotions
+	DiscountPercentage float64   `json:"discount_percentage" db:"discount_percentage"`
+	TaxCategoryID      *int      `json:"tax_category_id" db:"tax_category_id"`
+	TaxRate            float64   `json:"tax_rate" db:"tax_rate"`
+	TaxableAmount      float64   `json:"taxable_amount" db:"taxable_amount"` // amount before VAT, after all discounts
+	TaxAmount          float64   `json:"tax_amount" db:"tax_amount"`
+	AllocatedTaxAmount *float64  `json:"allocated_tax_amount" db:"allocated_tax_amount"` // share of an invoice tax amount given explicitly
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
 
 	// Relations
 	Invoice *Invoice `json:"invoice,omitempty"`
 }
 
+// ChargedTax returns the VAT charged on the item: its share of an invoice tax amount given
+// explicitly when there is one, otherwise the tax at its own rate
+func (i *InvoiceItem) ChargedTax() float64 {
+	if i.AllocatedTaxAmount != nil {
+		return *i.AllocatedTaxAmount
+	}
+	return i.TaxAmount
+}
+
 // InvoicePayment represents a payment for an invoice
 type InvoicePayment struct {
 	ID                   int        `json:"id" db:"id"`
 	InvoiceID            int        `json:"invoice_id" db:"invoice_id"`
 	Amount               float64    `json:"amount" db:"amount"`
+	TenderedAmount       *float64   `json:"tendered_amount" db:"tendered_amount"` // cash handed over when change was given
+	ChangeAmount         float64    `json:"change_amount" db:"change_amount"`
 	PaymentMethod        string     `json:"payment_method" db:"payment_method"`
 	PaymentDate          time.Time  `json:"payment_date" db:"payment_date"`
 	TransactionReference *string    `json:"transaction_reference" db:"transaction_reference"`
@@ -63,10 +94,13 @@ type InvoicePayment struct {
 	CorrectedBy          *int       `json:"corrected_by" db:"corrected_by"`
 	CorrectedAt          *time.Time `json:"corrected_at" db:"corrected_at"`
 	OriginalAmount       *float64   `json:"original_amount" db:"original_amount"`
+	PaymentType          string     `json:"payment_type" db:"payment_type"`               // payment or reversal
+	CorrectsPaymentID    *int       `json:"corrects_payment_id" db:"corrects_payment_id"` // payment reversed or replaced
 	Status               string     `json:"status" db:"status"`
 	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
 	CreatedBy            *int       `json:"created_by" db:"created_by"`
+	CreatedByUsername    *string    `json:"created_by_username" db:"created_by_username"`
 
 	// Relations
 	Invoice *Invoice `json:"invoice,omitempty"`
@@ -92,60 +126,78 @@ type InventoryLog struct {
 
 // CreateInvoiceRequest represents a request to create an invoice
 type CreateInvoiceRequest struct {
-	CustomerID         *int                      `json:"customer_id"`
-	CustomerPhone      string                    `json:"customer_phone" binding:"required"`
-	CustomerName       string                    `json:"customer_name" binding:"required"`
-	CustomerAddress    *string                   `json:"customer_address"`
-	Items              []CreateInvoiceItemRequest `json:"items" binding:"required,min=1"`
-	DiscountAmount     *float64                  `json:"discount_amount"`
-	DiscountPercentage *float64                  `json:"discount_percentage"`
-	TaxAmount          *float64                  `json:"tax_amount"`
-	TaxPercentage      *float64                  `json:"tax_percentage"`
-	PaymentMethod      *string                   `json:"payment_method"`
-	PaidAmount         *float64                  `json:"paid_amount"`
-	Notes              *string                   `json:"notes"`
+	CustomerID           *int                         `json:"customer_id"`
+	CustomerPhone        string                       `json:"customer_phone" binding:"required"`
+	CustomerName         string                       `json:"customer_name" binding:"required"`
+	CustomerAddress      *string                      `json:"customer_address"`
+	Items                []CreateInvoiceItemRequest   `json:"items" binding:"required,min=1"`
+	DiscountAmount       *float64                     `json:"discount_amount"`
+	DiscountPercentage   *float64                     `json:"discount_percentage"`
+	TaxAmount            *float64                     `json:"tax_amount"`
+	TaxPercentage        *float64                     `json:"tax_percentage"`
+	PriceMode            *string                      `json:"price_mode" binding:"omitempty,oneof=exclusive inclusive"`
+	PaymentMethod        *string                      `json:"payment_method"`
+	TransactionReference *string                      `json:"transaction_reference"`
+	PaidAmount           *float64                     `json:"paid_amount"`
+	Payments             []CreateInvoiceTenderRequest `json:"payments" binding:"omitempty,dive"` // split tender, instead of payment_method and paid_amount
+	ApplyDeposits        *bool                        `json:"apply_deposits"`                    // pay the rest from customer deposits, default true
+	Notes                *string                      `json:"notes"`
+}
+
+// CreateInvoiceTenderRequest represents one payment made at checkout. Cash tendered above
+// the amount due is given back as change.
+type CreateInvoiceTenderRequest struct {
+	PaymentMethod        string  `json:"payment_method" binding:"required"`
+	Amount               float64 `json:"amount" binding:"required,gt=0"`
+	TransactionReference *string `json:"transaction_reference"`
+	Notes                *string `json:"notes"`
 }
 
 // CreateInvoiceItemRequest represents a request to create an invoice item
 type CreateInvoiceItemRequest struct {
-	ProductID    *int    `json:"product_id"`
-	VariantID    *int    `json:"variant_id"`
-	ProductName  string  `json:"product_name" binding:"required"`
-	VariantName  string  `json:"variant_name" binding:"required"`
-	Unit         string  `json:"unit" binding:"required"`
-	Quantity     float64 `json:"quantity" binding:"required,gt=0"`
-	UnitPrice    float64 `json:"unit_price" binding:"required,gte=0"`
-	ProductNotes *string `json:"product_notes"`
+	ProductID          *int     `json:"product_id"`
+	VariantID          *int     `json:"variant_id"`
+	ProductName        string   `json:"product_name" binding:"required"`
+	VariantName        string   `json:"variant_name" binding:"required"`
+	Unit               string   `json:"unit" binding:"required"`
+	Quantity           float64  `json:"quantity" binding:"required,gt=0"`
+	UnitPrice          float64  `json:"unit_price" binding:"required,gte=0"`
+	DiscountAmount     *float64 `json:"discount_amount" binding:"omitempty,gte=0"`
+	DiscountPercentage *float64 `json:"discount_percentage" binding:"omitempty,gte=0,lte=100"`
+	ProductNotes       *string  `json:"product_notes"`
 }
 
 // UpdateInvoiceRequest represents a request to update an invoice
 type UpdateInvoiceRequest struct {
-	CustomerPhone      *string                   `json:"customer_phone"`
-	CustomerName       *string                   `json:"customer_name"`
-	CustomerAddress    *string                   `json:"customer_address"`
+	CustomerPhone      *string                    `json:"customer_phone"`
+	CustomerName       *string                    `json:"customer_name"`
+	CustomerAddress    *string                    `json:"customer_address"`
 	Items              []UpdateInvoiceItemRequest `json:"items"`
-	DiscountAmount     *float64                  `json:"discount_amount"`
-	DiscountPercentage *float64                  `json:"discount_percentage"`
-	TaxAmount          *float64                  `json:"tax_amount"`
-	TaxPercentage      *float64                  `json:"tax_percentage"`
-	PaymentMethod      *string                   `json:"payment_method"`
-	PaidAmount         *float64                  `json:"paid_amount"`
-	Status             *string                   `json:"status"`
-	Notes              *string                   `json:"notes"`
+	DiscountAmount     *float64                   `json:"discount_amount"`
+	DiscountPercentage *float64                   `json:"discount_percentage"`
+	TaxAmount          *float64                   `json:"tax_amount"`
+	TaxPercentage      *float64                   `json:"tax_percentage"`
+	PriceMode          *string                    `json:"price_mode" binding:"omitempty,oneof=exclusive inclusive"`
+	PaymentMethod      *string                    `json:"payment_method"`
+	PaidAmount         *float64                   `json:"paid_amount"`
+	Status             *string                    `json:"status"`
+	Notes              *string                    `json:"notes"`
 }
 
 // UpdateInvoiceItemRequest represents a request to update an invoice item
 type UpdateInvoiceItemRequest struct {
-	ID           *int     `json:"id"` // nil = create new, not nil = update existing
-	ProductID    *int     `json:"product_id"`
-	VariantID    *int     `json:"variant_id"`
-	ProductName  *string  `json:"product_name"`
-	VariantName  *string  `json:"variant_name"`
-	Unit         *string  `json:"unit"`
-	Quantity     *float64 `json:"quantity"`
-	UnitPrice    *float64 `json:"unit_price"`
-	ProductNotes *string  `json:"product_notes"`
-	IsDeleted    *bool    `json:"is_deleted"` // true = mark for deletion
+	ID                 *int     `json:"id"` // nil = create new, not nil = update existing
+	ProductID          *int     `json:"product_id"`
+	VariantID          *int     `json:"variant_id"`
+	ProductName        *string  `json:"product_name"`
+	VariantName        *string  `json:"variant_name"`
+	Unit               *string  `json:"unit"`
+	Quantity           *float64 `json:"quantity"`
+	UnitPrice          *float64 `json:"unit_price"`
+	DiscountAmount     *float64 `json:"discount_amount" binding:"omitempty,gte=0"`
+	DiscountPercentage *float64 `json:"discount_percentage" binding:"omitempty,gte=0,lte=100"`
+	ProductNotes       *string  `json:"product_notes"`
+	IsDeleted          *bool    `json:"is_deleted"` // true = mark for deletion
 }
 
 // CreateInvoicePaymentRequest represents a request to create an invoice payment
@@ -157,15 +209,53 @@ type CreateInvoicePaymentRequest struct {
 	Notes                *string  `json:"notes"`
 }
 
-// UpdateInvoicePaymentRequest represents a request to update an invoice payment
+// UpdateInvoicePaymentRequest represents a request to correct an invoice payment. The payment
+// is reversed and replaced by a payment with the corrected values.
 type UpdateInvoicePaymentRequest struct {
-	Amount               *float64   `json:"amount"`
+	Amount               *float64   `json:"amount" binding:"omitempty,gt=0"`
 	PaymentMethod        *string    `json:"payment_method"`
 	PaymentDate          *time.Time `json:"payment_date"`
 	TransactionReference *string    `json:"transaction_reference"`
 	Notes                *string    `json:"notes"`
-	CorrectionReason     *string    `json:"correction_reason"`
-	Status               *string    `json:"status"`
+	CorrectionReason     string     `json:"correction_reason" binding:"required"`
+}
+
+// ReverseInvoicePaymentRequest represents a request to reverse an invoice payment
+type ReverseInvoicePaymentRequest struct {
+	CorrectionReason string `json:"correction_reason" binding:"required"`
+}
+
+// PaymentCorrectionResult represents the records written by a payment correction
+type PaymentCorrectionResult struct {
+	Original    *InvoicePayment `json:"original"`
+	Reversal    *InvoicePayment `json:"reversal"`
+	Replacement *InvoicePayment `json:"replacement,omitempty"`
+}
+
+// PaymentCorrection represents a corrected payment in the corrections report
+type PaymentCorrection struct {
+	PaymentID            int       `json:"payment_id"`
+	InvoiceID            int       `json:"invoice_id"`
+	InvoiceCode          string    `json:"invoice_code"`
+	CorrectionReason     string    `json:"correction_reason"`
+	CorrectedBy          *int      `json:"corrected_by"`
+	CorrectedByUsername  *string   `json:"corrected_by_username"`
+	CorrectedAt          time.Time `json:"corrected_at"`
+	OriginalAmount       float64   `json:"original_amount"`
+	OriginalMethod       string    `json:"original_method"`
+	ReversalPaymentID    int       `json:"reversal_payment_id"`
+	ReplacementPaymentID *int      `json:"replacement_payment_id"`
+	NewAmount            *float64  `json:"new_amount"`
+	NewMethod            *string   `json:"new_method"`
+}
+
+// PaymentCorrectionReport represents the payment corrections made over a period
+type PaymentCorrectionReport struct {
+	From          time.Time            `json:"from"`
+	To            time.Time            `json:"to"`
+	Corrections   []*PaymentCorrection `json:"corrections"`
+	TotalReversed float64              `json:"total_reversed"`
+	TotalReplaced float64              `json:"total_replaced"`
 }
 
 // InvoiceListResponse represents a paginated list of invoices
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Built-in payment method codes
+const (
+	PaymentMethodCash         = "cash"
+	PaymentMethodBankTransfer = "bank_transfer"
+	PaymentMethodCard         = "card"
+	PaymentMethodEWallet      = "e_wallet"
+	PaymentMethodCredit       = "credit"  // customer credit, paid later
+	PaymentMethodDeposit      = "deposit" // paid from a customer deposit
+)
+
+// PaymentMethod represents a way customers can pay invoices
+type PaymentMethod struct {
+	ID                int       `json:"id" db:"id"`
+	Code              string    `json:"code" db:"code"`
+	Name              string    `json:"name" db:"name"`
+	Description       *string   `json:"description" db:"description"`
+	RequiresReference bool      `json:"requires_reference" db:"requires_reference"`
+	IsCash            bool      `json:"is_cash" db:"is_cash"`
+	IsActive          bool      `json:"is_active" db:"is_active"`
+	SortOrder         int       `json:"sort_order" db:"sort_order"`
+	CreatedBy         *int      `json:"created_by" db:"created_by"`
+	CreatedByName     *string   `json:"created_by_name" db:"created_by_name"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Request/Response structs
+
+// CreatePaymentMethodRequest represents a request to create a payment method
+type CreatePaymentMethodRequest struct {
+	Code              string  `json:"code" binding:"required,max=20"`
+	Name              string  `json:"name" binding:"required"`
+	Description       *string `json:"description"`
+	RequiresReference bool    `json:"requires_reference"`
+	IsCash            bool    `json:"is_cash"`
+	SortOrder         int     `json:"sort_order"`
+}
+
+// UpdatePaymentMethodRequest represents a request to update a payment method.
+// The code cannot change as it is stored on payments.
+type UpdatePaymentMethodRequest struct {
+	Name              *string `json:"name"`
+	Description       *string `json:"description"`
+	RequiresReference *bool   `json:"requires_reference"`
+	IsCash            *bool   `json:"is_cash"`
+	IsActive          *bool   `json:"is_active"`
+	SortOrder         *int    `json:"sort_order"`
+}
+
+// PaymentCollectionReport represents the payments collected per payment method over a period
+type PaymentCollectionReport struct {
+	From        time.Time             `json:"from"`
+	To          time.Time             `json:"to"`
+	Methods     []*PaymentMethodTotal `json:"methods"`
+	TotalCount  int                   `json:"total_count"`
+	TotalAmount float64               `json:"total_amount"`
+}
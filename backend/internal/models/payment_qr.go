@@ -0,0 +1,15 @@
+package models
+
+// PaymentQR represents the VietQR code a customer scans to pay the remainder of an invoice
+// by bank transfer
+type PaymentQR struct {
+	InvoiceID     int     `json:"invoice_id"`
+	InvoiceCode   string  `json:"invoice_code"`
+	BankBIN       string  `json:"bank_bin"`
+	BankName      string  `json:"bank_name"`
+	AccountNumber string  `json:"account_number"`
+	AccountName   string  `json:"account_name"`
+	Amount        float64 `json:"amount"`
+	Reference     string  `json:"reference"` // transfer description to match incoming transfers against
+	Payload       string  `json:"payload"`
+}
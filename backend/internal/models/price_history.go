@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Price change sources
+const (
+	PriceChangeSourceManual    = "manual"
+	PriceChangeSourceScheduled = "scheduled"
+	PriceChangeSourceBulk      = "bulk"
+	PriceChangeSourceRollback  = "rollback"
+)
+
+// Scheduled price change statuses
+const (
+	ScheduledPriceStatusPending   = "pending"
+	ScheduledPriceStatusApplied   = "applied"
+	ScheduledPriceStatusCancelled = "cancelled"
+)
+
+// VariantPriceHistory represents a single price change of a product variant
+type VariantPriceHistory struct {
+	ID                int       `json:"id" db:"id"`
+	VariantID         int       `json:"variant_id" db:"variant_id"`
+	ProductID         int       `json:"product_id" db:"product_id"`
+	OldPrice          float64   `json:"old_price" db:"old_price"`
+	NewPrice          float64   `json:"new_price" db:"new_price"`
+	Reason            *string   `json:"reason" db:"reason"`
+	Source            string    `json:"source" db:"source"`
+	ScheduledChangeID *int      `json:"scheduled_change_id" db:"scheduled_change_id"`
+	BatchID           *int      `json:"batch_id" db:"batch_id"`
+	ChangedBy         *int      `json:"changed_by" db:"changed_by"`
+	ChangedByName     *string   `json:"changed_by_name" db:"changed_by_name"`
+	ChangedAt         time.Time `json:"changed_at" db:"changed_at"`
+}
+
+// ScheduledPriceChange represents a price change that takes effect at a future date
+type ScheduledPriceChange struct {
+	ID            int        `json:"id" db:"id"`
+	VariantID     int        `json:"variant_id" db:"variant_id"`
+	NewPrice      float64    `json:"new_price" db:"new_price"`
+	EffectiveAt   time.Time  `json:"effective_at" db:"effective_at"`
+	Reason        *string    `json:"reason" db:"reason"`
+	Status        string     `json:"status" db:"status"`
+	AppliedAt     *time.Time `json:"applied_at" db:"applied_at"`
+	CreatedBy     *int       `json:"created_by" db:"created_by"`
+	CreatedByName *string    `json:"created_by_name" db:"created_by_name"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Request/Response structs
+
+// CreateScheduledPriceChangeRequest represents a request to schedule a future price change
+type CreateScheduledPriceChangeRequest struct {
+	NewPrice    float64   `json:"new_price" binding:"gte=0"`
+	EffectiveAt time.Time `json:"effective_at" binding:"required"`
+	Reason      *string   `json:"reason"`
+}
+
+// VariantPriceHistoryListResponse represents a paginated list of price changes
+type VariantPriceHistoryListResponse struct {
+	History []*VariantPriceHistory `json:"history"`
+	Total   int                    `json:"total"`
+	Page    int                    `json:"page"`
+	Limit   int                    `json:"limit"`
+}
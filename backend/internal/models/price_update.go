@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// Price adjustment types
+const (
+	PriceAdjustmentPercentage = "percentage"
+	PriceAdjustmentFixed      = "fixed"
+)
+
+// Price update batch statuses
+const (
+	PriceUpdateBatchStatusApplied    = "applied"
+	PriceUpdateBatchStatusRolledBack = "rolled_back"
+)
+
+// PriceUpdateBatch represents a bulk price change applied to many variants at once
+type PriceUpdateBatch struct {
+	ID               int        `json:"id" db:"id"`
+	AdjustmentType   string     `json:"adjustment_type" db:"adjustment_type"`
+	AdjustmentValue  float64    `json:"adjustment_value" db:"adjustment_value"`
+	RoundTo          float64    `json:"round_to" db:"round_to"`
+	Filters          JSONB      `json:"filters" db:"filters"`
+	Reason           *string    `json:"reason" db:"reason"`
+	VariantCount     int        `json:"variant_count" db:"variant_count"`
+	Status           string     `json:"status" db:"status"`
+	RolledBackBy     *int       `json:"rolled_back_by" db:"rolled_back_by"`
+	RolledBackByName *string    `json:"rolled_back_by_name" db:"rolled_back_by_name"`
+	RolledBackAt     *time.Time `json:"rolled_back_at" db:"rolled_back_at"`
+	CreatedBy        *int       `json:"created_by" db:"created_by"`
+	CreatedByName    *string    `json:"created_by_name" db:"created_by_name"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Relations
+	Changes []*VariantPriceHistory `json:"changes,omitempty"`
+}
+
+// PriceUpdateItem represents the computed price change of one variant in a bulk update
+type PriceUpdateItem struct {
+	VariantID   int     `json:"variant_id"`
+	ProductID   int     `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	VariantName string  `json:"variant_name"`
+	SKU         string  `json:"sku"`
+	Unit        string  `json:"unit"`
+	OldPrice    float64 `json:"old_price"`
+	NewPrice    float64 `json:"new_price"`
+}
+
+// Request/Response structs
+
+// PriceUpdateFilter selects the variants affected by a bulk price update
+type PriceUpdateFilter struct {
+	CategoryID  *int   `json:"category_id,omitempty"`
+	ProductIDs  []int  `json:"product_ids,omitempty"`
+	VariantName string `json:"variant_name,omitempty"` // attribute match on variant name, e.g. "phi 10"
+	Unit        string `json:"unit,omitempty"`         // attribute match on variant unit, e.g. "cây"
+	Search      string `json:"search,omitempty"`       // matches product name, variant name or SKU
+}
+
+// IsEmpty reports whether no filter is set
+func (f PriceUpdateFilter) IsEmpty() bool {
+	return f.CategoryID == nil && len(f.ProductIDs) == 0 && f.VariantName == "" && f.Unit == "" && f.Search == ""
+}
+
+// BulkPriceUpdateRequest represents a request to preview or apply a bulk price update
+type BulkPriceUpdateRequest struct {
+	Filter          PriceUpdateFilter `json:"filter"`
+	AdjustmentType  string            `json:"adjustment_type" binding:"required,oneof=percentage fixed"`
+	AdjustmentValue float64           `json:"adjustment_value" binding:"required"`
+	RoundTo         float64           `json:"round_to" binding:"gte=0"` // round new prices to a multiple of this, 0 = no rounding
+	Reason          *string           `json:"reason"`
+}
+
+// BulkPriceUpdatePreview represents the computed result of a bulk price update before it is applied
+type BulkPriceUpdatePreview struct {
+	Items []*PriceUpdateItem `json:"items"`
+	Total int                `json:"total"`
+}
+
+// PriceUpdateRollbackResult represents the result of rolling back a price update batch
+type PriceUpdateRollbackResult struct {
+	Batch      *PriceUpdateBatch `json:"batch"`
+	Restored   int               `json:"restored"`
+	SkippedIDs []int             `json:"skipped_variant_ids"` // variants whose price changed again since the batch
+}
+
+// PriceUpdateBatchListResponse represents a paginated list of price update batches
+type PriceUpdateBatchListResponse struct {
+	Batches []*PriceUpdateBatch `json:"batches"`
+	Total   int                 `json:"total"`
+	Page    int                 `json:"page"`
+	Limit   int                 `json:"limit"`
+}
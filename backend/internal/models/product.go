@@ -7,6 +7,7 @@ type Product struct {
 	ID            int       `json:"id" db:"id"`
 	Name          string    `json:"name" db:"name"`
 	CategoryID    *int      `json:"category_id" db:"category_id"`
+	TaxCategoryID *int      `json:"tax_category_id" db:"tax_category_id"`
 	Unit          string    `json:"unit" db:"unit"`
 	Notes         string    `json:"notes" db:"notes"`
 	IsActive      bool      `json:"is_active" db:"is_active"`
@@ -54,20 +55,22 @@ type ProductCategory struct {
 
 // Request/Response structs
 type CreateProductRequest struct {
-	Name       string                        `json:"name" binding:"required"`
-	CategoryID *int                          `json:"category_id"`
-	Unit       string                        `json:"unit" binding:"required"`
-	Notes      string                        `json:"notes"`
-	Variants   []CreateProductVariantRequest `json:"variants"`
+	Name          string                        `json:"name" binding:"required"`
+	CategoryID    *int                          `json:"category_id"`
+	TaxCategoryID *int                          `json:"tax_category_id"`
+	Unit          string                        `json:"unit" binding:"required"`
+	Notes         string                        `json:"notes"`
+	Variants      []CreateProductVariantRequest `json:"variants"`
 }
 
 type UpdateProductRequest struct {
-	Name       string                        `json:"name"`
-	CategoryID *int                          `json:"category_id"`
-	Unit       string                        `json:"unit"`
-	Notes      string                        `json:"notes"`
-	IsActive   *bool                         `json:"is_active"`
-	Variants   []UpdateProductVariantRequest `json:"variants"`
+	Name          string                        `json:"name"`
+	CategoryID    *int                          `json:"category_id"`
+	TaxCategoryID *int                          `json:"tax_category_id"`
+	Unit          string                        `json:"unit"`
+	Notes         string                        `json:"notes"`
+	IsActive      *bool                         `json:"is_active"`
+	Variants      []UpdateProductVariantRequest `json:"variants"`
 }
 
 type CreateProductVariantRequest struct {
@@ -79,14 +82,15 @@ type CreateProductVariantRequest struct {
 }
 
 type UpdateProductVariantRequest struct {
-	ID        *int     `json:"id"` // nil = create new, not nil = update existing
-	Name      string   `json:"name"`
-	SKU       string   `json:"sku"`
-	Stock     *int     `json:"stock"`
-	Price     *float64 `json:"price"`
-	Unit      string   `json:"unit"`
-	IsActive  *bool    `json:"is_active"`
-	IsDeleted *bool    `json:"is_deleted"` // true = mark for deletion
+	ID          *int     `json:"id"` // nil = create new, not nil = update existing
+	Name        string   `json:"name"`
+	SKU         string   `json:"sku"`
+	Stock       *int     `json:"stock"`
+	Price       *float64 `json:"price"`
+	PriceReason *string  `json:"price_reason"` // recorded in price history when price changes
+	Unit        string   `json:"unit"`
+	IsActive    *bool    `json:"is_active"`
+	IsDeleted   *bool    `json:"is_deleted"` // true = mark for deletion
 }
 
 type CreateProductCategoryRequest struct {
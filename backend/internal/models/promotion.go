@@ -0,0 +1,96 @@
+package models
+
+import "time"
+
+// Promotion types
+const (
+	PromotionTypePercentage = "percentage"  // percentage off every item in scope
+	PromotionTypeBuyXGetY   = "buy_x_get_y" // buy X units, get Y units of the same item free
+	PromotionTypeVolume     = "volume"      // percentage off once the quantity in scope reaches a minimum
+)
+
+// Promotion represents a configurable promotion evaluated when building invoices
+type Promotion struct {
+	ID                 int        `json:"id" db:"id"`
+	Name               string     `json:"name" db:"name"`
+	Description        *string    `json:"description" db:"description"`
+	PromotionType      string     `json:"promotion_type" db:"promotion_type"`
+	CategoryID         *int       `json:"category_id" db:"category_id"`
+	ProductID          *int       `json:"product_id" db:"product_id"`
+	VariantID          *int       `json:"variant_id" db:"variant_id"`
+	DiscountPercentage float64    `json:"discount_percentage" db:"discount_percentage"`
+	BuyQuantity        float64    `json:"buy_quantity" db:"buy_quantity"`
+	GetQuantity        float64    `json:"get_quantity" db:"get_quantity"`
+	MinQuantity        float64    `json:"min_quantity" db:"min_quantity"`
+	Unit               *string    `json:"unit" db:"unit"`
+	StartsAt           *time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt             *time.Time `json:"ends_at" db:"ends_at"`
+	IsActive           bool       `json:"is_active" db:"is_active"`
+	CreatedBy          *int       `json:"created_by" db:"created_by"`
+	CreatedByName      *string    `json:"created_by_name" db:"created_by_name"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// DiscountRoleCap limits the manual discount a role may give on an invoice
+type DiscountRoleCap struct {
+	Role                  string    `json:"role" db:"role"`
+	MaxDiscountPercentage float64   `json:"max_discount_percentage" db:"max_discount_percentage"`
+	UpdatedAt             time.Time `json:"updated_at" db:"updated_at"`
+	UpdatedBy             *int      `json:"updated_by" db:"updated_by"`
+	UpdatedByName         *string   `json:"updated_by_name" db:"updated_by_name"`
+}
+
+// Request/Response structs
+
+// CreatePromotionRequest represents a request to create a promotion
+type CreatePromotionRequest struct {
+	Name               string     `json:"name" binding:"required"`
+	Description        *string    `json:"description"`
+	PromotionType      string     `json:"promotion_type" binding:"required,oneof=percentage buy_x_get_y volume"`
+	CategoryID         *int       `json:"category_id"`
+	ProductID          *int       `json:"product_id"`
+	VariantID          *int       `json:"variant_id"`
+	DiscountPercentage float64    `json:"discount_percentage" binding:"gte=0,lte=100"`
+	BuyQuantity        float64    `json:"buy_quantity" binding:"gte=0"`
+	GetQuantity        float64    `json:"get_quantity" binding:"gte=0"`
+	MinQuantity        float64    `json:"min_quantity" binding:"gte=0"`
+	Unit               *string    `json:"unit"`
+	StartsAt           *time.Time `json:"starts_at"`
+	EndsAt             *time.Time `json:"ends_at"`
+}
+
+// UpdatePromotionRequest represents a request to update a promotion
+type UpdatePromotionRequest struct {
+	Name               *string    `json:"name"`
+	Description        *string    `json:"description"`
+	CategoryID         *int       `json:"category_id"`
+	ProductID          *int       `json:"product_id"`
+	VariantID          *int       `json:"variant_id"`
+	DiscountPercentage *float64   `json:"discount_percentage" binding:"omitempty,gte=0,lte=100"`
+	BuyQuantity        *float64   `json:"buy_quantity" binding:"omitempty,gte=0"`
+	GetQuantity        *float64   `json:"get_quantity" binding:"omitempty,gte=0"`
+	MinQuantity        *float64   `json:"min_quantity" binding:"omitempty,gte=0"`
+	Unit               *string    `json:"unit"`
+	StartsAt           *time.Time `json:"starts_at"`
+	EndsAt             *time.Time `json:"ends_at"`
+	IsActive           *bool      `json:"is_active"`
+	// Omitted fields are left unchanged; these flags clear a field instead.
+	// A value sent together with its flag wins, e.g. clear_scope with a new category_id.
+	ClearScope    bool `json:"clear_scope"`
+	ClearStartsAt bool `json:"clear_starts_at"`
+	ClearEndsAt   bool `json:"clear_ends_at"`
+}
+
+// UpdateDiscountRoleCapRequest represents a request to set the manual discount cap of a role
+type UpdateDiscountRoleCapRequest struct {
+	MaxDiscountPercentage float64 `json:"max_discount_percentage" binding:"gte=0,lte=100"`
+}
+
+// PromotionListResponse represents a paginated list of promotions
+type PromotionListResponse struct {
+	Promotions []*Promotion `json:"promotions"`
+	Total      int          `json:"total"`
+	Page       int          `json:"page"`
+	Limit      int          `json:"limit"`
+}
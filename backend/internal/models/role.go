@@ -0,0 +1,126 @@
+package models
+
+import "time"
+
+// Permissions checked by RequirePermission. Roles are bundles of these codes stored in the database.
+const (
+	PermissionUserView                = "user.view"
+	PermissionUserUpdate              = "user.update"
+	PermissionUserManage              = "user.manage"
+	PermissionUserResetPrivileged     = "user.reset_password.privileged"
+	PermissionRoleManage              = "role.manage"
+	PermissionProductManage           = "product.manage"
+	PermissionPriceEdit               = "price.edit"
+	PermissionImportCreate            = "import.create"
+	PermissionImportApprove           = "import.approve"
+	PermissionImportDelete            = "import.delete"
+	PermissionInvoiceCreate           = "invoice.create"
+	PermissionInvoiceUpdate           = "invoice.update"
+	PermissionInvoiceDelete           = "invoice.delete"
+	PermissionInvoiceExport           = "invoice.export"
+	PermissionInvoiceDiscountOverride = "invoice.discount.override"
+	PermissionPaymentCreate           = "payment.create"
+	PermissionPaymentCorrect          = "payment.correct"
+	PermissionPaymentReport           = "payment.report"
+	PermissionPaymentMethodManage     = "payment_method.manage"
+	PermissionCustomerManage          = "customer.manage"
+	PermissionDepositReceive          = "deposit.receive"
+	PermissionDepositRefund           = "deposit.refund"
+	PermissionPromotionManage         = "promotion.manage"
+	PermissionTaxManage               = "tax.manage"
+	PermissionTaxReport               = "tax.report"
+	PermissionEInvoiceIssue           = "einvoice.issue"
+	PermissionBankReconcile           = "bank.reconcile"
+	PermissionShiftManage             = "shift.manage"
+	PermissionSettingsManage          = "settings.manage"
+	PermissionAPIKeyManage            = "api_key.manage"
+	PermissionBranchManage            = "branch.manage"
+	PermissionBranchReport            = "branch.report"
+)
+
+// Permission describes a permission that can be granted to roles
+type Permission struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// AllPermissions is the catalog of permissions, in the order they are shown when editing a role
+var AllPermissions = []Permission{
+	{PermissionUserView, "Xem danh sách người dùng"},
+	{PermissionUserUpdate, "Sửa thông tin người dùng"},
+	{PermissionUserManage, "Tạo, xoá người dùng, đổi vai trò và quản lý phiên đăng nhập"},
+	{PermissionUserResetPrivileged, "Đặt lại mật khẩu của người dùng có quyền quản lý người dùng"},
+	{PermissionRoleManage, "Quản lý vai trò, quyền và hạn mức giảm giá"},
+	{PermissionProductManage, "Tạo, sửa, xoá sản phẩm và biến thể"},
+	{PermissionPriceEdit, "Cập nhật giá hàng loạt và hẹn giờ đổi giá"},
+	{PermissionImportCreate, "Tạo và sửa phiếu nhập hàng"},
+	{PermissionImportApprove, "Duyệt phiếu nhập hàng"},
+	{PermissionImportDelete, "Xoá phiếu nhập hàng"},
+	{PermissionInvoiceCreate, "Tạo hoá đơn"},
+	{PermissionInvoiceUpdate, "Sửa hoá đơn"},
+	{PermissionInvoiceDelete, "Xoá hoá đơn"},
+	{PermissionInvoiceExport, "Xuất danh sách hoá đơn"},
+	{PermissionInvoiceDiscountOverride, "Giảm giá vượt hạn mức của vai trò"},
+	{PermissionPaymentCreate, "Ghi nhận thanh toán hoá đơn"},
+	{PermissionPaymentCorrect, "Sửa và huỷ thanh toán đã ghi nhận"},
+	{PermissionPaymentReport, "Xem báo cáo thu tiền theo phương thức"},
+	{PermissionPaymentMethodManage, "Quản lý phương thức thanh toán"},
+	{PermissionCustomerManage, "Tạo, sửa, xoá khách hàng"},
+	{PermissionDepositReceive, "Nhận tiền đặt cọc và trừ cọc vào hoá đơn"},
+	{PermissionDepositRefund, "Hoàn tiền đặt cọc"},
+	{PermissionPromotionManage, "Quản lý khuyến mãi"},
+	{PermissionTaxManage, "Quản lý danh mục và thuế suất"},
+	{PermissionTaxReport, "Xem báo cáo thuế GTGT"},
+	{PermissionEInvoiceIssue, "Lập và gửi hoá đơn điện tử"},
+	{PermissionBankReconcile, "Đối soát sao kê ngân hàng"},
+	{PermissionShiftManage, "Xem ca và báo cáo Z của mọi thu ngân"},
+	{PermissionSettingsManage, "Sửa thông tin cửa hàng"},
+	{PermissionAPIKeyManage, "Tạo và thu hồi API key cho tích hợp và script"},
+	{PermissionBranchManage, "Quản lý chi nhánh, phân công nhân viên và làm việc ở mọi chi nhánh"},
+	{PermissionBranchReport, "Xem báo cáo tổng hợp các chi nhánh"},
+}
+
+// IsValidPermission reports whether the code is in the permission catalog
+func IsValidPermission(code string) bool {
+	for _, permission := range AllPermissions {
+		if permission.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Role is a named bundle of permissions assigned to users
+type Role struct {
+	ID               int       `json:"id" db:"id"`
+	Name             string    `json:"name" db:"name"`
+	Description      *string   `json:"description" db:"description"`
+	IsSystem         bool      `json:"is_system" db:"is_system"` // built-in admin role, which always has every permission
+	RequireTwoFactor bool      `json:"require_two_factor" db:"require_two_factor"`
+	Permissions      []string  `json:"permissions"`
+	UserCount        int       `json:"user_count"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateRoleRequest represents the request to create a role
+type CreateRoleRequest struct {
+	Name             string   `json:"name" binding:"required,max=20"`
+	Description      string   `json:"description"`
+	RequireTwoFactor bool     `json:"require_two_factor"`
+	Permissions      []string `json:"permissions"`
+}
+
+// UpdateRoleRequest represents the request to update a role; omitted fields are left unchanged.
+// The permissions of the admin role cannot be changed.
+type UpdateRoleRequest struct {
+	Description      *string   `json:"description"`
+	RequireTwoFactor *bool     `json:"require_two_factor"`
+	Permissions      *[]string `json:"permissions"`
+}
+
+// CurrentUser is the logged-in user together with the permissions of their role
+type CurrentUser struct {
+	*User
+	Permissions []string `json:"permissions"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// CreateSignedURLRequest asks for a short-lived URL that opens a printable document
+// without an Authorization header, e.g. in an iframe or a new browser tab
+type CreateSignedURLRequest struct {
+	Path string `json:"path" binding:"required"` // e.g. "/api/invoices/12/pdf?template=a5"
+}
+
+// SignedURL is a printable document path with its signature appended
+type SignedURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
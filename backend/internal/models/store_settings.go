@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// Invoice print templates
+const (
+	InvoiceTemplateA4      = "a4"
+	InvoiceTemplateA5      = "a5"
+	InvoiceTemplateReceipt = "receipt" // 80mm roll
+)
+
+// IsValidInvoiceTemplate reports whether an invoice print template exists
+func IsValidInvoiceTemplate(template string) bool {
+	switch template {
+	case InvoiceTemplateA4, InvoiceTemplateA5, InvoiceTemplateReceipt:
+		return true
+	default:
+		return false
+	}
+}
+
+// StoreSettings represents the store profile printed on invoices and receipts
+type StoreSettings struct {
+	Name                   string              `json:"name" db:"name"`
+	Address                *string             `json:"address" db:"address"`
+	Phones                 []string            `json:"phones" db:"phones"`
+	TaxCode                *string             `json:"tax_code" db:"tax_code"`
+	FooterText             *string             `json:"footer_text" db:"footer_text"`
+	HasLogo                bool                `json:"has_logo" db:"has_logo"`
+	DefaultInvoiceTemplate string              `json:"default_invoice_template" db:"default_invoice_template"`
+	BankAccounts           []*StoreBankAccount `json:"bank_accounts"`
+	UpdatedAt              time.Time           `json:"updated_at" db:"updated_at"`
+	UpdatedBy              *int                `json:"updated_by" db:"updated_by"`
+	UpdatedByName          *string             `json:"updated_by_name" db:"updated_by_name"`
+}
+
+// StoreBankAccount represents a bank account of the store customers can transfer to
+type StoreBankAccount struct {
+	ID            int     `json:"id" db:"id"`
+	BankBIN       *string `json:"bank_bin" db:"bank_bin"` // needed for VietQR codes
+	BankName      string  `json:"bank_name" db:"bank_name"`
+	AccountNumber string  `json:"account_number" db:"account_number"`
+	AccountName   string  `json:"account_name" db:"account_name"`
+	Branch        *string `json:"branch" db:"branch"`
+	SortOrder     int     `json:"sort_order" db:"sort_order"`
+}
+
+// StoreLogo represents the logo image of the store
+type StoreLogo struct {
+	Data        []byte
+	ContentType string
+}
+
+// Request/Response structs
+
+// UpdateStoreSettingsRequest represents a request to update the store profile.
+// BankAccounts, when given, replaces all bank accounts.
+type UpdateStoreSettingsRequest struct {
+	Name                   *string                    `json:"name" binding:"omitempty,min=1,max=200"`
+	Address                *string                    `json:"address"`
+	Phones                 *[]string                  `json:"phones"`
+	TaxCode                *string                    `json:"tax_code" binding:"omitempty,max=20"`
+	FooterText             *string                    `json:"footer_text"`
+	DefaultInvoiceTemplate *string                    `json:"default_invoice_template" binding:"omitempty,oneof=a4 a5 receipt"`
+	BankAccounts           *[]StoreBankAccountRequest `json:"bank_accounts" binding:"omitempty,dive"`
+}
+
+// StoreBankAccountRequest represents a bank account in a store settings update
+type StoreBankAccountRequest struct {
+	BankBIN       *string `json:"bank_bin" binding:"omitempty,numeric,len=6"`
+	BankName      string  `json:"bank_name" binding:"required,max=100"`
+	AccountNumber string  `json:"account_number" binding:"required,max=30"`
+	AccountName   string  `json:"account_name" binding:"required,max=100"`
+	Branch        *string `json:"branch"`
+}
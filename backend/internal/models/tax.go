@@ -0,0 +1,119 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Invoice pricing modes
+const (
+	PriceModeExclusive = "exclusive" // unit prices exclude VAT, tax is added on top
+	PriceModeInclusive = "inclusive" // unit prices include VAT, tax is extracted from them
+)
+
+// TaxCategory groups products that share the same VAT treatment
+type TaxCategory struct {
+	ID            int       `json:"id" db:"id"`
+	Code          string    `json:"code" db:"code"`
+	Name          string    `json:"name" db:"name"`
+	Description   *string   `json:"description" db:"description"`
+	IsActive      bool      `json:"is_active" db:"is_active"`
+	CreatedBy     *int      `json:"created_by" db:"created_by"`
+	CreatedByName *string   `json:"created_by_name" db:"created_by_name"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+
+	// Relations
+	CurrentRate *TaxRate   `json:"current_rate,omitempty"`
+	Rates       []*TaxRate `json:"rates,omitempty"`
+}
+
+// TaxRate represents the VAT rate of a tax category over a period of time
+type TaxRate struct {
+	ID             int        `json:"id" db:"id"`
+	TaxCategoryID  int        `json:"tax_category_id" db:"tax_category_id"`
+	Rate           float64    `json:"rate" db:"rate"`
+	EffectiveFrom  time.Time  `json:"effective_from" db:"effective_from"`
+	EffectiveTo    *time.Time `json:"effective_to" db:"effective_to"`
+	LegalReference *string    `json:"legal_reference" db:"legal_reference"`
+	CreatedBy      *int       `json:"created_by" db:"created_by"`
+	CreatedByName  *string    `json:"created_by_name" db:"created_by_name"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ProductTaxRate represents the tax category of a product and its rate at a point in time
+type ProductTaxRate struct {
+	ProductID     int      `json:"product_id"`
+	TaxCategoryID int      `json:"tax_category_id"`
+	Rate          *float64 `json:"rate"` // nil when no rate is in effect
+}
+
+// VATSummaryLine represents the taxable amount and VAT collected at one rate
+type VATSummaryLine struct {
+	TaxRate       float64 `json:"tax_rate"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+}
+
+// SummarizeVAT groups invoice items by tax rate, ordered by rate
+func SummarizeVAT(items []*InvoiceItem) []*VATSummaryLine {
+	byRate := make(map[float64]*VATSummaryLine)
+	for _, item := range items {
+		line, ok := byRate[item.TaxRate]
+		if !ok {
+			line = &VATSummaryLine{TaxRate: item.TaxRate}
+			byRate[item.TaxRate] = line
+		}
+		line.TaxableAmount += item.TaxableAmount
+		line.TaxAmount += item.ChargedTax()
+	}
+
+	summary := make([]*VATSummaryLine, 0, len(byRate))
+	for _, line := range byRate {
+		line.TaxableAmount = math.Round(line.TaxableAmount*100) / 100
+		line.TaxAmount = math.Round(line.TaxAmount*100) / 100
+		summary = append(summary, line)
+	}
+
+	sort.Slice(summary, func(i, j int) bool {
+		return summary[i].TaxRate < summary[j].TaxRate
+	})
+
+	return summary
+}
+
+// Request/Response structs
+
+// CreateTaxCategoryRequest represents a request to create a tax category
+type CreateTaxCategoryRequest struct {
+	Code        string  `json:"code" binding:"required"`
+	Name        string  `json:"name" binding:"required"`
+	Description *string `json:"description"`
+}
+
+// UpdateTaxCategoryRequest represents a request to update a tax category
+type UpdateTaxCategoryRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+// CreateTaxRateRequest represents a request to add a rate to a tax category.
+// The rate closes the open-ended rate that was in effect at EffectiveFrom.
+type CreateTaxRateRequest struct {
+	Rate           float64    `json:"rate" binding:"gte=0,lte=100"`
+	EffectiveFrom  time.Time  `json:"effective_from" binding:"required"`
+	EffectiveTo    *time.Time `json:"effective_to"`
+	LegalReference *string    `json:"legal_reference"`
+}
+
+// VATReport represents the VAT collected per rate over a period
+type VATReport struct {
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	InvoiceCount int               `json:"invoice_count"`
+	Lines        []*VATSummaryLine `json:"lines"`
+	TotalTaxable float64           `json:"total_taxable"`
+	TotalTax     float64           `json:"total_tax"`
+}
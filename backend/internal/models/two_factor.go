@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// UserTwoFactor is the TOTP enrollment of a user
+type UserTwoFactor struct {
+	UserID       int        `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"`
+	EnabledAt    *time.Time `json:"enabled_at" db:"enabled_at"` // nil while setup has not been confirmed
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TwoFactorStatus tells a user whether two-factor authentication is on for their account
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabled_at"`
+	Required          bool       `json:"required"` // required by the role of the user, so it cannot be disabled
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+// TwoFactorSetup is the secret to add to an authenticator app, as text and as a QR code
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+	QRCode          string `json:"qr_code"` // PNG data URI of the provisioning URI
+}
+
+// TwoFactorChallenge is returned by login instead of tokens when the user must pass a second factor
+type TwoFactorChallenge struct {
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int64  `json:"expires_in"`
+	SetupRequired  bool   `json:"setup_required"` // the role requires two-factor authentication but it is not set up yet
+}
+
+// TwoFactorCodeRequest confirms an action with a code from the authenticator app
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableTwoFactorRequest represents the request to turn off two-factor authentication
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // authenticator or recovery code
+}
+
+// TwoFactorLoginRequest completes a login with a code from the authenticator app or a recovery code
+type TwoFactorLoginRequest struct {
+	ChallengeToken string  `json:"challenge_token" binding:"required"`
+	Code           string  `json:"code"`
+	RecoveryCode   string  `json:"recovery_code"`
+	DeviceName     *string `json:"device_name" binding:"omitempty,max=100"`
+}
+
+// TwoFactorSetupLoginRequest starts two-factor setup during a login that requires it
+type TwoFactorSetupLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// RecoveryCodesResponse lists newly generated recovery codes; they are shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -5,15 +5,18 @@ import (
 )
 
 type User struct {
-	ID           int       `json:"id" db:"id"`
-	Username     string    `json:"username" db:"username"`
-	Email        string    `json:"email" db:"email"`
-	PasswordHash string    `json:"-" db:"password_hash"`
-	FullName     string    `json:"full_name" db:"full_name"`
-	Role         string    `json:"role" db:"role"`
-	IsActive     bool      `json:"is_active" db:"is_active"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID                 int        `json:"id" db:"id"`
+	Username           string     `json:"username" db:"username"`
+	Email              string     `json:"email" db:"email"`
+	PasswordHash       string     `json:"-" db:"password_hash"`
+	FullName           string     `json:"full_name" db:"full_name"`
+	Role               string     `json:"role" db:"role"`
+	IsActive           bool       `json:"is_active" db:"is_active"`
+	MustChangePassword bool       `json:"must_change_password" db:"must_change_password"` // only the password can be changed until it is
+	PasswordChangedAt  *time.Time `json:"password_changed_at" db:"password_changed_at"`
+	HasPin             bool       `json:"has_pin"` // a PIN is set for switching users on shared terminals
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 type CreateUserRequest struct {
@@ -22,6 +25,10 @@ type CreateUserRequest struct {
 	Password string `json:"password" validate:"required,min=6"`
 	FullName string `json:"full_name" validate:"required,min=2,max=100"`
 	Role     string `json:"role" validate:"required,oneof=admin manager accountant user"`
+	// MustChangePassword makes the user choose their own password at first login
+	MustChangePassword bool `json:"must_change_password"`
+	// BranchIDs are the branches the user works at; none means the first active branch
+	BranchIDs []int `json:"branch_ids"`
 }
 
 type UpdateUserRequest struct {
@@ -31,27 +38,77 @@ type UpdateUserRequest struct {
 }
 
 type LoginRequest struct {
-	Username string `json:"username" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	Username   string  `json:"username" validate:"required"`
+	Password   string  `json:"password" validate:"required"`
+	DeviceName *string `json:"device_name" validate:"omitempty,max=100"` // e.g. "Quầy 1", shown in the session list
 }
 
 type LoginResponse struct {
-	User         *User  `json:"user"`
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	TokenType    string `json:"token_type"`
-	ExpiresIn    int64  `json:"expires_in"`
+	User          *User               `json:"user"`
+	AccessToken   string              `json:"access_token"`
+	RefreshToken  string              `json:"refresh_token"`
+	TokenType     string              `json:"token_type"`
+	ExpiresIn     int64               `json:"expires_in"`
+	TwoFactor     *TwoFactorChallenge `json:"two_factor,omitempty"`     // set instead of tokens when a second factor is needed
+	RecoveryCodes []string            `json:"recovery_codes,omitempty"` // set when two-factor was set up during this login
 }
 
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+// UserSession represents a login of a user on a device
+type UserSession struct {
+	ID           string    `json:"id"`
+	UserID       int       `json:"user_id"`
+	ActiveUserID *int      `json:"active_user_id"` // user switched to with a PIN on a shared terminal
+	DeviceName   *string   `json:"device_name"`
+	IPAddress    string    `json:"ip_address"`
+	UserAgent    string    `json:"user_agent"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	IsCurrent    bool      `json:"is_current"` // the session of the request
+}
+
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" validate:"required"`
 	NewPassword     string `json:"new_password" validate:"required,min=6"`
 }
 
+// SetPinRequest sets the PIN used to switch to the user on a shared terminal
+type SetPinRequest struct {
+	Password string `json:"password" validate:"required"`
+	Pin      string `json:"pin" validate:"required"`
+}
+
+// SwitchUserRequest switches the active user of the current terminal session
+type SwitchUserRequest struct {
+	Username string `json:"username" validate:"required"`
+	Pin      string `json:"pin" validate:"required"`
+}
+
+// ResetPasswordRequest sets a new password with a one-time token issued by an admin
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// PasswordResetToken is a one-time token an admin hands to a user who forgot their password
+type PasswordResetToken struct {
+	Token     string    `json:"token"` // shown only once; only its hash is stored
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PasswordPolicy lists the rules new passwords must follow, so clients can show them
+type PasswordPolicy struct {
+	MinLength        int  `json:"min_length"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireSymbol    bool `json:"require_symbol"`
+}
+
 type UserRole string
 
 const (
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"steel-pos-backend/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+const apiKeySelect = `
+	SELECT k.id, k.name, k.key_prefix, k.user_id, u.username, k.permissions, k.expires_at,
+		k.last_used_at, k.last_used_ip, k.revoked_at, k.created_by, k.created_at, k.updated_at
+	FROM api_keys k
+	JOIN users u ON u.id = k.user_id`
+
+// Create stores a new key; only the hash of its secret is kept
+func (r *APIKeyRepository) Create(key *models.APIKey, keyHash string) error {
+	query := `
+		INSERT INTO api_keys (name, key_prefix, key_hash, user_id, permissions, expires_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(query, key.Name, key.Prefix, keyHash, key.UserID, pq.Array(key.Permissions),
+		key.ExpiresAt, key.CreatedBy, key.CreatedAt, key.UpdatedAt).
+		Scan(&key.ID, &key.CreatedAt, &key.UpdatedAt)
+}
+
+// GetAll gets every key, newest first, including revoked and expired ones
+func (r *APIKeyRepository) GetAll() ([]*models.APIKey, error) {
+	rows, err := r.db.Query(apiKeySelect + ` ORDER BY k.created_at DESC, k.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// GetByID gets a key, or nil when it does not exist
+func (r *APIKeyRepository) GetByID(id int) (*models.APIKey, error) {
+	return r.getOne(apiKeySelect+` WHERE k.id = $1`, id)
+}
+
+// GetByHash gets the key with the hash of a secret, or nil when there is none
+func (r *APIKeyRepository) GetByHash(keyHash string) (*models.APIKey, error) {
+	return r.getOne(apiKeySelect+` WHERE k.key_hash = $1`, keyHash)
+}
+
+// Update saves the name, permissions and expiry of a key that has not been revoked
+func (r *APIKeyRepository) Update(key *models.APIKey) error {
+	result, err := r.db.Exec(`
+		UPDATE api_keys SET name = $1, permissions = $2, expires_at = $3, updated_at = $4
+		WHERE id = $5 AND revoked_at IS NULL
+	`, key.Name, pq.Array(key.Permissions), key.ExpiresAt, key.UpdatedAt, key.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("API key not found or revoked")
+	}
+
+	return nil
+}
+
+// Revoke revokes a key; requests made with it are rejected from then on
+func (r *APIKeyRepository) Revoke(id int, at time.Time) error {
+	result, err := r.db.Exec(`UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`, at, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("API key not found or revoked")
+	}
+
+	return nil
+}
+
+// TouchLastUsed records when and from where a key was used. It writes at most once per interval so
+// that scripts calling the API in a loop do not update the row on every request.
+func (r *APIKeyRepository) TouchLastUsed(id int, ipAddress string, at time.Time, interval time.Duration) error {
+	_, err := r.db.Exec(`
+		UPDATE api_keys SET last_used_at = $1, last_used_ip = $2
+		WHERE id = $3 AND (last_used_at IS NULL OR last_used_at < $4 OR last_used_ip IS DISTINCT FROM $2)
+	`, at, ipAddress, id, at.Add(-interval))
+	return err
+}
+
+// Helper methods
+
+func (r *APIKeyRepository) getOne(query string, args ...interface{}) (*models.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRow(query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+type apiKeyScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(scanner apiKeyScanner) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	var permissions pq.StringArray
+
+	err := scanner.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.UserID,
+		&key.Username,
+		&permissions,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.LastUsedIP,
+		&key.RevokedAt,
+		&key.CreatedBy,
+		&key.CreatedAt,
+		&key.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Permissions = []string(permissions)
+	return key, nil
+}
@@ -0,0 +1,381 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"steel-pos-backend/internal/models"
+)
+
+type BankStatementRepository struct {
+	db *sql.DB
+}
+
+func NewBankStatementRepository(db *sql.DB) *BankStatementRepository {
+	return &BankStatementRepository{db: db}
+}
+
+const bankTransactionColumns = `
+	bt.id, bt.statement_id, bt.transaction_date, bt.amount, bt.description, bt.bank_reference,
+	bt.counterparty_name, bt.counterparty_account, bt.status, bt.invoice_id, bt.match_method,
+	bt.payment_id, bt.confirmed_by, bt.confirmed_by_name, bt.confirmed_at, bt.created_at,
+	bt.updated_at, i.invoice_code`
+
+// BankStatement methods
+
+// CreateStatement stores a statement with its transactions. Transactions whose bank
+// reference was already imported are skipped; only the stored ones are kept in
+// statement.Transactions.
+func (r *BankStatementRepository) CreateStatement(statement *models.BankStatement, transactions []*models.BankTransaction) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO bank_statements (file_name, transaction_count, skipped_count, created_by, created_by_name, created_at)
+		VALUES ($1, 0, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		statement.FileName, statement.SkippedCount, statement.CreatedBy, statement.CreatedByName, statement.CreatedAt,
+	).Scan(&statement.ID, &statement.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO bank_transactions (
+			statement_id, transaction_date, amount, description, bank_reference,
+			counterparty_name, counterparty_account, status, invoice_id, match_method,
+			created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (bank_reference) WHERE bank_reference IS NOT NULL DO NOTHING
+		RETURNING id, created_at, updated_at
+	`
+
+	statement.Transactions = nil
+	for _, transaction := range transactions {
+		transaction.StatementID = statement.ID
+		err := tx.QueryRow(
+			query,
+			transaction.StatementID,
+			transaction.TransactionDate,
+			transaction.Amount,
+			transaction.Description,
+			transaction.BankReference,
+			transaction.CounterpartyName,
+			transaction.CounterpartyAccount,
+			transaction.Status,
+			transaction.InvoiceID,
+			transaction.MatchMethod,
+			transaction.CreatedAt,
+			transaction.UpdatedAt,
+		).Scan(&transaction.ID, &transaction.CreatedAt, &transaction.UpdatedAt)
+
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// Already imported from an earlier statement
+				statement.SkippedCount++
+				continue
+			}
+			return err
+		}
+
+		statement.Transactions = append(statement.Transactions, transaction)
+	}
+
+	statement.TransactionCount = len(statement.Transactions)
+	_, err = tx.Exec(
+		`UPDATE bank_statements SET transaction_count = $1, skipped_count = $2 WHERE id = $3`,
+		statement.TransactionCount, statement.SkippedCount, statement.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *BankStatementRepository) GetStatementByID(id int) (*models.BankStatement, error) {
+	query := `
+		SELECT id, file_name, transaction_count, skipped_count, created_at, created_by, created_by_name
+		FROM bank_statements
+		WHERE id = $1
+	`
+
+	statement := &models.BankStatement{}
+	err := r.db.QueryRow(query, id).Scan(
+		&statement.ID,
+		&statement.FileName,
+		&statement.TransactionCount,
+		&statement.SkippedCount,
+		&statement.CreatedAt,
+		&statement.CreatedBy,
+		&statement.CreatedByName,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return statement, nil
+}
+
+func (r *BankStatementRepository) GetStatements(limit, offset int) ([]*models.BankStatement, error) {
+	query := `
+		SELECT id, file_name, transaction_count, skipped_count, created_at, created_by, created_by_name
+		FROM bank_statements
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.Query(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statements []*models.BankStatement
+	for rows.Next() {
+		statement := &models.BankStatement{}
+		err := rows.Scan(
+			&statement.ID,
+			&statement.FileName,
+			&statement.TransactionCount,
+			&statement.SkippedCount,
+			&statement.CreatedAt,
+			&statement.CreatedBy,
+			&statement.CreatedByName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement)
+	}
+
+	return statements, nil
+}
+
+func (r *BankStatementRepository) CountStatements() (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM bank_statements`).Scan(&count)
+	return count, err
+}
+
+// BankTransaction methods
+
+func (r *BankStatementRepository) GetTransactionByID(id int) (*models.BankTransaction, error) {
+	query := `SELECT ` + bankTransactionColumns + `
+		FROM bank_transactions bt
+		LEFT JOIN invoices i ON i.id = bt.invoice_id
+		WHERE bt.id = $1`
+
+	transaction, err := scanBankTransaction(r.db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// GetTransactions gets transactions, optionally of one statement (statementID > 0) and status
+func (r *BankStatementRepository) GetTransactions(limit, offset, statementID int, status string) ([]*models.BankTransaction, error) {
+	where, args := bankTransactionFilters(statementID, status)
+	args = append(args, limit, offset)
+
+	query := fmt.Sprintf(`SELECT %s
+		FROM bank_transactions bt
+		LEFT JOIN invoices i ON i.id = bt.invoice_id
+		%s
+		ORDER BY bt.transaction_date DESC, bt.id DESC
+		LIMIT $%d OFFSET $%d`,
+		bankTransactionColumns, where, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []*models.BankTransaction
+	for rows.Next() {
+		transaction, err := scanBankTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+func (r *BankStatementRepository) CountTransactions(statementID int, status string) (int, error) {
+	where, args := bankTransactionFilters(statementID, status)
+
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM bank_transactions bt `+where, args...).Scan(&count)
+	return count, err
+}
+
+// UpdateMatch updates the suggested invoice or ignored status of an unconfirmed transaction
+func (r *BankStatementRepository) UpdateMatch(transaction *models.BankTransaction) error {
+	query := `
+		UPDATE bank_transactions
+		SET status = $1, invoice_id = $2, match_method = $3, updated_at = $4
+		WHERE id = $5 AND status != 'confirmed'
+	`
+
+	result, err := r.db.Exec(query, transaction.Status, transaction.InvoiceID, transaction.MatchMethod, transaction.UpdatedAt, transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("bank transaction not found or already confirmed")
+	}
+
+	return nil
+}
+
+// ConfirmWithPayment marks an unconfirmed transaction, ignored ones included, as confirmed for
+// the given invoice and records its invoice payment in one transaction. It fails when another
+// user confirmed the transaction first. The invoice paid amount follows from the payment triggers.
+func (r *BankStatementRepository) ConfirmWithPayment(transaction *models.BankTransaction, payment *models.InvoicePayment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE bank_transactions
+		SET status = 'confirmed', invoice_id = $1, match_method = $2,
+			confirmed_by = $3, confirmed_by_name = $4, confirmed_at = $5, updated_at = $5
+		WHERE id = $6 AND status IN ('unmatched', 'suggested', 'ignored')
+	`
+
+	result, err := tx.Exec(
+		query,
+		transaction.InvoiceID,
+		transaction.MatchMethod,
+		transaction.ConfirmedBy,
+		transaction.ConfirmedByName,
+		transaction.ConfirmedAt,
+		transaction.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("bank transaction not found or already confirmed")
+	}
+
+	if err := insertInvoicePayment(tx, payment); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE bank_transactions SET payment_id = $1 WHERE id = $2`, payment.ID, transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetOpenInvoices gets the invoices that still have an amount to pay
+func (r *BankStatementRepository) GetOpenInvoices() ([]*models.OpenInvoice, error) {
+	query := `
+		SELECT id, invoice_code, customer_name, customer_phone, total_amount - paid_amount
+		FROM invoices
+		WHERE status != 'cancelled' AND total_amount > paid_amount
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []*models.OpenInvoice
+	for rows.Next() {
+		invoice := &models.OpenInvoice{}
+		if err := rows.Scan(&invoice.ID, &invoice.InvoiceCode, &invoice.CustomerName, &invoice.CustomerPhone, &invoice.Outstanding); err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	return invoices, nil
+}
+
+// Helper methods
+type bankTransactionScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBankTransaction(scanner bankTransactionScanner) (*models.BankTransaction, error) {
+	transaction := &models.BankTransaction{}
+	err := scanner.Scan(
+		&transaction.ID,
+		&transaction.StatementID,
+		&transaction.TransactionDate,
+		&transaction.Amount,
+		&transaction.Description,
+		&transaction.BankReference,
+		&transaction.CounterpartyName,
+		&transaction.CounterpartyAccount,
+		&transaction.Status,
+		&transaction.InvoiceID,
+		&transaction.MatchMethod,
+		&transaction.PaymentID,
+		&transaction.ConfirmedBy,
+		&transaction.ConfirmedByName,
+		&transaction.ConfirmedAt,
+		&transaction.CreatedAt,
+		&transaction.UpdatedAt,
+		&transaction.InvoiceCode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+func bankTransactionFilters(statementID int, status string) (string, []interface{}) {
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argCount := 0
+
+	if statementID > 0 {
+		argCount++
+		where += fmt.Sprintf(" AND bt.statement_id = $%d", argCount)
+		args = append(args, statementID)
+	}
+
+	if status != "" {
+		argCount++
+		where += fmt.Sprintf(" AND bt.status = $%d", argCount)
+		args = append(args, status)
+	}
+
+	return where, args
+}
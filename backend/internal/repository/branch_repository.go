@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"steel-pos-backend/internal/models"
+	"time"
+)
+
+type BranchRepository struct {
+	db *sql.DB
+}
+
+func NewBranchRepository(db *sql.DB) *BranchRepository {
+	return &BranchRepository{db: db}
+}
+
+const branchSelect = `
+	SELECT b.id, b.code, b.name, b.address, b.phone, b.is_active, b.created_at, b.updated_at
+	FROM branches b`
+
+// Branch methods
+func (r *BranchRepository) Create(branch *models.Branch) error {
+	query := `
+		INSERT INTO branches (code, name, address, phone, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(query, branch.Code, branch.Name, branch.Address, branch.Phone, branch.IsActive,
+		branch.CreatedAt, branch.UpdatedAt).
+		Scan(&branch.ID, &branch.CreatedAt, &branch.UpdatedAt)
+}
+
+// GetAll gets the branches in the order they were created, optionally only the active ones
+func (r *BranchRepository) GetAll(activeOnly bool) ([]*models.Branch, error) {
+	query := branchSelect
+	if activeOnly {
+		query += ` WHERE b.is_active = true`
+	}
+	return r.queryBranches(query + ` ORDER BY b.id ASC`)
+}
+
+// GetByID gets a branch, or nil when it does not exist
+func (r *BranchRepository) GetByID(id int) (*models.Branch, error) {
+	return r.getOne(branchSelect+` WHERE b.id = $1`, id)
+}
+
+// ExistsByCode reports whether a branch already uses the code
+func (r *BranchRepository) ExistsByCode(code string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM branches WHERE LOWER(code) = LOWER($1))`, code).Scan(&exists)
+	return exists, err
+}
+
+func (r *BranchRepository) Update(branch *models.Branch) error {
+	result, err := r.db.Exec(`
+		UPDATE branches SET name = $1, address = $2, phone = $3, is_active = $4, updated_at = $5
+		WHERE id = $6
+	`, branch.Name, branch.Address, branch.Phone, branch.IsActive, branch.UpdatedAt, branch.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("branch not found")
+	}
+
+	return nil
+}
+
+// User branch methods
+
+// GetUserBranches gets the active branches a user is assigned to
+func (r *BranchRepository) GetUserBranches(userID int) ([]*models.Branch, error) {
+	query := branchSelect + `
+		JOIN user_branches ub ON ub.branch_id = b.id
+		WHERE ub.user_id = $1 AND b.is_active = true
+		ORDER BY b.id ASC`
+	return r.queryBranches(query, userID)
+}
+
+// SetUserBranches replaces the branches a user is assigned to
+func (r *BranchRepository) SetUserBranches(userID int, branchIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM user_branches WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, branchID := range branchIDs {
+		if _, err := tx.Exec(`INSERT INTO user_branches (user_id, branch_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, userID, branchID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Stock methods
+
+// GetVariantStock gets the stock of a variant at every active branch, 0 where it has none
+func (r *BranchRepository) GetVariantStock(variantID int) ([]*models.BranchStock, error) {
+	query := `
+		SELECT b.id, b.code, b.name, $1::INTEGER, COALESCE(bs.stock, 0), COALESCE(bs.updated_at, b.created_at)
+		FROM branches b
+		LEFT JOIN branch_stock bs ON bs.branch_id = b.id AND bs.variant_id = $1
+		WHERE b.is_active = true OR bs.stock > 0
+		ORDER BY b.id ASC
+	`
+
+	rows, err := r.db.Query(query, variantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stocks := []*models.BranchStock{}
+	for rows.Next() {
+		stock := &models.BranchStock{}
+		err := rows.Scan(
+			&stock.BranchID,
+			&stock.BranchCode,
+			&stock.BranchName,
+			&stock.VariantID,
+			&stock.Stock,
+			&stock.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		stocks = append(stocks, stock)
+	}
+
+	return stocks, rows.Err()
+}
+
+// GetStock gets the stock of a variant at a branch
+func (r *BranchRepository) GetStock(branchID, variantID int) (int, error) {
+	var stock int
+	err := r.db.QueryRow(`SELECT stock FROM branch_stock WHERE branch_id = $1 AND variant_id = $2`, branchID, variantID).Scan(&stock)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return stock, err
+}
+
+// SetStock sets the stock of a variant at a branch. The total stock of the variant is kept in
+// sync by a trigger.
+func (r *BranchRepository) SetStock(branchID, variantID, stock int) error {
+	_, err := r.db.Exec(`
+		INSERT INTO branch_stock (branch_id, variant_id, stock, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (branch_id, variant_id)
+		DO UPDATE SET stock = EXCLUDED.stock, updated_at = EXCLUDED.updated_at
+	`, branchID, variantID, stock)
+	return err
+}
+
+// AdjustStock adds quantity (negative to remove) to the stock of a variant at a branch
+func (r *BranchRepository) AdjustStock(branchID, variantID, quantity int) error {
+	_, err := r.db.Exec(`
+		INSERT INTO branch_stock (branch_id, variant_id, stock, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (branch_id, variant_id)
+		DO UPDATE SET stock = branch_stock.stock + EXCLUDED.stock, updated_at = EXCLUDED.updated_at
+	`, branchID, variantID, quantity)
+	return err
+}
+
+// Report methods
+
+// GetReport sums invoices created, import orders approved and shifts opened in [from, to) and the
+// stock on hand of every branch
+func (r *BranchRepository) GetReport(from, to time.Time) ([]*models.BranchReportLine, error) {
+	query := `
+		SELECT b.id, b.code, b.name,
+			COALESCE(i.invoice_count, 0), COALESCE(i.cancelled_count, 0),
+			COALESCE(i.total_amount, 0), COALESCE(i.paid_amount, 0),
+			COALESCE(o.import_count, 0), COALESCE(o.import_value, 0),
+			COALESCE(s.shift_count, 0), COALESCE(s.cash_difference, 0),
+			COALESCE(st.stock_units, 0)
+		FROM branches b
+		LEFT JOIN (
+			SELECT branch_id,
+				COUNT(*) FILTER (WHERE status != 'cancelled') AS invoice_count,
+				COUNT(*) FILTER (WHERE status = 'cancelled') AS cancelled_count,
+				SUM(total_amount) FILTER (WHERE status != 'cancelled') AS total_amount,
+				SUM(paid_amount) FILTER (WHERE status != 'cancelled') AS paid_amount
+			FROM invoices
+			WHERE created_at >= $1 AND created_at < $2
+			GROUP BY branch_id
+		) i ON i.branch_id = b.id
+		LEFT JOIN (
+			SELECT branch_id, COUNT(*) AS import_count, SUM(total_value) AS import_value
+			FROM import_orders
+			WHERE status = 'approved' AND approved_at >= $1 AND approved_at < $2
+			GROUP BY branch_id
+		) o ON o.branch_id = b.id
+		LEFT JOIN (
+			SELECT branch_id, COUNT(*) AS shift_count, SUM(cash_difference) AS cash_difference
+			FROM cash_shifts
+			WHERE opened_at >= $1 AND opened_at < $2
+			GROUP BY branch_id
+		) s ON s.branch_id = b.id
+		LEFT JOIN (
+			SELECT branch_id, SUM(stock) AS stock_units
+			FROM branch_stock
+			GROUP BY branch_id
+		) st ON st.branch_id = b.id
+		ORDER BY b.id ASC
+	`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []*models.BranchReportLine{}
+	for rows.Next() {
+		line := &models.BranchReportLine{}
+		err := rows.Scan(
+			&line.BranchID,
+			&line.BranchCode,
+			&line.BranchName,
+			&line.InvoiceCount,
+			&line.CancelledCount,
+			&line.TotalAmount,
+			&line.PaidAmount,
+			&line.ImportOrderCount,
+			&line.ImportValue,
+			&line.ShiftCount,
+			&line.CashDifference,
+			&line.StockUnits,
+		)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// Helper methods
+
+func (r *BranchRepository) getOne(query string, args ...interface{}) (*models.Branch, error) {
+	branch, err := scanBranch(r.db.QueryRow(query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return branch, nil
+}
+
+func (r *BranchRepository) queryBranches(query string, args ...interface{}) ([]*models.Branch, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	branches := []*models.Branch{}
+	for rows.Next() {
+		branch, err := scanBranch(rows)
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, branch)
+	}
+
+	return branches, rows.Err()
+}
+
+type branchScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBranch(scanner branchScanner) (*models.Branch, error) {
+	branch := &models.Branch{}
+	err := scanner.Scan(
+		&branch.ID,
+		&branch.Code,
+		&branch.Name,
+		&branch.Address,
+		&branch.Phone,
+		&branch.IsActive,
+		&branch.CreatedAt,
+		&branch.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return branch, nil
+}
@@ -0,0 +1,377 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"steel-pos-backend/internal/models"
+	"time"
+)
+
+type CashShiftRepository struct {
+	db *sql.DB
+}
+
+func NewCashShiftRepository(db *sql.DB) *CashShiftRepository {
+	return &CashShiftRepository{db: db}
+}
+
+const cashShiftColumns = `
+	id, cashier_id, cashier_name, status, opening_float, opening_notes, opened_at,
+	expected_cash, counted_cash, cash_difference, closing_notes, closed_at, closed_by,
+	closed_by_name, created_at, updated_at, branch_id`
+
+// CashShift methods
+func (r *CashShiftRepository) Create(shift *models.CashShift) error {
+	query := `
+		INSERT INTO cash_shifts (cashier_id, cashier_name, status, opening_float, opening_notes, opened_at, created_at, updated_at, branch_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		shift.CashierID,
+		shift.CashierName,
+		shift.Status,
+		shift.OpeningFloat,
+		shift.OpeningNotes,
+		shift.OpenedAt,
+		shift.CreatedAt,
+		shift.UpdatedAt,
+		shift.BranchID,
+	).Scan(&shift.ID, &shift.CreatedAt, &shift.UpdatedAt)
+}
+
+func (r *CashShiftRepository) GetByID(id int) (*models.CashShift, error) {
+	shift, err := scanCashShift(r.db.QueryRow(`SELECT `+cashShiftColumns+` FROM cash_shifts WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return shift, nil
+}
+
+// GetOpenByCashier gets the open shift of a cashier
+func (r *CashShiftRepository) GetOpenByCashier(cashierID int) (*models.CashShift, error) {
+	shift, err := scanCashShift(r.db.QueryRow(`SELECT `+cashShiftColumns+` FROM cash_shifts WHERE cashier_id = $1 AND status = 'open'`, cashierID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return shift, nil
+}
+
+// GetAll gets shifts, optionally of one branch (branchID > 0), one cashier (cashierID > 0) and status
+func (r *CashShiftRepository) GetAll(limit, offset, branchID, cashierID int, status string) ([]*models.CashShift, error) {
+	where, args := cashShiftFilters(branchID, cashierID, status)
+	args = append(args, limit, offset)
+
+	query := fmt.Sprintf(`SELECT %s FROM cash_shifts %s ORDER BY opened_at DESC LIMIT $%d OFFSET $%d`,
+		cashShiftColumns, where, len(args)-1, len(args))
+
+	return r.queryShifts(query, args...)
+}
+
+func (r *CashShiftRepository) Count(branchID, cashierID int, status string) (int, error) {
+	where, args := cashShiftFilters(branchID, cashierID, status)
+
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM cash_shifts `+where, args...).Scan(&count)
+	return count, err
+}
+
+// GetOpenedBetween gets the shifts opened in [from, to), of one branch when branchID > 0
+func (r *CashShiftRepository) GetOpenedBetween(from, to time.Time, branchID int) ([]*models.CashShift, error) {
+	query := `SELECT ` + cashShiftColumns + ` FROM cash_shifts WHERE opened_at >= $1 AND opened_at < $2 AND ($3 = 0 OR branch_id = $3) ORDER BY opened_at ASC`
+	return r.queryShifts(query, from, to, branchID)
+}
+
+// GetBranchID gets the branch of a shift, 0 when it does not exist
+func (r *CashShiftRepository) GetBranchID(id int) (int, error) {
+	var branchID int
+	err := r.db.QueryRow(`SELECT branch_id FROM cash_shifts WHERE id = $1`, id).Scan(&branchID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return branchID, err
+}
+
+// Close records the closing count of an open shift
+func (r *CashShiftRepository) Close(shift *models.CashShift) error {
+	query := `
+		UPDATE cash_shifts
+		SET status = 'closed', expected_cash = $1, counted_cash = $2, cash_difference = $3,
+			closing_notes = $4, closed_at = $5, closed_by = $6, closed_by_name = $7, updated_at = $5
+		WHERE id = $8 AND status = 'open'
+	`
+
+	result, err := r.db.Exec(
+		query,
+		shift.ExpectedCash,
+		shift.CountedCash,
+		shift.CashDifference,
+		shift.ClosingNotes,
+		shift.ClosedAt,
+		shift.ClosedBy,
+		shift.ClosedByName,
+		shift.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("cash shift not found or already closed")
+	}
+
+	return nil
+}
+
+// CashMovement methods
+func (r *CashShiftRepository) CreateMovement(movement *models.CashMovement) error {
+	query := `
+		INSERT INTO cash_movements (shift_id, movement_type, amount, reason, created_by, created_by_name, created_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7
+		WHERE EXISTS (SELECT 1 FROM cash_shifts WHERE id = $1 AND status = 'open')
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		movement.ShiftID,
+		movement.MovementType,
+		movement.Amount,
+		movement.Reason,
+		movement.CreatedBy,
+		movement.CreatedByName,
+		movement.CreatedAt,
+	).Scan(&movement.ID, &movement.CreatedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("cash shift not found or already closed")
+	}
+	return err
+}
+
+func (r *CashShiftRepository) GetMovements(shiftID int) ([]*models.CashMovement, error) {
+	query := `
+		SELECT id, shift_id, movement_type, amount, reason, created_at, created_by, created_by_name
+		FROM cash_movements
+		WHERE shift_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(query, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []*models.CashMovement
+	for rows.Next() {
+		movement := &models.CashMovement{}
+		err := rows.Scan(
+			&movement.ID,
+			&movement.ShiftID,
+			&movement.MovementType,
+			&movement.Amount,
+			&movement.Reason,
+			&movement.CreatedAt,
+			&movement.CreatedBy,
+			&movement.CreatedByName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, nil
+}
+
+// Report methods
+
+// GetPaymentTotals gets confirmed invoice payments and customer deposits received or refunded
+// in [from, to) per payment method, optionally only those recorded by one user (userID > 0) and
+// only those recorded by cashiers during their shifts at one branch (branchID > 0).
+// Payments made from deposits were counted when the deposit was received.
+func (r *CashShiftRepository) GetPaymentTotals(userID, branchID int, from, to time.Time) ([]*models.PaymentMethodTotal, error) {
+	query := `
+		SELECT p.payment_method, COALESCE(pm.name, p.payment_method), COALESCE(pm.is_cash, false),
+			COUNT(*) FILTER (WHERE p.counted), COALESCE(SUM(p.amount), 0)
+		FROM (
+			SELECT payment_method, amount, created_by, created_at, payment_type = 'payment' AS counted
+			FROM invoice_payments
+			WHERE status = 'confirmed' AND payment_method != 'deposit'
+			UNION ALL
+			SELECT payment_method, amount, created_by, created_at, true
+			FROM customer_deposits
+			UNION ALL
+			SELECT payment_method, -amount, created_by, created_at, false
+			FROM customer_deposit_movements
+			WHERE movement_type = 'refunded'
+		) p
+		LEFT JOIN payment_methods pm ON pm.code = p.payment_method
+		WHERE p.created_at >= $1 AND p.created_at < $2
+	`
+	args := []interface{}{from, to}
+	if userID > 0 {
+		args = append(args, userID)
+		query += fmt.Sprintf(` AND p.created_by = $%d`, len(args))
+	}
+	if branchID > 0 {
+		args = append(args, branchID)
+		query += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM cash_shifts s
+			WHERE s.branch_id = $%d AND s.cashier_id = p.created_by
+				AND p.created_at >= s.opened_at AND (s.closed_at IS NULL OR p.created_at < s.closed_at)
+		)`, len(args))
+	}
+	query += ` GROUP BY p.payment_method, pm.name, pm.is_cash, pm.sort_order ORDER BY pm.sort_order ASC, p.payment_method ASC`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []*models.PaymentMethodTotal
+	for rows.Next() {
+		total := &models.PaymentMethodTotal{}
+		if err := rows.Scan(&total.PaymentMethod, &total.Name, &total.IsCash, &total.Count, &total.Amount); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	return totals, nil
+}
+
+// GetSalesTotals gets the invoices created in [from, to), optionally only those created by
+// one user (userID > 0) and at one branch (branchID > 0). Cancelled invoices are only counted.
+func (r *CashShiftRepository) GetSalesTotals(userID, branchID int, from, to time.Time) (*models.SalesTotals, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status != 'cancelled'),
+			COUNT(*) FILTER (WHERE status = 'cancelled'),
+			COALESCE(SUM(subtotal) FILTER (WHERE status != 'cancelled'), 0),
+			COALESCE(SUM(discount_amount) FILTER (WHERE status != 'cancelled'), 0),
+			COALESCE(SUM(tax_amount) FILTER (WHERE status != 'cancelled'), 0),
+			COALESCE(SUM(total_amount) FILTER (WHERE status != 'cancelled'), 0)
+		FROM invoices
+		WHERE created_at >= $1 AND created_at < $2
+	`
+	args := []interface{}{from, to}
+	if userID > 0 {
+		args = append(args, userID)
+		query += fmt.Sprintf(` AND created_by = $%d`, len(args))
+	}
+	if branchID > 0 {
+		args = append(args, branchID)
+		query += fmt.Sprintf(` AND branch_id = $%d`, len(args))
+	}
+
+	totals := &models.SalesTotals{}
+	err := r.db.QueryRow(query, args...).Scan(
+		&totals.InvoiceCount,
+		&totals.CancelledCount,
+		&totals.Subtotal,
+		&totals.DiscountAmount,
+		&totals.TaxAmount,
+		&totals.TotalAmount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// Helper methods
+type cashShiftScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCashShift(scanner cashShiftScanner) (*models.CashShift, error) {
+	shift := &models.CashShift{}
+	err := scanner.Scan(
+		&shift.ID,
+		&shift.CashierID,
+		&shift.CashierName,
+		&shift.Status,
+		&shift.OpeningFloat,
+		&shift.OpeningNotes,
+		&shift.OpenedAt,
+		&shift.ExpectedCash,
+		&shift.CountedCash,
+		&shift.CashDifference,
+		&shift.ClosingNotes,
+		&shift.ClosedAt,
+		&shift.ClosedBy,
+		&shift.ClosedByName,
+		&shift.CreatedAt,
+		&shift.UpdatedAt,
+		&shift.BranchID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
+func (r *CashShiftRepository) queryShifts(query string, args ...interface{}) ([]*models.CashShift, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shifts []*models.CashShift
+	for rows.Next() {
+		shift, err := scanCashShift(rows)
+		if err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, shift)
+	}
+
+	return shifts, nil
+}
+
+func cashShiftFilters(branchID, cashierID int, status string) (string, []interface{}) {
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argCount := 0
+
+	if branchID > 0 {
+		argCount++
+		where += fmt.Sprintf(" AND branch_id = $%d", argCount)
+		args = append(args, branchID)
+	}
+
+	if cashierID > 0 {
+		argCount++
+		where += fmt.Sprintf(" AND cashier_id = $%d", argCount)
+		args = append(args, cashierID)
+	}
+
+	if status != "" {
+		argCount++
+		where += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, status)
+	}
+
+	return where, args
+}
@@ -0,0 +1,491 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"steel-pos-backend/internal/models"
+	"time"
+)
+
+type CustomerDepositRepository struct {
+	db *sql.DB
+}
+
+func NewCustomerDepositRepository(db *sql.DB) *CustomerDepositRepository {
+	return &CustomerDepositRepository{db: db}
+}
+
+const customerDepositColumns = `
+	id, customer_id, amount, remaining_amount, payment_method, transaction_reference, notes,
+	created_at, updated_at, created_by, created_by_name`
+
+// CustomerDeposit methods
+func (r *CustomerDepositRepository) Create(deposit *models.CustomerDeposit) error {
+	query := `
+		INSERT INTO customer_deposits (customer_id, amount, remaining_amount, payment_method, transaction_reference, notes, created_by, created_by_name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		deposit.CustomerID,
+		deposit.Amount,
+		deposit.RemainingAmount,
+		deposit.PaymentMethod,
+		deposit.TransactionReference,
+		deposit.Notes,
+		deposit.CreatedBy,
+		deposit.CreatedByName,
+		deposit.CreatedAt,
+		deposit.UpdatedAt,
+	).Scan(&deposit.ID, &deposit.CreatedAt, &deposit.UpdatedAt)
+}
+
+func (r *CustomerDepositRepository) GetByID(id int) (*models.CustomerDeposit, error) {
+	deposit, err := scanCustomerDeposit(r.db.QueryRow(`SELECT `+customerDepositColumns+` FROM customer_deposits WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return deposit, nil
+}
+
+func (r *CustomerDepositRepository) GetByCustomer(customerID int) ([]*models.CustomerDeposit, error) {
+	query := `SELECT ` + customerDepositColumns + ` FROM customer_deposits WHERE customer_id = $1 ORDER BY created_at ASC, id ASC`
+
+	rows, err := r.db.Query(query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deposits []*models.CustomerDeposit
+	for rows.Next() {
+		deposit, err := scanCustomerDeposit(rows)
+		if err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, deposit)
+	}
+
+	return deposits, nil
+}
+
+// GetBalance gets the deposit amount of a customer not yet applied or refunded
+func (r *CustomerDepositRepository) GetBalance(customerID int) (float64, error) {
+	var balance float64
+	err := r.db.QueryRow(`SELECT COALESCE(SUM(remaining_amount), 0) FROM customer_deposits WHERE customer_id = $1`, customerID).Scan(&balance)
+	return balance, err
+}
+
+// CustomerDepositMovement methods
+func (r *CustomerDepositRepository) GetMovements(depositID int) ([]*models.CustomerDepositMovement, error) {
+	query := `
+		SELECT id, deposit_id, movement_type, amount, invoice_id, invoice_payment_id, payment_method,
+			transaction_reference, reason, created_at, created_by, created_by_name
+		FROM customer_deposit_movements
+		WHERE deposit_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.Query(query, depositID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []*models.CustomerDepositMovement
+	for rows.Next() {
+		movement := &models.CustomerDepositMovement{}
+		err := rows.Scan(
+			&movement.ID,
+			&movement.DepositID,
+			&movement.MovementType,
+			&movement.Amount,
+			&movement.InvoiceID,
+			&movement.InvoicePaymentID,
+			&movement.PaymentMethod,
+			&movement.TransactionReference,
+			&movement.Reason,
+			&movement.CreatedAt,
+			&movement.CreatedBy,
+			&movement.CreatedByName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, nil
+}
+
+// Apply pays an invoice of the customer from the customer's deposits, oldest first. It applies
+// the amount due, or at most maxAmount when maxAmount > 0, and records one invoice payment per
+// deposit used. Without deposit balance nothing is applied.
+func (r *CustomerDepositRepository) Apply(customerID, invoiceID int, maxAmount float64, createdBy int, createdByName string) ([]*models.CustomerDepositMovement, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var totalAmount, paidAmount float64
+	var status string
+	err = tx.QueryRow(`SELECT total_amount, paid_amount, status FROM invoices WHERE id = $1 AND customer_id = $2 FOR UPDATE`, invoiceID, customerID).
+		Scan(&totalAmount, &paidAmount, &status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("invoice not found for this customer")
+		}
+		return nil, err
+	}
+
+	if status == "cancelled" {
+		return nil, errors.New("cannot apply deposits to a cancelled invoice")
+	}
+
+	due := math.Round((totalAmount-paidAmount)*100) / 100
+	if due <= 0 {
+		return nil, errors.New("invoice is already paid")
+	}
+
+	amount := due
+	if maxAmount > 0 {
+		if maxAmount > due {
+			return nil, fmt.Errorf("amount exceeds the %.0f due on the invoice", due)
+		}
+		amount = maxAmount
+	}
+
+	rows, err := tx.Query(`SELECT id, remaining_amount FROM customer_deposits WHERE customer_id = $1 AND remaining_amount > 0 ORDER BY created_at ASC, id ASC FOR UPDATE`, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var deposits []*models.CustomerDeposit
+	for rows.Next() {
+		deposit := &models.CustomerDeposit{}
+		if err := rows.Scan(&deposit.ID, &deposit.RemainingAmount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		deposits = append(deposits, deposit)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	uses, left := allocateDeposits(amount, deposits)
+
+	now := time.Now()
+	var movements []*models.CustomerDepositMovement
+	for _, use := range uses {
+		deposit, used := use.deposit, use.amount
+
+		notes := fmt.Sprintf("Deposit #%d", deposit.ID)
+		payment := &models.InvoicePayment{
+			InvoiceID:         invoiceID,
+			Amount:            used,
+			PaymentMethod:     models.PaymentMethodDeposit,
+			PaymentDate:       now,
+			Notes:             &notes,
+			PaymentType:       models.PaymentTypePayment,
+			Status:            "confirmed",
+			CreatedBy:         &createdBy,
+			CreatedByUsername: &createdByName,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+		if err := insertInvoicePayment(tx, payment); err != nil {
+			return nil, err
+		}
+
+		_, err := tx.Exec(`UPDATE customer_deposits SET remaining_amount = remaining_amount - $1, updated_at = $2 WHERE id = $3`, used, now, deposit.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		movement := &models.CustomerDepositMovement{
+			DepositID:        deposit.ID,
+			MovementType:     models.DepositMovementApplied,
+			Amount:           used,
+			InvoiceID:        &invoiceID,
+			InvoicePaymentID: &payment.ID,
+			CreatedAt:        now,
+			CreatedBy:        &createdBy,
+			CreatedByName:    &createdByName,
+		}
+		if err := insertDepositMovement(tx, movement); err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	if maxAmount > 0 && left > 0 {
+		return nil, errors.New("amount exceeds the customer's deposit balance")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+// Refund records money of a deposit paid back to the customer
+func (r *CustomerDepositRepository) Refund(movement *models.CustomerDepositMovement) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		`UPDATE customer_deposits SET remaining_amount = remaining_amount - $1, updated_at = $2 WHERE id = $3 AND remaining_amount >= $1`,
+		movement.Amount, movement.CreatedAt, movement.DepositID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("refund exceeds the remaining deposit")
+	}
+
+	if err := insertDepositMovement(tx, movement); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Ledger methods
+
+// GetLedgerEntries gets the invoices, payments and deposit movements of a customer in date order.
+// Payments made from deposits are returned as deposit_applied entries only.
+func (r *CustomerDepositRepository) GetLedgerEntries(customerID int) ([]*models.CustomerLedgerEntry, error) {
+	query := `
+		SELECT date, entry_type, reference, description, invoice_id, deposit_id, debit, credit
+		FROM (
+			SELECT i.created_at AS date, 'invoice' AS entry_type, i.invoice_code AS reference,
+				COALESCE(i.notes, '') AS description, i.id AS invoice_id, NULL::INTEGER AS deposit_id,
+				i.total_amount AS debit, 0::DECIMAL(15,2) AS credit, 1 AS sort_order
+			FROM invoices i
+			WHERE i.customer_id = $1 AND i.status != 'cancelled'
+
+			UNION ALL
+
+			SELECT p.payment_date, 'payment', i.invoice_code, COALESCE(pm.name, p.payment_method),
+				i.id, NULL, 0, p.amount, 2
+			FROM invoice_payments p
+			JOIN invoices i ON i.id = p.invoice_id
+			LEFT JOIN payment_methods pm ON pm.code = p.payment_method
+			WHERE i.customer_id = $1 AND i.status != 'cancelled' AND p.status = 'confirmed'
+				AND p.payment_method != 'deposit'
+
+			UNION ALL
+
+			SELECT d.created_at, 'deposit', COALESCE(d.transaction_reference, ''),
+				COALESCE(pm.name, d.payment_method), NULL, d.id, 0, d.amount, 0
+			FROM customer_deposits d
+			LEFT JOIN payment_methods pm ON pm.code = d.payment_method
+			WHERE d.customer_id = $1
+
+			UNION ALL
+
+			SELECT m.created_at, 'deposit_applied', i.invoice_code, '', m.invoice_id, m.deposit_id,
+				m.amount, m.amount, 3
+			FROM customer_deposit_movements m
+			JOIN customer_deposits d ON d.id = m.deposit_id
+			JOIN invoices i ON i.id = m.invoice_id
+			WHERE d.customer_id = $1 AND m.movement_type = 'applied'
+
+			UNION ALL
+
+			SELECT m.created_at, 'deposit_released', i.invoice_code, '', m.invoice_id, m.deposit_id,
+				m.amount, m.amount, 5
+			FROM customer_deposit_movements m
+			JOIN customer_deposits d ON d.id = m.deposit_id
+			JOIN invoices i ON i.id = m.invoice_id
+			WHERE d.customer_id = $1 AND m.movement_type = 'released'
+
+			UNION ALL
+
+			SELECT m.created_at, 'deposit_refunded', COALESCE(m.transaction_reference, ''),
+				COALESCE(m.reason, ''), NULL, m.deposit_id, m.amount, 0, 4
+			FROM customer_deposit_movements m
+			JOIN customer_deposits d ON d.id = m.deposit_id
+			WHERE d.customer_id = $1 AND m.movement_type = 'refunded'
+		) entries
+		ORDER BY date ASC, sort_order ASC
+	`
+
+	rows, err := r.db.Query(query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.CustomerLedgerEntry
+	for rows.Next() {
+		entry := &models.CustomerLedgerEntry{}
+		err := rows.Scan(
+			&entry.Date,
+			&entry.EntryType,
+			&entry.Reference,
+			&entry.Description,
+			&entry.InvoiceID,
+			&entry.DepositID,
+			&entry.Debit,
+			&entry.Credit,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Helper methods
+type customerDepositScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCustomerDeposit(scanner customerDepositScanner) (*models.CustomerDeposit, error) {
+	deposit := &models.CustomerDeposit{}
+	err := scanner.Scan(
+		&deposit.ID,
+		&deposit.CustomerID,
+		&deposit.Amount,
+		&deposit.RemainingAmount,
+		&deposit.PaymentMethod,
+		&deposit.TransactionReference,
+		&deposit.Notes,
+		&deposit.CreatedAt,
+		&deposit.UpdatedAt,
+		&deposit.CreatedBy,
+		&deposit.CreatedByName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return deposit, nil
+}
+
+// depositUse is the part of a deposit used to pay an invoice
+type depositUse struct {
+	deposit *models.CustomerDeposit
+	amount  float64
+}
+
+// allocateDeposits takes amount from the deposits in order, each giving at most what is left of
+// it, and returns what is taken from each deposit and the part of amount they do not cover
+func allocateDeposits(amount float64, deposits []*models.CustomerDeposit) ([]depositUse, float64) {
+	var uses []depositUse
+	for _, deposit := range deposits {
+		if amount <= 0 {
+			break
+		}
+		if deposit.RemainingAmount <= 0 {
+			continue
+		}
+
+		used := math.Min(amount, deposit.RemainingAmount)
+		amount = math.Round((amount-used)*100) / 100
+		uses = append(uses, depositUse{deposit: deposit, amount: used})
+	}
+	return uses, amount
+}
+
+// releaseDepositPayments gives the deposit payments of an invoice back to their deposits, only
+// paymentID when paymentID > 0. Payments already released are skipped.
+func releaseDepositPayments(tx *sql.Tx, invoiceID, paymentID int, createdBy int, createdByName string) ([]*models.CustomerDepositMovement, error) {
+	rows, err := tx.Query(`
+		SELECT m.deposit_id, m.amount, m.invoice_payment_id
+		FROM customer_deposit_movements m
+		WHERE m.movement_type = 'applied' AND m.invoice_id = $1 AND ($2 = 0 OR m.invoice_payment_id = $2)
+			AND NOT EXISTS (
+				SELECT 1 FROM customer_deposit_movements r
+				WHERE r.movement_type = 'released' AND r.invoice_payment_id = m.invoice_payment_id
+			)
+		ORDER BY m.id ASC
+	`, invoiceID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var movements []*models.CustomerDepositMovement
+	for rows.Next() {
+		movement := &models.CustomerDepositMovement{
+			MovementType:  models.DepositMovementReleased,
+			InvoiceID:     &invoiceID,
+			CreatedAt:     now,
+			CreatedBy:     &createdBy,
+			CreatedByName: &createdByName,
+		}
+		if err := rows.Scan(&movement.DepositID, &movement.Amount, &movement.InvoicePaymentID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, movement := range movements {
+		_, err := tx.Exec(`UPDATE customer_deposits SET remaining_amount = remaining_amount + $1, updated_at = $2 WHERE id = $3`, movement.Amount, now, movement.DepositID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := insertDepositMovement(tx, movement); err != nil {
+			return nil, err
+		}
+	}
+
+	return movements, nil
+}
+
+func insertDepositMovement(q rowQuerier, movement *models.CustomerDepositMovement) error {
+	query := `
+		INSERT INTO customer_deposit_movements (
+			deposit_id, movement_type, amount, invoice_id, invoice_payment_id, payment_method,
+			transaction_reference, reason, created_at, created_by, created_by_name
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at
+	`
+
+	return q.QueryRow(
+		query,
+		movement.DepositID,
+		movement.MovementType,
+		movement.Amount,
+		movement.InvoiceID,
+		movement.InvoicePaymentID,
+		movement.PaymentMethod,
+		movement.TransactionReference,
+		movement.Reason,
+		movement.CreatedAt,
+		movement.CreatedBy,
+		movement.CreatedByName,
+	).Scan(&movement.ID, &movement.CreatedAt)
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"testing"
+
+	"steel-pos-backend/internal/models"
+)
+
+func TestAllocateDeposits(t *testing.T) {
+	newDeposits := func(remaining ...float64) []*models.CustomerDeposit {
+		deposits := make([]*models.CustomerDeposit, len(remaining))
+		for i, amount := range remaining {
+			deposits[i] = &models.CustomerDeposit{ID: i + 1, RemainingAmount: amount}
+		}
+		return deposits
+	}
+
+	tests := []struct {
+		name     string
+		amount   float64
+		deposits []*models.CustomerDeposit
+		wantUses map[int]float64 // deposit ID -> amount used
+		wantLeft float64
+	}{
+		{
+			name:     "oldest deposit covers the amount",
+			amount:   3000000,
+			deposits: newDeposits(5000000, 2000000),
+			wantUses: map[int]float64{1: 3000000},
+		},
+		{
+			name:     "spread over deposits in order",
+			amount:   6000000,
+			deposits: newDeposits(5000000, 2000000),
+			wantUses: map[int]float64{1: 5000000, 2: 1000000},
+		},
+		{
+			name:     "used up deposits are skipped",
+			amount:   1500000,
+			deposits: newDeposits(0, 2000000),
+			wantUses: map[int]float64{2: 1500000},
+		},
+		{
+			name:     "deposits do not cover the amount",
+			amount:   8000000.5,
+			deposits: newDeposits(5000000, 2000000),
+			wantUses: map[int]float64{1: 5000000, 2: 2000000},
+			wantLeft: 1000000.5,
+		},
+		{
+			name:     "no deposits",
+			amount:   100000,
+			wantUses: map[int]float64{},
+			wantLeft: 100000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uses, left := allocateDeposits(tt.amount, tt.deposits)
+
+			if left != tt.wantLeft {
+				t.Fatalf("left %v, want %v", left, tt.wantLeft)
+			}
+			if len(uses) != len(tt.wantUses) {
+				t.Fatalf("used %d deposits, want %d", len(uses), len(tt.wantUses))
+			}
+			for i, use := range uses {
+				if want, ok := tt.wantUses[use.deposit.ID]; !ok || use.amount != want {
+					t.Fatalf("deposit %d used %v, want %v", use.deposit.ID, use.amount, want)
+				}
+				if i > 0 && use.deposit.ID < uses[i-1].deposit.ID {
+					t.Fatalf("deposit %d used before deposit %d", uses[i-1].deposit.ID, use.deposit.ID)
+				}
+			}
+		})
+	}
+}
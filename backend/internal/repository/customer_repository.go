@@ -38,7 +38,7 @@ func (r *CustomerRepository) GetAllCustomers(page, limit int) ([]*models.Custome
 	// Get customers with pagination
 	query := `
 		SELECT 
-			id, name, phone, address, is_active,
+			id, name, phone, address, tax_code, is_active,
 			created_by, created_at, updated_at
 		FROM customers 
 		WHERE is_active = true
@@ -60,6 +60,7 @@ func (r *CustomerRepository) GetAllCustomers(page, limit int) ([]*models.Custome
 			&customer.Name,
 			&customer.Phone,
 			&customer.Address,
+			&customer.TaxCode,
 			&customer.IsActive,
 			&customer.CreatedBy,
 			&customer.CreatedAt,
@@ -101,7 +102,7 @@ func (r *CustomerRepository) SearchCustomers(query string, limit int) ([]*models
 	// Search customers with pagination
 	searchQuery := `
 		SELECT 
-			id, name, phone, address, is_active,
+			id, name, phone, address, tax_code, is_active,
 			created_by, created_at, updated_at
 		FROM customers 
 		WHERE (name ILIKE $1 OR phone ILIKE $1)
@@ -130,6 +131,7 @@ func (r *CustomerRepository) SearchCustomers(query string, limit int) ([]*models
 			&customer.Name,
 			&customer.Phone,
 			&customer.Address,
+			&customer.TaxCode,
 			&customer.IsActive,
 			&customer.CreatedBy,
 			&customer.CreatedAt,
@@ -152,7 +154,7 @@ func (r *CustomerRepository) SearchCustomers(query string, limit int) ([]*models
 func (r *CustomerRepository) GetByID(id int) (*models.Customer, error) {
 	query := `
 		SELECT 
-			id, name, phone, address, is_active,
+			id, name, phone, address, tax_code, is_active,
 			created_by, created_at, updated_at
 		FROM customers 
 		WHERE id = $1 AND is_active = true
@@ -164,6 +166,7 @@ func (r *CustomerRepository) GetByID(id int) (*models.Customer, error) {
 		&customer.Name,
 		&customer.Phone,
 		&customer.Address,
+		&customer.TaxCode,
 		&customer.IsActive,
 		&customer.CreatedBy,
 		&customer.CreatedAt,
@@ -184,7 +187,7 @@ func (r *CustomerRepository) GetByID(id int) (*models.Customer, error) {
 func (r *CustomerRepository) GetByPhone(phone string) (*models.Customer, error) {
 	query := `
 		SELECT 
-			id, name, phone, address, is_active,
+			id, name, phone, address, tax_code, is_active,
 			created_by, created_at, updated_at
 		FROM customers 
 		WHERE phone = $1 AND is_active = true
@@ -196,6 +199,7 @@ func (r *CustomerRepository) GetByPhone(phone string) (*models.Customer, error)
 		&customer.Name,
 		&customer.Phone,
 		&customer.Address,
+		&customer.TaxCode,
 		&customer.IsActive,
 		&customer.CreatedBy,
 		&customer.CreatedAt,
@@ -215,8 +219,8 @@ func (r *CustomerRepository) GetByPhone(phone string) (*models.Customer, error)
 // Create creates a new customer
 func (r *CustomerRepository) Create(customer *models.Customer) (*models.Customer, error) {
 	query := `
-		INSERT INTO customers (name, phone, address, is_active, created_by)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO customers (name, phone, address, tax_code, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -225,6 +229,7 @@ func (r *CustomerRepository) Create(customer *models.Customer) (*models.Customer
 		customer.Name,
 		customer.Phone,
 		customer.Address,
+		customer.TaxCode,
 		customer.IsActive,
 		customer.CreatedBy,
 	).Scan(&customer.ID, &customer.CreatedAt, &customer.UpdatedAt)
@@ -265,7 +270,7 @@ func (r *CustomerRepository) Update(id int, updateData map[string]interface{}, u
 		UPDATE customers 
 		SET %s
 		WHERE id = $%d AND is_active = true
-		RETURNING id, name, phone, address, is_active, created_by, created_at, updated_at
+		RETURNING id, name, phone, address, tax_code, is_active, created_by, created_at, updated_at
 	`, strings.Join(setParts, ", "), argIndex)
 
 	customer := &models.Customer{}
@@ -274,6 +279,7 @@ func (r *CustomerRepository) Update(id int, updateData map[string]interface{}, u
 		&customer.Name,
 		&customer.Phone,
 		&customer.Address,
+		&customer.TaxCode,
 		&customer.IsActive,
 		&customer.CreatedBy,
 		&customer.CreatedAt,
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"steel-pos-backend/internal/models"
+	"time"
+)
+
+type EInvoiceRepository struct {
+	db *sql.DB
+}
+
+func NewEInvoiceRepository(db *sql.DB) *EInvoiceRepository {
+	return &EInvoiceRepository{db: db}
+}
+
+const einvoiceColumns = `
+	id, invoice_id, template_code, series, invoice_number, issue_date, buyer_tax_code,
+	xml_content, status, provider, provider_reference, lookup_code, error_message,
+	submitted_at, created_at, updated_at, created_by, created_by_name`
+
+// Create stores a new e-invoice under the next number of its template and series. The invoice
+// is locked and must not have changed since invoiceUpdatedAt, the version the e-invoice is
+// rendered from. render is called with the number taken and returns the XML of the e-invoice;
+// when it fails nothing is stored and the number is given back, so numbers have no gaps.
+func (r *EInvoiceRepository) Create(einvoice *models.EInvoice, invoiceUpdatedAt time.Time, render func(number int) (string, error)) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	var updatedAt time.Time
+	err = tx.QueryRow(`SELECT status, updated_at FROM invoices WHERE id = $1 FOR UPDATE`, einvoice.InvoiceID).Scan(&status, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("invoice not found")
+		}
+		return err
+	}
+
+	if status != "confirmed" || !updatedAt.Equal(invoiceUpdatedAt) {
+		return errors.New("invoice changed while its e-invoice was generated, generate it again")
+	}
+
+	counterQuery := `
+		INSERT INTO einvoice_number_counters (template_code, series, last_number)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (template_code, series)
+		DO UPDATE SET last_number = einvoice_number_counters.last_number + 1
+		RETURNING last_number
+	`
+
+	var number int
+	if err := tx.QueryRow(counterQuery, einvoice.TemplateCode, einvoice.Series).Scan(&number); err != nil {
+		return err
+	}
+
+	xmlContent, err := render(number)
+	if err != nil {
+		return err
+	}
+
+	einvoice.InvoiceNumber = number
+	einvoice.XMLContent = xmlContent
+
+	query := `
+		INSERT INTO einvoices (
+			invoice_id, template_code, series, invoice_number, issue_date, buyer_tax_code,
+			xml_content, status, created_by, created_by_name, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		einvoice.InvoiceID,
+		einvoice.TemplateCode,
+		einvoice.Series,
+		einvoice.InvoiceNumber,
+		einvoice.IssueDate.Format("2006-01-02"), // the date printed on the e-invoice, whatever the session time zone
+		einvoice.BuyerTaxCode,
+		einvoice.XMLContent,
+		einvoice.Status,
+		einvoice.CreatedBy,
+		einvoice.CreatedByName,
+		einvoice.CreatedAt,
+		einvoice.UpdatedAt,
+	).Scan(&einvoice.ID, &einvoice.CreatedAt, &einvoice.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *EInvoiceRepository) GetByID(id int) (*models.EInvoice, error) {
+	return r.getOne(`SELECT `+einvoiceColumns+` FROM einvoices WHERE id = $1`, id)
+}
+
+func (r *EInvoiceRepository) GetByInvoiceID(invoiceID int) (*models.EInvoice, error) {
+	return r.getOne(`SELECT `+einvoiceColumns+` FROM einvoices WHERE invoice_id = $1`, invoiceID)
+}
+
+// UpdateContent replaces the rendered XML of an e-invoice that was not submitted yet
+func (r *EInvoiceRepository) UpdateContent(einvoice *models.EInvoice) error {
+	query := `
+		UPDATE einvoices
+		SET issue_date = $1, buyer_tax_code = $2, xml_content = $3, status = $4,
+			error_message = NULL, updated_at = $5
+		WHERE id = $6 AND status != 'submitted'
+	`
+
+	result, err := r.db.Exec(
+		query,
+		einvoice.IssueDate.Format("2006-01-02"),
+		einvoice.BuyerTaxCode,
+		einvoice.XMLContent,
+		einvoice.Status,
+		einvoice.UpdatedAt,
+		einvoice.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("e-invoice not found or already submitted")
+	}
+
+	return nil
+}
+
+// UpdateSubmission records the outcome of submitting an e-invoice
+func (r *EInvoiceRepository) UpdateSubmission(einvoice *models.EInvoice) error {
+	query := `
+		UPDATE einvoices
+		SET status = $1, provider = $2, provider_reference = $3, lookup_code = $4,
+			error_message = $5, submitted_at = $6, updated_at = $7
+		WHERE id = $8
+	`
+
+	result, err := r.db.Exec(
+		query,
+		einvoice.Status,
+		einvoice.Provider,
+		einvoice.ProviderReference,
+		einvoice.LookupCode,
+		einvoice.ErrorMessage,
+		einvoice.SubmittedAt,
+		einvoice.UpdatedAt,
+		einvoice.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("e-invoice not found")
+	}
+
+	return nil
+}
+
+// Helper methods
+func (r *EInvoiceRepository) getOne(query string, arg interface{}) (*models.EInvoice, error) {
+	einvoice := &models.EInvoice{}
+	err := r.db.QueryRow(query, arg).Scan(
+		&einvoice.ID,
+		&einvoice.InvoiceID,
+		&einvoice.TemplateCode,
+		&einvoice.Series,
+		&einvoice.InvoiceNumber,
+		&einvoice.IssueDate,
+		&einvoice.BuyerTaxCode,
+		&einvoice.XMLContent,
+		&einvoice.Status,
+		&einvoice.Provider,
+		&einvoice.ProviderReference,
+		&einvoice.LookupCode,
+		&einvoice.ErrorMessage,
+		&einvoice.SubmittedAt,
+		&einvoice.CreatedAt,
+		&einvoice.UpdatedAt,
+		&einvoice.CreatedBy,
+		&einvoice.CreatedByName,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return einvoice, nil
+}
@@ -20,8 +20,8 @@ func NewImportOrderRepository(db *sql.DB) *ImportOrderRepository {
 // ImportOrder methods
 func (r *ImportOrderRepository) Create(order *models.ImportOrder) error {
 	query := `
-		INSERT INTO import_orders (import_code, supplier_name, import_date, total_value, status, approval_note, import_images, created_by, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO import_orders (import_code, supplier_name, import_date, total_value, status, approval_note, import_images, created_by, created_at, updated_at, branch_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -37,6 +37,7 @@ func (r *ImportOrderRepository) Create(order *models.ImportOrder) error {
 		order.CreatedBy,
 		order.CreatedAt,
 		order.UpdatedAt,
+		order.BranchID,
 	).Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
 
 	return err
@@ -44,7 +45,7 @@ func (r *ImportOrderRepository) Create(order *models.ImportOrder) error {
 
 func (r *ImportOrderRepository) GetByID(id int) (*models.ImportOrder, error) {
 	query := `
-		SELECT id, import_code, supplier_name, import_date, total_value, status, approval_note, import_images, approved_by, approved_at, approval_note, created_by, created_at, updated_at
+		SELECT id, import_code, supplier_name, import_date, total_value, status, approval_note, import_images, approved_by, approved_at, approval_note, created_by, created_at, updated_at, branch_id
 		FROM import_orders
 		WHERE id = $1
 	`
@@ -66,6 +67,7 @@ func (r *ImportOrderRepository) GetByID(id int) (*models.ImportOrder, error) {
 		&order.CreatedBy,
 		&order.CreatedAt,
 		&order.UpdatedAt,
+		&order.BranchID,
 	)
 
 	if err == nil {
@@ -82,9 +84,10 @@ func (r *ImportOrderRepository) GetByID(id int) (*models.ImportOrder, error) {
 	return order, nil
 }
 
-func (r *ImportOrderRepository) GetAll(limit, offset int, status string) ([]*models.ImportOrder, error) {
+// GetAll gets import orders, of one branch when branchID > 0
+func (r *ImportOrderRepository) GetAll(limit, offset, branchID int, status string) ([]*models.ImportOrder, error) {
 	query := `
-		SELECT id, import_code, supplier_name, import_date, total_value, status, approval_note, import_images, approved_by, approved_at, approval_note, created_by, created_at, updated_at
+		SELECT id, import_code, supplier_name, import_date, total_value, status, approval_note, import_images, approved_by, approved_at, approval_note, created_by, created_at, updated_at, branch_id
 		FROM import_orders
 		WHERE 1=1
 	`
@@ -92,6 +95,12 @@ func (r *ImportOrderRepository) GetAll(limit, offset int, status string) ([]*mod
 	args := []interface{}{}
 	argCount := 1
 
+	if branchID > 0 {
+		query += fmt.Sprintf(" AND branch_id = $%d", argCount)
+		args = append(args, branchID)
+		argCount++
+	}
+
 	if status != "" {
 		query += fmt.Sprintf(" AND status = $%d", argCount)
 		args = append(args, status)
@@ -126,6 +135,7 @@ func (r *ImportOrderRepository) GetAll(limit, offset int, status string) ([]*mod
 			&order.CreatedBy,
 			&order.CreatedAt,
 			&order.UpdatedAt,
+			&order.BranchID,
 		)
 		if err != nil {
 			return nil, err
@@ -213,12 +223,28 @@ func (r *ImportOrderRepository) Approve(id int, approvedBy int, approvalNote str
 	return tx.Commit()
 }
 
-func (r *ImportOrderRepository) Count(status string) (int, error) {
+// GetBranchID gets the branch of an import order, 0 when it does not exist
+func (r *ImportOrderRepository) GetBranchID(id int) (int, error) {
+	var branchID int
+	err := r.db.QueryRow(`SELECT branch_id FROM import_orders WHERE id = $1`, id).Scan(&branchID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return branchID, err
+}
+
+func (r *ImportOrderRepository) Count(branchID int, status string) (int, error) {
 	query := `SELECT COUNT(*) FROM import_orders WHERE 1=1`
 
 	args := []interface{}{}
 	argCount := 1
 
+	if branchID > 0 {
+		query += fmt.Sprintf(" AND branch_id = $%d", argCount)
+		args = append(args, branchID)
+		argCount++
+	}
+
 	if status != "" {
 		query += fmt.Sprintf(" AND status = $%d", argCount)
 		args = append(args, status)
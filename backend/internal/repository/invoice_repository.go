@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"steel-pos-backend/internal/models"
+	"time"
 )
 
 type InvoiceRepository struct {
@@ -18,48 +19,44 @@ func NewInvoiceRepository(db *sql.DB) *InvoiceRepository {
 
 // Invoice methods
 func (r *InvoiceRepository) CreateInvoice(invoice *models.Invoice) error {
-	query := `
-		INSERT INTO invoices (
-			invoice_code, customer_id, customer_phone, customer_name, customer_address,
-			subtotal, discount_amount, discount_percentage, tax_amount, tax_percentage,
-			total_amount, paid_amount, payment_status, status, notes,
-			created_by, created_at, updated_at
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
-		RETURNING id, created_at, updated_at
-	`
+	return insertInvoice(r.db, invoice)
+}
 
-	err := r.db.QueryRow(
-		query,
-		invoice.InvoiceCode,
-		invoice.CustomerID,
-		invoice.CustomerPhone,
-		invoice.CustomerName,
-		invoice.CustomerAddress,
-		invoice.Subtotal,
-		invoice.DiscountAmount,
-		invoice.DiscountPercentage,
-		invoice.TaxAmount,
-		invoice.TaxPercentage,
-		invoice.TotalAmount,
-		invoice.PaidAmount,
-		invoice.PaymentStatus,
-		invoice.Status,
-		invoice.Notes,
-		invoice.CreatedBy,
-		invoice.CreatedAt,
-		invoice.UpdatedAt,
-	).Scan(&invoice.ID, &invoice.CreatedAt, &invoice.UpdatedAt)
+// CreateInvoiceWithDetails creates an invoice with its items and payments in one transaction
+func (r *InvoiceRepository) CreateInvoiceWithDetails(invoice *models.Invoice, items []*models.InvoiceItem, payments []*models.InvoicePayment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	return err
+	if err := insertInvoice(tx, invoice); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		item.InvoiceID = invoice.ID
+		if err := insertInvoiceItem(tx, item); err != nil {
+			return err
+		}
+	}
+
+	for _, payment := range payments {
+		payment.InvoiceID = invoice.ID
+		if err := insertInvoicePayment(tx, payment); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (r *InvoiceRepository) GetInvoiceByID(id int) (*models.Invoice, error) {
 	query := `
 		SELECT id, invoice_code, customer_id, customer_phone, customer_name, customer_address,
-			   subtotal, discount_amount, discount_percentage, tax_amount, tax_percentage,
+			   subtotal, discount_amount, discount_percentage, tax_amount, tax_percentage, price_mode,
 			   total_amount, paid_amount, payment_status, status, notes,
-			   created_at, updated_at, created_by
+			   created_at, updated_at, created_by, branch_id
 		FROM invoices
 		WHERE id = $1
 	`
@@ -77,6 +74,7 @@ func (r *InvoiceRepository) GetInvoiceByID(id int) (*models.Invoice, error) {
 		&invoice.DiscountPercentage,
 		&invoice.TaxAmount,
 		&invoice.TaxPercentage,
+		&invoice.PriceMode,
 		&invoice.TotalAmount,
 		&invoice.PaidAmount,
 		&invoice.PaymentStatus,
@@ -85,6 +83,7 @@ func (r *InvoiceRepository) GetInvoiceByID(id int) (*models.Invoice, error) {
 		&invoice.CreatedAt,
 		&invoice.UpdatedAt,
 		&invoice.CreatedBy,
+		&invoice.BranchID,
 	)
 
 	if err != nil {
@@ -105,9 +104,9 @@ func (r *InvoiceRepository) GetInvoiceByID(id int) (*models.Invoice, error) {
 func (r *InvoiceRepository) GetInvoiceByCode(code string) (*models.Invoice, error) {
 	query := `
 		SELECT id, invoice_code, customer_id, customer_phone, customer_name, customer_address,
-			   subtotal, discount_amount, discount_percentage, tax_amount, tax_percentage,
+			   subtotal, discount_amount, discount_percentage, tax_amount, tax_percentage, price_mode,
 			   total_amount, paid_amount, payment_status, status, notes,
-			   created_at, updated_at, created_by
+			   created_at, updated_at, created_by, branch_id
 		FROM invoices
 		WHERE invoice_code = $1
 	`
@@ -125,6 +124,7 @@ func (r *InvoiceRepository) GetInvoiceByCode(code string) (*models.Invoice, erro
 		&invoice.DiscountPercentage,
 		&invoice.TaxAmount,
 		&invoice.TaxPercentage,
+		&invoice.PriceMode,
 		&invoice.TotalAmount,
 		&invoice.PaidAmount,
 		&invoice.PaymentStatus,
@@ -133,6 +133,7 @@ func (r *InvoiceRepository) GetInvoiceByCode(code string) (*models.Invoice, erro
 		&invoice.CreatedAt,
 		&invoice.UpdatedAt,
 		&invoice.CreatedBy,
+		&invoice.BranchID,
 	)
 
 	if err != nil {
@@ -150,12 +151,13 @@ func (r *InvoiceRepository) GetInvoiceByCode(code string) (*models.Invoice, erro
 	return invoice, nil
 }
 
-func (r *InvoiceRepository) GetAllInvoices(limit, offset int, search string, status string, paymentStatus string) ([]*models.Invoice, error) {
+// GetAllInvoices gets invoices, of one branch when branchID > 0
+func (r *InvoiceRepository) GetAllInvoices(limit, offset, branchID int, search string, status string, paymentStatus string) ([]*models.Invoice, error) {
 	query := `
 		SELECT id, invoice_code, customer_id, customer_phone, customer_name, customer_address,
-			   subtotal, discount_amount, discount_percentage, tax_amount, tax_percentage,
+			   subtotal, discount_amount, discount_percentage, tax_amount, tax_percentage, price_mode,
 			   total_amount, paid_amount, payment_status, status, notes,
-			   created_at, updated_at, created_by
+			   created_at, updated_at, created_by, branch_id
 		FROM invoices
 		WHERE 1=1
 	`
@@ -163,6 +165,12 @@ func (r *InvoiceRepository) GetAllInvoices(limit, offset int, search string, sta
 	args := []interface{}{}
 	argCount := 1
 
+	if branchID > 0 {
+		query += fmt.Sprintf(" AND branch_id = $%d", argCount)
+		args = append(args, branchID)
+		argCount++
+	}
+
 	if search != "" {
 		query += fmt.Sprintf(" AND (invoice_code ILIKE $%d OR customer_name ILIKE $%d OR customer_phone ILIKE $%d)", argCount, argCount, argCount)
 		args = append(args, "%"+search+"%")
@@ -205,6 +213,7 @@ func (r *InvoiceRepository) GetAllInvoices(limit, offset int, search string, sta
 			&invoice.DiscountPercentage,
 			&invoice.TaxAmount,
 			&invoice.TaxPercentage,
+			&invoice.PriceMode,
 			&invoice.TotalAmount,
 			&invoice.PaidAmount,
 			&invoice.PaymentStatus,
@@ -213,6 +222,7 @@ func (r *InvoiceRepository) GetAllInvoices(limit, offset int, search string, sta
 			&invoice.CreatedAt,
 			&invoice.UpdatedAt,
 			&invoice.CreatedBy,
+			&invoice.BranchID,
 		)
 		if err != nil {
 			return nil, err
@@ -223,12 +233,18 @@ func (r *InvoiceRepository) GetAllInvoices(limit, offset int, search string, sta
 	return invoices, nil
 }
 
-func (r *InvoiceRepository) CountInvoices(search string, status string, paymentStatus string) (int, error) {
+func (r *InvoiceRepository) CountInvoices(branchID int, search string, status string, paymentStatus string) (int, error) {
 	query := `SELECT COUNT(*) FROM invoices WHERE 1=1`
 
 	args := []interface{}{}
 	argCount := 1
 
+	if branchID > 0 {
+		query += fmt.Sprintf(" AND branch_id = $%d", argCount)
+		args = append(args, branchID)
+		argCount++
+	}
+
 	if search != "" {
 		query += fmt.Sprintf(" AND (invoice_code ILIKE $%d OR customer_name ILIKE $%d OR customer_phone ILIKE $%d)", argCount, argCount, argCount)
 		args = append(args, "%"+search+"%")
@@ -252,15 +268,37 @@ func (r *InvoiceRepository) CountInvoices(search string, status string, paymentS
 	return count, err
 }
 
+// GetInvoiceBranchID gets the branch of an invoice, 0 when it does not exist
+func (r *InvoiceRepository) GetInvoiceBranchID(id int) (int, error) {
+	var branchID int
+	err := r.db.QueryRow(`SELECT branch_id FROM invoices WHERE id = $1`, id).Scan(&branchID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return branchID, err
+}
+
+// GetPaymentBranchID gets the branch of the invoice of a payment, 0 when it does not exist
+func (r *InvoiceRepository) GetPaymentBranchID(paymentID int) (int, error) {
+	var branchID int
+	err := r.db.QueryRow(`
+		SELECT i.branch_id FROM invoice_payments p JOIN invoices i ON i.id = p.invoice_id WHERE p.id = $1
+	`, paymentID).Scan(&branchID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return branchID, err
+}
+
 func (r *InvoiceRepository) UpdateInvoice(invoice *models.Invoice) error {
 	query := `
 		UPDATE invoices
 		SET customer_phone = $1, customer_name = $2, customer_address = $3,
 			subtotal = $4, discount_amount = $5, discount_percentage = $6,
-			tax_amount = $7, tax_percentage = $8, total_amount = $9,
-			paid_amount = $10, payment_status = $11, status = $12, notes = $13,
-			updated_at = $14
-		WHERE id = $15
+			tax_amount = $7, tax_percentage = $8, price_mode = $9, total_amount = $10,
+			paid_amount = $11, payment_status = $12, status = $13, notes = $14,
+			updated_at = $15
+		WHERE id = $16
 	`
 
 	result, err := r.db.Exec(
@@ -273,6 +311,7 @@ func (r *InvoiceRepository) UpdateInvoice(invoice *models.Invoice) error {
 		invoice.DiscountPercentage,
 		invoice.TaxAmount,
 		invoice.TaxPercentage,
+		invoice.PriceMode,
 		invoice.TotalAmount,
 		invoice.PaidAmount,
 		invoice.PaymentStatus,
@@ -298,60 +337,68 @@ func (r *InvoiceRepository) UpdateInvoice(invoice *models.Invoice) error {
 	return nil
 }
 
-func (r *InvoiceRepository) DeleteInvoice(id int) error {
-	query := `UPDATE invoices SET status = 'cancelled' WHERE id = $1`
+// DeleteInvoice cancels an invoice and gives the money paid from customer deposits back to the
+// deposits
+func (r *InvoiceRepository) DeleteInvoice(id int, cancelledBy int, cancelledByUsername string) ([]*models.CustomerDepositMovement, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	result, err := r.db.Exec(query, id)
+	// Lock the invoice so that no e-invoice is issued for it while it is cancelled
+	var hasEInvoice bool
+	err = tx.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM einvoices WHERE invoice_id = $1) FROM invoices WHERE id = $1 FOR UPDATE`,
+		id,
+	).Scan(&hasEInvoice)
 	if err != nil {
-		return err
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("invoice not found")
+		}
+		return nil, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	if hasEInvoice {
+		return nil, errors.New("invoice has an e-invoice, correct it with a replacement or adjustment e-invoice")
+	}
+
+	if _, err := tx.Exec(`UPDATE invoices SET status = 'cancelled' WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+
+	released, err := releaseDepositPayments(tx, id, 0, cancelledBy, cancelledByUsername)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if rowsAffected == 0 {
-		return errors.New("invoice not found")
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return released, nil
+}
+
+// HasEInvoice reports whether an e-invoice was generated for the invoice
+func (r *InvoiceRepository) HasEInvoice(invoiceID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM einvoices WHERE invoice_id = $1)`, invoiceID).Scan(&exists)
+	return exists, err
 }
 
 // InvoiceItem methods
 func (r *InvoiceRepository) CreateInvoiceItem(item *models.InvoiceItem) error {
-	query := `
-		INSERT INTO invoice_items (
-			invoice_id, product_id, variant_id, product_name, variant_name, unit,
-			quantity, unit_price, total_price, product_notes, created_at, updated_at
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-		RETURNING id, created_at, updated_at
-	`
-
-	err := r.db.QueryRow(
-		query,
-		item.InvoiceID,
-		item.ProductID,
-		item.VariantID,
-		item.ProductName,
-		item.VariantName,
-		item.Unit,
-		item.Quantity,
-		item.UnitPrice,
-		item.TotalPrice,
-		item.ProductNotes,
-		item.CreatedAt,
-		item.UpdatedAt,
-	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
-
-	return err
+	return insertInvoiceItem(r.db, item)
 }
 
 func (r *InvoiceRepository) GetInvoiceItemsByInvoiceID(invoiceID int) ([]*models.InvoiceItem, error) {
 	query := `
 		SELECT id, invoice_id, product_id, variant_id, product_name, variant_name, unit,
-			   quantity, unit_price, total_price, product_notes, created_at, updated_at
+			   quantity, unit_price, total_price, product_notes,
+			   promotion_id, promotion_name, promotion_discount,
+			   discount_amount, discount_percentage,
+			   tax_category_id, tax_rate, taxable_amount, tax_amount, allocated_tax_amount,
+			   created_at, updated_at
 		FROM invoice_items
 		WHERE invoice_id = $1
 		ORDER BY created_at ASC
@@ -378,6 +425,16 @@ func (r *InvoiceRepository) GetInvoiceItemsByInvoiceID(invoiceID int) ([]*models
 			&item.UnitPrice,
 			&item.TotalPrice,
 			&item.ProductNotes,
+			&item.PromotionID,
+			&item.PromotionName,
+			&item.PromotionDiscount,
+			&item.DiscountAmount,
+			&item.DiscountPercentage,
+			&item.TaxCategoryID,
+			&item.TaxRate,
+			&item.TaxableAmount,
+			&item.TaxAmount,
+			&item.AllocatedTaxAmount,
 			&item.CreatedAt,
 			&item.UpdatedAt,
 		)
@@ -395,8 +452,8 @@ func (r *InvoiceRepository) UpdateInvoiceItem(item *models.InvoiceItem) error {
 		UPDATE invoice_items
 		SET product_name = $1, variant_name = $2, unit = $3,
 			quantity = $4, unit_price = $5, total_price = $6,
-			product_notes = $7, updated_at = $8
-		WHERE id = $9
+			product_notes = $7, discount_amount = $8, discount_percentage = $9, updated_at = $10
+		WHERE id = $11
 	`
 
 	result, err := r.db.Exec(
@@ -408,6 +465,8 @@ func (r *InvoiceRepository) UpdateInvoiceItem(item *models.InvoiceItem) error {
 		item.UnitPrice,
 		item.TotalPrice,
 		item.ProductNotes,
+		item.DiscountAmount,
+		item.DiscountPercentage,
 		item.UpdatedAt,
 		item.ID,
 	)
@@ -457,75 +516,145 @@ func (r *InvoiceRepository) DeleteInvoiceItemsByInvoiceID(invoiceID int) error {
 
 // InvoicePayment methods
 func (r *InvoiceRepository) CreateInvoicePayment(payment *models.InvoicePayment) error {
+	return insertInvoicePayment(r.db, payment)
+}
+
+func (r *InvoiceRepository) GetInvoicePaymentByID(id int) (*models.InvoicePayment, error) {
+	payment, err := scanInvoicePayment(r.db.QueryRow(`SELECT `+invoicePaymentColumns+` FROM invoice_payments WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (r *InvoiceRepository) GetInvoicePaymentsByInvoiceID(invoiceID int) ([]*models.InvoicePayment, error) {
+	query := `SELECT ` + invoicePaymentColumns + ` FROM invoice_payments WHERE invoice_id = $1 ORDER BY created_at ASC, id ASC`
+
+	rows, err := r.db.Query(query, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*models.InvoicePayment
+	for rows.Next() {
+		payment, err := scanInvoicePayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, nil
+}
+
+// CorrectInvoicePayment marks a payment as corrected and records its reversal and, when the
+// payment is replaced, the replacement in one transaction. The invoice paid amount follows
+// from the payment triggers.
+func (r *InvoiceRepository) CorrectInvoicePayment(original, reversal, replacement *models.InvoicePayment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO invoice_payments (
-			invoice_id, amount, payment_method, payment_date, transaction_reference,
-			notes, status, created_by, created_at, updated_at
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		RETURNING id, created_at, updated_at
+		UPDATE invoice_payments
+		SET correction_reason = $1, corrected_by = $2, corrected_at = $3, original_amount = $4, updated_at = $3
+		WHERE id = $5 AND payment_type = 'payment' AND corrected_at IS NULL
 	`
 
-	err := r.db.QueryRow(
+	result, err := tx.Exec(
 		query,
-		payment.InvoiceID,
-		payment.Amount,
-		payment.PaymentMethod,
-		payment.PaymentDate,
-		payment.TransactionReference,
-		payment.Notes,
-		payment.Status,
-		payment.CreatedBy,
-		payment.CreatedAt,
-		payment.UpdatedAt,
-	).Scan(&payment.ID, &payment.CreatedAt, &payment.UpdatedAt)
+		original.CorrectionReason,
+		original.CorrectedBy,
+		original.CorrectedAt,
+		original.OriginalAmount,
+		original.ID,
+	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("invoice payment not found or already corrected")
+	}
+
+	if err := insertInvoicePayment(tx, reversal); err != nil {
+		return err
+	}
+
+	if replacement != nil {
+		if err := insertInvoicePayment(tx, replacement); err != nil {
+			return err
+		}
+	}
+
+	// A reversed deposit payment gives the money back to the deposit
+	if original.PaymentMethod == models.PaymentMethodDeposit {
+		if _, err := releaseDepositPayments(tx, original.InvoiceID, original.ID, *reversal.CreatedBy, *reversal.CreatedByUsername); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-func (r *InvoiceRepository) GetInvoicePaymentsByInvoiceID(invoiceID int) ([]*models.InvoicePayment, error) {
+// GetPaymentCorrections gets the payments corrected in [from, to) with their reversal and replacement,
+// of invoices of one branch when branchID > 0
+func (r *InvoiceRepository) GetPaymentCorrections(from, to time.Time, branchID int) ([]*models.PaymentCorrection, error) {
 	query := `
-		SELECT id, invoice_id, amount, payment_method, payment_date, transaction_reference,
-			   notes, correction_reason, corrected_by, corrected_at, original_amount,
-			   status, created_at, updated_at, created_by
-		FROM invoice_payments
-		WHERE invoice_id = $1
-		ORDER BY created_at ASC
+		SELECT p.id, p.invoice_id, i.invoice_code, p.correction_reason, p.corrected_by, u.username,
+			p.corrected_at, p.original_amount, p.payment_method, rv.id, rp.id, rp.amount, rp.payment_method
+		FROM invoice_payments p
+		JOIN invoices i ON i.id = p.invoice_id
+		JOIN invoice_payments rv ON rv.corrects_payment_id = p.id AND rv.payment_type = 'reversal'
+		LEFT JOIN invoice_payments rp ON rp.corrects_payment_id = p.id AND rp.payment_type = 'payment'
+		LEFT JOIN users u ON u.id = p.corrected_by
+		WHERE p.corrected_at >= $1 AND p.corrected_at < $2 AND ($3 = 0 OR i.branch_id = $3)
+		ORDER BY p.corrected_at ASC
 	`
 
-	rows, err := r.db.Query(query, invoiceID)
+	rows, err := r.db.Query(query, from, to, branchID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var payments []*models.InvoicePayment
+	var corrections []*models.PaymentCorrection
 	for rows.Next() {
-		payment := &models.InvoicePayment{}
+		correction := &models.PaymentCorrection{}
 		err := rows.Scan(
-			&payment.ID,
-			&payment.InvoiceID,
-			&payment.Amount,
-			&payment.PaymentMethod,
-			&payment.PaymentDate,
-			&payment.TransactionReference,
-			&payment.Notes,
-			&payment.CorrectionReason,
-			&payment.CorrectedBy,
-			&payment.CorrectedAt,
-			&payment.OriginalAmount,
-			&payment.Status,
-			&payment.CreatedAt,
-			&payment.UpdatedAt,
-			&payment.CreatedBy,
+			&correction.PaymentID,
+			&correction.InvoiceID,
+			&correction.InvoiceCode,
+			&correction.CorrectionReason,
+			&correction.CorrectedBy,
+			&correction.CorrectedByUsername,
+			&correction.CorrectedAt,
+			&correction.OriginalAmount,
+			&correction.OriginalMethod,
+			&correction.ReversalPaymentID,
+			&correction.ReplacementPaymentID,
+			&correction.NewAmount,
+			&correction.NewMethod,
 		)
 		if err != nil {
 			return nil, err
 		}
-		payments = append(payments, payment)
+		corrections = append(corrections, correction)
 	}
 
-	return payments, nil
+	return corrections, nil
 }
 
 func (r *InvoiceRepository) UpdateInvoicePayment(payment *models.InvoicePayment) error {
@@ -570,26 +699,6 @@ func (r *InvoiceRepository) UpdateInvoicePayment(payment *models.InvoicePayment)
 	return nil
 }
 
-func (r *InvoiceRepository) DeleteInvoicePayment(id int) error {
-	query := `DELETE FROM invoice_payments WHERE id = $1`
-
-	result, err := r.db.Exec(query, id)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return errors.New("invoice payment not found")
-	}
-
-	return nil
-}
-
 // InventoryLog methods
 func (r *InvoiceRepository) CreateInventoryLog(log *models.InventoryLog) error {
 	query := `
@@ -621,6 +730,167 @@ func (r *InvoiceRepository) CreateInventoryLog(log *models.InventoryLog) error {
 }
 
 // Helper methods
+
+// rowQuerier runs inserts on the database or inside a transaction
+type rowQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func insertInvoice(q rowQuerier, invoice *models.Invoice) error {
+	query := `
+		INSERT INTO invoices (
+			invoice_code, customer_id, customer_phone, customer_name, customer_address,
+			subtotal, discount_amount, discount_percentage, tax_amount, tax_percentage, price_mode,
+			total_amount, paid_amount, payment_status, status, notes,
+			created_by, created_at, updated_at, branch_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := q.QueryRow(
+		query,
+		invoice.InvoiceCode,
+		invoice.CustomerID,
+		invoice.CustomerPhone,
+		invoice.CustomerName,
+		invoice.CustomerAddress,
+		invoice.Subtotal,
+		invoice.DiscountAmount,
+		invoice.DiscountPercentage,
+		invoice.TaxAmount,
+		invoice.TaxPercentage,
+		invoice.PriceMode,
+		invoice.TotalAmount,
+		invoice.PaidAmount,
+		invoice.PaymentStatus,
+		invoice.Status,
+		invoice.Notes,
+		invoice.CreatedBy,
+		invoice.CreatedAt,
+		invoice.UpdatedAt,
+		invoice.BranchID,
+	).Scan(&invoice.ID, &invoice.CreatedAt, &invoice.UpdatedAt)
+
+	return err
+}
+
+func insertInvoiceItem(q rowQuerier, item *models.InvoiceItem) error {
+	query := `
+		INSERT INTO invoice_items (
+			invoice_id, product_id, variant_id, product_name, variant_name, unit,
+			quantity, unit_price, total_price, product_notes,
+			promotion_id, promotion_name, promotion_discount,
+			discount_amount, discount_percentage,
+			tax_category_id, tax_rate, taxable_amount, tax_amount, allocated_tax_amount,
+			created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := q.QueryRow(
+		query,
+		item.InvoiceID,
+		item.ProductID,
+		item.VariantID,
+		item.ProductName,
+		item.VariantName,
+		item.Unit,
+		item.Quantity,
+		item.UnitPrice,
+		item.TotalPrice,
+		item.ProductNotes,
+		item.PromotionID,
+		item.PromotionName,
+		item.PromotionDiscount,
+		item.DiscountAmount,
+		item.DiscountPercentage,
+		item.TaxCategoryID,
+		item.TaxRate,
+		item.TaxableAmount,
+		item.TaxAmount,
+		item.AllocatedTaxAmount,
+		item.CreatedAt,
+		item.UpdatedAt,
+	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+
+	return err
+}
+
+func insertInvoicePayment(q rowQuerier, payment *models.InvoicePayment) error {
+	query := `
+		INSERT INTO invoice_payments (
+			invoice_id, amount, tendered_amount, change_amount, payment_method, payment_date,
+			transaction_reference, notes, original_amount, payment_type, corrects_payment_id,
+			status, created_by, created_by_username, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := q.QueryRow(
+		query,
+		payment.InvoiceID,
+		payment.Amount,
+		payment.TenderedAmount,
+		payment.ChangeAmount,
+		payment.PaymentMethod,
+		payment.PaymentDate,
+		payment.TransactionReference,
+		payment.Notes,
+		payment.OriginalAmount,
+		payment.PaymentType,
+		payment.CorrectsPaymentID,
+		payment.Status,
+		payment.CreatedBy,
+		payment.CreatedByUsername,
+		payment.CreatedAt,
+		payment.UpdatedAt,
+	).Scan(&payment.ID, &payment.CreatedAt, &payment.UpdatedAt)
+
+	return err
+}
+
+const invoicePaymentColumns = `
+	id, invoice_id, amount, tendered_amount, change_amount, payment_method, payment_date, transaction_reference,
+	notes, correction_reason, corrected_by, corrected_at, original_amount, payment_type, corrects_payment_id,
+	status, created_at, updated_at, created_by, created_by_username`
+
+type invoicePaymentScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanInvoicePayment(scanner invoicePaymentScanner) (*models.InvoicePayment, error) {
+	payment := &models.InvoicePayment{}
+	err := scanner.Scan(
+		&payment.ID,
+		&payment.InvoiceID,
+		&payment.Amount,
+		&payment.TenderedAmount,
+		&payment.ChangeAmount,
+		&payment.PaymentMethod,
+		&payment.PaymentDate,
+		&payment.TransactionReference,
+		&payment.Notes,
+		&payment.CorrectionReason,
+		&payment.CorrectedBy,
+		&payment.CorrectedAt,
+		&payment.OriginalAmount,
+		&payment.PaymentType,
+		&payment.CorrectsPaymentID,
+		&payment.Status,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+		&payment.CreatedBy,
+		&payment.CreatedByUsername,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
 func (r *InvoiceRepository) loadInvoiceRelations(invoice *models.Invoice) error {
 	// Load items
 	items, err := r.GetInvoiceItemsByInvoiceID(invoice.ID)
@@ -628,6 +898,7 @@ func (r *InvoiceRepository) loadInvoiceRelations(invoice *models.Invoice) error
 		return err
 	}
 	invoice.Items = items
+	invoice.VATSummary = models.SummarizeVAT(items)
 
 	// Load payments
 	payments, err := r.GetInvoicePaymentsByInvoiceID(invoice.ID)
@@ -636,6 +907,13 @@ func (r *InvoiceRepository) loadInvoiceRelations(invoice *models.Invoice) error
 	}
 	invoice.Payments = payments
 
+	invoice.ChangeAmount = 0
+	for _, payment := range payments {
+		if payment.Status == "confirmed" {
+			invoice.ChangeAmount += payment.ChangeAmount
+		}
+	}
+
 	return nil
 }
 
@@ -652,8 +930,8 @@ func (r *InvoiceRepository) GenerateInvoiceCode() (string, error) {
 	return invoiceCode, nil
 }
 
-// Get invoice summary statistics
-func (r *InvoiceRepository) GetInvoiceSummary() (*models.InvoiceSummary, error) {
+// Get invoice summary statistics, of one branch when branchID > 0
+func (r *InvoiceRepository) GetInvoiceSummary(branchID int) (*models.InvoiceSummary, error) {
 	query := `
 		SELECT 
 			COUNT(*) as total_invoices,
@@ -663,11 +941,11 @@ func (r *InvoiceRepository) GetInvoiceSummary() (*models.InvoiceSummary, error)
 			COUNT(CASE WHEN DATE(created_at) = CURRENT_DATE THEN 1 END) as today_invoices,
 			COALESCE(SUM(CASE WHEN DATE(created_at) = CURRENT_DATE THEN total_amount ELSE 0 END), 0) as today_amount
 		FROM invoices
-		WHERE status != 'cancelled'
+		WHERE status != 'cancelled' AND ($1 = 0 OR branch_id = $1)
 	`
 
 	summary := &models.InvoiceSummary{}
-	err := r.db.QueryRow(query).Scan(
+	err := r.db.QueryRow(query, branchID).Scan(
 		&summary.TotalInvoices,
 		&summary.TotalAmount,
 		&summary.PaidAmount,
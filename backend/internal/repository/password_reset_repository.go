@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type PasswordResetRepository struct {
+	db *sql.DB
+}
+
+func NewPasswordResetRepository(db *sql.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// Create stores a reset token for a user, discarding the unused tokens issued to them before,
+// and flags the user as having to change their password
+func (r *PasswordResetRepository) Create(userID int, tokenHash string, createdBy int, expiresAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM password_reset_tokens WHERE user_id = $1 AND used_at IS NULL`, userID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO password_reset_tokens (user_id, token_hash, created_by, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, userID, tokenHash, createdBy, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE users SET must_change_password = true, updated_at = NOW() WHERE id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Use marks an unused, unexpired token as used and sets the new password of its user, which
+// clears must_change_password. It returns the user ID, or 0 when the token is not valid.
+func (r *PasswordResetRepository) Use(tokenHash, passwordHash string, at time.Time) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var userID int
+	err = tx.QueryRow(`
+		UPDATE password_reset_tokens t SET used_at = $1
+		FROM users u
+		WHERE t.token_hash = $2 AND t.used_at IS NULL AND t.expires_at > $1
+			AND u.id = t.user_id AND u.is_active = true
+		RETURNING t.user_id
+	`, at, tokenHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE users
+		SET password_hash = $1, must_change_password = false, password_changed_at = $2, updated_at = $2
+		WHERE id = $3
+	`, passwordHash, at, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// GetUserID gets the user of an unused, unexpired token, or 0 when the token is not valid
+func (r *PasswordResetRepository) GetUserID(tokenHash string, at time.Time) (int, error) {
+	var userID int
+	err := r.db.QueryRow(`
+		SELECT user_id FROM password_reset_tokens
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+	`, tokenHash, at).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// DeleteExpired removes tokens that have been used or have expired
+func (r *PasswordResetRepository) DeleteExpired(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM password_reset_tokens WHERE used_at IS NOT NULL OR expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"steel-pos-backend/internal/models"
+	"time"
+)
+
+type PaymentMethodRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentMethodRepository(db *sql.DB) *PaymentMethodRepository {
+	return &PaymentMethodRepository{db: db}
+}
+
+const paymentMethodColumns = `
+	id, code, name, description, requires_reference, is_cash, is_active, sort_order,
+	created_by, created_by_name, created_at, updated_at`
+
+// PaymentMethod methods
+func (r *PaymentMethodRepository) Create(method *models.PaymentMethod) error {
+	query := `
+		INSERT INTO payment_methods (code, name, description, requires_reference, is_cash, is_active, sort_order, created_by, created_by_name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		method.Code,
+		method.Name,
+		method.Description,
+		method.RequiresReference,
+		method.IsCash,
+		method.IsActive,
+		method.SortOrder,
+		method.CreatedBy,
+		method.CreatedByName,
+		method.CreatedAt,
+		method.UpdatedAt,
+	).Scan(&method.ID, &method.CreatedAt, &method.UpdatedAt)
+}
+
+func (r *PaymentMethodRepository) GetByID(id int) (*models.PaymentMethod, error) {
+	method, err := scanPaymentMethod(r.db.QueryRow(`SELECT `+paymentMethodColumns+` FROM payment_methods WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return method, nil
+}
+
+func (r *PaymentMethodRepository) GetByCode(code string) (*models.PaymentMethod, error) {
+	method, err := scanPaymentMethod(r.db.QueryRow(`SELECT `+paymentMethodColumns+` FROM payment_methods WHERE code = $1`, code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return method, nil
+}
+
+func (r *PaymentMethodRepository) GetAll(activeOnly bool) ([]*models.PaymentMethod, error) {
+	query := `SELECT ` + paymentMethodColumns + ` FROM payment_methods`
+	if activeOnly {
+		query += ` WHERE is_active = true`
+	}
+	query += ` ORDER BY sort_order ASC, code ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var methods []*models.PaymentMethod
+	for rows.Next() {
+		method, err := scanPaymentMethod(rows)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, method)
+	}
+
+	return methods, nil
+}
+
+func (r *PaymentMethodRepository) Update(method *models.PaymentMethod) error {
+	query := `
+		UPDATE payment_methods
+		SET name = $1, description = $2, requires_reference = $3, is_cash = $4, is_active = $5,
+			sort_order = $6, updated_at = $7
+		WHERE id = $8
+	`
+
+	result, err := r.db.Exec(
+		query,
+		method.Name,
+		method.Description,
+		method.RequiresReference,
+		method.IsCash,
+		method.IsActive,
+		method.SortOrder,
+		method.UpdatedAt,
+		method.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("payment method not found")
+	}
+
+	return nil
+}
+
+// Report methods
+
+// GetCollections gets the confirmed invoice payments dated in [from, to) per payment method,
+// with customer deposits received or refunded in that period. Payments made from deposits
+// were collected when the deposit was received.
+func (r *PaymentMethodRepository) GetCollections(from, to time.Time) ([]*models.PaymentMethodTotal, error) {
+	query := `
+		SELECT p.payment_method, COALESCE(pm.name, p.payment_method), COALESCE(pm.is_cash, false),
+			COUNT(*) FILTER (WHERE p.counted), COALESCE(SUM(p.amount), 0)
+		FROM (
+			SELECT payment_method, amount, payment_date, payment_type = 'payment' AS counted
+			FROM invoice_payments
+			WHERE status = 'confirmed' AND payment_method != 'deposit'
+			UNION ALL
+			SELECT payment_method, amount, created_at, true
+			FROM customer_deposits
+			UNION ALL
+			SELECT payment_method, -amount, created_at, false
+			FROM customer_deposit_movements
+			WHERE movement_type = 'refunded'
+		) p
+		LEFT JOIN payment_methods pm ON pm.code = p.payment_method
+		WHERE p.payment_date >= $1 AND p.payment_date < $2
+		GROUP BY p.payment_method, pm.name, pm.is_cash, pm.sort_order
+		ORDER BY pm.sort_order ASC, p.payment_method ASC
+	`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []*models.PaymentMethodTotal
+	for rows.Next() {
+		total := &models.PaymentMethodTotal{}
+		if err := rows.Scan(&total.PaymentMethod, &total.Name, &total.IsCash, &total.Count, &total.Amount); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	return totals, nil
+}
+
+// Helper methods
+type paymentMethodScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPaymentMethod(scanner paymentMethodScanner) (*models.PaymentMethod, error) {
+	method := &models.PaymentMethod{}
+	err := scanner.Scan(
+		&method.ID,
+		&method.Code,
+		&method.Name,
+		&method.Description,
+		&method.RequiresReference,
+		&method.IsCash,
+		&method.IsActive,
+		&method.SortOrder,
+		&method.CreatedBy,
+		&method.CreatedByName,
+		&method.CreatedAt,
+		&method.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return method, nil
+}
@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"steel-pos-backend/internal/models"
+	"time"
+)
+
+type PriceHistoryRepository struct {
+	db *sql.DB
+}
+
+func NewPriceHistoryRepository(db *sql.DB) *PriceHistoryRepository {
+	return &PriceHistoryRepository{db: db}
+}
+
+// Price history methods
+func (r *PriceHistoryRepository) Create(history *models.VariantPriceHistory) error {
+	query := `
+		INSERT INTO variant_price_history (
+			variant_id, product_id, old_price, new_price, reason, source,
+			scheduled_change_id, batch_id, changed_by, changed_by_name, changed_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, changed_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		history.VariantID,
+		history.ProductID,
+		history.OldPrice,
+		history.NewPrice,
+		history.Reason,
+		history.Source,
+		history.ScheduledChangeID,
+		history.BatchID,
+		history.ChangedBy,
+		history.ChangedByName,
+		history.ChangedAt,
+	).Scan(&history.ID, &history.ChangedAt)
+
+	return err
+}
+
+func (r *PriceHistoryRepository) GetByVariantID(variantID int, limit, offset int) ([]*models.VariantPriceHistory, error) {
+	query := `
+		SELECT id, variant_id, product_id, old_price, new_price, reason, source,
+			   scheduled_change_id, batch_id, changed_by, changed_by_name, changed_at
+		FROM variant_price_history
+		WHERE variant_id = $1
+		ORDER BY changed_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(query, variantID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []*models.VariantPriceHistory
+	for rows.Next() {
+		entry := &models.VariantPriceHistory{}
+		err := rows.Scan(
+			&entry.ID,
+			&entry.VariantID,
+			&entry.ProductID,
+			&entry.OldPrice,
+			&entry.NewPrice,
+			&entry.Reason,
+			&entry.Source,
+			&entry.ScheduledChangeID,
+			&entry.BatchID,
+			&entry.ChangedBy,
+			&entry.ChangedByName,
+			&entry.ChangedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+
+	return history, nil
+}
+
+func (r *PriceHistoryRepository) CountByVariantID(variantID int) (int, error) {
+	query := `SELECT COUNT(*) FROM variant_price_history WHERE variant_id = $1`
+
+	var count int
+	err := r.db.QueryRow(query, variantID).Scan(&count)
+	return count, err
+}
+
+// Scheduled price change methods
+func (r *PriceHistoryRepository) CreateScheduledChange(change *models.ScheduledPriceChange) error {
+	query := `
+		INSERT INTO scheduled_price_changes (
+			variant_id, new_price, effective_at, reason, status,
+			created_by, created_by_name, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		change.VariantID,
+		change.NewPrice,
+		change.EffectiveAt,
+		change.Reason,
+		change.Status,
+		change.CreatedBy,
+		change.CreatedByName,
+		change.CreatedAt,
+		change.UpdatedAt,
+	).Scan(&change.ID, &change.CreatedAt, &change.UpdatedAt)
+
+	return err
+}
+
+func (r *PriceHistoryRepository) GetScheduledChangeByID(id int) (*models.ScheduledPriceChange, error) {
+	query := `
+		SELECT id, variant_id, new_price, effective_at, reason, status, applied_at,
+			   created_by, created_by_name, created_at, updated_at
+		FROM scheduled_price_changes
+		WHERE id = $1
+	`
+
+	change := &models.ScheduledPriceChange{}
+	err := r.db.QueryRow(query, id).Scan(
+		&change.ID,
+		&change.VariantID,
+		&change.NewPrice,
+		&change.EffectiveAt,
+		&change.Reason,
+		&change.Status,
+		&change.AppliedAt,
+		&change.CreatedBy,
+		&change.CreatedByName,
+		&change.CreatedAt,
+		&change.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return change, nil
+}
+
+func (r *PriceHistoryRepository) GetScheduledChangesByVariantID(variantID int, status string) ([]*models.ScheduledPriceChange, error) {
+	query := `
+		SELECT id, variant_id, new_price, effective_at, reason, status, applied_at,
+			   created_by, created_by_name, created_at, updated_at
+		FROM scheduled_price_changes
+		WHERE variant_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY effective_at ASC
+	`
+
+	return r.queryScheduledChanges(query, variantID, status)
+}
+
+// GetDueScheduledChanges returns pending changes whose effective date has passed
+func (r *PriceHistoryRepository) GetDueScheduledChanges(now time.Time) ([]*models.ScheduledPriceChange, error) {
+	query := `
+		SELECT id, variant_id, new_price, effective_at, reason, status, applied_at,
+			   created_by, created_by_name, created_at, updated_at
+		FROM scheduled_price_changes
+		WHERE status = 'pending' AND effective_at <= $1
+		ORDER BY effective_at ASC, id ASC
+	`
+
+	return r.queryScheduledChanges(query, now)
+}
+
+func (r *PriceHistoryRepository) UpdateScheduledChangeStatus(id int, status string, appliedAt *time.Time) error {
+	query := `
+		UPDATE scheduled_price_changes
+		SET status = $1, applied_at = $2, updated_at = NOW()
+		WHERE id = $3 AND status = 'pending'
+	`
+
+	result, err := r.db.Exec(query, status, appliedAt, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("pending scheduled price change not found")
+	}
+
+	return nil
+}
+
+// ApplyScheduledChange sets the variant price of a due pending change, records the price history and
+// marks the change applied in one transaction. The change row is claimed with SKIP LOCKED so that
+// concurrent runs apply it once; it returns false when the change was claimed or handled elsewhere.
+// A change whose variant was deleted is cancelled.
+func (r *PriceHistoryRepository) ApplyScheduledChange(changeID int, now time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	change := &models.ScheduledPriceChange{}
+	err = tx.QueryRow(`
+		SELECT id, variant_id, new_price, reason, created_by, created_by_name
+		FROM scheduled_price_changes
+		WHERE id = $1 AND status = 'pending' AND effective_at <= $2
+		FOR UPDATE SKIP LOCKED
+	`, changeID, now).Scan(
+		&change.ID,
+		&change.VariantID,
+		&change.NewPrice,
+		&change.Reason,
+		&change.CreatedBy,
+		&change.CreatedByName,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var productID int
+	var oldPrice float64
+	err = tx.QueryRow(`SELECT product_id, price FROM product_variants WHERE id = $1 FOR UPDATE`, change.VariantID).
+		Scan(&productID, &oldPrice)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	// Variant was deleted after the change was scheduled
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err = tx.Exec(`UPDATE scheduled_price_changes SET status = $1, updated_at = $2 WHERE id = $3`,
+			models.ScheduledPriceStatusCancelled, now, change.ID)
+		if err != nil {
+			return false, err
+		}
+		return false, tx.Commit()
+	}
+
+	_, err = tx.Exec(`UPDATE product_variants SET price = $1, updated_at = $2 WHERE id = $3`, change.NewPrice, now, change.VariantID)
+	if err != nil {
+		return false, err
+	}
+
+	if oldPrice != change.NewPrice {
+		err = insertPriceHistory(tx, &models.VariantPriceHistory{
+			VariantID:         change.VariantID,
+			ProductID:         productID,
+			OldPrice:          oldPrice,
+			NewPrice:          change.NewPrice,
+			Reason:            change.Reason,
+			Source:            models.PriceChangeSourceScheduled,
+			ScheduledChangeID: &change.ID,
+			ChangedBy:         change.CreatedBy,
+			ChangedByName:     change.CreatedByName,
+			ChangedAt:         now,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE scheduled_price_changes SET status = $1, applied_at = $2, updated_at = $2 WHERE id = $3`,
+		models.ScheduledPriceStatusApplied, now, change.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Helper methods
+func (r *PriceHistoryRepository) queryScheduledChanges(query string, args ...interface{}) ([]*models.ScheduledPriceChange, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*models.ScheduledPriceChange
+	for rows.Next() {
+		change := &models.ScheduledPriceChange{}
+		err := rows.Scan(
+			&change.ID,
+			&change.VariantID,
+			&change.NewPrice,
+			&change.EffectiveAt,
+			&change.Reason,
+			&change.Status,
+			&change.AppliedAt,
+			&change.CreatedBy,
+			&change.CreatedByName,
+			&change.CreatedAt,
+			&change.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
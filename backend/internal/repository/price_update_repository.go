@@ -0,0 +1,413 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"steel-pos-backend/internal/models"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type PriceUpdateRepository struct {
+	db *sql.DB
+}
+
+func NewPriceUpdateRepository(db *sql.DB) *PriceUpdateRepository {
+	return &PriceUpdateRepository{db: db}
+}
+
+// FindVariants returns the active variants matching the filter with their current price
+func (r *PriceUpdateRepository) FindVariants(filter models.PriceUpdateFilter) ([]*models.PriceUpdateItem, error) {
+	query := `
+		SELECT pv.id, pv.product_id, p.name, pv.name, pv.sku, pv.unit, pv.price
+		FROM product_variants pv
+		JOIN products p ON p.id = pv.product_id
+		WHERE pv.is_active = true AND p.is_active = true
+	`
+
+	args := []interface{}{}
+	argCount := 1
+
+	if filter.CategoryID != nil {
+		query += fmt.Sprintf(" AND p.category_id = $%d", argCount)
+		args = append(args, *filter.CategoryID)
+		argCount++
+	}
+
+	if len(filter.ProductIDs) > 0 {
+		query += fmt.Sprintf(" AND pv.product_id = ANY($%d)", argCount)
+		args = append(args, pq.Array(filter.ProductIDs))
+		argCount++
+	}
+
+	if filter.VariantName != "" {
+		query += fmt.Sprintf(" AND normalize_vietnamese(pv.name) LIKE normalize_vietnamese($%d)", argCount)
+		args = append(args, "%"+strings.TrimSpace(filter.VariantName)+"%")
+		argCount++
+	}
+
+	if filter.Unit != "" {
+		query += fmt.Sprintf(" AND normalize_vietnamese(pv.unit) = normalize_vietnamese($%d)", argCount)
+		args = append(args, strings.TrimSpace(filter.Unit))
+		argCount++
+	}
+
+	if filter.Search != "" {
+		query += fmt.Sprintf(` AND (
+			normalize_vietnamese(p.name) LIKE normalize_vietnamese($%d)
+			OR normalize_vietnamese(pv.name) LIKE normalize_vietnamese($%d)
+			OR normalize_vietnamese(pv.sku) LIKE normalize_vietnamese($%d)
+		)`, argCount, argCount, argCount)
+		args = append(args, "%"+strings.TrimSpace(filter.Search)+"%")
+		argCount++
+	}
+
+	query += " ORDER BY p.name, pv.name"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.PriceUpdateItem
+	for rows.Next() {
+		item := &models.PriceUpdateItem{}
+		err := rows.Scan(
+			&item.VariantID,
+			&item.ProductID,
+			&item.ProductName,
+			&item.VariantName,
+			&item.SKU,
+			&item.Unit,
+			&item.OldPrice,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// ApplyBatch creates the batch and updates every variant price in a single transaction.
+// It fails if any variant price changed after the items were computed.
+func (r *PriceUpdateRepository) ApplyBatch(batch *models.PriceUpdateBatch, items []*models.PriceUpdateItem) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	batchQuery := `
+		INSERT INTO price_update_batches (
+			adjustment_type, adjustment_value, round_to, filters, reason, variant_count,
+			status, created_by, created_by_name, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		batchQuery,
+		batch.AdjustmentType,
+		batch.AdjustmentValue,
+		batch.RoundTo,
+		batch.Filters,
+		batch.Reason,
+		batch.VariantCount,
+		batch.Status,
+		batch.CreatedBy,
+		batch.CreatedByName,
+		batch.CreatedAt,
+		batch.UpdatedAt,
+	).Scan(&batch.ID, &batch.CreatedAt, &batch.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, item := range items {
+		result, err := tx.Exec(
+			`UPDATE product_variants SET price = $1, updated_at = $2 WHERE id = $3 AND price = $4 AND is_active = true`,
+			item.NewPrice, now, item.VariantID, item.OldPrice,
+		)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("price of variant %s changed during bulk update, please retry", item.SKU)
+		}
+
+		err = insertPriceHistory(tx, &models.VariantPriceHistory{
+			VariantID:     item.VariantID,
+			ProductID:     item.ProductID,
+			OldPrice:      item.OldPrice,
+			NewPrice:      item.NewPrice,
+			Reason:        batch.Reason,
+			Source:        models.PriceChangeSourceBulk,
+			BatchID:       &batch.ID,
+			ChangedBy:     batch.CreatedBy,
+			ChangedByName: batch.CreatedByName,
+			ChangedAt:     now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RollbackBatch restores the old price of every variant in the batch whose price has not
+// changed since the batch was applied, and returns the IDs of the variants that were skipped
+func (r *PriceUpdateRepository) RollbackBatch(batch *models.PriceUpdateBatch, rolledBackBy int, rolledBackByName string) (int, []int, error) {
+	changes, err := r.GetBatchChanges(batch.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	reason := fmt.Sprintf("Rollback of price update batch #%d", batch.ID)
+	restored := 0
+	skipped := []int{}
+
+	for _, change := range changes {
+		result, err := tx.Exec(
+			`UPDATE product_variants SET price = $1, updated_at = $2 WHERE id = $3 AND price = $4 AND is_active = true`,
+			change.OldPrice, now, change.VariantID, change.NewPrice,
+		)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if rowsAffected == 0 {
+			skipped = append(skipped, change.VariantID)
+			continue
+		}
+
+		err = insertPriceHistory(tx, &models.VariantPriceHistory{
+			VariantID:     change.VariantID,
+			ProductID:     change.ProductID,
+			OldPrice:      change.NewPrice,
+			NewPrice:      change.OldPrice,
+			Reason:        &reason,
+			Source:        models.PriceChangeSourceRollback,
+			BatchID:       &batch.ID,
+			ChangedBy:     &rolledBackBy,
+			ChangedByName: &rolledBackByName,
+			ChangedAt:     now,
+		})
+		if err != nil {
+			return 0, nil, err
+		}
+
+		restored++
+	}
+
+	result, err := tx.Exec(`
+		UPDATE price_update_batches
+		SET status = $1, rolled_back_by = $2, rolled_back_by_name = $3, rolled_back_at = $4, updated_at = $4
+		WHERE id = $5 AND status = $6`,
+		models.PriceUpdateBatchStatusRolledBack, rolledBackBy, rolledBackByName, now, batch.ID, models.PriceUpdateBatchStatusApplied,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if rowsAffected == 0 {
+		return 0, nil, errors.New("price update batch has already been rolled back")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, nil, err
+	}
+
+	return restored, skipped, nil
+}
+
+func (r *PriceUpdateRepository) GetBatchByID(id int) (*models.PriceUpdateBatch, error) {
+	query := `
+		SELECT id, adjustment_type, adjustment_value, round_to, filters, reason, variant_count,
+			   status, rolled_back_by, rolled_back_by_name, rolled_back_at,
+			   created_by, created_by_name, created_at, updated_at
+		FROM price_update_batches
+		WHERE id = $1
+	`
+
+	batch := &models.PriceUpdateBatch{}
+	err := r.db.QueryRow(query, id).Scan(
+		&batch.ID,
+		&batch.AdjustmentType,
+		&batch.AdjustmentValue,
+		&batch.RoundTo,
+		&batch.Filters,
+		&batch.Reason,
+		&batch.VariantCount,
+		&batch.Status,
+		&batch.RolledBackBy,
+		&batch.RolledBackByName,
+		&batch.RolledBackAt,
+		&batch.CreatedBy,
+		&batch.CreatedByName,
+		&batch.CreatedAt,
+		&batch.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+func (r *PriceUpdateRepository) GetBatches(limit, offset int) ([]*models.PriceUpdateBatch, error) {
+	query := `
+		SELECT id, adjustment_type, adjustment_value, round_to, filters, reason, variant_count,
+			   status, rolled_back_by, rolled_back_by_name, rolled_back_at,
+			   created_by, created_by_name, created_at, updated_at
+		FROM price_update_batches
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.Query(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batches []*models.PriceUpdateBatch
+	for rows.Next() {
+		batch := &models.PriceUpdateBatch{}
+		err := rows.Scan(
+			&batch.ID,
+			&batch.AdjustmentType,
+			&batch.AdjustmentValue,
+			&batch.RoundTo,
+			&batch.Filters,
+			&batch.Reason,
+			&batch.VariantCount,
+			&batch.Status,
+			&batch.RolledBackBy,
+			&batch.RolledBackByName,
+			&batch.RolledBackAt,
+			&batch.CreatedBy,
+			&batch.CreatedByName,
+			&batch.CreatedAt,
+			&batch.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+
+	return batches, nil
+}
+
+func (r *PriceUpdateRepository) CountBatches() (int, error) {
+	query := `SELECT COUNT(*) FROM price_update_batches`
+
+	var count int
+	err := r.db.QueryRow(query).Scan(&count)
+	return count, err
+}
+
+// GetBatchChanges returns the price changes made when the batch was applied
+func (r *PriceUpdateRepository) GetBatchChanges(batchID int) ([]*models.VariantPriceHistory, error) {
+	query := `
+		SELECT id, variant_id, product_id, old_price, new_price, reason, source,
+			   scheduled_change_id, batch_id, changed_by, changed_by_name, changed_at
+		FROM variant_price_history
+		WHERE batch_id = $1 AND source = 'bulk'
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.Query(query, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*models.VariantPriceHistory
+	for rows.Next() {
+		change := &models.VariantPriceHistory{}
+		err := rows.Scan(
+			&change.ID,
+			&change.VariantID,
+			&change.ProductID,
+			&change.OldPrice,
+			&change.NewPrice,
+			&change.Reason,
+			&change.Source,
+			&change.ScheduledChangeID,
+			&change.BatchID,
+			&change.ChangedBy,
+			&change.ChangedByName,
+			&change.ChangedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// Helper methods
+func insertPriceHistory(tx *sql.Tx, history *models.VariantPriceHistory) error {
+	query := `
+		INSERT INTO variant_price_history (
+			variant_id, product_id, old_price, new_price, reason, source,
+			scheduled_change_id, batch_id, changed_by, changed_by_name, changed_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, changed_at
+	`
+
+	return tx.QueryRow(
+		query,
+		history.VariantID,
+		history.ProductID,
+		history.OldPrice,
+		history.NewPrice,
+		history.Reason,
+		history.Source,
+		history.ScheduledChangeID,
+		history.BatchID,
+		history.ChangedBy,
+		history.ChangedByName,
+		history.ChangedAt,
+	).Scan(&history.ID, &history.ChangedAt)
+}
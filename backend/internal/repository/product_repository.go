@@ -19,8 +19,8 @@ func NewProductRepository(db *sql.DB) *ProductRepository {
 // Product methods
 func (r *ProductRepository) Create(product *models.Product) error {
 	query := `
-		INSERT INTO products (name, category_id, unit, notes, is_active, created_by, created_by_name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO products (name, category_id, tax_category_id, unit, notes, is_active, created_by, created_by_name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -28,6 +28,7 @@ func (r *ProductRepository) Create(product *models.Product) error {
 		query,
 		product.Name,
 		product.CategoryID,
+		product.TaxCategoryID,
 		product.Unit,
 		product.Notes,
 		product.IsActive,
@@ -42,7 +43,7 @@ func (r *ProductRepository) Create(product *models.Product) error {
 
 func (r *ProductRepository) GetByID(id int) (*models.Product, error) {
 	query := `
-		SELECT id, name, category_id, unit, notes, is_active, created_by, created_at, updated_at
+		SELECT id, name, category_id, tax_category_id, unit, notes, is_active, created_by, created_at, updated_at
 		FROM products
 		WHERE id = $1 AND is_active = true
 	`
@@ -52,6 +53,7 @@ func (r *ProductRepository) GetByID(id int) (*models.Product, error) {
 		&product.ID,
 		&product.Name,
 		&product.CategoryID,
+		&product.TaxCategoryID,
 		&product.Unit,
 		&product.Notes,
 		&product.IsActive,
@@ -79,7 +81,7 @@ func (r *ProductRepository) GetByID(id int) (*models.Product, error) {
 
 func (r *ProductRepository) GetAll(limit, offset int, search string) ([]*models.Product, error) {
 	query := `
-		SELECT id, name, category_id, unit, notes, is_active, created_by, created_at, updated_at
+		SELECT id, name, category_id, tax_category_id, unit, notes, is_active, created_by, created_at, updated_at
 		FROM products
 		WHERE is_active = true
 	`
@@ -109,6 +111,7 @@ func (r *ProductRepository) GetAll(limit, offset int, search string) ([]*models.
 			&product.ID,
 			&product.Name,
 			&product.CategoryID,
+			&product.TaxCategoryID,
 			&product.Unit,
 			&product.Notes,
 			&product.IsActive,
@@ -136,14 +139,15 @@ func (r *ProductRepository) GetAll(limit, offset int, search string) ([]*models.
 func (r *ProductRepository) Update(product *models.Product) error {
 	query := `
 		UPDATE products
-		SET name = $1, category_id = $2, unit = $3, notes = $4, is_active = $5, updated_at = $6
-		WHERE id = $7
+		SET name = $1, category_id = $2, tax_category_id = $3, unit = $4, notes = $5, is_active = $6, updated_at = $7
+		WHERE id = $8
 	`
 
 	result, err := r.db.Exec(
 		query,
 		product.Name,
 		product.CategoryID,
+		product.TaxCategoryID,
 		product.Unit,
 		product.Notes,
 		product.IsActive,
@@ -213,7 +217,7 @@ func (r *ProductRepository) SearchProductsHybrid(query string, limit int, offset
 	searchQuery := strings.ReplaceAll(query, " ", " & ")
 
 	sqlQuery := `
-		SELECT p.id, p.name, p.category_id, p.unit, p.notes,
+		SELECT p.id, p.name, p.category_id, p.tax_category_id, p.unit, p.notes,
 			   p.is_active, p.created_by, p.created_at, p.updated_at,
 			   GREATEST(
 				   CASE WHEN normalize_vietnamese(p.name) = normalize_vietnamese($1) THEN 1.0 ELSE 0 END,
@@ -250,6 +254,7 @@ func (r *ProductRepository) SearchProductsHybrid(query string, limit int, offset
 			&product.ID,
 			&product.Name,
 			&product.CategoryID,
+			&product.TaxCategoryID,
 			&product.Unit,
 			&product.Notes,
 			&product.IsActive,
@@ -285,7 +290,7 @@ func (r *ProductRepository) SearchProductsWithVariants(query string, limit int)
 	searchQuery := strings.ReplaceAll(query, " ", " & ")
 
 	sqlQuery := `
-		SELECT DISTINCT p.id, p.name, p.category_id, p.unit, p.notes,
+		SELECT DISTINCT p.id, p.name, p.category_id, p.tax_category_id, p.unit, p.notes,
 			   p.is_active, p.created_by, p.created_at, p.updated_at,
 			   GREATEST(
 				   CASE WHEN normalize_vietnamese(p.name) = normalize_vietnamese($1) THEN 1.0 ELSE 0 END,
@@ -333,6 +338,7 @@ func (r *ProductRepository) SearchProductsWithVariants(query string, limit int)
 			&product.ID,
 			&product.Name,
 			&product.CategoryID,
+			&product.TaxCategoryID,
 			&product.Unit,
 			&product.Notes,
 			&product.IsActive,
@@ -401,16 +407,16 @@ func (r *ProductRepository) CountSearchResults(query string) (int, error) {
 func (r *ProductRepository) CreateVariant(variant *models.ProductVariant) error {
 	query := `
 		INSERT INTO product_variants (product_id, name, sku, stock, sold, price, unit, is_active, created_by, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		VALUES ($1, $2, $3, 0, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at, updated_at
 	`
 
+	// A new variant has no stock until it is put at a branch in branch_stock
 	err := r.db.QueryRow(
 		query,
 		variant.ProductID,
 		variant.Name,
 		variant.SKU,
-		variant.Stock,
 		variant.Sold,
 		variant.Price,
 		variant.Unit,
@@ -499,15 +505,15 @@ func (r *ProductRepository) GetVariantsByProductID(productID int) ([]*models.Pro
 func (r *ProductRepository) UpdateVariant(variant *models.ProductVariant) error {
 	query := `
 		UPDATE product_variants
-		SET name = $1, sku = $2, stock = $3, sold = $4, price = $5, unit = $6, is_active = $7, updated_at = $8
-		WHERE id = $9
+		SET name = $1, sku = $2, sold = $3, price = $4, unit = $5, is_active = $6, updated_at = $7
+		WHERE id = $8
 	`
 
+	// Stock is kept per branch in branch_stock and summed into product_variants.stock by a trigger
 	result, err := r.db.Exec(
 		query,
 		variant.Name,
 		variant.SKU,
-		variant.Stock,
 		variant.Sold,
 		variant.Price,
 		variant.Unit,
@@ -551,23 +557,3 @@ func (r *ProductRepository) DeleteVariant(id int) error {
 
 	return nil
 }
-
-func (r *ProductRepository) UpdateStock(variantID int, quantity int) error {
-	query := `UPDATE product_variants SET stock = stock + $1 WHERE id = $2`
-
-	result, err := r.db.Exec(query, quantity, variantID)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return errors.New("variant not found")
-	}
-
-	return nil
-}
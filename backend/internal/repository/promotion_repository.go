@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"steel-pos-backend/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type PromotionRepository struct {
+	db *sql.DB
+}
+
+func NewPromotionRepository(db *sql.DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+const promotionColumns = `
+	id, name, description, promotion_type, category_id, product_id, variant_id,
+	discount_percentage, buy_quantity, get_quantity, min_quantity, unit,
+	starts_at, ends_at, is_active, created_by, created_by_name, created_at, updated_at
+`
+
+// Promotion methods
+func (r *PromotionRepository) Create(promotion *models.Promotion) error {
+	query := `
+		INSERT INTO promotions (
+			name, description, promotion_type, category_id, product_id, variant_id,
+			discount_percentage, buy_quantity, get_quantity, min_quantity, unit,
+			starts_at, ends_at, is_active, created_by, created_by_name, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		promotion.Name,
+		promotion.Description,
+		promotion.PromotionType,
+		promotion.CategoryID,
+		promotion.ProductID,
+		promotion.VariantID,
+		promotion.DiscountPercentage,
+		promotion.BuyQuantity,
+		promotion.GetQuantity,
+		promotion.MinQuantity,
+		promotion.Unit,
+		promotion.StartsAt,
+		promotion.EndsAt,
+		promotion.IsActive,
+		promotion.CreatedBy,
+		promotion.CreatedByName,
+		promotion.CreatedAt,
+		promotion.UpdatedAt,
+	).Scan(&promotion.ID, &promotion.CreatedAt, &promotion.UpdatedAt)
+}
+
+func (r *PromotionRepository) GetByID(id int) (*models.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE id = $1`
+
+	promotion, err := scanPromotion(r.db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+func (r *PromotionRepository) GetAll(limit, offset int, activeOnly bool) ([]*models.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions`
+	if activeOnly {
+		query += ` WHERE is_active = true`
+	}
+	query += ` ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+
+	return r.queryPromotions(query, limit, offset)
+}
+
+func (r *PromotionRepository) Count(activeOnly bool) (int, error) {
+	query := `SELECT COUNT(*) FROM promotions`
+	if activeOnly {
+		query += ` WHERE is_active = true`
+	}
+
+	var count int
+	err := r.db.QueryRow(query).Scan(&count)
+	return count, err
+}
+
+// GetRunningPromotions returns the active promotions whose campaign window contains the given time
+func (r *PromotionRepository) GetRunningPromotions(at time.Time) ([]*models.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions
+		WHERE is_active = true
+		  AND (starts_at IS NULL OR starts_at <= $1)
+		  AND (ends_at IS NULL OR ends_at > $1)
+		ORDER BY id ASC`
+
+	return r.queryPromotions(query, at)
+}
+
+func (r *PromotionRepository) Update(promotion *models.Promotion) error {
+	query := `
+		UPDATE promotions
+		SET name = $1, description = $2, category_id = $3, product_id = $4, variant_id = $5,
+			discount_percentage = $6, buy_quantity = $7, get_quantity = $8, min_quantity = $9,
+			unit = $10, starts_at = $11, ends_at = $12, is_active = $13, updated_at = $14
+		WHERE id = $15
+	`
+
+	result, err := r.db.Exec(
+		query,
+		promotion.Name,
+		promotion.Description,
+		promotion.CategoryID,
+		promotion.ProductID,
+		promotion.VariantID,
+		promotion.DiscountPercentage,
+		promotion.BuyQuantity,
+		promotion.GetQuantity,
+		promotion.MinQuantity,
+		promotion.Unit,
+		promotion.StartsAt,
+		promotion.EndsAt,
+		promotion.IsActive,
+		promotion.UpdatedAt,
+		promotion.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("promotion not found")
+	}
+
+	return nil
+}
+
+// Delete deactivates a promotion so invoices that used it keep a valid reference
+func (r *PromotionRepository) Delete(id int) error {
+	query := `UPDATE promotions SET is_active = false, updated_at = $1 WHERE id = $2`
+
+	result, err := r.db.Exec(query, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("promotion not found")
+	}
+
+	return nil
+}
+
+// GetProductCategories returns the category of each given product, keyed by product ID
+func (r *PromotionRepository) GetProductCategories(productIDs []int) (map[int]int, error) {
+	categories := make(map[int]int)
+	if len(productIDs) == 0 {
+		return categories, nil
+	}
+
+	query := `SELECT id, category_id FROM products WHERE id = ANY($1) AND category_id IS NOT NULL`
+
+	rows, err := r.db.Query(query, pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID, categoryID int
+		if err := rows.Scan(&productID, &categoryID); err != nil {
+			return nil, err
+		}
+		categories[productID] = categoryID
+	}
+
+	return categories, nil
+}
+
+// Discount role cap methods
+func (r *PromotionRepository) GetRoleCaps() ([]*models.DiscountRoleCap, error) {
+	query := `
+		SELECT role, max_discount_percentage, updated_at, updated_by, updated_by_name
+		FROM discount_role_caps
+		ORDER BY role ASC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var caps []*models.DiscountRoleCap
+	for rows.Next() {
+		roleCap := &models.DiscountRoleCap{}
+		err := rows.Scan(
+			&roleCap.Role,
+			&roleCap.MaxDiscountPercentage,
+			&roleCap.UpdatedAt,
+			&roleCap.UpdatedBy,
+			&roleCap.UpdatedByName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		caps = append(caps, roleCap)
+	}
+
+	return caps, nil
+}
+
+func (r *PromotionRepository) GetRoleCap(role string) (*models.DiscountRoleCap, error) {
+	query := `
+		SELECT role, max_discount_percentage, updated_at, updated_by, updated_by_name
+		FROM discount_role_caps
+		WHERE role = $1
+	`
+
+	roleCap := &models.DiscountRoleCap{}
+	err := r.db.QueryRow(query, role).Scan(
+		&roleCap.Role,
+		&roleCap.MaxDiscountPercentage,
+		&roleCap.UpdatedAt,
+		&roleCap.UpdatedBy,
+		&roleCap.UpdatedByName,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return roleCap, nil
+}
+
+func (r *PromotionRepository) UpsertRoleCap(roleCap *models.DiscountRoleCap) error {
+	query := `
+		INSERT INTO discount_role_caps (role, max_discount_percentage, updated_at, updated_by, updated_by_name)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (role) DO UPDATE
+		SET max_discount_percentage = EXCLUDED.max_discount_percentage,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by,
+			updated_by_name = EXCLUDED.updated_by_name
+		RETURNING updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		roleCap.Role,
+		roleCap.MaxDiscountPercentage,
+		roleCap.UpdatedAt,
+		roleCap.UpdatedBy,
+		roleCap.UpdatedByName,
+	).Scan(&roleCap.UpdatedAt)
+}
+
+// Helper methods
+func (r *PromotionRepository) queryPromotions(query string, args ...interface{}) ([]*models.Promotion, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []*models.Promotion
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, promotion)
+	}
+
+	return promotions, nil
+}
+
+type promotionScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPromotion(row promotionScanner) (*models.Promotion, error) {
+	promotion := &models.Promotion{}
+	err := row.Scan(
+		&promotion.ID,
+		&promotion.Name,
+		&promotion.Description,
+		&promotion.PromotionType,
+		&promotion.CategoryID,
+		&promotion.ProductID,
+		&promotion.VariantID,
+		&promotion.DiscountPercentage,
+		&promotion.BuyQuantity,
+		&promotion.GetQuantity,
+		&promotion.MinQuantity,
+		&promotion.Unit,
+		&promotion.StartsAt,
+		&promotion.EndsAt,
+		&promotion.IsActive,
+		&promotion.CreatedBy,
+		&promotion.CreatedByName,
+		&promotion.CreatedAt,
+		&promotion.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return promotion, nil
+}
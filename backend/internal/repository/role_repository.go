@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"steel-pos-backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type RoleRepository struct {
+	db *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+const roleSelect = `
+	SELECT r.id, r.name, r.description, r.is_system, r.require_two_factor, r.created_at, r.updated_at,
+		COALESCE(ARRAY(SELECT rp.permission FROM role_permissions rp WHERE rp.role_id = r.id ORDER BY rp.permission), '{}'),
+		(SELECT COUNT(*) FROM users u WHERE u.role = r.name AND u.is_active = true)
+	FROM roles r`
+
+// Role methods
+func (r *RoleRepository) Create(role *models.Role) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO roles (name, description, is_system, require_two_factor, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(query, role.Name, role.Description, role.IsSystem, role.RequireTwoFactor, role.CreatedAt, role.UpdatedAt).
+		Scan(&role.ID, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertRolePermissions(tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RoleRepository) GetByName(name string) (*models.Role, error) {
+	role, err := scanRole(r.db.QueryRow(roleSelect+` WHERE r.name = $1`, name))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (r *RoleRepository) GetAll() ([]*models.Role, error) {
+	rows, err := r.db.Query(roleSelect + ` ORDER BY r.is_system DESC, r.id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []*models.Role
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+// Update updates the description and two-factor policy of a role and replaces its permissions
+func (r *RoleRepository) Update(role *models.Role) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE roles SET description = $1, require_two_factor = $2, updated_at = $3 WHERE id = $4`,
+		role.Description, role.RequireTwoFactor, role.UpdatedAt, role.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("role not found")
+	}
+
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE role_id = $1`, role.ID); err != nil {
+		return err
+	}
+
+	if err := insertRolePermissions(tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RoleRepository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM roles WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("role not found")
+	}
+
+	return nil
+}
+
+// CountUsers counts the users assigned to a role, deactivated users included
+func (r *RoleRepository) CountUsers(name string) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM users WHERE role = $1`, name).Scan(&count)
+	return count, err
+}
+
+// GetPermissionsByRole gets the permissions of every role keyed by role name
+func (r *RoleRepository) GetPermissionsByRole() (map[string][]string, error) {
+	rows, err := r.db.Query(`
+		SELECT r.name, rp.permission
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		ORDER BY r.name, rp.permission
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := make(map[string][]string)
+	for rows.Next() {
+		var role string
+		var permission sql.NullString
+		if err := rows.Scan(&role, &permission); err != nil {
+			return nil, err
+		}
+		if _, ok := permissions[role]; !ok {
+			permissions[role] = []string{}
+		}
+		if permission.Valid {
+			permissions[role] = append(permissions[role], permission.String)
+		}
+	}
+
+	return permissions, rows.Err()
+}
+
+// Helper methods
+
+func insertRolePermissions(tx *sql.Tx, roleID int, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO role_permissions (role_id, permission)
+		SELECT $1, unnest($2::text[])
+	`, roleID, pq.Array(permissions))
+	return err
+}
+
+type roleScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRole(scanner roleScanner) (*models.Role, error) {
+	role := &models.Role{}
+	var permissions pq.StringArray
+	err := scanner.Scan(
+		&role.ID,
+		&role.Name,
+		&role.Description,
+		&role.IsSystem,
+		&role.RequireTwoFactor,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+		&permissions,
+		&role.UserCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Permissions = []string(permissions)
+	return role, nil
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"database/sql"
+	"steel-pos-backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type StoreSettingsRepository struct {
+	db *sql.DB
+}
+
+func NewStoreSettingsRepository(db *sql.DB) *StoreSettingsRepository {
+	return &StoreSettingsRepository{db: db}
+}
+
+// Get gets the store settings with the bank accounts, without the logo image
+func (r *StoreSettingsRepository) Get() (*models.StoreSettings, error) {
+	query := `
+		SELECT name, address, phones, tax_code, footer_text, logo IS NOT NULL, default_invoice_template,
+		       updated_at, updated_by, updated_by_name
+		FROM store_settings
+		WHERE id = 1
+	`
+
+	settings := &models.StoreSettings{}
+	var phones pq.StringArray
+	err := r.db.QueryRow(query).Scan(
+		&settings.Name,
+		&settings.Address,
+		&phones,
+		&settings.TaxCode,
+		&settings.FooterText,
+		&settings.HasLogo,
+		&settings.DefaultInvoiceTemplate,
+		&settings.UpdatedAt,
+		&settings.UpdatedBy,
+		&settings.UpdatedByName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	settings.Phones = []string(phones)
+
+	rows, err := r.db.Query(`
+		SELECT id, bank_bin, bank_name, account_number, account_name, branch, sort_order
+		FROM store_bank_accounts
+		ORDER BY sort_order ASC, id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings.BankAccounts = []*models.StoreBankAccount{}
+	for rows.Next() {
+		account := &models.StoreBankAccount{}
+		if err := rows.Scan(
+			&account.ID,
+			&account.BankBIN,
+			&account.BankName,
+			&account.AccountNumber,
+			&account.AccountName,
+			&account.Branch,
+			&account.SortOrder,
+		); err != nil {
+			return nil, err
+		}
+		settings.BankAccounts = append(settings.BankAccounts, account)
+	}
+
+	return settings, rows.Err()
+}
+
+// Update saves the store profile. Bank accounts are replaced when replaceBankAccounts is set.
+func (r *StoreSettingsRepository) Update(settings *models.StoreSettings, replaceBankAccounts bool) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE store_settings
+		SET name = $1, address = $2, phones = $3, tax_code = $4, footer_text = $5,
+		    default_invoice_template = $6, updated_by = $7, updated_by_name = $8
+		WHERE id = 1
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		settings.Name,
+		settings.Address,
+		pq.Array(settings.Phones),
+		settings.TaxCode,
+		settings.FooterText,
+		settings.DefaultInvoiceTemplate,
+		settings.UpdatedBy,
+		settings.UpdatedByName,
+	).Scan(&settings.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if replaceBankAccounts {
+		if _, err := tx.Exec(`DELETE FROM store_bank_accounts`); err != nil {
+			return err
+		}
+
+		for i, account := range settings.BankAccounts {
+			account.SortOrder = i + 1
+			err := tx.QueryRow(`
+				INSERT INTO store_bank_accounts (bank_bin, bank_name, account_number, account_name, branch, sort_order)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				RETURNING id
+			`, account.BankBIN, account.BankName, account.AccountNumber, account.AccountName, account.Branch, account.SortOrder).Scan(&account.ID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetLogo gets the logo image of the store, nil when there is none
+func (r *StoreSettingsRepository) GetLogo() (*models.StoreLogo, error) {
+	logo := &models.StoreLogo{}
+	var contentType sql.NullString
+	err := r.db.QueryRow(`SELECT logo, logo_content_type FROM store_settings WHERE id = 1`).Scan(&logo.Data, &contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	if logo.Data == nil {
+		return nil, nil
+	}
+	logo.ContentType = contentType.String
+
+	return logo, nil
+}
+
+// SetLogo replaces the logo image of the store; a nil logo removes it
+func (r *StoreSettingsRepository) SetLogo(logo *models.StoreLogo, updatedBy int, updatedByName string) error {
+	var data []byte
+	var contentType *string
+	if logo != nil {
+		data = logo.Data
+		contentType = &logo.ContentType
+	}
+
+	_, err := r.db.Exec(`
+		UPDATE store_settings
+		SET logo = $1, logo_content_type = $2, updated_by = $3, updated_by_name = $4
+		WHERE id = 1
+	`, data, contentType, updatedBy, updatedByName)
+
+	return err
+}
@@ -0,0 +1,346 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"steel-pos-backend/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type TaxRepository struct {
+	db *sql.DB
+}
+
+func NewTaxRepository(db *sql.DB) *TaxRepository {
+	return &TaxRepository{db: db}
+}
+
+// TaxCategory methods
+func (r *TaxRepository) CreateCategory(category *models.TaxCategory) error {
+	query := `
+		INSERT INTO tax_categories (code, name, description, is_active, created_by, created_by_name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		category.Code,
+		category.Name,
+		category.Description,
+		category.IsActive,
+		category.CreatedBy,
+		category.CreatedByName,
+		category.CreatedAt,
+		category.UpdatedAt,
+	).Scan(&category.ID, &category.CreatedAt, &category.UpdatedAt)
+}
+
+func (r *TaxRepository) GetCategoryByID(id int) (*models.TaxCategory, error) {
+	query := `
+		SELECT id, code, name, description, is_active, created_by, created_by_name, created_at, updated_at
+		FROM tax_categories
+		WHERE id = $1
+	`
+
+	category := &models.TaxCategory{}
+	err := r.db.QueryRow(query, id).Scan(
+		&category.ID,
+		&category.Code,
+		&category.Name,
+		&category.Description,
+		&category.IsActive,
+		&category.CreatedBy,
+		&category.CreatedByName,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (r *TaxRepository) GetCategories(activeOnly bool) ([]*models.TaxCategory, error) {
+	query := `
+		SELECT id, code, name, description, is_active, created_by, created_by_name, created_at, updated_at
+		FROM tax_categories
+	`
+	if activeOnly {
+		query += ` WHERE is_active = true`
+	}
+	query += ` ORDER BY code ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*models.TaxCategory
+	for rows.Next() {
+		category := &models.TaxCategory{}
+		err := rows.Scan(
+			&category.ID,
+			&category.Code,
+			&category.Name,
+			&category.Description,
+			&category.IsActive,
+			&category.CreatedBy,
+			&category.CreatedByName,
+			&category.CreatedAt,
+			&category.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, nil
+}
+
+func (r *TaxRepository) UpdateCategory(category *models.TaxCategory) error {
+	query := `
+		UPDATE tax_categories
+		SET name = $1, description = $2, is_active = $3, updated_at = $4
+		WHERE id = $5
+	`
+
+	result, err := r.db.Exec(query, category.Name, category.Description, category.IsActive, category.UpdatedAt, category.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("tax category not found")
+	}
+
+	return nil
+}
+
+// TaxRate methods
+
+// CreateRate adds a rate to a tax category. The open-ended rate in effect at the new rate's
+// start is closed at that start; when the new rate is itself bounded (e.g. a temporary
+// reduction by decree), the closed rate resumes after the new rate ends.
+func (r *TaxRepository) CreateRate(rate *models.TaxRate) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var openRate models.TaxRate
+	err = tx.QueryRow(`
+		SELECT id, rate, legal_reference
+		FROM tax_rates
+		WHERE tax_category_id = $1 AND effective_to IS NULL AND effective_from < $2
+		FOR UPDATE`,
+		rate.TaxCategoryID, rate.EffectiveFrom,
+	).Scan(&openRate.ID, &openRate.Rate, &openRate.LegalReference)
+
+	hasOpenRate := true
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		hasOpenRate = false
+	}
+
+	if hasOpenRate {
+		_, err = tx.Exec(`UPDATE tax_rates SET effective_to = $1 WHERE id = $2`, rate.EffectiveFrom, openRate.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Reject rates overlapping an existing period
+	var overlaps bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM tax_rates
+			WHERE tax_category_id = $1
+			  AND ($3::timestamptz IS NULL OR effective_from < $3)
+			  AND (effective_to IS NULL OR effective_to > $2)
+		)`,
+		rate.TaxCategoryID, rate.EffectiveFrom, rate.EffectiveTo,
+	).Scan(&overlaps)
+	if err != nil {
+		return err
+	}
+
+	if overlaps {
+		return errors.New("tax rate overlaps an existing rate of this category")
+	}
+
+	err = insertTaxRate(tx, rate)
+	if err != nil {
+		return err
+	}
+
+	if hasOpenRate && rate.EffectiveTo != nil {
+		err = insertTaxRate(tx, &models.TaxRate{
+			TaxCategoryID:  rate.TaxCategoryID,
+			Rate:           openRate.Rate,
+			EffectiveFrom:  *rate.EffectiveTo,
+			LegalReference: openRate.LegalReference,
+			CreatedBy:      rate.CreatedBy,
+			CreatedByName:  rate.CreatedByName,
+			CreatedAt:      rate.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *TaxRepository) GetRatesByCategoryID(categoryID int) ([]*models.TaxRate, error) {
+	query := `
+		SELECT id, tax_category_id, rate, effective_from, effective_to, legal_reference,
+			   created_by, created_by_name, created_at
+		FROM tax_rates
+		WHERE tax_category_id = $1
+		ORDER BY effective_from DESC
+	`
+
+	rows, err := r.db.Query(query, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []*models.TaxRate
+	for rows.Next() {
+		rate := &models.TaxRate{}
+		err := rows.Scan(
+			&rate.ID,
+			&rate.TaxCategoryID,
+			&rate.Rate,
+			&rate.EffectiveFrom,
+			&rate.EffectiveTo,
+			&rate.LegalReference,
+			&rate.CreatedBy,
+			&rate.CreatedByName,
+			&rate.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+// GetProductTaxRates returns the tax category and the rate in effect at the given time for
+// each product that has an active tax category, keyed by product ID
+func (r *TaxRepository) GetProductTaxRates(productIDs []int, at time.Time) (map[int]*models.ProductTaxRate, error) {
+	rates := make(map[int]*models.ProductTaxRate)
+	if len(productIDs) == 0 {
+		return rates, nil
+	}
+
+	query := `
+		SELECT p.id, tc.id, tr.rate
+		FROM products p
+		JOIN tax_categories tc ON tc.id = p.tax_category_id AND tc.is_active = true
+		LEFT JOIN tax_rates tr ON tr.tax_category_id = tc.id
+			AND tr.effective_from <= $2
+			AND (tr.effective_to IS NULL OR tr.effective_to > $2)
+		WHERE p.id = ANY($1)
+	`
+
+	rows, err := r.db.Query(query, pq.Array(productIDs), at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rate := &models.ProductTaxRate{}
+		if err := rows.Scan(&rate.ProductID, &rate.TaxCategoryID, &rate.Rate); err != nil {
+			return nil, err
+		}
+		rates[rate.ProductID] = rate
+	}
+
+	return rates, nil
+}
+
+// GetVATSummary returns the VAT collected per rate on non-cancelled invoices created in [from, to).
+// Items that got a share of an explicit invoice tax amount count that share.
+func (r *TaxRepository) GetVATSummary(from, to time.Time) ([]*models.VATSummaryLine, int, error) {
+	query := `
+		SELECT ii.tax_rate, COALESCE(SUM(ii.taxable_amount), 0), COALESCE(SUM(COALESCE(ii.allocated_tax_amount, ii.tax_amount)), 0)
+		FROM invoice_items ii
+		JOIN invoices i ON i.id = ii.invoice_id
+		WHERE i.status != 'cancelled' AND i.created_at >= $1 AND i.created_at < $2
+		GROUP BY ii.tax_rate
+		ORDER BY ii.tax_rate ASC
+	`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var lines []*models.VATSummaryLine
+	for rows.Next() {
+		line := &models.VATSummaryLine{}
+		if err := rows.Scan(&line.TaxRate, &line.TaxableAmount, &line.TaxAmount); err != nil {
+			return nil, 0, err
+		}
+		lines = append(lines, line)
+	}
+
+	var invoiceCount int
+	err = r.db.QueryRow(
+		`SELECT COUNT(*) FROM invoices WHERE status != 'cancelled' AND created_at >= $1 AND created_at < $2`,
+		from, to,
+	).Scan(&invoiceCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return lines, invoiceCount, nil
+}
+
+// Helper methods
+func insertTaxRate(tx *sql.Tx, rate *models.TaxRate) error {
+	query := `
+		INSERT INTO tax_rates (
+			tax_category_id, rate, effective_from, effective_to, legal_reference,
+			created_by, created_by_name, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	return tx.QueryRow(
+		query,
+		rate.TaxCategoryID,
+		rate.Rate,
+		rate.EffectiveFrom,
+		rate.EffectiveTo,
+		rate.LegalReference,
+		rate.CreatedBy,
+		rate.CreatedByName,
+		rate.CreatedAt,
+	).Scan(&rate.ID, &rate.CreatedAt)
+}
@@ -16,8 +16,8 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 
 func (r *UserRepository) Create(user *models.User) error {
 	query := `
-		INSERT INTO users (username, email, password_hash, full_name, role, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO users (username, email, password_hash, full_name, role, is_active, must_change_password, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at, updated_at
 	`
 	
@@ -29,6 +29,7 @@ func (r *UserRepository) Create(user *models.User) error {
 		user.FullName,
 		user.Role,
 		user.IsActive,
+		user.MustChangePassword,
 		user.CreatedAt,
 		user.UpdatedAt,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
@@ -39,7 +40,7 @@ func (r *UserRepository) Create(user *models.User) error {
 func (r *UserRepository) GetByID(id int) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, username, email, password_hash, full_name, role, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, full_name, role, is_active, must_change_password, password_changed_at, pin_hash IS NOT NULL, created_at, updated_at
 		FROM users
 		WHERE id = $1 AND is_active = true
 	`
@@ -52,6 +53,9 @@ func (r *UserRepository) GetByID(id int) (*models.User, error) {
 		&user.FullName,
 		&user.Role,
 		&user.IsActive,
+		&user.MustChangePassword,
+		&user.PasswordChangedAt,
+		&user.HasPin,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -69,7 +73,7 @@ func (r *UserRepository) GetByID(id int) (*models.User, error) {
 func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, username, email, password_hash, full_name, role, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, full_name, role, is_active, must_change_password, password_changed_at, pin_hash IS NOT NULL, created_at, updated_at
 		FROM users
 		WHERE username = $1 AND is_active = true
 	`
@@ -82,6 +86,9 @@ func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
 		&user.FullName,
 		&user.Role,
 		&user.IsActive,
+		&user.MustChangePassword,
+		&user.PasswordChangedAt,
+		&user.HasPin,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -99,7 +106,7 @@ func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
 func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, username, email, password_hash, full_name, role, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, full_name, role, is_active, must_change_password, password_changed_at, pin_hash IS NOT NULL, created_at, updated_at
 		FROM users
 		WHERE email = $1 AND is_active = true
 	`
@@ -112,6 +119,9 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 		&user.FullName,
 		&user.Role,
 		&user.IsActive,
+		&user.MustChangePassword,
+		&user.PasswordChangedAt,
+		&user.HasPin,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -128,7 +138,7 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 
 func (r *UserRepository) GetAll(limit, offset int) ([]*models.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, full_name, role, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, full_name, role, is_active, must_change_password, password_changed_at, pin_hash IS NOT NULL, created_at, updated_at
 		FROM users
 		WHERE is_active = true
 		ORDER BY created_at DESC
@@ -152,6 +162,9 @@ func (r *UserRepository) GetAll(limit, offset int) ([]*models.User, error) {
 			&user.FullName,
 			&user.Role,
 			&user.IsActive,
+			&user.MustChangePassword,
+			&user.PasswordChangedAt,
+			&user.HasPin,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
@@ -188,10 +201,11 @@ func (r *UserRepository) Update(user *models.User) error {
 	return nil
 }
 
+// UpdatePassword sets a password chosen by the user, which clears must_change_password
 func (r *UserRepository) UpdatePassword(userID int, passwordHash string) error {
 	query := `
 		UPDATE users
-		SET password_hash = $1, updated_at = NOW()
+		SET password_hash = $1, must_change_password = false, password_changed_at = NOW(), updated_at = NOW()
 		WHERE id = $2
 	`
 	
@@ -212,6 +226,88 @@ func (r *UserRepository) UpdatePassword(userID int, passwordHash string) error {
 	return nil
 }
 
+// SetMustChangePassword flags or unflags a user as having to change their password
+func (r *UserRepository) SetMustChangePassword(userID int, mustChange bool) error {
+	query := `
+		UPDATE users
+		SET must_change_password = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := r.db.Exec(query, mustChange, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// MustChangePassword reports whether an active user has to change their password
+func (r *UserRepository) MustChangePassword(userID int) (bool, error) {
+	query := `SELECT must_change_password FROM users WHERE id = $1 AND is_active = true`
+
+	var mustChange bool
+	err := r.db.QueryRow(query, userID).Scan(&mustChange)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, errors.New("user not found")
+		}
+		return false, err
+	}
+
+	return mustChange, nil
+}
+
+// UpdatePin sets the PIN hash of a user, or removes their PIN when pinHash is nil
+func (r *UserRepository) UpdatePin(userID int, pinHash *string) error {
+	query := `
+		UPDATE users
+		SET pin_hash = $1, updated_at = NOW()
+		WHERE id = $2 AND is_active = true
+	`
+
+	result, err := r.db.Exec(query, pinHash, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// GetPinHash gets the PIN hash of a user, nil when the user has no PIN
+func (r *UserRepository) GetPinHash(userID int) (*string, error) {
+	query := `SELECT pin_hash FROM users WHERE id = $1`
+
+	var pinHash *string
+	err := r.db.QueryRow(query, userID).Scan(&pinHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	return pinHash, nil
+}
+
 func (r *UserRepository) Delete(id int) error {
 	query := `
 		UPDATE users
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"steel-pos-backend/internal/handlers"
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAPIKeyRoutes configures API key management routes
+func SetupAPIKeyRoutes(api *gin.RouterGroup, apiKeyHandler *handlers.APIKeyHandler, authMiddleware *middleware.AuthMiddleware) {
+	apiKeys := api.Group("/api-keys")
+	{
+		apiKeys.GET("", authMiddleware.RequirePermission(models.PermissionAPIKeyManage), apiKeyHandler.GetAPIKeys)
+		apiKeys.GET("/:id", authMiddleware.RequirePermission(models.PermissionAPIKeyManage), apiKeyHandler.GetAPIKey)
+		apiKeys.POST("", authMiddleware.RequirePermission(models.PermissionAPIKeyManage), apiKeyHandler.CreateAPIKey)
+		apiKeys.PUT("/:id", authMiddleware.RequirePermission(models.PermissionAPIKeyManage), apiKeyHandler.UpdateAPIKey)
+		apiKeys.DELETE("/:id", authMiddleware.RequirePermission(models.PermissionAPIKeyManage), apiKeyHandler.RevokeAPIKey)
+	}
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"steel-pos-backend/internal/handlers"
 	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,13 +13,33 @@ func SetupAuthRoutes(api *gin.RouterGroup, authHandler *handlers.AuthHandler, au
 	auth := api.Group("/auth")
 	{
 		auth.GET("/whoami", authHandler.WhoAmI)
+		auth.POST("/logout-all", authHandler.LogoutAll)
+		auth.POST("/change-password", authHandler.ChangePassword)
+		auth.GET("/sessions", authHandler.GetMySessions)
+		auth.DELETE("/sessions/:sessionId", authHandler.RevokeMySession)
+		auth.GET("/2fa", authHandler.GetTwoFactorStatus)
+		auth.POST("/2fa/setup", authHandler.BeginTwoFactorSetup)
+		auth.POST("/2fa/enable", authHandler.EnableTwoFactor)
+		auth.POST("/2fa/disable", authHandler.DisableTwoFactor)
+		auth.POST("/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
+		auth.PUT("/pin", authHandler.SetPin)
+		auth.DELETE("/pin", authHandler.RemovePin)
+		auth.POST("/switch-user", authHandler.SwitchUser)
+		auth.DELETE("/ip-lockouts/:ip", authMiddleware.RequirePermission(models.PermissionUserManage), authHandler.UnlockIP)
 	}
 	users := api.Group("/users")
 	{
-		users.POST("", authMiddleware.RequireAdmin(), authHandler.CreateUser)
-		users.GET("", authMiddleware.RequireManager(), authHandler.GetAllUsers)
-		users.GET("/:id", authMiddleware.RequireManager(), authHandler.GetUserByID)
-		users.PUT("/:id", authMiddleware.RequireManager(), authHandler.UpdateUser)
-		users.DELETE("/:id", authMiddleware.RequireAdmin(), authHandler.DeleteUser)
+		users.POST("", authMiddleware.RequirePermission(models.PermissionUserManage), authHandler.CreateUser)
+		users.GET("", authMiddleware.RequirePermission(models.PermissionUserView), authHandler.GetAllUsers)
+		users.GET("/:id", authMiddleware.RequirePermission(models.PermissionUserView), authHandler.GetUserByID)
+		users.PUT("/:id", authMiddleware.RequirePermission(models.PermissionUserUpdate), authHandler.UpdateUser)
+		users.DELETE("/:id", authMiddleware.RequirePermission(models.PermissionUserManage), authHandler.DeleteUser)
+		users.GET("/:id/sessions", authMiddleware.RequirePermission(models.PermissionUserManage), authHandler.GetUserSessions)
+		users.DELETE("/:id/sessions", authMiddleware.RequirePermission(models.PermissionUserManage), authHandler.RevokeAllUserSessions)
+		users.DELETE("/:id/sessions/:sessionId", authMiddleware.RequirePermission(models.PermissionUserManage), authHandler.RevokeUserSession)
+		users.POST("/:id/unlock", authMiddleware.RequirePermission(models.PermissionUserManage), authHandler.UnlockUser)
+		users.POST("/:id/reset-password", authMiddleware.RequirePermission(models.PermissionUserManage), authHandler.IssuePasswordReset)
+		users.DELETE("/:id/2fa", authMiddleware.RequirePermission(models.PermissionUserManage), authHandler.ResetUserTwoFactor)
+		users.DELETE("/:id/pin", authMiddleware.RequirePermission(models.PermissionUserManage), authHandler.ResetUserPin)
 	}
 }
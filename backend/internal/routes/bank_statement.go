@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"steel-pos-backend/internal/handlers"
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupBankStatementRoutes configures bank statement import and reconciliation routes
+func SetupBankStatementRoutes(api *gin.RouterGroup, bankStatementHandler *handlers.BankStatementHandler, authMiddleware *middleware.AuthMiddleware) {
+	// Bank statements (accountant only)
+	statements := api.Group("/bank-statements")
+	{
+		statements.POST("", authMiddleware.RequirePermission(models.PermissionBankReconcile), bankStatementHandler.UploadStatement)
+		statements.GET("", authMiddleware.RequirePermission(models.PermissionBankReconcile), bankStatementHandler.GetStatements)
+		statements.GET("/:id", authMiddleware.RequirePermission(models.PermissionBankReconcile), bankStatementHandler.GetStatement)
+	}
+
+	// Reconciliation of imported transactions
+	transactions := api.Group("/bank-transactions")
+	{
+		transactions.GET("", authMiddleware.RequirePermission(models.PermissionBankReconcile), bankStatementHandler.GetTransactions)
+		transactions.POST("/:id/confirm", authMiddleware.RequirePermission(models.PermissionBankReconcile), bankStatementHandler.ConfirmTransaction)
+		transactions.POST("/:id/ignore", authMiddleware.RequirePermission(models.PermissionBankReconcile), bankStatementHandler.IgnoreTransaction)
+		transactions.POST("/:id/restore", authMiddleware.RequirePermission(models.PermissionBankReconcile), bankStatementHandler.RestoreTransaction)
+	}
+}
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"steel-pos-backend/internal/handlers"
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupBranchRoutes configures branch, user branch assignment and consolidated report routes
+func SetupBranchRoutes(api *gin.RouterGroup, branchHandler *handlers.BranchHandler, authMiddleware *middleware.AuthMiddleware) {
+	branches := api.Group("/branches")
+	{
+		branches.GET("", branchHandler.GetBranches)
+		// Branches of the current user; the client sends the chosen one back in the X-Branch-ID header
+		branches.GET("/mine", branchHandler.GetMyBranches)
+		branches.GET("/:id", branchHandler.GetBranch)
+		branches.POST("", authMiddleware.RequirePermission(models.PermissionBranchManage), branchHandler.CreateBranch)
+		branches.PUT("/:id", authMiddleware.RequirePermission(models.PermissionBranchManage), branchHandler.UpdateBranch)
+	}
+
+	users := api.Group("/users")
+	{
+		users.GET("/:id/branches", authMiddleware.RequirePermission(models.PermissionBranchManage), branchHandler.GetUserBranches)
+		users.PUT("/:id/branches", authMiddleware.RequirePermission(models.PermissionBranchManage), branchHandler.AssignUserBranches)
+	}
+
+	reports := api.Group("/reports")
+	{
+		reports.GET("/branches", authMiddleware.RequirePermission(models.PermissionBranchReport), branchHandler.GetConsolidatedReport)
+	}
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"steel-pos-backend/internal/handlers"
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCashShiftRoutes configures cash drawer shift and Z report routes
+func SetupCashShiftRoutes(api *gin.RouterGroup, cashShiftHandler *handlers.CashShiftHandler, authMiddleware *middleware.AuthMiddleware) {
+	// Shifts are opened at the current branch; managers only see the shifts of their branches
+	shiftBranch := authMiddleware.RequireBranchAccess("id", cashShiftHandler.ShiftBranchID)
+
+	shifts := api.Group("/cash-shifts")
+	{
+		// Own shift of the current cashier
+		shifts.POST("/open", authMiddleware.RequireBranch(), cashShiftHandler.OpenShift)
+		shifts.GET("/current", cashShiftHandler.GetCurrentShift)
+		shifts.POST("/:id/cash-movements", shiftBranch, cashShiftHandler.AddMovement)
+		shifts.POST("/:id/close", shiftBranch, cashShiftHandler.CloseShift)
+		shifts.GET("/:id/z-report", shiftBranch, cashShiftHandler.GetShiftZReport)
+
+		// All shifts and end-of-day report (manager only)
+		shifts.GET("", authMiddleware.RequirePermission(models.PermissionShiftManage), authMiddleware.RequireBranch(), cashShiftHandler.GetShifts)
+		shifts.GET("/z-report/daily", authMiddleware.RequirePermission(models.PermissionShiftManage), authMiddleware.RequireBranch(), cashShiftHandler.GetDailyZReport)
+		shifts.GET("/:id", authMiddleware.RequirePermission(models.PermissionShiftManage), shiftBranch, cashShiftHandler.GetShift)
+	}
+}
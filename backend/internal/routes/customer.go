@@ -3,6 +3,7 @@ package routes
 import (
 	"steel-pos-backend/internal/handlers"
 	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,12 +13,12 @@ func SetupCustomerRoutes(api *gin.RouterGroup, customerHandler *handlers.Custome
 	customers := api.Group("/customers")
 	{
 		// Customer CRUD operations
-		customers.POST("", authMiddleware.RequireManager(), customerHandler.CreateCustomer)
+		customers.POST("", authMiddleware.RequirePermission(models.PermissionCustomerManage), customerHandler.CreateCustomer)
 		customers.GET("", customerHandler.GetAllCustomers)
 		customers.GET("/:id", customerHandler.GetCustomerByID)
 		customers.GET("/phone/:phone", customerHandler.GetCustomerByPhone)
-		customers.PUT("/:id", authMiddleware.RequireManager(), customerHandler.UpdateCustomer)
-		customers.DELETE("/:id", authMiddleware.RequireManager(), customerHandler.DeleteCustomer)
+		customers.PUT("/:id", authMiddleware.RequirePermission(models.PermissionCustomerManage), customerHandler.UpdateCustomer)
+		customers.DELETE("/:id", authMiddleware.RequirePermission(models.PermissionCustomerManage), customerHandler.DeleteCustomer)
 
 		// Search and filter
 		customers.GET("/search", customerHandler.SearchCustomers)
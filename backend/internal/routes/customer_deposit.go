@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"steel-pos-backend/internal/handlers"
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCustomerDepositRoutes configures customer deposit and customer ledger routes
+func SetupCustomerDepositRoutes(api *gin.RouterGroup, depositHandler *handlers.CustomerDepositHandler, authMiddleware *middleware.AuthMiddleware) {
+	customers := api.Group("/customers")
+	{
+		customers.GET("/:id/deposits", depositHandler.GetCustomerDeposits)
+		customers.POST("/:id/deposits", authMiddleware.RequirePermission(models.PermissionDepositReceive), depositHandler.ReceiveDeposit)
+		customers.POST("/:id/deposits/apply", authMiddleware.RequirePermission(models.PermissionDepositReceive), depositHandler.ApplyDeposits)
+		customers.GET("/:id/ledger", depositHandler.GetCustomerLedger)
+	}
+
+	deposits := api.Group("/customer-deposits")
+	{
+		deposits.GET("/:id", depositHandler.GetDeposit)
+		deposits.POST("/:id/refund", authMiddleware.RequirePermission(models.PermissionDepositRefund), depositHandler.RefundDeposit)
+	}
+}
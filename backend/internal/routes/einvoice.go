@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"steel-pos-backend/internal/handlers"
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupEInvoiceRoutes configures e-invoice routes
+func SetupEInvoiceRoutes(api *gin.RouterGroup, einvoiceHandler *handlers.EInvoiceHandler, authMiddleware *middleware.AuthMiddleware) {
+	// E-invoice of an invoice
+	api.POST("/invoices/:id/einvoice", authMiddleware.RequirePermission(models.PermissionEInvoiceIssue), einvoiceHandler.GenerateEInvoice)
+	api.GET("/invoices/:id/einvoice", einvoiceHandler.GetInvoiceEInvoice)
+
+	einvoices := api.Group("/einvoices")
+	{
+		einvoices.GET("/:id/xml", einvoiceHandler.GetEInvoiceXML)
+		einvoices.POST("/:id/submit", authMiddleware.RequirePermission(models.PermissionEInvoiceIssue), einvoiceHandler.SubmitEInvoice)
+	}
+}
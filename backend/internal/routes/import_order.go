@@ -3,30 +3,34 @@ package routes
 import (
 	"steel-pos-backend/internal/handlers"
 	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupImportOrderRoutes configures import order routes
 func SetupImportOrderRoutes(api *gin.RouterGroup, importOrderHandler *handlers.ImportOrderHandler, authMiddleware *middleware.AuthMiddleware) {
+	// Import orders add stock to the branch they were created at; the list shows the current branch
+	importOrderBranch := authMiddleware.RequireBranchAccess("id", importOrderHandler.ImportOrderBranchID)
+
 	importOrders := api.Group("/import-orders")
 	{
 		// Create import order
-		importOrders.POST("", authMiddleware.RequireRole("admin", "manager"), importOrderHandler.CreateImportOrder)
+		importOrders.POST("", authMiddleware.RequirePermission(models.PermissionImportCreate), authMiddleware.RequireBranch(), importOrderHandler.CreateImportOrder)
 
 		// Get all import orders
-		importOrders.GET("", importOrderHandler.GetAllImportOrders)
+		importOrders.GET("", authMiddleware.RequireBranch(), importOrderHandler.GetAllImportOrders)
 
 		// Get import order by ID
-		importOrders.GET("/:id", importOrderHandler.GetImportOrderByID)
+		importOrders.GET("/:id", importOrderBranch, importOrderHandler.GetImportOrderByID)
 
 		// Update import order
-		importOrders.PUT("/:id", authMiddleware.RequireRole("admin", "manager"), importOrderHandler.UpdateImportOrder)
+		importOrders.PUT("/:id", authMiddleware.RequirePermission(models.PermissionImportCreate), importOrderBranch, importOrderHandler.UpdateImportOrder)
 
 		// Approve import order
-		importOrders.POST("/:id/approve", authMiddleware.RequireRole("admin", "manager"), importOrderHandler.ApproveImportOrder)
+		importOrders.POST("/:id/approve", authMiddleware.RequirePermission(models.PermissionImportApprove), importOrderBranch, importOrderHandler.ApproveImportOrder)
 
 		// Delete import order
-		importOrders.DELETE("/:id", authMiddleware.RequireRole("admin"), importOrderHandler.DeleteImportOrder)
+		importOrders.DELETE("/:id", authMiddleware.RequirePermission(models.PermissionImportDelete), importOrderBranch, importOrderHandler.DeleteImportOrder)
 	}
 }
@@ -3,40 +3,55 @@ package routes
 import (
 	"steel-pos-backend/internal/handlers"
 	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
 
 func SetupInvoiceRoutes(api *gin.RouterGroup, invoiceHandler *handlers.InvoiceHandler, authMiddleware *middleware.AuthMiddleware) {
+	// Invoices belong to the branch they were created at; lists show the current branch
+	invoiceBranch := authMiddleware.RequireBranchAccess("id", invoiceHandler.InvoiceBranchID)
+
 	// Invoice routes
 	invoices := api.Group("/invoices")
 	{
 		// Invoice CRUD operations
-		invoices.POST("", authMiddleware.RequireManager(), invoiceHandler.CreateInvoice)
-		invoices.GET("", invoiceHandler.GetAllInvoices)
-		invoices.GET("/:id", invoiceHandler.GetInvoiceByID)
+		invoices.POST("", authMiddleware.RequirePermission(models.PermissionInvoiceCreate), authMiddleware.RequireBranch(), invoiceHandler.CreateInvoice)
+		invoices.GET("", authMiddleware.RequireBranch(), invoiceHandler.GetAllInvoices)
+		invoices.GET("/:id", invoiceBranch, invoiceHandler.GetInvoiceByID)
 		invoices.GET("/code/:code", invoiceHandler.GetInvoiceByCode)
-		invoices.PUT("/:id", authMiddleware.RequireManager(), invoiceHandler.UpdateInvoice)
-		invoices.DELETE("/:id", authMiddleware.RequireManager(), invoiceHandler.DeleteInvoice)
+		invoices.PUT("/:id", authMiddleware.RequirePermission(models.PermissionInvoiceUpdate), invoiceBranch, invoiceHandler.UpdateInvoice)
+		invoices.DELETE("/:id", authMiddleware.RequirePermission(models.PermissionInvoiceDelete), invoiceBranch, invoiceHandler.DeleteInvoice)
 
 		// Search and filter
-		invoices.GET("/search", invoiceHandler.SearchInvoices)
+		invoices.GET("/search", authMiddleware.RequireBranch(), invoiceHandler.SearchInvoices)
 
 		// Export and print
-		invoices.GET("/export", authMiddleware.RequireManager(), invoiceHandler.ExportInvoices)
+		invoices.GET("/export", authMiddleware.RequirePermission(models.PermissionInvoiceExport), invoiceHandler.ExportInvoices)
 
 		// Summary/Statistics
-		invoices.GET("/summary", invoiceHandler.GetInvoiceSummary)
+		invoices.GET("/summary", authMiddleware.RequireBranch(), invoiceHandler.GetInvoiceSummary)
 		
+		// VietQR payment code
+		invoices.GET("/:id/payment-qr", invoiceBranch, invoiceHandler.GetInvoicePaymentQR)
+
+		// Thermal receipt on the counter printer
+		invoices.POST("/:id/receipt/print", invoiceBranch, invoiceHandler.PrintInvoiceReceipt)
+
 		// Audit logs for invoice
-		invoices.GET("/:id/audit-logs", invoiceHandler.GetInvoiceAuditLogs)
+		invoices.GET("/:id/audit-logs", invoiceBranch, invoiceHandler.GetInvoiceAuditLogs)
 	}
 
+	paymentBranch := authMiddleware.RequireBranchAccess("paymentId", invoiceHandler.PaymentBranchID)
+
 	// Invoice Payment routes
 	payments := api.Group("/invoice-payments")
 	{
-		payments.POST("/:invoiceId", authMiddleware.RequireManager(), invoiceHandler.CreateInvoicePayment)
-		payments.PUT("/:paymentId", authMiddleware.RequireManager(), invoiceHandler.UpdateInvoicePayment)
-		payments.DELETE("/:paymentId", authMiddleware.RequireManager(), invoiceHandler.DeleteInvoicePayment)
+		payments.POST("/:invoiceId", authMiddleware.RequirePermission(models.PermissionPaymentCreate), authMiddleware.RequireBranchAccess("invoiceId", invoiceHandler.InvoiceBranchID), invoiceHandler.CreateInvoicePayment)
+
+		// Corrections keep the original payment and add reversal/replacement records (accountant or admin only)
+		payments.GET("/corrections", authMiddleware.RequirePermission(models.PermissionPaymentCorrect), authMiddleware.RequireBranch(), invoiceHandler.GetPaymentCorrectionReport)
+		payments.PUT("/:paymentId", authMiddleware.RequirePermission(models.PermissionPaymentCorrect), paymentBranch, invoiceHandler.CorrectInvoicePayment)
+		payments.DELETE("/:paymentId", authMiddleware.RequirePermission(models.PermissionPaymentCorrect), paymentBranch, invoiceHandler.ReverseInvoicePayment)
 	}
 }
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"steel-pos-backend/internal/handlers"
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPaymentMethodRoutes configures payment method and collection report routes
+func SetupPaymentMethodRoutes(api *gin.RouterGroup, paymentMethodHandler *handlers.PaymentMethodHandler, authMiddleware *middleware.AuthMiddleware) {
+	methods := api.Group("/payment-methods")
+	{
+		// Collections grouped by method
+		methods.GET("/collections", authMiddleware.RequirePermission(models.PermissionPaymentReport), paymentMethodHandler.GetCollectionReport)
+
+		methods.GET("", paymentMethodHandler.GetPaymentMethods)
+		methods.GET("/:id", paymentMethodHandler.GetPaymentMethod)
+		methods.POST("", authMiddleware.RequirePermission(models.PermissionPaymentMethodManage), paymentMethodHandler.CreatePaymentMethod)
+		methods.PUT("/:id", authMiddleware.RequirePermission(models.PermissionPaymentMethodManage), paymentMethodHandler.UpdatePaymentMethod)
+	}
+}
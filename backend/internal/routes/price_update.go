@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"steel-pos-backend/internal/handlers"
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPriceUpdateRoutes configures bulk price update routes
+func SetupPriceUpdateRoutes(api *gin.RouterGroup, priceUpdateHandler *handlers.PriceUpdateHandler, authMiddleware *middleware.AuthMiddleware) {
+	priceUpdates := api.Group("/price-updates")
+	{
+		// Preview and apply bulk price updates
+		priceUpdates.POST("/preview", authMiddleware.RequirePermission(models.PermissionPriceEdit), priceUpdateHandler.PreviewPriceUpdate)
+		priceUpdates.POST("", authMiddleware.RequirePermission(models.PermissionPriceEdit), priceUpdateHandler.ApplyPriceUpdate)
+
+		// Batch history
+		priceUpdates.GET("", authMiddleware.RequirePermission(models.PermissionPriceEdit), priceUpdateHandler.GetPriceUpdateBatches)
+		priceUpdates.GET("/:id", authMiddleware.RequirePermission(models.PermissionPriceEdit), priceUpdateHandler.GetPriceUpdateBatch)
+
+		// Roll back a batch
+		priceUpdates.POST("/:id/rollback", authMiddleware.RequirePermission(models.PermissionPriceEdit), priceUpdateHandler.RollbackPriceUpdate)
+	}
+}
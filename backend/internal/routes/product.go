@@ -3,6 +3,7 @@ package routes
 import (
 	"steel-pos-backend/internal/handlers"
 	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,11 +13,11 @@ func SetupProductRoutes(api *gin.RouterGroup, productHandler *handlers.ProductHa
 	products := api.Group("/products")
 	{
 		// Product CRUD operations
-		products.POST("", authMiddleware.RequireManager(), productHandler.CreateProduct)
+		products.POST("", authMiddleware.RequirePermission(models.PermissionProductManage), productHandler.CreateProduct)
 		products.GET("", productHandler.GetAllProducts)
 		products.GET("/:id", productHandler.GetProductByID)
-		products.PUT("/:id", authMiddleware.RequireManager(), productHandler.UpdateProduct)
-		products.DELETE("/:id", authMiddleware.RequireManager(), productHandler.DeleteProduct)
+		products.PUT("/:id", authMiddleware.RequirePermission(models.PermissionProductManage), productHandler.UpdateProduct)
+		products.DELETE("/:id", authMiddleware.RequirePermission(models.PermissionProductManage), productHandler.DeleteProduct)
 
 		// Search endpoints
 		products.GET("/search", productHandler.SearchProducts)
@@ -27,7 +28,7 @@ func SetupProductRoutes(api *gin.RouterGroup, productHandler *handlers.ProductHa
 	// Product variants routes - use a separate group to avoid conflicts
 	productVariants := api.Group("/product-variants")
 	{
-		productVariants.POST("/:productId", authMiddleware.RequireManager(), productHandler.CreateVariant)
+		productVariants.POST("/:productId", authMiddleware.RequirePermission(models.PermissionProductManage), productHandler.CreateVariant)
 		productVariants.GET("/:productId", productHandler.GetVariantsByProductID)
 	}
 
@@ -35,7 +36,16 @@ func SetupProductRoutes(api *gin.RouterGroup, productHandler *handlers.ProductHa
 	variants := api.Group("/variants")
 	{
 		variants.GET("/:variantId", productHandler.GetVariantByID)
-		variants.PUT("/:variantId", authMiddleware.RequireManager(), productHandler.UpdateVariant)
-		variants.DELETE("/:variantId", authMiddleware.RequireManager(), productHandler.DeleteVariant)
+		variants.PUT("/:variantId", authMiddleware.RequirePermission(models.PermissionProductManage), productHandler.UpdateVariant)
+		variants.DELETE("/:variantId", authMiddleware.RequirePermission(models.PermissionProductManage), productHandler.DeleteVariant)
+
+		// Stock at each branch; stock set through the product routes is that of the current branch
+		variants.GET("/:variantId/branch-stock", productHandler.GetVariantBranchStock)
+
+		// Price history and scheduled price changes
+		variants.GET("/:variantId/price-history", productHandler.GetVariantPriceHistory)
+		variants.GET("/:variantId/scheduled-prices", productHandler.GetScheduledPriceChanges)
+		variants.POST("/:variantId/scheduled-prices", authMiddleware.RequirePermission(models.PermissionPriceEdit), productHandler.SchedulePriceChange)
+		variants.DELETE("/:variantId/scheduled-prices/:changeId", authMiddleware.RequirePermission(models.PermissionPriceEdit), productHandler.CancelScheduledPriceChange)
 	}
 }
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"steel-pos-backend/internal/handlers"
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPromotionRoutes configures promotion and discount cap routes
+func SetupPromotionRoutes(api *gin.RouterGroup, promotionHandler *handlers.PromotionHandler, authMiddleware *middleware.AuthMiddleware) {
+	promotions := api.Group("/promotions")
+	{
+		// Discount caps by role
+		promotions.GET("/role-caps", authMiddleware.RequirePermission(models.PermissionPromotionManage), promotionHandler.GetDiscountRoleCaps)
+		promotions.PUT("/role-caps/:role", authMiddleware.RequirePermission(models.PermissionRoleManage), promotionHandler.SetDiscountRoleCap)
+
+		// Promotion CRUD
+		promotions.GET("", promotionHandler.GetPromotions)
+		promotions.GET("/:id", promotionHandler.GetPromotion)
+		promotions.POST("", authMiddleware.RequirePermission(models.PermissionPromotionManage), promotionHandler.CreatePromotion)
+		promotions.PUT("/:id", authMiddleware.RequirePermission(models.PermissionPromotionManage), promotionHandler.UpdatePromotion)
+		promotions.DELETE("/:id", authMiddleware.RequirePermission(models.PermissionPromotionManage), promotionHandler.DeletePromotion)
+	}
+}
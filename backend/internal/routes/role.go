@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"steel-pos-backend/internal/handlers"
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRoleRoutes configures role and permission routes
+func SetupRoleRoutes(api *gin.RouterGroup, roleHandler *handlers.RoleHandler, authMiddleware *middleware.AuthMiddleware) {
+	api.GET("/permissions", authMiddleware.RequirePermission(models.PermissionRoleManage), roleHandler.GetPermissions)
+
+	roles := api.Group("/roles")
+	{
+		roles.GET("", authMiddleware.RequirePermission(models.PermissionUserView), roleHandler.GetRoles)
+		roles.GET("/:name", authMiddleware.RequirePermission(models.PermissionUserView), roleHandler.GetRole)
+		roles.POST("", authMiddleware.RequirePermission(models.PermissionRoleManage), roleHandler.CreateRole)
+		roles.PUT("/:name", authMiddleware.RequirePermission(models.PermissionRoleManage), roleHandler.UpdateRole)
+		roles.DELETE("/:name", authMiddleware.RequirePermission(models.PermissionRoleManage), roleHandler.DeleteRole)
+	}
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"steel-pos-backend/internal/handlers"
 	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,6 +17,19 @@ func SetupAllRoutes(
 	invoiceHandler *handlers.InvoiceHandler,
 	customerHandler *handlers.CustomerHandler,
 	auditLogHandler *handlers.AuditLogHandler,
+	priceUpdateHandler *handlers.PriceUpdateHandler,
+	promotionHandler *handlers.PromotionHandler,
+	taxHandler *handlers.TaxHandler,
+	einvoiceHandler *handlers.EInvoiceHandler,
+	bankStatementHandler *handlers.BankStatementHandler,
+	cashShiftHandler *handlers.CashShiftHandler,
+	paymentMethodHandler *handlers.PaymentMethodHandler,
+	customerDepositHandler *handlers.CustomerDepositHandler,
+	storeSettingsHandler *handlers.StoreSettingsHandler,
+	signedURLHandler *handlers.SignedURLHandler,
+	roleHandler *handlers.RoleHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	branchHandler *handlers.BranchHandler,
 	authMiddleware *middleware.AuthMiddleware,
 	tokenRefreshMiddleware *middleware.TokenRefreshMiddleware,
 ) {
@@ -26,12 +40,24 @@ func SetupAllRoutes(
 	publicAuth := api.Group("/auth")
 	{
 		publicAuth.POST("/login", authHandler.Login)
+		publicAuth.POST("/login/2fa", authHandler.LoginTwoFactor)
+		publicAuth.POST("/login/2fa/setup", authHandler.BeginTwoFactorSetupLogin)
+		publicAuth.POST("/login/2fa/enable", authHandler.EnableTwoFactorLogin)
 		publicAuth.POST("/refresh", authHandler.RefreshToken)
+		publicAuth.POST("/logout", authHandler.Logout)
+		publicAuth.POST("/reset-password", authHandler.ResetPassword)
+		publicAuth.GET("/password-policy", authHandler.GetPasswordPolicy)
 	}
 
-	// PDF endpoints (custom authentication with query param support)
-	api.GET("/invoices/:id/print", authMiddleware.AuthenticateWithQueryParam(), invoiceHandler.PrintInvoice)
-	api.GET("/invoices/:id/pdf", authMiddleware.AuthenticateWithQueryParam(), invoiceHandler.PrintInvoice)
+	// PDF and image endpoints (Authorization header or a signed URL from POST /signed-urls)
+	invoiceBranch := authMiddleware.RequireBranchAccess("id", invoiceHandler.InvoiceBranchID)
+	api.GET("/invoices/:id/print", authMiddleware.AuthenticateSignedURL(), invoiceBranch, invoiceHandler.PrintInvoice)
+	api.GET("/invoices/:id/pdf", authMiddleware.AuthenticateSignedURL(), invoiceBranch, invoiceHandler.PrintInvoice)
+	api.GET("/invoices/:id/payment-qr.png", authMiddleware.AuthenticateSignedURL(), invoiceBranch, invoiceHandler.GetInvoicePaymentQRImage)
+	api.GET("/invoices/:id/receipt", authMiddleware.AuthenticateSignedURL(), invoiceBranch, invoiceHandler.GetInvoiceReceipt)
+	api.GET("/cash-shifts/:id/z-report/pdf", authMiddleware.AuthenticateSignedURL(), authMiddleware.RequireBranchAccess("id", cashShiftHandler.ShiftBranchID), cashShiftHandler.PrintShiftZReport)
+	api.GET("/cash-shifts/z-report/daily/pdf", authMiddleware.AuthenticateSignedURL(), authMiddleware.RequirePermission(models.PermissionShiftManage), authMiddleware.RequireBranch(), cashShiftHandler.PrintDailyZReport)
+	api.GET("/store-settings/logo", authMiddleware.AuthenticateSignedURL(), storeSettingsHandler.GetLogo)
 
 	// Apply token refresh middleware first, then authentication middleware
 	api.Use(tokenRefreshMiddleware.TokenRefresh())
@@ -44,4 +70,17 @@ func SetupAllRoutes(
 	SetupInvoiceRoutes(api, invoiceHandler, authMiddleware)
 	SetupCustomerRoutes(api, customerHandler, authMiddleware)
 	SetupAuditLogRoutes(api, auditLogHandler, authMiddleware)
+	SetupPriceUpdateRoutes(api, priceUpdateHandler, authMiddleware)
+	SetupPromotionRoutes(api, promotionHandler, authMiddleware)
+	SetupTaxRoutes(api, taxHandler, authMiddleware)
+	SetupEInvoiceRoutes(api, einvoiceHandler, authMiddleware)
+	SetupBankStatementRoutes(api, bankStatementHandler, authMiddleware)
+	SetupCashShiftRoutes(api, cashShiftHandler, authMiddleware)
+	SetupPaymentMethodRoutes(api, paymentMethodHandler, authMiddleware)
+	SetupCustomerDepositRoutes(api, customerDepositHandler, authMiddleware)
+	SetupStoreSettingsRoutes(api, storeSettingsHandler, authMiddleware)
+	SetupSignedURLRoutes(api, signedURLHandler)
+	SetupRoleRoutes(api, roleHandler, authMiddleware)
+	SetupAPIKeyRoutes(api, apiKeyHandler, authMiddleware)
+	SetupBranchRoutes(api, branchHandler, authMiddleware)
 }
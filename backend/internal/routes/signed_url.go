@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"steel-pos-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSignedURLRoutes configures routes issuing signed URLs for printable documents
+func SetupSignedURLRoutes(api *gin.RouterGroup, signedURLHandler *handlers.SignedURLHandler) {
+	api.POST("/signed-urls", signedURLHandler.CreateSignedURL)
+}
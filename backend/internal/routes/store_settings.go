@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"steel-pos-backend/internal/handlers"
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupStoreSettingsRoutes configures store profile routes
+func SetupStoreSettingsRoutes(api *gin.RouterGroup, storeSettingsHandler *handlers.StoreSettingsHandler, authMiddleware *middleware.AuthMiddleware) {
+	settings := api.Group("/store-settings")
+	{
+		settings.GET("", storeSettingsHandler.GetSettings)
+		settings.PUT("", authMiddleware.RequirePermission(models.PermissionSettingsManage), storeSettingsHandler.UpdateSettings)
+		settings.POST("/logo", authMiddleware.RequirePermission(models.PermissionSettingsManage), storeSettingsHandler.UploadLogo)
+		settings.DELETE("/logo", authMiddleware.RequirePermission(models.PermissionSettingsManage), storeSettingsHandler.DeleteLogo)
+	}
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"steel-pos-backend/internal/handlers"
+	"steel-pos-backend/internal/middleware"
+	"steel-pos-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupTaxRoutes configures tax category, rate and VAT report routes
+func SetupTaxRoutes(api *gin.RouterGroup, taxHandler *handlers.TaxHandler, authMiddleware *middleware.AuthMiddleware) {
+	tax := api.Group("/tax")
+	{
+		// VAT report
+		tax.GET("/vat-report", authMiddleware.RequirePermission(models.PermissionTaxReport), taxHandler.GetVATReport)
+
+		// Tax categories
+		tax.GET("/categories", taxHandler.GetTaxCategories)
+		tax.GET("/categories/:id", taxHandler.GetTaxCategory)
+		tax.POST("/categories", authMiddleware.RequirePermission(models.PermissionTaxManage), taxHandler.CreateTaxCategory)
+		tax.PUT("/categories/:id", authMiddleware.RequirePermission(models.PermissionTaxManage), taxHandler.UpdateTaxCategory)
+
+		// Effective-dated rates
+		tax.POST("/categories/:id/rates", authMiddleware.RequirePermission(models.PermissionTaxManage), taxHandler.AddTaxRate)
+	}
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/repository"
+)
+
+// APIKeyPrefix starts every API key, which tells them apart from JWT access tokens
+const APIKeyPrefix = "spk_"
+
+// apiKeyPrefixLength is how much of a key is stored in clear to show which key is which
+const apiKeyPrefixLength = len(APIKeyPrefix) + 8
+
+// apiKeyLastUsedInterval limits how often the last use of a key is written to the database
+const apiKeyLastUsedInterval = time.Minute
+
+type APIKeyService struct {
+	apiKeyRepo      *repository.APIKeyRepository
+	userRepo        *repository.UserRepository
+	roleService     *RoleService
+	auditLogService AuditLogService
+}
+
+func NewAPIKeyService(apiKeyRepo *repository.APIKeyRepository, userRepo *repository.UserRepository, roleService *RoleService, auditLogService AuditLogService) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo:      apiKeyRepo,
+		userRepo:        userRepo,
+		roleService:     roleService,
+		auditLogService: auditLogService,
+	}
+}
+
+// IsAPIKey reports whether a credential looks like an API key rather than a JWT
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// GetAPIKeys gets every API key, including revoked and expired ones
+func (s *APIKeyService) GetAPIKeys() ([]*models.APIKey, error) {
+	return s.apiKeyRepo.GetAll()
+}
+
+func (s *APIKeyService) GetAPIKey(id int) (*models.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil {
+		return nil, errors.New("API key not found")
+	}
+
+	return key, nil
+}
+
+// CreateAPIKey creates a key that acts as a user with some of the permissions of their role. The
+// secret is returned only here; the database keeps its hash.
+func (s *APIKeyService) CreateAPIKey(req *models.CreateAPIKeyRequest, userID int, userName string) (*models.CreatedAPIKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("API key name is required")
+	}
+
+	user, err := s.userRepo.GetByID(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.scopePermissions(user, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAPIKeyExpiry(req.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	key := &models.APIKey{
+		Name:        name,
+		Prefix:      secret[:apiKeyPrefixLength],
+		UserID:      user.ID,
+		Username:    user.Username,
+		Permissions: permissions,
+		ExpiresAt:   req.ExpiresAt,
+		CreatedBy:   &userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.apiKeyRepo.Create(key, hashAPIKey(secret)); err != nil {
+		return nil, err
+	}
+
+	s.logAPIKeyChange(key.ID, "created", fmt.Sprintf("Created API key %s for %s", key.Name, user.Username),
+		nil, apiKeyData(key), userID, userName)
+
+	return &models.CreatedAPIKey{APIKey: key, Key: secret}, nil
+}
+
+// UpdateAPIKey updates the name, permissions and expiry of a key that has not been revoked
+func (s *APIKeyService) UpdateAPIKey(id int, req *models.UpdateAPIKeyRequest, userID int, userName string) (*models.APIKey, error) {
+	key, err := s.GetAPIKey(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.RevokedAt != nil {
+		return nil, errors.New("API key has been revoked")
+	}
+
+	oldData := apiKeyData(key)
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("API key name is required")
+		}
+		key.Name = name
+	}
+
+	if req.Permissions != nil {
+		user, err := s.userRepo.GetByID(key.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		key.Permissions, err = s.scopePermissions(user, *req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.ExpiresAt != nil {
+		if err := validateAPIKeyExpiry(req.ExpiresAt); err != nil {
+			return nil, err
+		}
+		key.ExpiresAt = req.ExpiresAt
+	}
+
+	key.UpdatedAt = time.Now()
+
+	if err := s.apiKeyRepo.Update(key); err != nil {
+		return nil, err
+	}
+
+	s.logAPIKeyChange(key.ID, "updated", fmt.Sprintf("Updated API key %s", key.Name), oldData, apiKeyData(key), userID, userName)
+
+	return key, nil
+}
+
+// RevokeAPIKey revokes a key; requests made with it are rejected from then on
+func (s *APIKeyService) RevokeAPIKey(id int, userID int, userName string) error {
+	key, err := s.GetAPIKey(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.apiKeyRepo.Revoke(key.ID, time.Now()); err != nil {
+		return err
+	}
+
+	s.logAPIKeyChange(key.ID, "deleted", fmt.Sprintf("Revoked API key %s", key.Name), apiKeyData(key), nil, userID, userName)
+
+	return nil
+}
+
+// Authenticate checks an API key and returns the user it acts as and the permissions the request
+// gets: those of the key that the role of the user still grants
+func (s *APIKeyService) Authenticate(secret, ipAddress string) (*models.User, *models.APIKey, []string, error) {
+	if !IsAPIKey(secret) {
+		return nil, nil, nil, errors.New("invalid API key")
+	}
+
+	now := time.Now()
+	key, err := s.apiKeyRepo.GetByHash(hashAPIKey(secret))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if key == nil || !key.IsActive(now) {
+		return nil, nil, nil, errors.New("invalid, revoked or expired API key")
+	}
+
+	// Keys of a deactivated user stop working
+	user, err := s.userRepo.GetByID(key.UserID)
+	if err != nil {
+		return nil, nil, nil, errors.New("invalid API key: user is not active")
+	}
+
+	rolePermissions, err := s.roleService.GetRolePermissions(user.Role)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	granted := make(map[string]bool, len(rolePermissions))
+	for _, permission := range rolePermissions {
+		granted[permission] = true
+	}
+
+	permissions := []string{}
+	for _, permission := range key.Permissions {
+		if granted[permission] {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(key.ID, ipAddress, now, apiKeyLastUsedInterval); err != nil {
+		// Log error but don't fail the request
+		log.Printf("Failed to record last use of API key %d: %v", key.ID, err)
+	}
+
+	return user, key, permissions, nil
+}
+
+// Helper methods
+
+// scopePermissions validates the permissions of a key: they must be granted by the role of the
+// user it acts as, and a key cannot manage API keys itself
+func (s *APIKeyService) scopePermissions(user *models.User, codes []string) ([]string, error) {
+	permissions, err := normalizePermissions(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(permissions) == 0 {
+		return nil, errors.New("API key needs at least one permission")
+	}
+
+	for _, permission := range permissions {
+		if permission == models.PermissionAPIKeyManage {
+			return nil, fmt.Errorf("permission %s cannot be granted to API keys", permission)
+		}
+
+		granted, err := s.roleService.HasPermission(user.Role, permission)
+		if err != nil {
+			return nil, err
+		}
+		if !granted {
+			return nil, fmt.Errorf("role %s of %s does not grant permission %s", user.Role, user.Username, permission)
+		}
+	}
+
+	return permissions, nil
+}
+
+func (s *APIKeyService) logAPIKeyChange(keyID int, action, summary string, oldData, newData map[string]interface{}, userID int, userName string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	req := models.AuditLogCreateRequest{
+		EntityType:     "api_key",
+		EntityID:       keyID,
+		Action:         action,
+		UserID:         &userID,
+		UserName:       &userName,
+		OldData:        oldData,
+		NewData:        newData,
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the API key change
+		log.Printf("Failed to create audit log for API key %d: %v", keyID, err)
+	}
+}
+
+func validateAPIKeyExpiry(expiresAt *time.Time) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return errors.New("API key expiry must be in the future")
+	}
+	return nil
+}
+
+// generateAPIKey returns a new key: the prefix followed by 48 hex characters
+func generateAPIKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return APIKeyPrefix + hex.EncodeToString(b), nil
+}
+
+// hashAPIKey hashes an API key; only the hash is stored
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+	return hex.EncodeToString(sum[:])
+}
+
+func apiKeyData(key *models.APIKey) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        key.Name,
+		"prefix":      key.Prefix,
+		"user_id":     key.UserID,
+		"permissions": key.Permissions,
+		"expires_at":  key.ExpiresAt,
+	}
+}
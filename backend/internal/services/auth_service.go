@@ -1,8 +1,16 @@
 package services
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
 	"time"
+	"unicode"
 
 	"steel-pos-backend/internal/config"
 	"steel-pos-backend/internal/models"
@@ -11,29 +19,54 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// PINs only switch users on a session that is already logged in, so they are short
+const (
+	minPinLength = 4
+	maxPinLength = 8
+)
+
 type AuthService struct {
-	userRepo  *repository.UserRepository
-	jwtService *JWTService
-	config    *config.Config
+	userRepo          *repository.UserRepository
+	passwordResetRepo *repository.PasswordResetRepository
+	jwtService        *JWTService
+	roleService       *RoleService
+	loginThrottle     *LoginThrottleService
+	twoFactorService  *TwoFactorService
+	branchService     *BranchService
+	auditLogService   AuditLogService
+	config            *config.Config
 }
 
-func NewAuthService(userRepo *repository.UserRepository, jwtService *JWTService, config *config.Config) *AuthService {
+func NewAuthService(userRepo *repository.UserRepository, passwordResetRepo *repository.PasswordResetRepository, jwtService *JWTService, roleService *RoleService, loginThrottle *LoginThrottleService, twoFactorService *TwoFactorService, branchService *BranchService, auditLogService AuditLogService, config *config.Config) *AuthService {
 	return &AuthService{
-		userRepo:   userRepo,
-		jwtService: jwtService,
-		config:     config,
+		userRepo:          userRepo,
+		passwordResetRepo: passwordResetRepo,
+		jwtService:        jwtService,
+		roleService:       roleService,
+		loginThrottle:     loginThrottle,
+		twoFactorService:  twoFactorService,
+		branchService:     branchService,
+		auditLogService:   auditLogService,
+		config:            config,
 	}
 }
 
-func (s *AuthService) Login(req *models.LoginRequest) (*models.LoginResponse, error) {
+func (s *AuthService) Login(req *models.LoginRequest, ipAddress, userAgent string) (*models.LoginResponse, error) {
+	// Chặn đăng nhập khi username hoặc IP vừa sai nhiều lần
+	if err := s.loginThrottle.Check(req.Username, ipAddress); err != nil {
+		return nil, err
+	}
+
 	// Tìm user theo username
 	user, err := s.userRepo.GetByUsername(req.Username)
 	if err != nil {
+		s.loginThrottle.RecordFailure(req.Username, ipAddress, userAgent, nil)
 		return nil, errors.New("invalid credentials")
 	}
 
 	// Kiểm tra password
 	if !s.checkPassword(req.Password, user.PasswordHash) {
+		s.loginThrottle.RecordFailure(req.Username, ipAddress, userAgent, user)
 		return nil, errors.New("invalid credentials")
 	}
 
@@ -42,33 +75,127 @@ func (s *AuthService) Login(req *models.LoginRequest) (*models.LoginResponse, er
 		return nil, errors.New("account is deactivated")
 	}
 
-	// Generate tokens
-	accessToken, err := s.jwtService.GenerateAccessToken(user)
+	// Yêu cầu bước xác thực thứ hai nếu user đã bật 2FA hoặc vai trò bắt buộc 2FA
+	challenge, err := s.twoFactorChallenge(user)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := s.jwtService.GenerateRefreshToken(user)
+	if challenge != nil {
+		return &models.LoginResponse{User: user, TwoFactor: challenge}, nil
+	}
+
+	return s.completeLogin(user, req.DeviceName, ipAddress, userAgent)
+}
+
+// LoginTwoFactor hoàn tất đăng nhập bằng mã từ ứng dụng xác thực hoặc mã khôi phục
+func (s *AuthService) LoginTwoFactor(req *models.TwoFactorLoginRequest, ipAddress, userAgent string) (*models.LoginResponse, error) {
+	user, err := s.challengeUser(req.ChallengeToken)
 	if err != nil {
 		return nil, err
 	}
 
-	expiration, _ := time.ParseDuration(s.config.JWT.AccessTokenExpiry)
+	// Mã 2FA sai cũng tính vào số lần đăng nhập sai
+	if err := s.loginThrottle.Check(user.Username, ipAddress); err != nil {
+		return nil, err
+	}
 
-	return &models.LoginResponse{
-		User:         user,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		TokenType:    "Bearer",
-		ExpiresIn:    int64(expiration.Seconds()),
-	}, nil
+	if err := s.twoFactorService.Verify(user, req.Code, req.RecoveryCode); err != nil {
+		s.loginThrottle.RecordFailure(user.Username, ipAddress, userAgent, user)
+		return nil, err
+	}
+
+	return s.completeLogin(user, req.DeviceName, ipAddress, userAgent)
+}
+
+// BeginTwoFactorSetupLogin bắt đầu cài đặt 2FA trong lúc đăng nhập, khi vai trò bắt buộc 2FA
+// mà user chưa cài đặt
+func (s *AuthService) BeginTwoFactorSetupLogin(challengeToken string) (*models.TwoFactorSetup, error) {
+	user, err := s.challengeUser(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.twoFactorService.BeginSetup(user)
+}
+
+// EnableTwoFactorLogin xác nhận cài đặt 2FA trong lúc đăng nhập và hoàn tất đăng nhập.
+// Mã khôi phục được trả về cùng tokens.
+func (s *AuthService) EnableTwoFactorLogin(req *models.TwoFactorLoginRequest, ipAddress, userAgent string) (*models.LoginResponse, error) {
+	user, err := s.challengeUser(req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loginThrottle.Check(user.Username, ipAddress); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.twoFactorService.EnableTwoFactor(user, req.Code)
+	if err != nil {
+		s.loginThrottle.RecordFailure(user.Username, ipAddress, userAgent, user)
+		return nil, err
+	}
+
+	response, err := s.completeLogin(user, req.DeviceName, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
 }
 
 func (s *AuthService) RefreshToken(refreshToken string) (*models.LoginResponse, error) {
 	return s.jwtService.RefreshToken(refreshToken)
 }
 
-func (s *AuthService) ChangePassword(userID int, req *models.ChangePasswordRequest) error {
+// Logout revokes the session of a refresh token
+func (s *AuthService) Logout(refreshToken string) error {
+	return s.jwtService.RevokeRefreshToken(refreshToken)
+}
+
+// LogoutAll revokes every session of a user
+func (s *AuthService) LogoutAll(userID int) error {
+	return s.jwtService.RevokeUserSessions(userID)
+}
+
+// GetSessions gets the active sessions of a user, marking the session of the request
+func (s *AuthService) GetSessions(userID int, currentSessionID string) ([]*models.UserSession, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.jwtService.GetUserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.UserSession, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, &models.UserSession{
+			ID:           session.ID,
+			UserID:       session.UserID,
+			ActiveUserID: session.ActiveUserID,
+			DeviceName:   session.DeviceName,
+			IPAddress:    session.IPAddress,
+			UserAgent:    session.UserAgent,
+			CreatedAt:    session.CreatedAt,
+			LastSeenAt:   session.LastSeenAt,
+			ExpiresAt:    session.ExpiresAt,
+			IsCurrent:    session.ID == currentSessionID,
+		})
+	}
+
+	return result, nil
+}
+
+// RevokeSession revokes a session of a user, logging that device out
+func (s *AuthService) RevokeSession(userID int, sessionID string) error {
+	return s.jwtService.RevokeSession(userID, sessionID)
+}
+
+func (s *AuthService) ChangePassword(userID int, currentSessionID string, req *models.ChangePasswordRequest) error {
 	// Lấy thông tin user
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
@@ -80,25 +207,183 @@ func (s *AuthService) ChangePassword(userID int, req *models.ChangePasswordReque
 		return errors.New("current password is incorrect")
 	}
 
+	if req.NewPassword == req.CurrentPassword {
+		return errors.New("new password must be different from the current password")
+	}
+
+	// Kiểm tra password mới theo chính sách mật khẩu
+	if err := s.validatePassword(req.NewPassword, user.Username); err != nil {
+		return err
+	}
+
 	// Hash password mới
 	hashedPassword, err := s.hashPassword(req.NewPassword)
 	if err != nil {
 		return err
 	}
 
-	// Cập nhật password
-	return s.userRepo.UpdatePassword(userID, hashedPassword)
+	// Cập nhật password, đồng thời bỏ cờ bắt buộc đổi mật khẩu
+	if err := s.userRepo.UpdatePassword(userID, hashedPassword); err != nil {
+		return err
+	}
+
+	// Mật khẩu cũ có thể đã lộ: đăng xuất các phiên khác, giữ phiên đang đổi mật khẩu
+	if err := s.jwtService.RevokeOtherSessions(userID, currentSessionID); err != nil {
+		return err
+	}
+
+	s.logPasswordChange(user.ID, fmt.Sprintf("Changed password of %s", user.Username),
+		map[string]interface{}{"must_change_password": false}, &user.ID, &user.FullName)
+
+	return nil
+}
+
+// GetPasswordPolicy lấy chính sách mật khẩu để client hiển thị
+func (s *AuthService) GetPasswordPolicy() *models.PasswordPolicy {
+	policy := s.config.Password
+	return &models.PasswordPolicy{
+		MinLength:        policy.MinLength,
+		RequireUppercase: policy.RequireUppercase,
+		RequireLowercase: policy.RequireLowercase,
+		RequireDigit:     policy.RequireDigit,
+		RequireSymbol:    policy.RequireSymbol,
+	}
+}
+
+// IssuePasswordReset tạo token đặt lại mật khẩu dùng một lần cho user quên mật khẩu.
+// User bị đăng xuất khỏi mọi thiết bị và phải đổi mật khẩu ở lần đăng nhập tiếp theo.
+func (s *AuthService) IssuePasswordReset(userID int, issuedBy int, issuedByName string) (*models.PasswordResetToken, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Đặt lại mật khẩu của user quản lý được user khác cần quyền riêng, tránh chiếm tài khoản
+	// có quyền cao hơn
+	privileged, err := s.roleService.HasPermission(user.Role, models.PermissionUserManage)
+	if err != nil {
+		return nil, err
+	}
+	if privileged {
+		issuer, err := s.userRepo.GetByID(issuedBy)
+		if err != nil {
+			return nil, err
+		}
+		canReset, err := s.roleService.HasPermission(issuer.Role, models.PermissionUserResetPrivileged)
+		if err != nil {
+			return nil, err
+		}
+		if !canReset {
+			return nil, errors.New("insufficient permissions to reset the password of a user manager")
+		}
+	}
+
+	expiry, err := time.ParseDuration(s.config.Password.ResetTokenExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("invalid password reset token expiry: %w", err)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	expiresAt := time.Now().Add(expiry).Truncate(time.Second)
+
+	if err := s.passwordResetRepo.Create(user.ID, hashResetToken(token), issuedBy, expiresAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.jwtService.RevokeUserSessions(user.ID); err != nil {
+		return nil, err
+	}
+
+	s.logPasswordChange(user.ID, fmt.Sprintf("Issued password reset for %s", user.Username),
+		map[string]interface{}{"must_change_password": true, "reset_expires_at": expiresAt}, &issuedBy, &issuedByName)
+
+	return &models.PasswordResetToken{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// ResetPassword đặt mật khẩu mới bằng token do admin cấp. Token chỉ dùng được một lần.
+func (s *AuthService) ResetPassword(req *models.ResetPasswordRequest) error {
+	tokenHash := hashResetToken(req.Token)
+	now := time.Now()
+
+	userID, err := s.passwordResetRepo.GetUserID(tokenHash, now)
+	if err != nil {
+		return err
+	}
+	if userID == 0 {
+		return errors.New("invalid or expired password reset token")
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.validatePassword(req.NewPassword, user.Username); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	userID, err = s.passwordResetRepo.Use(tokenHash, hashedPassword, now)
+	if err != nil {
+		return err
+	}
+	if userID == 0 {
+		return errors.New("invalid or expired password reset token")
+	}
+
+	// Mật khẩu cũ có thể đã lộ: đăng xuất mọi phiên còn lại và mở khoá đăng nhập
+	if err := s.jwtService.RevokeUserSessions(user.ID); err != nil {
+		return err
+	}
+	if err := s.loginThrottle.RecordSuccess(user.Username, ""); err != nil {
+		return err
+	}
+
+	s.logPasswordChange(user.ID, fmt.Sprintf("Reset password of %s with a reset token", user.Username),
+		map[string]interface{}{"must_change_password": false}, &user.ID, &user.FullName)
+
+	return nil
+}
+
+// MustChangePassword kiểm tra user có bị bắt buộc đổi mật khẩu không, dùng trong middleware
+func (s *AuthService) MustChangePassword(userID int) (bool, error) {
+	return s.userRepo.MustChangePassword(userID)
+}
+
+// DeleteExpiredPasswordResets xoá các token đặt lại mật khẩu đã dùng hoặc hết hạn
+func (s *AuthService) DeleteExpiredPasswordResets() (int64, error) {
+	return s.passwordResetRepo.DeleteExpired(time.Now())
 }
 
 func (s *AuthService) CreateUser(req *models.CreateUserRequest, createdBy int) (*models.User, error) {
-	// Kiểm tra quyền tạo user (chỉ admin mới được tạo user)
+	// Kiểm tra quyền tạo user
 	creator, err := s.userRepo.GetByID(createdBy)
 	if err != nil {
 		return nil, err
 	}
 
-	if creator.Role != "admin" {
-		return nil, errors.New("only admin can create users")
+	canManage, err := s.roleService.HasPermission(creator.Role, models.PermissionUserManage)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		return nil, errors.New("insufficient permissions to create users")
+	}
+
+	// Kiểm tra vai trò tồn tại
+	if err := s.checkRoleExists(req.Role); err != nil {
+		return nil, err
 	}
 
 	// Kiểm tra username đã tồn tại chưa
@@ -119,6 +404,17 @@ func (s *AuthService) CreateUser(req *models.CreateUserRequest, createdBy int) (
 		return nil, errors.New("email already exists")
 	}
 
+	// Kiểm tra password theo chính sách mật khẩu
+	if err := s.validatePassword(req.Password, req.Username); err != nil {
+		return nil, err
+	}
+
+	// Kiểm tra chi nhánh làm việc
+	branchIDs, err := s.branchService.CheckBranchIDs(req.BranchIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	hashedPassword, err := s.hashPassword(req.Password)
 	if err != nil {
@@ -127,14 +423,15 @@ func (s *AuthService) CreateUser(req *models.CreateUserRequest, createdBy int) (
 
 	// Tạo user mới
 	user := &models.User{
-		Username:     req.Username,
-		Email:        req.Email,
-		PasswordHash: hashedPassword,
-		FullName:     req.FullName,
-		Role:         req.Role,
-		IsActive:     true,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		Username:           req.Username,
+		Email:              req.Email,
+		PasswordHash:       hashedPassword,
+		FullName:           req.FullName,
+		Role:               req.Role,
+		IsActive:           true,
+		MustChangePassword: req.MustChangePassword,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
 	}
 
 	err = s.userRepo.Create(user)
@@ -142,6 +439,11 @@ func (s *AuthService) CreateUser(req *models.CreateUserRequest, createdBy int) (
 		return nil, err
 	}
 
+	// Phân công chi nhánh cho user mới
+	if err := s.branchService.SetNewUserBranches(user.ID, branchIDs); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
 
@@ -149,6 +451,237 @@ func (s *AuthService) GetUserByID(userID int) (*models.User, error) {
 	return s.userRepo.GetByID(userID)
 }
 
+// UnlockUser mở khoá đăng nhập của user bị khoá do sai mật khẩu nhiều lần
+func (s *AuthService) UnlockUser(userID int, unlockedBy int, unlockedByName string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.loginThrottle.UnlockUser(user, unlockedBy, unlockedByName)
+}
+
+// UnlockIP mở khoá đăng nhập từ một địa chỉ IP
+func (s *AuthService) UnlockIP(ipAddress string, unlockedBy int, unlockedByName string) error {
+	if net.ParseIP(ipAddress) == nil {
+		return errors.New("invalid IP address")
+	}
+
+	return s.loginThrottle.UnlockIP(ipAddress, unlockedBy, unlockedByName)
+}
+
+// GetTwoFactorStatus lấy trạng thái 2FA của user
+func (s *AuthService) GetTwoFactorStatus(userID int) (*models.TwoFactorStatus, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.twoFactorService.GetStatus(user)
+}
+
+// BeginTwoFactorSetup tạo secret mới để user thêm vào ứng dụng xác thực
+func (s *AuthService) BeginTwoFactorSetup(userID int) (*models.TwoFactorSetup, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.twoFactorService.BeginSetup(user)
+}
+
+// EnableTwoFactor bật 2FA sau khi user nhập đúng mã từ ứng dụng xác thực
+func (s *AuthService) EnableTwoFactor(userID int, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.twoFactorService.EnableTwoFactor(user, code)
+}
+
+// DisableTwoFactor tắt 2FA, yêu cầu cả mật khẩu và mã 2FA
+func (s *AuthService) DisableTwoFactor(userID int, req *models.DisableTwoFactorRequest) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if !s.checkPassword(req.Password, user.PasswordHash) {
+		return errors.New("password is incorrect")
+	}
+
+	return s.twoFactorService.DisableTwoFactor(user, req.Code)
+}
+
+// RegenerateRecoveryCodes tạo lại mã khôi phục, các mã cũ không dùng được nữa
+func (s *AuthService) RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.twoFactorService.RegenerateRecoveryCodes(user, code)
+}
+
+// ResetTwoFactor xoá 2FA của user bị mất ứng dụng xác thực và mã khôi phục
+func (s *AuthService) ResetTwoFactor(userID int, resetBy int, resetByName string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.twoFactorService.ResetTwoFactor(user, resetBy, resetByName); err != nil {
+		return err
+	}
+
+	return s.jwtService.RevokeUserSessions(userID)
+}
+
+// SetPin đặt mã PIN để chuyển nhanh sang user trên máy bán hàng dùng chung, yêu cầu mật khẩu
+func (s *AuthService) SetPin(userID int, req *models.SetPinRequest) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if !s.checkPassword(req.Password, user.PasswordHash) {
+		return errors.New("password is incorrect")
+	}
+
+	if err := validatePin(req.Pin); err != nil {
+		return err
+	}
+
+	pinHash, err := s.hashPassword(req.Pin)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePin(userID, &pinHash); err != nil {
+		return err
+	}
+
+	s.logPinChange(user.ID, fmt.Sprintf("Set PIN of %s", user.Username),
+		map[string]interface{}{"has_pin": true}, user.ID, user.FullName)
+
+	return nil
+}
+
+// RemovePin xoá mã PIN của user, user không thể được chuyển sang bằng PIN nữa
+func (s *AuthService) RemovePin(userID int) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.removePin(user, user.ID, user.FullName)
+}
+
+// ResetUserPin xoá mã PIN của user khác, ví dụ khi PIN bị lộ
+func (s *AuthService) ResetUserPin(userID int, resetBy int, resetByName string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.removePin(user, resetBy, resetByName)
+}
+
+// SwitchUser chuyển user đang làm việc trên phiên đăng nhập hiện tại sang user khác bằng username
+// và PIN, để hoá đơn và thanh toán ghi đúng người thu ngân mà không phải đăng nhập lại.
+// Tokens cũ của phiên không dùng được nữa.
+func (s *AuthService) SwitchUser(sessionID string, currentUserID int, req *models.SwitchUserRequest, ipAddress, userAgent string) (*models.LoginResponse, error) {
+	// PIN sai cũng tính vào số lần đăng nhập sai
+	if err := s.loginThrottle.Check(req.Username, ipAddress); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByUsername(req.Username)
+	if err != nil {
+		s.loginThrottle.RecordFailure(req.Username, ipAddress, userAgent, nil)
+		return nil, errors.New("invalid credentials")
+	}
+
+	pinHash, err := s.userRepo.GetPinHash(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pinHash == nil || !s.checkPassword(req.Pin, *pinHash) {
+		s.loginThrottle.RecordFailure(req.Username, ipAddress, userAgent, user)
+		return nil, errors.New("invalid credentials")
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("account is deactivated")
+	}
+
+	if user.ID == currentUserID {
+		return nil, errors.New("user is already active on this session")
+	}
+
+	// PIN không thay thế được mật khẩu mới hoặc bước xác thực thứ hai
+	if user.MustChangePassword {
+		return nil, errors.New("password change required, please log in with your password")
+	}
+
+	challenge, err := s.twoFactorChallenge(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge != nil {
+		return nil, errors.New("two-factor authentication is required, please log in with your password")
+	}
+
+	if err := s.loginThrottle.RecordSuccess(user.Username, ipAddress); err != nil {
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := s.jwtService.SwitchSessionUser(sessionID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := s.userRepo.GetByID(currentUserID)
+	if err == nil {
+		s.logSwitchUser(user, previous, ipAddress, userAgent)
+	}
+
+	expiration, _ := time.ParseDuration(s.config.JWT.AccessTokenExpiry)
+
+	return &models.LoginResponse{
+		User:         user,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(expiration.Seconds()),
+	}, nil
+}
+
+// GetCurrentUser lấy user cùng các quyền của vai trò, dùng cho WhoAmI
+func (s *AuthService) GetCurrentUser(userID int) (*models.CurrentUser, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.roleService.GetRolePermissions(user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	return &models.CurrentUser{
+		User:        user,
+		Permissions: permissions,
+	}, nil
+}
+
 func (s *AuthService) GetAllUsers(limit, offset int) ([]*models.User, error) {
 	return s.userRepo.GetAll(limit, offset)
 }
@@ -160,8 +693,11 @@ func (s *AuthService) UpdateUser(userID int, req *models.UpdateUserRequest, upda
 		return nil, err
 	}
 
-	// Chỉ admin và manager mới được update user
-	if updater.Role != "admin" && updater.Role != "manager" {
+	canUpdate, err := s.roleService.HasPermission(updater.Role, models.PermissionUserUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if !canUpdate {
 		return nil, errors.New("insufficient permissions")
 	}
 
@@ -171,7 +707,23 @@ func (s *AuthService) UpdateUser(userID int, req *models.UpdateUserRequest, upda
 		return nil, err
 	}
 
+	// Đổi vai trò cần quyền quản lý user, tránh tự nâng quyền
+	if req.Role != user.Role {
+		canManage, err := s.roleService.HasPermission(updater.Role, models.PermissionUserManage)
+		if err != nil {
+			return nil, err
+		}
+		if !canManage {
+			return nil, errors.New("insufficient permissions to change user roles")
+		}
+
+		if err := s.checkRoleExists(req.Role); err != nil {
+			return nil, err
+		}
+	}
+
 	// Cập nhật thông tin
+	roleChanged := req.Role != user.Role
 	user.FullName = req.FullName
 	user.Role = req.Role
 	if req.IsActive != nil {
@@ -185,6 +737,14 @@ func (s *AuthService) UpdateUser(userID int, req *models.UpdateUserRequest, upda
 		return nil, err
 	}
 
+	// User bị vô hiệu hoá hoặc đổi vai trò bị đăng xuất khỏi mọi thiết bị,
+	// vì token đang dùng còn mang vai trò cũ
+	if !user.IsActive || roleChanged {
+		if err := s.jwtService.RevokeUserSessions(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	return user, nil
 }
 
@@ -195,9 +755,12 @@ func (s *AuthService) DeleteUser(userID int, deletedBy int) error {
 		return err
 	}
 
-	// Chỉ admin mới được delete user
-	if deleter.Role != "admin" {
-		return errors.New("only admin can delete users")
+	canManage, err := s.roleService.HasPermission(deleter.Role, models.PermissionUserManage)
+	if err != nil {
+		return err
+	}
+	if !canManage {
+		return errors.New("insufficient permissions to delete users")
 	}
 
 	// Không cho phép delete chính mình
@@ -205,10 +768,259 @@ func (s *AuthService) DeleteUser(userID int, deletedBy int) error {
 		return errors.New("cannot delete yourself")
 	}
 
-	return s.userRepo.Delete(userID)
+	if err := s.userRepo.Delete(userID); err != nil {
+		return err
+	}
+
+	return s.jwtService.RevokeUserSessions(userID)
 }
 
 // Helper functions
+
+// twoFactorChallenge trả về challenge khi user phải qua bước 2FA, hoặc nil nếu không cần
+func (s *AuthService) twoFactorChallenge(user *models.User) (*models.TwoFactorChallenge, error) {
+	enabled, err := s.twoFactorService.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := s.roleService.RequiresTwoFactor(user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled && !required {
+		return nil, nil
+	}
+
+	token, expiresIn, err := s.jwtService.GenerateChallengeToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorChallenge{
+		ChallengeToken: token,
+		ExpiresIn:      expiresIn,
+		SetupRequired:  !enabled,
+	}, nil
+}
+
+// challengeUser lấy user của challenge token, user phải còn active
+func (s *AuthService) challengeUser(challengeToken string) (*models.User, error) {
+	claims, err := s.jwtService.ValidateChallengeToken(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// User bị vô hiệu hoá sau khi nhập mật khẩu không được hoàn tất đăng nhập; GetByID chỉ tìm user active
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, errors.New("account is deactivated")
+		}
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("account is deactivated")
+	}
+
+	return user, nil
+}
+
+// completeLogin xoá số lần đăng nhập sai và tạo phiên đăng nhập cùng tokens
+func (s *AuthService) completeLogin(user *models.User, deviceName *string, ipAddress, userAgent string) (*models.LoginResponse, error) {
+	if err := s.loginThrottle.RecordSuccess(user.Username, ipAddress); err != nil {
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := s.jwtService.StartSession(user, deviceName, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	expiration, _ := time.ParseDuration(s.config.JWT.AccessTokenExpiry)
+
+	return &models.LoginResponse{
+		User:         user,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(expiration.Seconds()),
+	}, nil
+}
+func (s *AuthService) checkRoleExists(role string) error {
+	exists, err := s.roleService.RoleExists(role)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("role not found")
+	}
+	return nil
+}
+
+// validatePassword kiểm tra mật khẩu mới theo chính sách mật khẩu trong config
+func (s *AuthService) validatePassword(password, username string) error {
+	policy := s.config.Password
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	var missing []string
+	if len([]rune(password)) < policy.MinLength {
+		missing = append(missing, fmt.Sprintf("at least %d characters", policy.MinLength))
+	}
+	if policy.RequireUppercase && !hasUpper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if policy.RequireLowercase && !hasLower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if policy.RequireDigit && !hasDigit {
+		missing = append(missing, "a digit")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		missing = append(missing, "a symbol")
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("password must contain %s", strings.Join(missing, ", "))
+	}
+
+	if username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return errors.New("password must not contain the username")
+	}
+
+	return nil
+}
+
+func (s *AuthService) logPasswordChange(userID int, summary string, data map[string]interface{}, changedBy *int, changedByName *string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	req := models.AuditLogCreateRequest{
+		EntityType:     "user",
+		EntityID:       userID,
+		Action:         "updated",
+		UserID:         changedBy,
+		UserName:       changedByName,
+		NewData:        data,
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the password change
+		log.Printf("Failed to create audit log for password change of user %d: %v", userID, err)
+	}
+}
+
+func (s *AuthService) removePin(user *models.User, removedBy int, removedByName string) error {
+	if err := s.userRepo.UpdatePin(user.ID, nil); err != nil {
+		return err
+	}
+
+	s.logPinChange(user.ID, fmt.Sprintf("Removed PIN of %s", user.Username),
+		map[string]interface{}{"has_pin": false}, removedBy, removedByName)
+
+	return nil
+}
+
+func (s *AuthService) logPinChange(userID int, summary string, data map[string]interface{}, changedBy int, changedByName string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	req := models.AuditLogCreateRequest{
+		EntityType:     "user",
+		EntityID:       userID,
+		Action:         "updated",
+		UserID:         &changedBy,
+		UserName:       &changedByName,
+		NewData:        data,
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the PIN change
+		log.Printf("Failed to create audit log for PIN change of user %d: %v", userID, err)
+	}
+}
+
+// logSwitchUser records who took over a terminal session from whom, so that the invoices created
+// afterwards can be traced back to the switch
+func (s *AuthService) logSwitchUser(user, previous *models.User, ipAddress, userAgent string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	summary := fmt.Sprintf("Switched from %s to %s", previous.Username, user.Username)
+	req := models.AuditLogCreateRequest{
+		EntityType: "user",
+		EntityID:   user.ID,
+		Action:     "switched",
+		UserID:     &user.ID,
+		UserName:   &user.FullName,
+		NewData: map[string]interface{}{
+			"previous_user_id":  previous.ID,
+			"previous_username": previous.Username,
+		},
+		ChangesSummary: &summary,
+		IPAddress:      &ipAddress,
+		UserAgent:      &userAgent,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the switch
+		log.Printf("Failed to create audit log for user switch to %d: %v", user.ID, err)
+	}
+}
+
+// validatePin kiểm tra PIN gồm 4-8 chữ số và không quá dễ đoán
+func validatePin(pin string) error {
+	if len(pin) < minPinLength || len(pin) > maxPinLength {
+		return fmt.Errorf("PIN must be %d to %d digits", minPinLength, maxPinLength)
+	}
+
+	for _, r := range pin {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("PIN must be %d to %d digits", minPinLength, maxPinLength)
+		}
+	}
+
+	// Chặn các PIN như 1111, 1234 hoặc 9876
+	repeated, ascending, descending := true, true, true
+	for i := 1; i < len(pin); i++ {
+		repeated = repeated && pin[i] == pin[i-1]
+		ascending = ascending && pin[i] == pin[i-1]+1
+		descending = descending && pin[i] == pin[i-1]-1
+	}
+
+	if repeated || ascending || descending {
+		return errors.New("PIN is too easy to guess")
+	}
+
+	return nil
+}
+
+// hashResetToken hashes a password reset token; only the hash is stored
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
 func (s *AuthService) hashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
@@ -0,0 +1,435 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"steel-pos-backend/internal/bankstatement"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/repository"
+	"steel-pos-backend/internal/vietqr"
+)
+
+type BankReconciliationService struct {
+	bankStatementRepo *repository.BankStatementRepository
+	invoiceService    *InvoiceService
+	auditLogService   AuditLogService
+}
+
+func NewBankReconciliationService(bankStatementRepo *repository.BankStatementRepository, invoiceService *InvoiceService, auditLogService AuditLogService) *BankReconciliationService {
+	return &BankReconciliationService{
+		bankStatementRepo: bankStatementRepo,
+		invoiceService:    invoiceService,
+		auditLogService:   auditLogService,
+	}
+}
+
+// ImportStatement parses a bank statement file, stores its incoming transfers and matches
+// each of them to an open invoice where possible. Matches are only suggestions until an
+// accountant confirms them.
+func (s *BankReconciliationService) ImportStatement(fileName string, file io.Reader, createdBy int, createdByName string) (*models.BankStatement, error) {
+	parsed, err := bankstatement.Parse(fileName, file)
+	if err != nil {
+		return nil, err
+	}
+
+	openInvoices, err := s.bankStatementRepo.GetOpenInvoices()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	statement := &models.BankStatement{
+		FileName:      fileName,
+		CreatedBy:     &createdBy,
+		CreatedByName: &createdByName,
+		CreatedAt:     now,
+	}
+
+	var transactions []*models.BankTransaction
+	for _, line := range parsed {
+		if line.Amount <= 0 {
+			// Only money received can pay invoices
+			statement.SkippedCount++
+			continue
+		}
+
+		transaction := &models.BankTransaction{
+			TransactionDate:     line.Date,
+			Amount:              line.Amount,
+			Description:         optionalString(line.Description),
+			BankReference:       optionalString(line.Reference),
+			CounterpartyName:    optionalString(line.CounterpartyName),
+			CounterpartyAccount: optionalString(line.CounterpartyAccount),
+			Status:              models.BankTransactionStatusUnmatched,
+			CreatedAt:           now,
+			UpdatedAt:           now,
+		}
+
+		setMatch(transaction, line, openInvoices)
+
+		transactions = append(transactions, transaction)
+	}
+
+	if err := s.bankStatementRepo.CreateStatement(statement, transactions); err != nil {
+		return nil, err
+	}
+
+	return s.GetStatement(statement.ID)
+}
+
+func (s *BankReconciliationService) GetStatements(page, limit int) (*models.BankStatementListResponse, error) {
+	offset := (page - 1) * limit
+
+	statements, err := s.bankStatementRepo.GetStatements(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.bankStatementRepo.CountStatements()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BankStatementListResponse{
+		Statements: statements,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+	}, nil
+}
+
+// GetStatement gets a statement with its transactions
+func (s *BankReconciliationService) GetStatement(id int) (*models.BankStatement, error) {
+	statement, err := s.bankStatementRepo.GetStatementByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if statement == nil {
+		return nil, errors.New("bank statement not found")
+	}
+
+	transactions, err := s.bankStatementRepo.GetTransactions(math.MaxInt32, 0, id, "")
+	if err != nil {
+		return nil, err
+	}
+	statement.Transactions = transactions
+
+	return statement, nil
+}
+
+func (s *BankReconciliationService) GetTransactions(page, limit, statementID int, status string) (*models.BankTransactionListResponse, error) {
+	offset := (page - 1) * limit
+
+	transactions, err := s.bankStatementRepo.GetTransactions(limit, offset, statementID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.bankStatementRepo.CountTransactions(statementID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BankTransactionListResponse{
+		Transactions: transactions,
+		Total:        total,
+		Page:         page,
+		Limit:        limit,
+	}, nil
+}
+
+// ConfirmTransaction records a transfer as a bank transfer payment of its matched invoice,
+// or of the invoice given in the request. An ignored transfer can still be confirmed.
+func (s *BankReconciliationService) ConfirmTransaction(id int, req *models.ConfirmBankTransactionRequest, confirmedBy int, confirmedByName string) (*models.BankTransaction, error) {
+	transaction, err := s.bankStatementRepo.GetTransactionByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction == nil {
+		return nil, errors.New("bank transaction not found")
+	}
+
+	if transaction.Status == models.BankTransactionStatusConfirmed {
+		return nil, errors.New("bank transaction has already been confirmed")
+	}
+
+	// A candidate found by amount alone is only paid once the invoice is chosen explicitly
+	invoiceID := transaction.InvoiceID
+	if transaction.Status != models.BankTransactionStatusSuggested {
+		invoiceID = nil
+	}
+	if req.InvoiceID != nil {
+		invoiceID = req.InvoiceID
+		if transaction.InvoiceID == nil || *transaction.InvoiceID != *req.InvoiceID {
+			method := models.MatchMethodManual
+			transaction.MatchMethod = &method
+		}
+	}
+
+	if invoiceID == nil {
+		return nil, errors.New("bank transaction is not matched to an invoice, choose the invoice it pays")
+	}
+
+	invoice, err := s.invoiceService.GetInvoiceByID(*invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invoice == nil {
+		return nil, errors.New("invoice not found")
+	}
+
+	if invoice.Status == "cancelled" {
+		return nil, errors.New("invoice is cancelled")
+	}
+
+	outstanding := invoice.TotalAmount - invoice.PaidAmount
+	if transaction.Amount > outstanding+0.5 {
+		return nil, fmt.Errorf("transfer of %.0f exceeds the %.0f still due on invoice %s", transaction.Amount, outstanding, invoice.InvoiceCode)
+	}
+
+	now := time.Now()
+	transaction.InvoiceID = invoiceID
+	transaction.ConfirmedBy = &confirmedBy
+	transaction.ConfirmedByName = &confirmedByName
+	transaction.ConfirmedAt = &now
+
+	reference := fmt.Sprintf("BANK-%d", transaction.ID)
+	if transaction.BankReference != nil {
+		reference = *transaction.BankReference
+	}
+
+	payment := &models.InvoicePayment{
+		InvoiceID:            invoice.ID,
+		Amount:               transaction.Amount,
+		PaymentMethod:        models.PaymentMethodBankTransfer,
+		PaymentDate:          transaction.TransactionDate,
+		TransactionReference: &reference,
+		Notes:                transaction.Description,
+		PaymentType:          models.PaymentTypePayment,
+		Status:               "confirmed",
+		CreatedBy:            &confirmedBy,
+		CreatedByUsername:    &confirmedByName,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	// Claim the transaction and record its payment together so that it is paid once or not at all
+	if err := s.bankStatementRepo.ConfirmWithPayment(transaction, payment); err != nil {
+		return nil, err
+	}
+
+	s.logReconciliation(transaction, invoice, reference, confirmedBy, confirmedByName)
+
+	return s.bankStatementRepo.GetTransactionByID(id)
+}
+
+// IgnoreTransaction marks a transfer that does not pay an invoice
+func (s *BankReconciliationService) IgnoreTransaction(id int) (*models.BankTransaction, error) {
+	transaction, err := s.bankStatementRepo.GetTransactionByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction == nil {
+		return nil, errors.New("bank transaction not found")
+	}
+
+	transaction.Status = models.BankTransactionStatusIgnored
+	transaction.InvoiceID = nil
+	transaction.MatchMethod = nil
+	transaction.UpdatedAt = time.Now()
+
+	if err := s.bankStatementRepo.UpdateMatch(transaction); err != nil {
+		return nil, err
+	}
+
+	return s.bankStatementRepo.GetTransactionByID(id)
+}
+
+// RestoreTransaction brings an ignored transfer back to reconciliation, matched again against
+// the invoices open now
+func (s *BankReconciliationService) RestoreTransaction(id int) (*models.BankTransaction, error) {
+	transaction, err := s.bankStatementRepo.GetTransactionByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction == nil {
+		return nil, errors.New("bank transaction not found")
+	}
+
+	if transaction.Status != models.BankTransactionStatusIgnored {
+		return nil, errors.New("only ignored bank transactions can be restored")
+	}
+
+	openInvoices, err := s.bankStatementRepo.GetOpenInvoices()
+	if err != nil {
+		return nil, err
+	}
+
+	line := &bankstatement.Transaction{
+		Date:   transaction.TransactionDate,
+		Amount: transaction.Amount,
+	}
+	if transaction.Description != nil {
+		line.Description = *transaction.Description
+	}
+	if transaction.CounterpartyName != nil {
+		line.CounterpartyName = *transaction.CounterpartyName
+	}
+	if transaction.CounterpartyAccount != nil {
+		line.CounterpartyAccount = *transaction.CounterpartyAccount
+	}
+
+	transaction.Status = models.BankTransactionStatusUnmatched
+	setMatch(transaction, line, openInvoices)
+	transaction.UpdatedAt = time.Now()
+
+	if err := s.bankStatementRepo.UpdateMatch(transaction); err != nil {
+		return nil, err
+	}
+
+	return s.bankStatementRepo.GetTransactionByID(id)
+}
+
+// Helper methods
+
+// setMatch matches a transfer against the open invoices. Matches by invoice code or by amount
+// and customer are suggested; a match by amount alone only records the invoice as a candidate
+// and leaves the transfer unmatched.
+func setMatch(transaction *models.BankTransaction, line *bankstatement.Transaction, openInvoices []*models.OpenInvoice) {
+	invoice, method := matchTransaction(line, openInvoices)
+	if invoice == nil {
+		return
+	}
+
+	if method != models.MatchMethodAmount {
+		transaction.Status = models.BankTransactionStatusSuggested
+	}
+	transaction.InvoiceID = &invoice.ID
+	transaction.MatchMethod = &method
+}
+
+// matchTransaction finds the open invoice a transfer pays. The invoice code in the transfer
+// description is the strongest evidence; otherwise the amount due must be unique among the
+// invoices of the customer named in the transfer, by name or phone number. An amount due
+// that is unique among all open invoices without the customer being named is only returned
+// as a candidate, with MatchMethodAmount.
+func matchTransaction(line *bankstatement.Transaction, openInvoices []*models.OpenInvoice) (*models.OpenInvoice, string) {
+	description := compactReference(line.Description)
+
+	var byCode *models.OpenInvoice
+	longest := 0
+	for _, invoice := range openInvoices {
+		reference := vietqr.Reference(invoice.InvoiceCode)
+		if reference == "" || !strings.Contains(description, reference) {
+			continue
+		}
+		// "INV2024001" is also found in "INV20240012"; the longest code wins
+		if len(reference) > longest {
+			byCode, longest = invoice, len(reference)
+		}
+	}
+	if byCode != nil {
+		return byCode, models.MatchMethodInvoiceCode
+	}
+
+	var byAmount []*models.OpenInvoice
+	for _, invoice := range openInvoices {
+		if math.Abs(invoice.Outstanding-line.Amount) < 1 {
+			byAmount = append(byAmount, invoice)
+		}
+	}
+
+	text := bankstatement.Normalize(line.Description + " " + line.CounterpartyName)
+	descriptionDigits := digitsOnly(line.Description)
+	accountDigits := digitsOnly(line.CounterpartyAccount)
+
+	var byCustomer []*models.OpenInvoice
+	for _, invoice := range byAmount {
+		name := bankstatement.Normalize(invoice.CustomerName)
+		phone := digitsOnly(invoice.CustomerPhone)
+		namedIn := len(name) >= 3 && strings.Contains(text, name)
+		phoneIn := len(phone) >= 9 && (strings.Contains(descriptionDigits, phone) || strings.Contains(accountDigits, phone))
+		if namedIn || phoneIn {
+			byCustomer = append(byCustomer, invoice)
+		}
+	}
+
+	if len(byCustomer) == 1 {
+		return byCustomer[0], models.MatchMethodAmountCustomer
+	}
+
+	if len(byCustomer) == 0 && len(byAmount) == 1 {
+		return byAmount[0], models.MatchMethodAmount
+	}
+
+	return nil, ""
+}
+
+func (s *BankReconciliationService) logReconciliation(transaction *models.BankTransaction, invoice *models.Invoice, reference string, userID int, userName string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	summary := fmt.Sprintf("Bank transfer %s of %.0f reconciled with invoice %s", reference, transaction.Amount, invoice.InvoiceCode)
+	req := models.AuditLogCreateRequest{
+		EntityType: "invoice",
+		EntityID:   invoice.ID,
+		Action:     "updated",
+		UserID:     &userID,
+		UserName:   &userName,
+		NewData: map[string]interface{}{
+			"bank_transaction_id": transaction.ID,
+			"amount":              transaction.Amount,
+			"transaction_date":    transaction.TransactionDate,
+			"reference":           reference,
+			"match_method":        transaction.MatchMethod,
+		},
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the reconciliation
+		log.Printf("Failed to create audit log for bank transaction %d: %v", transaction.ID, err)
+	}
+}
+
+// compactReference keeps the letters and digits of a transfer description, uppercased,
+// so that it can be searched for invoice references
+func compactReference(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
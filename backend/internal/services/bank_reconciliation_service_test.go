@@ -0,0 +1,145 @@
+package services
+
+import (
+	"testing"
+
+	"steel-pos-backend/internal/bankstatement"
+	"steel-pos-backend/internal/models"
+)
+
+func testOpenInvoices() []*models.OpenInvoice {
+	return []*models.OpenInvoice{
+		{ID: 1, InvoiceCode: "INV-2024-001", CustomerName: "Nguyễn Văn An", CustomerPhone: "0901 234 567", Outstanding: 5000000},
+		{ID: 2, InvoiceCode: "INV-2024-0012", CustomerName: "Trần Thị Bình", CustomerPhone: "0912345678", Outstanding: 3200000},
+		{ID: 3, InvoiceCode: "INV-2024-003", CustomerName: "Lê Văn Cường", CustomerPhone: "0987654321", Outstanding: 3200000},
+		{ID: 4, InvoiceCode: "INV-2024-004", CustomerName: "Phạm Văn Dũng", CustomerPhone: "0977111222", Outstanding: 750000},
+	}
+}
+
+func TestMatchTransaction(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       bankstatement.Transaction
+		wantID     int // 0 when nothing matches
+		wantMethod string
+	}{
+		{
+			name:       "invoice code in description",
+			line:       bankstatement.Transaction{Amount: 1000000, Description: "CK thanh toan INV2024001"},
+			wantID:     1,
+			wantMethod: models.MatchMethodInvoiceCode,
+		},
+		{
+			name:       "longest invoice code wins",
+			line:       bankstatement.Transaction{Amount: 3200000, Description: "TT INV-2024-0012 thep hop"},
+			wantID:     2,
+			wantMethod: models.MatchMethodInvoiceCode,
+		},
+		{
+			name:       "unique amount with customer name",
+			line:       bankstatement.Transaction{Amount: 5000000, Description: "NGUYEN VAN AN chuyen tien"},
+			wantID:     1,
+			wantMethod: models.MatchMethodAmountCustomer,
+		},
+		{
+			name:       "unique amount with counterparty name",
+			line:       bankstatement.Transaction{Amount: 5000000, Description: "chuyen tien", CounterpartyName: "NGUYEN VAN AN"},
+			wantID:     1,
+			wantMethod: models.MatchMethodAmountCustomer,
+		},
+		{
+			name:       "unique amount with phone in counterparty account",
+			line:       bankstatement.Transaction{Amount: 750000, Description: "chuyen tien", CounterpartyAccount: "0977111222"},
+			wantID:     4,
+			wantMethod: models.MatchMethodAmountCustomer,
+		},
+		{
+			name:       "unique amount alone is only a candidate",
+			line:       bankstatement.Transaction{Amount: 5000000, Description: "chuyen tien"},
+			wantID:     1,
+			wantMethod: models.MatchMethodAmount,
+		},
+		{
+			name:       "unique amount with another customer named",
+			line:       bankstatement.Transaction{Amount: 750000, Description: "Nguyen Van An chuyen tien"},
+			wantID:     4,
+			wantMethod: models.MatchMethodAmount,
+		},
+		{
+			name:       "shared amount told apart by phone",
+			line:       bankstatement.Transaction{Amount: 3200000, Description: "ck 0987.654.321 tien thep"},
+			wantID:     3,
+			wantMethod: models.MatchMethodAmountCustomer,
+		},
+		{
+			name: "shared amount without customer",
+			line: bankstatement.Transaction{Amount: 3200000, Description: "chuyen tien"},
+		},
+		{
+			name: "no invoice of that amount",
+			line: bankstatement.Transaction{Amount: 123000, Description: "Nguyen Van An"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice, method := matchTransaction(&tt.line, testOpenInvoices())
+			if tt.wantID == 0 {
+				if invoice != nil {
+					t.Fatalf("matched invoice %d by %s, want no match", invoice.ID, method)
+				}
+				return
+			}
+			if invoice == nil || invoice.ID != tt.wantID || method != tt.wantMethod {
+				t.Fatalf("got %v by %q, want invoice %d by %q", invoice, method, tt.wantID, tt.wantMethod)
+			}
+		})
+	}
+}
+
+func TestSetMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       bankstatement.Transaction
+		wantStatus string
+		wantID     int
+	}{
+		{
+			name:       "suggested with the customer named",
+			line:       bankstatement.Transaction{Amount: 5000000, Description: "Nguyen Van An"},
+			wantStatus: models.BankTransactionStatusSuggested,
+			wantID:     1,
+		},
+		{
+			name:       "candidate by amount stays unmatched",
+			line:       bankstatement.Transaction{Amount: 5000000, Description: "chuyen tien"},
+			wantStatus: models.BankTransactionStatusUnmatched,
+			wantID:     1,
+		},
+		{
+			name:       "no match",
+			line:       bankstatement.Transaction{Amount: 3200000, Description: "chuyen tien"},
+			wantStatus: models.BankTransactionStatusUnmatched,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := &models.BankTransaction{Amount: tt.line.Amount, Status: models.BankTransactionStatusUnmatched}
+			setMatch(transaction, &tt.line, testOpenInvoices())
+
+			if transaction.Status != tt.wantStatus {
+				t.Fatalf("status %q, want %q", transaction.Status, tt.wantStatus)
+			}
+			if tt.wantID == 0 {
+				if transaction.InvoiceID != nil || transaction.MatchMethod != nil {
+					t.Fatalf("matched invoice %v by %v, want no match", transaction.InvoiceID, transaction.MatchMethod)
+				}
+				return
+			}
+			if transaction.InvoiceID == nil || *transaction.InvoiceID != tt.wantID {
+				t.Fatalf("invoice %v, want %d", transaction.InvoiceID, tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/repository"
+)
+
+type BranchService struct {
+	branchRepo      *repository.BranchRepository
+	userRepo        *repository.UserRepository
+	auditLogService AuditLogService
+}
+
+func NewBranchService(branchRepo *repository.BranchRepository, userRepo *repository.UserRepository, auditLogService AuditLogService) *BranchService {
+	return &BranchService{
+		branchRepo:      branchRepo,
+		userRepo:        userRepo,
+		auditLogService: auditLogService,
+	}
+}
+
+// GetBranches gets the branches, optionally only the active ones
+func (s *BranchService) GetBranches(activeOnly bool) ([]*models.Branch, error) {
+	return s.branchRepo.GetAll(activeOnly)
+}
+
+func (s *BranchService) GetBranch(id int) (*models.Branch, error) {
+	branch, err := s.branchRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if branch == nil {
+		return nil, errors.New("branch not found")
+	}
+
+	return branch, nil
+}
+
+func (s *BranchService) CreateBranch(req *models.CreateBranchRequest, userID int, userName string) (*models.Branch, error) {
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	name := strings.TrimSpace(req.Name)
+	if code == "" || name == "" {
+		return nil, errors.New("branch code and name are required")
+	}
+
+	exists, err := s.branchRepo.ExistsByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("branch code %s already exists", code)
+	}
+
+	now := time.Now()
+	branch := &models.Branch{
+		Code:      code,
+		Name:      name,
+		Address:   req.Address,
+		Phone:     req.Phone,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.branchRepo.Create(branch); err != nil {
+		return nil, err
+	}
+
+	s.logBranchChange("branch", branch.ID, "created", fmt.Sprintf("Created branch %s - %s", branch.Code, branch.Name),
+		nil, branchData(branch), userID, userName)
+
+	return branch, nil
+}
+
+// UpdateBranch updates a branch. The last active branch cannot be deactivated.
+func (s *BranchService) UpdateBranch(id int, req *models.UpdateBranchRequest, userID int, userName string) (*models.Branch, error) {
+	branch, err := s.GetBranch(id)
+	if err != nil {
+		return nil, err
+	}
+
+	oldData := branchData(branch)
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("branch name is required")
+		}
+		branch.Name = name
+	}
+	if req.Address != nil {
+		branch.Address = req.Address
+	}
+	if req.Phone != nil {
+		branch.Phone = req.Phone
+	}
+
+	if req.IsActive != nil {
+		if branch.IsActive && !*req.IsActive {
+			active, err := s.branchRepo.GetAll(true)
+			if err != nil {
+				return nil, err
+			}
+			if len(active) <= 1 {
+				return nil, errors.New("cannot deactivate the last active branch")
+			}
+		}
+		branch.IsActive = *req.IsActive
+	}
+
+	branch.UpdatedAt = time.Now()
+
+	if err := s.branchRepo.Update(branch); err != nil {
+		return nil, err
+	}
+
+	s.logBranchChange("branch", branch.ID, "updated", fmt.Sprintf("Updated branch %s - %s", branch.Code, branch.Name),
+		oldData, branchData(branch), userID, userName)
+
+	return branch, nil
+}
+
+// GetUserBranches gets the active branches a user is assigned to
+func (s *BranchService) GetUserBranches(userID int) ([]*models.Branch, error) {
+	return s.branchRepo.GetUserBranches(userID)
+}
+
+// AssignUserBranches sets the branches a user works at
+func (s *BranchService) AssignUserBranches(targetUserID int, req *models.UpdateUserBranchesRequest, userID int, userName string) ([]*models.Branch, error) {
+	user, err := s.userRepo.GetByID(targetUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	branchIDs, err := s.CheckBranchIDs(req.BranchIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	oldBranches, err := s.branchRepo.GetUserBranches(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.branchRepo.SetUserBranches(user.ID, branchIDs); err != nil {
+		return nil, err
+	}
+
+	branches, err := s.branchRepo.GetUserBranches(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logBranchChange("user", user.ID, "updated", fmt.Sprintf("Assigned %s to branches %s", user.Username, branchCodes(branches)),
+		map[string]interface{}{"branches": branchCodes(oldBranches)}, map[string]interface{}{"branches": branchCodes(branches)},
+		userID, userName)
+
+	return branches, nil
+}
+
+// SetNewUserBranches assigns a user that has just been created to branches checked by
+// CheckBranchIDs
+func (s *BranchService) SetNewUserBranches(userID int, branchIDs []int) error {
+	return s.branchRepo.SetUserBranches(userID, branchIDs)
+}
+
+// CheckBranchIDs checks that the branches exist and are active and removes duplicates. No
+// branches means the first active branch.
+func (s *BranchService) CheckBranchIDs(branchIDs []int) ([]int, error) {
+	active, err := s.branchRepo.GetAll(true)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(active) == 0 {
+		return nil, errors.New("no active branch")
+	}
+
+	if len(branchIDs) == 0 {
+		return []int{active[0].ID}, nil
+	}
+
+	isActive := make(map[int]bool, len(active))
+	for _, branch := range active {
+		isActive[branch.ID] = true
+	}
+
+	seen := make(map[int]bool)
+	checked := []int{}
+	for _, branchID := range branchIDs {
+		if !isActive[branchID] {
+			return nil, fmt.Errorf("branch %d not found or inactive", branchID)
+		}
+		if !seen[branchID] {
+			seen[branchID] = true
+			checked = append(checked, branchID)
+		}
+	}
+
+	return checked, nil
+}
+
+// ResolveBranches gets the branches a user can work at: every active branch with branch.manage,
+// else those the user is assigned to. The active branch is the requested one, which must be among
+// them, or the first of them; it is 0 when the user has no branch.
+func (s *BranchService) ResolveBranches(userID int, allBranches bool, requestedID int) (*models.UserBranches, error) {
+	var branches []*models.Branch
+	var err error
+	if allBranches {
+		branches, err = s.branchRepo.GetAll(true)
+	} else {
+		branches, err = s.branchRepo.GetUserBranches(userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.UserBranches{
+		Branches:    branches,
+		AllBranches: allBranches,
+	}
+
+	if requestedID == 0 {
+		if len(branches) > 0 {
+			result.ActiveBranchID = branches[0].ID
+		}
+		return result, nil
+	}
+
+	for _, branch := range branches {
+		if branch.ID == requestedID {
+			result.ActiveBranchID = branch.ID
+			return result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("branch %d is not one of your branches", requestedID)
+}
+
+// GetVariantStock gets the stock of a variant at each branch
+func (s *BranchService) GetVariantStock(variantID int) ([]*models.BranchStock, error) {
+	return s.branchRepo.GetVariantStock(variantID)
+}
+
+// GetConsolidatedReport compares the sales, imports, shifts and stock of every branch over
+// [from, to) and sums them up
+func (s *BranchService) GetConsolidatedReport(from, to time.Time) (*models.ConsolidatedBranchReport, error) {
+	if !to.After(from) {
+		return nil, errors.New("report end must be after its start")
+	}
+
+	lines, err := s.branchRepo.GetReport(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	total := &models.BranchReportLine{BranchName: "Tổng cộng"}
+	for _, line := range lines {
+		line.OutstandingAmount = roundAmount(line.TotalAmount - line.PaidAmount)
+
+		total.InvoiceCount += line.InvoiceCount
+		total.CancelledCount += line.CancelledCount
+		total.TotalAmount += line.TotalAmount
+		total.PaidAmount += line.PaidAmount
+		total.ImportOrderCount += line.ImportOrderCount
+		total.ImportValue += line.ImportValue
+		total.ShiftCount += line.ShiftCount
+		total.CashDifference += line.CashDifference
+		total.StockUnits += line.StockUnits
+	}
+
+	total.TotalAmount = roundAmount(total.TotalAmount)
+	total.PaidAmount = roundAmount(total.PaidAmount)
+	total.OutstandingAmount = roundAmount(total.TotalAmount - total.PaidAmount)
+	total.ImportValue = roundAmount(total.ImportValue)
+	total.CashDifference = roundAmount(total.CashDifference)
+
+	return &models.ConsolidatedBranchReport{
+		From:        from,
+		To:          to,
+		Branches:    lines,
+		Total:       total,
+		GeneratedAt: time.Now(),
+	}, nil
+}
+
+// Helper methods
+
+func (s *BranchService) logBranchChange(entityType string, entityID int, action, summary string, oldData, newData map[string]interface{}, userID int, userName string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	req := models.AuditLogCreateRequest{
+		EntityType:     entityType,
+		EntityID:       entityID,
+		Action:         action,
+		UserID:         &userID,
+		UserName:       &userName,
+		OldData:        oldData,
+		NewData:        newData,
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the branch change
+		log.Printf("Failed to create audit log for %s %d: %v", entityType, entityID, err)
+	}
+}
+
+func branchCodes(branches []*models.Branch) string {
+	codes := make([]string, len(branches))
+	for i, branch := range branches {
+		codes[i] = branch.Code
+	}
+	return strings.Join(codes, ", ")
+}
+
+func branchData(branch *models.Branch) map[string]interface{} {
+	return map[string]interface{}{
+		"code":      branch.Code,
+		"name":      branch.Name,
+		"address":   branch.Address,
+		"phone":     branch.Phone,
+		"is_active": branch.IsActive,
+	}
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/repository"
+)
+
+type CashShiftService struct {
+	cashShiftRepo   *repository.CashShiftRepository
+	auditLogService AuditLogService
+}
+
+func NewCashShiftService(cashShiftRepo *repository.CashShiftRepository, auditLogService AuditLogService) *CashShiftService {
+	return &CashShiftService{
+		cashShiftRepo:   cashShiftRepo,
+		auditLogService: auditLogService,
+	}
+}
+
+// OpenShift opens a shift for the cashier at a branch with the cash put in the drawer
+func (s *CashShiftService) OpenShift(req *models.OpenCashShiftRequest, cashierID int, cashierName string, branchID int) (*models.CashShift, error) {
+	if branchID == 0 {
+		return nil, errors.New("cash shift must be opened at a branch")
+	}
+
+	current, err := s.cashShiftRepo.GetOpenByCashier(cashierID)
+	if err != nil {
+		return nil, err
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("cashier already has an open shift (#%d), close it first", current.ID)
+	}
+
+	now := time.Now()
+	shift := &models.CashShift{
+		CashierID:    cashierID,
+		CashierName:  cashierName,
+		BranchID:     branchID,
+		Status:       models.CashShiftStatusOpen,
+		OpeningFloat: req.OpeningFloat,
+		OpeningNotes: req.Notes,
+		OpenedAt:     now,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := s.cashShiftRepo.Create(shift); err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf("Cash shift opened by %s with float %.0f", cashierName, shift.OpeningFloat)
+	s.logShift(shift, "created", summary, cashierID, cashierName)
+
+	return s.GetShift(shift.ID)
+}
+
+// GetCurrentShift gets the open shift of the cashier, nil when there is none
+func (s *CashShiftService) GetCurrentShift(cashierID int) (*models.CashShift, error) {
+	shift, err := s.cashShiftRepo.GetOpenByCashier(cashierID)
+	if err != nil || shift == nil {
+		return nil, err
+	}
+
+	return s.GetShift(shift.ID)
+}
+
+// AddMovement records cash put in or taken out of the drawer during an open shift.
+// Only the cashier of the shift or a manager may do so.
+func (s *CashShiftService) AddMovement(shiftID int, req *models.CreateCashMovementRequest, userID int, userName string, isManager bool) (*models.CashMovement, error) {
+	shift, err := s.getAccessibleShift(shiftID, userID, isManager)
+	if err != nil {
+		return nil, err
+	}
+
+	if shift.Status != models.CashShiftStatusOpen {
+		return nil, errors.New("cash shift is already closed")
+	}
+
+	movement := &models.CashMovement{
+		ShiftID:       shift.ID,
+		MovementType:  req.MovementType,
+		Amount:        req.Amount,
+		Reason:        req.Reason,
+		CreatedAt:     time.Now(),
+		CreatedBy:     &userID,
+		CreatedByName: &userName,
+	}
+
+	if err := s.cashShiftRepo.CreateMovement(movement); err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+// CloseShift closes an open shift with the cash counted in the drawer and records the
+// difference to the cash expected from the float, cash payments and cash movements
+func (s *CashShiftService) CloseShift(shiftID int, req *models.CloseCashShiftRequest, userID int, userName string, isManager bool) (*models.CashShift, error) {
+	shift, err := s.getAccessibleShift(shiftID, userID, isManager)
+	if err != nil {
+		return nil, err
+	}
+
+	if shift.Status != models.CashShiftStatusOpen {
+		return nil, errors.New("cash shift is already closed")
+	}
+
+	now := time.Now()
+	expected, err := s.expectedCash(shift, now)
+	if err != nil {
+		return nil, err
+	}
+
+	counted := roundAmount(*req.CountedCash)
+	difference := roundAmount(counted - expected)
+
+	shift.ExpectedCash = &expected
+	shift.CountedCash = &counted
+	shift.CashDifference = &difference
+	shift.ClosingNotes = req.Notes
+	shift.ClosedAt = &now
+	shift.ClosedBy = &userID
+	shift.ClosedByName = &userName
+
+	if err := s.cashShiftRepo.Close(shift); err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf("Cash shift closed: expected %.0f, counted %.0f, difference %.0f", expected, counted, difference)
+	s.logShift(shift, "updated", summary, userID, userName)
+
+	return s.GetShift(shift.ID)
+}
+
+// GetShiftBranchID gets the branch of a shift, 0 when it does not exist
+func (s *CashShiftService) GetShiftBranchID(id int) (int, error) {
+	return s.cashShiftRepo.GetBranchID(id)
+}
+
+// GetShifts gets shifts with pagination, filtered by branch, cashier and status
+func (s *CashShiftService) GetShifts(page, limit, branchID, cashierID int, status string) (*models.CashShiftListResponse, error) {
+	offset := (page - 1) * limit
+
+	shifts, err := s.cashShiftRepo.GetAll(limit, offset, branchID, cashierID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.cashShiftRepo.Count(branchID, cashierID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CashShiftListResponse{
+		Shifts: shifts,
+		Total:  total,
+		Page:   page,
+		Limit:  limit,
+	}, nil
+}
+
+// GetShift gets a shift with its cash movements
+func (s *CashShiftService) GetShift(id int) (*models.CashShift, error) {
+	shift, err := s.cashShiftRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if shift == nil {
+		return nil, errors.New("cash shift not found")
+	}
+
+	movements, err := s.cashShiftRepo.GetMovements(id)
+	if err != nil {
+		return nil, err
+	}
+	shift.Movements = movements
+
+	return shift, nil
+}
+
+// GetShiftZReport gets the Z report of one shift. The report of an open shift covers the
+// shift up to now.
+func (s *CashShiftService) GetShiftZReport(shiftID int, userID int, isManager bool) (*models.ZReport, error) {
+	shift, err := s.getAccessibleShift(shiftID, userID, isManager)
+	if err != nil {
+		return nil, err
+	}
+
+	to := time.Now()
+	if shift.ClosedAt != nil {
+		to = *shift.ClosedAt
+	}
+
+	report, err := s.buildReport(fmt.Sprintf("Báo cáo ca #%d - %s", shift.ID, shift.CashierName), shift.CashierID, 0, shift.OpenedAt, to, []*models.CashShift{shift})
+	if err != nil {
+		return nil, err
+	}
+
+	report.BranchID = shift.BranchID
+	return report, nil
+}
+
+// GetDailyZReport gets the end-of-day Z report of the shifts opened on the given day, of one branch
+// when branchID > 0
+func (s *CashShiftService) GetDailyZReport(date time.Time, branchID int) (*models.ZReport, error) {
+	from := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 1)
+
+	shifts, err := s.cashShiftRepo.GetOpenedBetween(from, to, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, shift := range shifts {
+		movements, err := s.cashShiftRepo.GetMovements(shift.ID)
+		if err != nil {
+			return nil, err
+		}
+		shift.Movements = movements
+	}
+
+	report, err := s.buildReport(fmt.Sprintf("Báo cáo cuối ngày %s", from.Format("02/01/2006")), 0, branchID, from, to, shifts)
+	if err != nil {
+		return nil, err
+	}
+
+	report.BranchID = branchID
+	return report, nil
+}
+
+// Helper methods
+
+// getAccessibleShift gets a shift that belongs to the user, or any shift for a manager
+func (s *CashShiftService) getAccessibleShift(shiftID, userID int, isManager bool) (*models.CashShift, error) {
+	shift, err := s.GetShift(shiftID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isManager && shift.CashierID != userID {
+		return nil, errors.New("insufficient permissions")
+	}
+
+	return shift, nil
+}
+
+// expectedCash is the opening float plus payments in cash methods taken by the cashier during the
+// shift plus cash in minus cash out
+func (s *CashShiftService) expectedCash(shift *models.CashShift, to time.Time) (float64, error) {
+	cashPayments, err := s.cashPayments(shift.CashierID, shift.OpenedAt, to)
+	if err != nil {
+		return 0, err
+	}
+
+	cashIn, cashOut := movementTotals(shift.Movements)
+	return roundAmount(shift.OpeningFloat + cashPayments + cashIn - cashOut), nil
+}
+
+func (s *CashShiftService) cashPayments(cashierID int, from, to time.Time) (float64, error) {
+	totals, err := s.cashShiftRepo.GetPaymentTotals(cashierID, 0, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	cash := 0.0
+	for _, total := range totals {
+		if total.IsCash {
+			cash += total.Amount
+		}
+	}
+	return cash, nil
+}
+
+// buildReport sums sales and payments in [from, to), of one cashier when cashierID > 0 and of
+// one branch when branchID > 0, and the cash drawer figures of the given shifts
+func (s *CashShiftService) buildReport(title string, cashierID, branchID int, from, to time.Time, shifts []*models.CashShift) (*models.ZReport, error) {
+	sales, err := s.cashShiftRepo.GetSalesTotals(cashierID, branchID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.cashShiftRepo.GetPaymentTotals(cashierID, branchID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ZReport{
+		Title:            title,
+		From:             from,
+		To:               to,
+		Shifts:           shifts,
+		Sales:            sales,
+		PaymentsByMethod: payments,
+		GeneratedAt:      time.Now(),
+	}
+	if report.Shifts == nil {
+		report.Shifts = []*models.CashShift{}
+	}
+	if report.PaymentsByMethod == nil {
+		report.PaymentsByMethod = []*models.PaymentMethodTotal{}
+	}
+
+	for _, payment := range payments {
+		if payment.IsCash {
+			report.CashPayments += payment.Amount
+		}
+	}
+
+	allClosed := len(shifts) > 0
+	counted := 0.0
+	for _, shift := range shifts {
+		report.OpeningFloat += shift.OpeningFloat
+
+		cashIn, cashOut := movementTotals(shift.Movements)
+		report.CashIn += cashIn
+		report.CashOut += cashOut
+
+		if shift.CountedCash == nil {
+			allClosed = false
+		} else {
+			counted += *shift.CountedCash
+		}
+	}
+
+	report.ExpectedCash = roundAmount(report.OpeningFloat + report.CashPayments + report.CashIn - report.CashOut)
+
+	// The drawer is only counted at close, so the difference is known once every shift is closed
+	if allClosed {
+		counted = roundAmount(counted)
+		difference := roundAmount(counted - report.ExpectedCash)
+		report.CountedCash = &counted
+		report.CashDifference = &difference
+	}
+
+	return report, nil
+}
+
+func movementTotals(movements []*models.CashMovement) (float64, float64) {
+	cashIn, cashOut := 0.0, 0.0
+	for _, movement := range movements {
+		switch movement.MovementType {
+		case models.CashMovementIn:
+			cashIn += movement.Amount
+		case models.CashMovementOut:
+			cashOut += movement.Amount
+		}
+	}
+	return cashIn, cashOut
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func (s *CashShiftService) logShift(shift *models.CashShift, action, summary string, userID int, userName string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	req := models.AuditLogCreateRequest{
+		EntityType: "cash_shift",
+		EntityID:   shift.ID,
+		Action:     action,
+		UserID:     &userID,
+		UserName:   &userName,
+		NewData: map[string]interface{}{
+			"cashier_id":      shift.CashierID,
+			"branch_id":       shift.BranchID,
+			"status":          shift.Status,
+			"opening_float":   shift.OpeningFloat,
+			"expected_cash":   shift.ExpectedCash,
+			"counted_cash":    shift.CountedCash,
+			"cash_difference": shift.CashDifference,
+		},
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the shift operation
+		log.Printf("Failed to create audit log for cash shift %d: %v", shift.ID, err)
+	}
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/repository"
+)
+
+type CustomerDepositService struct {
+	depositRepo          *repository.CustomerDepositRepository
+	customerService      *CustomerService
+	paymentMethodService *PaymentMethodService
+	auditLogService      AuditLogService
+}
+
+func NewCustomerDepositService(depositRepo *repository.CustomerDepositRepository, customerService *CustomerService, paymentMethodService *PaymentMethodService, auditLogService AuditLogService) *CustomerDepositService {
+	return &CustomerDepositService{
+		depositRepo:          depositRepo,
+		customerService:      customerService,
+		paymentMethodService: paymentMethodService,
+		auditLogService:      auditLogService,
+	}
+}
+
+// ReceiveDeposit records money a customer paid in advance of future invoices
+func (s *CustomerDepositService) ReceiveDeposit(customerID int, req *models.CreateCustomerDepositRequest, createdBy int, createdByName string) (*models.CustomerDeposit, error) {
+	if _, err := s.customerService.GetCustomerByID(customerID); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateMethod(req.PaymentMethod, req.TransactionReference); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deposit := &models.CustomerDeposit{
+		CustomerID:           customerID,
+		Amount:               req.Amount,
+		RemainingAmount:      req.Amount,
+		PaymentMethod:        req.PaymentMethod,
+		TransactionReference: req.TransactionReference,
+		Notes:                req.Notes,
+		CreatedBy:            &createdBy,
+		CreatedByName:        &createdByName,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	if err := s.depositRepo.Create(deposit); err != nil {
+		return nil, err
+	}
+
+	s.logDeposit(deposit.ID, "created", fmt.Sprintf("Received deposit of %.0f", deposit.Amount), depositData(deposit), createdBy, createdByName)
+
+	return deposit, nil
+}
+
+// GetDeposits gets the deposits of a customer with their movements and the balance left
+func (s *CustomerDepositService) GetDeposits(customerID int) (*models.CustomerDepositBalance, error) {
+	if _, err := s.customerService.GetCustomerByID(customerID); err != nil {
+		return nil, err
+	}
+
+	deposits, err := s.depositRepo.GetByCustomer(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &models.CustomerDepositBalance{
+		CustomerID: customerID,
+		Deposits:   deposits,
+	}
+	if balance.Deposits == nil {
+		balance.Deposits = []*models.CustomerDeposit{}
+	}
+
+	for _, deposit := range deposits {
+		deposit.Movements, err = s.depositRepo.GetMovements(deposit.ID)
+		if err != nil {
+			return nil, err
+		}
+		balance.Balance += deposit.RemainingAmount
+	}
+
+	return balance, nil
+}
+
+func (s *CustomerDepositService) GetDeposit(id int) (*models.CustomerDeposit, error) {
+	deposit, err := s.depositRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if deposit == nil {
+		return nil, errors.New("deposit not found")
+	}
+
+	deposit.Movements, err = s.depositRepo.GetMovements(deposit.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return deposit, nil
+}
+
+// ApplyDeposits pays an invoice of the customer from the customer's deposits, the amount
+// due when no amount is given
+func (s *CustomerDepositService) ApplyDeposits(customerID int, req *models.ApplyCustomerDepositRequest, createdBy int, createdByName string) ([]*models.CustomerDepositMovement, error) {
+	balance, err := s.depositRepo.GetBalance(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if balance <= 0 {
+		return nil, errors.New("customer has no deposit balance")
+	}
+
+	maxAmount := 0.0
+	if req.Amount != nil {
+		maxAmount = *req.Amount
+	}
+
+	movements, err := s.depositRepo.Apply(customerID, req.InvoiceID, maxAmount, createdBy, createdByName)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logApplied(movements, createdBy, createdByName)
+
+	return movements, nil
+}
+
+// ApplyAvailableDeposits pays what is due on a new invoice from the customer's deposits, if any
+func (s *CustomerDepositService) ApplyAvailableDeposits(customerID, invoiceID int, createdBy int, createdByName string) ([]*models.CustomerDepositMovement, error) {
+	balance, err := s.depositRepo.GetBalance(customerID)
+	if err != nil || balance <= 0 {
+		return nil, err
+	}
+
+	movements, err := s.depositRepo.Apply(customerID, invoiceID, 0, createdBy, createdByName)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logApplied(movements, createdBy, createdByName)
+
+	return movements, nil
+}
+
+// RefundDeposit pays back part or all of what is left of a deposit
+func (s *CustomerDepositService) RefundDeposit(depositID int, req *models.RefundCustomerDepositRequest, createdBy int, createdByName string) (*models.CustomerDepositMovement, error) {
+	deposit, err := s.depositRepo.GetByID(depositID)
+	if err != nil {
+		return nil, err
+	}
+
+	if deposit == nil {
+		return nil, errors.New("deposit not found")
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errors.New("refund reason is required")
+	}
+
+	amount := deposit.RemainingAmount
+	if req.Amount != nil {
+		amount = math.Round(*req.Amount*100) / 100
+	}
+
+	if amount <= 0 {
+		return nil, errors.New("deposit has nothing left to refund")
+	}
+
+	if amount > deposit.RemainingAmount {
+		return nil, fmt.Errorf("refund exceeds the remaining deposit of %.0f", deposit.RemainingAmount)
+	}
+
+	if err := s.validateMethod(req.PaymentMethod, req.TransactionReference); err != nil {
+		return nil, err
+	}
+
+	movement := &models.CustomerDepositMovement{
+		DepositID:            deposit.ID,
+		MovementType:         models.DepositMovementRefunded,
+		Amount:               amount,
+		PaymentMethod:        &req.PaymentMethod,
+		TransactionReference: req.TransactionReference,
+		Reason:               &reason,
+		CreatedAt:            time.Now(),
+		CreatedBy:            &createdBy,
+		CreatedByName:        &createdByName,
+	}
+
+	if err := s.depositRepo.Refund(movement); err != nil {
+		return nil, err
+	}
+
+	deposit.RemainingAmount = roundAmount(deposit.RemainingAmount - amount)
+	s.logDeposit(deposit.ID, "updated", fmt.Sprintf("Refunded %.0f: %s", amount, reason), depositData(deposit), createdBy, createdByName)
+
+	return movement, nil
+}
+
+// GetCustomerLedger gets the invoices, payments and deposits of a customer with running balances
+func (s *CustomerDepositService) GetCustomerLedger(customerID int) (*models.CustomerLedger, error) {
+	customer, err := s.customerService.GetCustomerByID(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.depositRepo.GetLedgerEntries(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := &models.CustomerLedger{
+		CustomerID:   customer.ID,
+		CustomerName: customer.Name,
+		Entries:      entries,
+	}
+	if ledger.Entries == nil {
+		ledger.Entries = []*models.CustomerLedgerEntry{}
+	}
+
+	addLedgerBalances(ledger)
+
+	return ledger, nil
+}
+
+// Helper methods
+
+// addLedgerBalances sets the totals of a ledger and the running balances of its entries. The
+// balance is what the customer owes; the deposit balance is the deposit money not yet used.
+func addLedgerBalances(ledger *models.CustomerLedger) {
+	for _, entry := range ledger.Entries {
+		ledger.TotalDebit += entry.Debit
+		ledger.TotalCredit += entry.Credit
+		ledger.Balance = roundAmount(ledger.Balance + entry.Debit - entry.Credit)
+
+		switch entry.EntryType {
+		case "deposit", "deposit_released":
+			ledger.DepositBalance += entry.Credit
+		case "deposit_applied", "deposit_refunded":
+			ledger.DepositBalance -= entry.Debit
+		}
+		ledger.DepositBalance = roundAmount(ledger.DepositBalance)
+
+		entry.Balance = ledger.Balance
+		entry.DepositBalance = ledger.DepositBalance
+	}
+}
+
+// validateMethod checks the payment method money is received or paid back with. Deposits are
+// real money, so neither customer credit nor deposits themselves are accepted.
+func (s *CustomerDepositService) validateMethod(code string, transactionReference *string) error {
+	if code == models.PaymentMethodCredit || code == models.PaymentMethodDeposit {
+		return fmt.Errorf("payment method %s cannot be used for deposits", code)
+	}
+
+	if s.paymentMethodService == nil {
+		return nil
+	}
+
+	_, err := s.paymentMethodService.ValidatePayment(code, transactionReference)
+	return err
+}
+
+func (s *CustomerDepositService) logApplied(movements []*models.CustomerDepositMovement, userID int, userName string) {
+	for _, movement := range movements {
+		summary := fmt.Sprintf("Applied %.0f to invoice #%d", movement.Amount, *movement.InvoiceID)
+		newData := map[string]interface{}{
+			"invoice_id":         movement.InvoiceID,
+			"invoice_payment_id": movement.InvoicePaymentID,
+			"amount":             movement.Amount,
+		}
+		s.logDeposit(movement.DepositID, "updated", summary, newData, userID, userName)
+	}
+}
+
+func (s *CustomerDepositService) logDeposit(depositID int, action, summary string, newData map[string]interface{}, userID int, userName string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	req := models.AuditLogCreateRequest{
+		EntityType:     "customer_deposit",
+		EntityID:       depositID,
+		Action:         action,
+		UserID:         &userID,
+		UserName:       &userName,
+		NewData:        newData,
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the deposit operation
+		log.Printf("Failed to create audit log for customer deposit %d: %v", depositID, err)
+	}
+}
+
+func depositData(deposit *models.CustomerDeposit) map[string]interface{} {
+	return map[string]interface{}{
+		"customer_id":      deposit.CustomerID,
+		"amount":           deposit.Amount,
+		"remaining_amount": deposit.RemainingAmount,
+		"payment_method":   deposit.PaymentMethod,
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+
+	"steel-pos-backend/internal/models"
+)
+
+func TestAddLedgerBalancesDepositApplyAndRelease(t *testing.T) {
+	entry := func(entryType string, debit, credit float64) *models.CustomerLedgerEntry {
+		return &models.CustomerLedgerEntry{EntryType: entryType, Debit: debit, Credit: credit}
+	}
+
+	// A deposit of 5M pays 3M of an invoice, and is given back when the invoice is cancelled.
+	// The cancelled invoice no longer appears; its deposit movements do.
+	ledger := &models.CustomerLedger{
+		Entries: []*models.CustomerLedgerEntry{
+			entry("deposit", 0, 5000000),
+			entry("deposit_applied", 3000000, 3000000),
+			entry("deposit_released", 3000000, 3000000),
+			entry("invoice", 2000000, 0),
+			entry("deposit_applied", 2000000, 2000000),
+			entry("deposit_refunded", 1000000, 0),
+		},
+	}
+
+	addLedgerBalances(ledger)
+
+	want := []struct {
+		balance        float64
+		depositBalance float64
+	}{
+		{-5000000, 5000000},
+		{-5000000, 2000000}, // applying deposits moves money, it does not change what is owed
+		{-5000000, 5000000},
+		{-3000000, 5000000},
+		{-3000000, 3000000},
+		{-2000000, 2000000},
+	}
+
+	for i, entry := range ledger.Entries {
+		if entry.Balance != want[i].balance || entry.DepositBalance != want[i].depositBalance {
+			t.Fatalf("entry %d (%s): balance %v deposit balance %v, want %v and %v",
+				i, entry.EntryType, entry.Balance, entry.DepositBalance, want[i].balance, want[i].depositBalance)
+		}
+	}
+
+	if ledger.Balance != -2000000 || ledger.DepositBalance != 2000000 {
+		t.Fatalf("ledger balance %v deposit balance %v, want -2000000 and 2000000", ledger.Balance, ledger.DepositBalance)
+	}
+	if ledger.TotalDebit != 11000000 || ledger.TotalCredit != 13000000 {
+		t.Fatalf("totals %v and %v, want 11000000 and 13000000", ledger.TotalDebit, ledger.TotalCredit)
+	}
+}
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"steel-pos-backend/internal/config"
+	"steel-pos-backend/internal/einvoice"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/repository"
+)
+
+type EInvoiceService struct {
+	einvoiceRepo         *repository.EInvoiceRepository
+	invoiceService       *InvoiceService
+	customerService      *CustomerService
+	storeSettingsService *StoreSettingsService
+	provider             einvoice.Provider
+	auditLogService      AuditLogService
+	cfg                  config.EInvoiceConfig
+}
+
+func NewEInvoiceService(einvoiceRepo *repository.EInvoiceRepository, invoiceService *InvoiceService, customerService *CustomerService, storeSettingsService *StoreSettingsService, provider einvoice.Provider, auditLogService AuditLogService, cfg config.EInvoiceConfig) *EInvoiceService {
+	return &EInvoiceService{
+		einvoiceRepo:         einvoiceRepo,
+		invoiceService:       invoiceService,
+		customerService:      customerService,
+		storeSettingsService: storeSettingsService,
+		provider:             provider,
+		auditLogService:      auditLogService,
+		cfg:                  cfg,
+	}
+}
+
+// GenerateEInvoice renders a confirmed invoice into e-invoice XML and validates it against
+// the schema. An e-invoice that was not submitted yet is rendered again, keeping its number.
+func (s *EInvoiceService) GenerateEInvoice(invoiceID int, req *models.GenerateEInvoiceRequest, createdBy int, createdByName string) (*models.EInvoice, error) {
+	seller, err := s.seller()
+	if err != nil {
+		return nil, err
+	}
+
+	if seller.TaxCode == "" {
+		return nil, errors.New("seller tax code is not configured")
+	}
+
+	invoice, err := s.invoiceService.GetInvoiceByID(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invoice == nil {
+		return nil, errors.New("invoice not found")
+	}
+
+	if invoice.Status != "confirmed" {
+		return nil, errors.New("only confirmed invoices can be issued as e-invoices")
+	}
+
+	if !hasLineTaxes(invoice) {
+		return nil, errors.New("invoice has no per-line VAT breakdown, update it to recalculate its taxes")
+	}
+
+	existing, err := s.einvoiceRepo.GetByInvoiceID(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil && existing.Status == models.EInvoiceStatusSubmitted {
+		return nil, errors.New("e-invoice has already been submitted")
+	}
+
+	buyerTaxCode, err := s.buyerTaxCode(invoice, req.BuyerTaxCode)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := existing
+	if record == nil {
+		record = &models.EInvoice{
+			InvoiceID:     invoiceID,
+			TemplateCode:  s.cfg.TemplateCode,
+			Series:        s.cfg.Series,
+			CreatedBy:     &createdBy,
+			CreatedByName: &createdByName,
+			CreatedAt:     now,
+		}
+	}
+
+	record.IssueDate = now
+	record.BuyerTaxCode = buyerTaxCode
+	record.Status = models.EInvoiceStatusValidated
+	record.UpdatedAt = now
+
+	if existing == nil {
+		// The number is taken in the transaction storing the e-invoice, once it has validated
+		err = s.einvoiceRepo.Create(record, invoice.UpdatedAt, func(number int) (string, error) {
+			record.InvoiceNumber = number
+			xmlContent, err := s.render(invoice, record, seller)
+			return string(xmlContent), err
+		})
+	} else {
+		var xmlContent []byte
+		xmlContent, err = s.render(invoice, record, seller)
+		if err == nil {
+			record.XMLContent = string(xmlContent)
+			err = s.einvoiceRepo.UpdateContent(record)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logEInvoiceChange(record, fmt.Sprintf("E-invoice %s/%d issued for invoice %s", record.Series, record.InvoiceNumber, invoice.InvoiceCode), createdBy, createdByName)
+
+	return record, nil
+}
+
+// SubmitEInvoice sends a validated e-invoice through the configured provider
+func (s *EInvoiceService) SubmitEInvoice(id int, submittedBy int, submittedByName string) (*models.EInvoice, error) {
+	record, err := s.einvoiceRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if record == nil {
+		return nil, errors.New("e-invoice not found")
+	}
+
+	if record.Status == models.EInvoiceStatusSubmitted {
+		return nil, errors.New("e-invoice has already been submitted")
+	}
+
+	invoice, err := s.invoiceService.GetInvoiceByID(record.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invoice == nil || invoice.Status != "confirmed" {
+		return nil, errors.New("only confirmed invoices can be issued as e-invoices")
+	}
+
+	seller, err := s.seller()
+	if err != nil {
+		return nil, err
+	}
+
+	// Never submit a document that does not match the schema or no longer matches its invoice
+	xmlContent, err := s.render(invoice, record, seller)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(xmlContent) != record.XMLContent {
+		return nil, errors.New("e-invoice no longer matches its invoice, generate it again")
+	}
+
+	providerName := s.provider.Name()
+	record.Provider = &providerName
+	record.UpdatedAt = time.Now()
+
+	result, submitErr := s.provider.Submit(&einvoice.Submission{
+		InvoiceCode:  invoice.InvoiceCode,
+		TemplateCode: record.TemplateCode,
+		Series:       record.Series,
+		Number:       record.InvoiceNumber,
+		XML:          []byte(record.XMLContent),
+	})
+
+	if submitErr != nil {
+		message := submitErr.Error()
+		record.Status = models.EInvoiceStatusFailed
+		record.ErrorMessage = &message
+	} else {
+		submittedAt := time.Now()
+		record.Status = models.EInvoiceStatusSubmitted
+		record.ProviderReference = &result.Reference
+		record.LookupCode = &result.LookupCode
+		record.ErrorMessage = nil
+		record.SubmittedAt = &submittedAt
+	}
+
+	if err := s.einvoiceRepo.UpdateSubmission(record); err != nil {
+		return nil, err
+	}
+
+	if submitErr != nil {
+		return nil, fmt.Errorf("e-invoice submission failed: %w", submitErr)
+	}
+
+	s.logEInvoiceChange(record, fmt.Sprintf("E-invoice %s/%d submitted via %s", record.Series, record.InvoiceNumber, providerName), submittedBy, submittedByName)
+
+	return record, nil
+}
+
+func (s *EInvoiceService) GetEInvoiceByID(id int) (*models.EInvoice, error) {
+	record, err := s.einvoiceRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if record == nil {
+		return nil, errors.New("e-invoice not found")
+	}
+
+	return record, nil
+}
+
+func (s *EInvoiceService) GetEInvoiceByInvoiceID(invoiceID int) (*models.EInvoice, error) {
+	record, err := s.einvoiceRepo.GetByInvoiceID(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if record == nil {
+		return nil, errors.New("e-invoice not found")
+	}
+
+	return record, nil
+}
+
+// Helper methods
+
+// buyerTaxCode returns the requested buyer tax code, or the tax code of the invoice's customer
+func (s *EInvoiceService) buyerTaxCode(invoice *models.Invoice, requested *string) (*string, error) {
+	if requested != nil {
+		taxCode := strings.TrimSpace(*requested)
+		if taxCode == "" {
+			return nil, nil
+		}
+		return &taxCode, nil
+	}
+
+	if invoice.CustomerID == nil {
+		return nil, nil
+	}
+
+	customer, err := s.customerService.GetCustomerByID(*invoice.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if customer == nil || customer.TaxCode == nil || *customer.TaxCode == "" {
+		return nil, nil
+	}
+
+	return customer.TaxCode, nil
+}
+
+// seller gets the seller printed on e-invoices from the store settings
+func (s *EInvoiceService) seller() (einvoice.Party, error) {
+	settings, err := s.storeSettingsService.GetSettings()
+	if err != nil {
+		return einvoice.Party{}, err
+	}
+
+	return sellerParty(settings, s.cfg), nil
+}
+
+// render builds the XML of an e-invoice from its invoice and validates it against the schema
+func (s *EInvoiceService) render(invoice *models.Invoice, record *models.EInvoice, seller einvoice.Party) ([]byte, error) {
+	buyer := einvoice.Party{
+		Name:    invoice.CustomerName,
+		Address: stringValue(invoice.CustomerAddress),
+		Phone:   invoice.CustomerPhone,
+	}
+	if record.BuyerTaxCode != nil {
+		buyer.TaxCode = *record.BuyerTaxCode
+	}
+
+	xmlContent, err := einvoice.Build(invoice, einvoice.Header{
+		TemplateCode: record.TemplateCode,
+		Series:       record.Series,
+		Number:       record.InvoiceNumber,
+		IssueDate:    record.IssueDate,
+		Seller:       seller,
+		Buyer:        buyer,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := einvoice.Validate(xmlContent); err != nil {
+		return nil, err
+	}
+
+	return xmlContent, nil
+}
+
+// logEInvoiceChange records e-invoice changes in the audit log of the invoice
+func (s *EInvoiceService) logEInvoiceChange(record *models.EInvoice, summary string, userID int, userName string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	req := models.AuditLogCreateRequest{
+		EntityType: "invoice",
+		EntityID:   record.InvoiceID,
+		Action:     "updated",
+		UserID:     &userID,
+		UserName:   &userName,
+		NewData: map[string]interface{}{
+			"einvoice_id":    record.ID,
+			"template_code":  record.TemplateCode,
+			"series":         record.Series,
+			"invoice_number": record.InvoiceNumber,
+			"status":         record.Status,
+			"lookup_code":    record.LookupCode,
+		},
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the e-invoice change
+		log.Printf("Failed to create audit log for e-invoice %d: %v", record.ID, err)
+	}
+}
+
+// hasLineTaxes reports whether the invoice's VAT is broken down on its items. Invoices
+// created before per-line tax only carry an invoice-level tax amount.
+func hasLineTaxes(invoice *models.Invoice) bool {
+	lineTax := 0.0
+	for _, item := range invoice.Items {
+		lineTax += item.ChargedTax()
+	}
+	return math.Abs(lineTax-invoice.TaxAmount) < 1
+}
+
+// sellerParty takes the seller from the store settings, so e-invoices name the store printed on
+// its invoices. A field left empty in the settings falls back to the configured one.
+func sellerParty(settings *models.StoreSettings, cfg config.EInvoiceConfig) einvoice.Party {
+	seller := einvoice.Party{
+		Name:    cfg.SellerName,
+		TaxCode: cfg.SellerTaxCode,
+		Address: cfg.SellerAddress,
+		Phone:   cfg.SellerPhone,
+	}
+	if settings.Name != "" {
+		seller.Name = settings.Name
+	}
+	if taxCode := stringValue(settings.TaxCode); taxCode != "" {
+		seller.TaxCode = taxCode
+	}
+	if address := stringValue(settings.Address); address != "" {
+		seller.Address = address
+	}
+	if len(settings.Phones) > 0 {
+		seller.Phone = settings.Phones[0]
+	}
+	return seller
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
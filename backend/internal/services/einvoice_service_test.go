@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+
+	"steel-pos-backend/internal/config"
+	"steel-pos-backend/internal/einvoice"
+	"steel-pos-backend/internal/models"
+)
+
+func TestSellerParty(t *testing.T) {
+	cfg := config.EInvoiceConfig{
+		SellerName:    "ĐẠI LÝ SẮT THÉP KIÊN PHƯỚC",
+		SellerTaxCode: "3001234567",
+		SellerAddress: "Trường Sơn Đức Thọ Hà Tĩnh",
+		SellerPhone:   "0972851015",
+	}
+
+	tests := []struct {
+		name     string
+		settings *models.StoreSettings
+		want     einvoice.Party
+	}{
+		{
+			name: "store settings",
+			settings: &models.StoreSettings{
+				Name:    "Công ty TNHH Thép Minh Phát",
+				TaxCode: stringPtr("0312345678"),
+				Address: stringPtr("12 Quốc lộ 1A, Bình Chánh, TP.HCM"),
+				Phones:  []string{"0281234567", "0909123456"},
+			},
+			want: einvoice.Party{
+				Name:    "Công ty TNHH Thép Minh Phát",
+				TaxCode: "0312345678",
+				Address: "12 Quốc lộ 1A, Bình Chánh, TP.HCM",
+				Phone:   "0281234567",
+			},
+		},
+		{
+			name:     "configured fields fill in the blanks",
+			settings: &models.StoreSettings{Name: "Công ty TNHH Thép Minh Phát", TaxCode: stringPtr("")},
+			want: einvoice.Party{
+				Name:    "Công ty TNHH Thép Minh Phát",
+				TaxCode: "3001234567",
+				Address: "Trường Sơn Đức Thọ Hà Tĩnh",
+				Phone:   "0972851015",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sellerParty(tt.settings, cfg); got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
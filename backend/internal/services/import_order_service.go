@@ -19,8 +19,12 @@ func NewImportOrderService(importOrderRepo *repository.ImportOrderRepository) *I
 	}
 }
 
-// CreateImportOrder creates a new import order
-func (s *ImportOrderService) CreateImportOrder(req *models.CreateImportOrderRequest, userID int) (*models.ImportOrder, error) {
+// CreateImportOrder creates a new import order for stock of a branch
+func (s *ImportOrderService) CreateImportOrder(req *models.CreateImportOrderRequest, userID int, branchID int) (*models.ImportOrder, error) {
+	if branchID == 0 {
+		return nil, errors.New("import order must be created at a branch")
+	}
+
 	// Generate import code
 	importCode, err := s.generateImportCode()
 	if err != nil {
@@ -36,6 +40,7 @@ func (s *ImportOrderService) CreateImportOrder(req *models.CreateImportOrderRequ
 		Status:       "pending",
 		Notes:        req.Notes,
 		ImportImages: req.ImportImages,
+		BranchID:     branchID,
 		CreatedBy:    &userID,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
@@ -105,19 +110,24 @@ func (s *ImportOrderService) GetImportOrderByID(id int) (*models.ImportOrder, er
 	return importOrder, nil
 }
 
-// GetAllImportOrders gets all import orders with pagination and filters
-func (s *ImportOrderService) GetAllImportOrders(page, limit int, status, supplierName, search string) (*models.ImportOrderListResponse, error) {
+// GetImportOrderBranchID gets the branch of an import order, 0 when it does not exist
+func (s *ImportOrderService) GetImportOrderBranchID(id int) (int, error) {
+	return s.importOrderRepo.GetBranchID(id)
+}
+
+// GetAllImportOrders gets all import orders with pagination and filters, of one branch when branchID > 0
+func (s *ImportOrderService) GetAllImportOrders(page, limit, branchID int, status, supplierName, search string) (*models.ImportOrderListResponse, error) {
 	// Calculate offset
 	offset := (page - 1) * limit
 
 	// Get orders
-	orders, err := s.importOrderRepo.GetAll(limit, offset, status)
+	orders, err := s.importOrderRepo.GetAll(limit, offset, branchID, status)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get total count
-	total, err := s.importOrderRepo.Count(status)
+	total, err := s.importOrderRepo.Count(branchID, status)
 	if err != nil {
 		return nil, err
 	}
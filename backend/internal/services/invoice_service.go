@@ -2,33 +2,49 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"math"
 	"steel-pos-backend/internal/models"
 	"steel-pos-backend/internal/repository"
+	"strings"
 	"time"
 )
 
 type InvoiceService struct {
-	invoiceRepo     *repository.InvoiceRepository
-	customerService *CustomerService
-	auditLogService AuditLogService
+	invoiceRepo          *repository.InvoiceRepository
+	customerService      *CustomerService
+	promotionService     *PromotionService
+	taxService           *TaxService
+	paymentMethodService *PaymentMethodService
+	depositService       *CustomerDepositService
+	auditLogService      AuditLogService
 }
 
-func NewInvoiceService(invoiceRepo *repository.InvoiceRepository, customerService *CustomerService, auditLogService AuditLogService) *InvoiceService {
+func NewInvoiceService(invoiceRepo *repository.InvoiceRepository, customerService *CustomerService, promotionService *PromotionService, taxService *TaxService, paymentMethodService *PaymentMethodService, depositService *CustomerDepositService, auditLogService AuditLogService) *InvoiceService {
 	return &InvoiceService{
-		invoiceRepo:     invoiceRepo,
-		customerService: customerService,
-		auditLogService: auditLogService,
+		invoiceRepo:          invoiceRepo,
+		customerService:      customerService,
+		promotionService:     promotionService,
+		taxService:           taxService,
+		paymentMethodService: paymentMethodService,
+		depositService:       depositService,
+		auditLogService:      auditLogService,
 	}
 }
 
 
 // Invoice methods
-func (s *InvoiceService) CreateInvoice(req *models.CreateInvoiceRequest, createdBy int, createdByUsername string) (*models.Invoice, error) {
+func (s *InvoiceService) CreateInvoice(req *models.CreateInvoiceRequest, createdBy int, createdByUsername string, role string, branchID int) (*models.Invoice, error) {
 	// Validate request
 	if len(req.Items) == 0 {
 		return nil, errors.New("invoice must have at least one item")
 	}
 
+	if branchID == 0 {
+		return nil, errors.New("invoice must be created at a branch")
+	}
+
 	// Handle customer - either use existing ID or create/get by phone
 	var customer *models.Customer
 	var err error
@@ -57,10 +73,38 @@ func (s *InvoiceService) CreateInvoice(req *models.CreateInvoiceRequest, created
 		return nil, err
 	}
 
+	// Build invoice items
+	items := make([]*models.InvoiceItem, 0, len(req.Items))
+	for _, itemReq := range req.Items {
+		item := &models.InvoiceItem{
+			ProductID:    itemReq.ProductID,
+			VariantID:    itemReq.VariantID,
+			ProductName:  itemReq.ProductName,
+			VariantName:  itemReq.VariantName,
+			Unit:         itemReq.Unit,
+			Quantity:     itemReq.Quantity,
+			UnitPrice:    itemReq.UnitPrice,
+			TotalPrice:   itemReq.Quantity * itemReq.UnitPrice,
+			ProductNotes: itemReq.ProductNotes,
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		}
+		setItemDiscount(item, itemReq.DiscountAmount, itemReq.DiscountPercentage)
+		items = append(items, item)
+	}
+
+	// Apply promotions
+	if s.promotionService != nil {
+		err = s.promotionService.ApplyPromotions(items, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Calculate totals
-	subtotal := 0.0
-	for _, item := range req.Items {
-		subtotal += item.Quantity * item.UnitPrice
+	subtotal, lineDiscounts, err := calculateItemTotals(items)
+	if err != nil {
+		return nil, err
 	}
 
 	// Apply discount
@@ -71,19 +115,52 @@ func (s *InvoiceService) CreateInvoice(req *models.CreateInvoiceRequest, created
 		discountAmount = subtotal * (*req.DiscountPercentage / 100)
 	}
 
+	// Enforce the manual discount cap of the user's role, line discounts included
+	if s.promotionService != nil {
+		err = s.promotionService.CheckDiscountCap(role, subtotal+lineDiscounts, discountAmount+lineDiscounts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Apply tax
-	taxAmount := 0.0
-	if req.TaxAmount != nil {
-		taxAmount = *req.TaxAmount
-	} else if req.TaxPercentage != nil {
-		taxAmount = (subtotal - discountAmount) * (*req.TaxPercentage / 100)
+	priceMode := models.PriceModeExclusive
+	if req.PriceMode != nil {
+		priceMode = *req.PriceMode
+	}
+
+	taxAmount, err := s.calculateTax(items, priceMode, subtotal, discountAmount, req.TaxAmount, req.TaxPercentage, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	totalAmount := invoiceTotal(priceMode, subtotal, discountAmount, taxAmount)
+
+	// Build the payments made at checkout, either split tender or a single payment
+	tenders := req.Payments
+	if len(tenders) > 0 && (req.PaymentMethod != nil || req.PaidAmount != nil) {
+		return nil, errors.New("use either payments or payment_method and paid_amount")
+	}
+	if len(tenders) == 0 && req.PaymentMethod != nil && req.PaidAmount != nil && *req.PaidAmount > 0 {
+		tenders = []models.CreateInvoiceTenderRequest{{
+			PaymentMethod:        *req.PaymentMethod,
+			Amount:               *req.PaidAmount,
+			TransactionReference: req.TransactionReference,
+		}}
 	}
 
-	totalAmount := subtotal - discountAmount + taxAmount
+	payments, err := s.buildCheckoutPayments(tenders, totalAmount, createdBy)
+	if err != nil {
+		return nil, err
+	}
 
 	// Determine payment status
 	paidAmount := 0.0
-	if req.PaidAmount != nil {
+	for _, payment := range payments {
+		paidAmount += payment.Amount
+	}
+	if len(tenders) == 0 && req.PaidAmount != nil {
+		// Paid amount without a payment method is recorded without a payment
 		paidAmount = *req.PaidAmount
 	}
 
@@ -108,11 +185,13 @@ func (s *InvoiceService) CreateInvoice(req *models.CreateInvoiceRequest, created
 		DiscountPercentage: 0,
 		TaxAmount:          taxAmount,
 		TaxPercentage:      0,
+		PriceMode:          priceMode,
 		TotalAmount:        totalAmount,
 		PaidAmount:         paidAmount,
 		PaymentStatus:      paymentStatus,
 		Status:             "confirmed",
 		Notes:              req.Notes,
+		BranchID:           branchID,
 		CreatedBy:          &createdBy,
 		CreatedByUsername:  &createdByUsername,
 		CreatedAt:          time.Now(),
@@ -129,58 +208,28 @@ func (s *InvoiceService) CreateInvoice(req *models.CreateInvoiceRequest, created
 		invoice.TaxPercentage = *req.TaxPercentage
 	}
 
-	err = s.invoiceRepo.CreateInvoice(invoice)
+	// Create invoice with its items and payments in one transaction
+	err = s.invoiceRepo.CreateInvoiceWithDetails(invoice, items, payments)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create invoice items
-	for _, itemReq := range req.Items {
-		item := &models.InvoiceItem{
-			InvoiceID:    invoice.ID,
-			ProductID:    itemReq.ProductID,
-			VariantID:    itemReq.VariantID,
-			ProductName:  itemReq.ProductName,
-			VariantName:  itemReq.VariantName,
-			Unit:         itemReq.Unit,
-			Quantity:     itemReq.Quantity,
-			UnitPrice:    itemReq.UnitPrice,
-			TotalPrice:   itemReq.Quantity * itemReq.UnitPrice,
-			ProductNotes: itemReq.ProductNotes,
-			CreatedAt:    time.Now(),
-			UpdatedAt:    time.Now(),
-		}
-
-		err = s.invoiceRepo.CreateInvoiceItem(item)
-		if err != nil {
-			return nil, err
-		}
-
-		// Create inventory log for sale
-		if itemReq.VariantID != nil {
-			err = s.createInventoryLogForSale(*itemReq.VariantID, itemReq.Quantity, invoice.ID, createdBy)
+	// Create inventory logs for sale
+	for _, item := range items {
+		if item.VariantID != nil {
+			err = s.createInventoryLogForSale(*item.VariantID, item.Quantity, invoice.ID, createdBy)
 			if err != nil {
 				return nil, err
 			}
 		}
 	}
 
-	// Create payment if paid amount > 0
-	if paidAmount > 0 && req.PaymentMethod != nil {
-		payment := &models.InvoicePayment{
-			InvoiceID:     invoice.ID,
-			Amount:        paidAmount,
-			PaymentMethod: *req.PaymentMethod,
-			PaymentDate:   time.Now(),
-			Status:        "confirmed",
-			CreatedBy:     &createdBy,
-			CreatedAt:     time.Now(),
-			UpdatedAt:     time.Now(),
-		}
-
-		err = s.invoiceRepo.CreateInvoicePayment(payment)
+	// Pay what is still due from the customer's deposits
+	if s.depositService != nil && (req.ApplyDeposits == nil || *req.ApplyDeposits) && paidAmount < totalAmount {
+		_, err = s.depositService.ApplyAvailableDeposits(customer.ID, invoice.ID, createdBy, createdByUsername)
 		if err != nil {
-			return nil, err
+			// The invoice stands; deposits can still be applied manually
+			log.Printf("Failed to apply deposits to invoice %s: %v", invoice.InvoiceCode, err)
 		}
 	}
 
@@ -221,15 +270,26 @@ func (s *InvoiceService) GetInvoiceByCode(code string) (*models.Invoice, error)
 	return s.invoiceRepo.GetInvoiceByCode(code)
 }
 
-func (s *InvoiceService) GetAllInvoices(page, limit int, search string, status string, paymentStatus string) (*models.InvoiceListResponse, error) {
+// GetInvoiceBranchID gets the branch of an invoice, 0 when it does not exist
+func (s *InvoiceService) GetInvoiceBranchID(id int) (int, error) {
+	return s.invoiceRepo.GetInvoiceBranchID(id)
+}
+
+// GetPaymentBranchID gets the branch of the invoice of a payment, 0 when it does not exist
+func (s *InvoiceService) GetPaymentBranchID(paymentID int) (int, error) {
+	return s.invoiceRepo.GetPaymentBranchID(paymentID)
+}
+
+// GetAllInvoices gets invoices with pagination, of one branch when branchID > 0
+func (s *InvoiceService) GetAllInvoices(page, limit, branchID int, search string, status string, paymentStatus string) (*models.InvoiceListResponse, error) {
 	offset := (page - 1) * limit
 
-	invoices, err := s.invoiceRepo.GetAllInvoices(limit, offset, search, status, paymentStatus)
+	invoices, err := s.invoiceRepo.GetAllInvoices(limit, offset, branchID, search, status, paymentStatus)
 	if err != nil {
 		return nil, err
 	}
 
-	total, err := s.invoiceRepo.CountInvoices(search, status, paymentStatus)
+	total, err := s.invoiceRepo.CountInvoices(branchID, search, status, paymentStatus)
 	if err != nil {
 		return nil, err
 	}
@@ -242,7 +302,7 @@ func (s *InvoiceService) GetAllInvoices(page, limit int, search string, status s
 	}, nil
 }
 
-func (s *InvoiceService) UpdateInvoice(id int, req *models.UpdateInvoiceRequest, updatedBy int, updatedByUsername string) (*models.Invoice, error) {
+func (s *InvoiceService) UpdateInvoice(id int, req *models.UpdateInvoiceRequest, updatedBy int, updatedByUsername string, role string) (*models.Invoice, error) {
 	// Get existing invoice
 	oldInvoice, err := s.invoiceRepo.GetInvoiceByID(id)
 	if err != nil {
@@ -253,6 +313,16 @@ func (s *InvoiceService) UpdateInvoice(id int, req *models.UpdateInvoiceRequest,
 		return nil, errors.New("invoice not found")
 	}
 
+	// An issued e-invoice is a legal document that the invoice must keep matching
+	hasEInvoice, err := s.invoiceRepo.HasEInvoice(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasEInvoice {
+		return nil, errors.New("invoice has an e-invoice, correct it with a replacement or adjustment e-invoice")
+	}
+
 	// Create a copy for updating
 	invoice := *oldInvoice
 
@@ -267,8 +337,15 @@ func (s *InvoiceService) UpdateInvoice(id int, req *models.UpdateInvoiceRequest,
 		invoice.CustomerAddress = req.CustomerAddress
 	}
 
-	// Update status if provided
-	if req.Status != nil {
+	// Update status if provided. Cancelling goes through DeleteInvoice, which gives deposit
+	// payments back to the deposits.
+	if req.Status != nil && *req.Status != invoice.Status {
+		if *req.Status == "cancelled" {
+			return nil, errors.New("use DELETE /invoices/:id to cancel an invoice")
+		}
+		if invoice.Status == "cancelled" {
+			return nil, errors.New("a cancelled invoice cannot be reopened")
+		}
 		invoice.Status = *req.Status
 	}
 
@@ -279,19 +356,17 @@ func (s *InvoiceService) UpdateInvoice(id int, req *models.UpdateInvoiceRequest,
 
 	// Update items if provided
 	if len(req.Items) > 0 {
-		// Delete existing items
-		err = s.invoiceRepo.DeleteInvoiceItemsByInvoiceID(id)
-		if err != nil {
-			return nil, err
-		}
-
-		// Create new items
-		subtotal := 0.0
+		// Build new items
+		var items []*models.InvoiceItem
 		for _, itemReq := range req.Items {
 			if itemReq.IsDeleted != nil && *itemReq.IsDeleted {
 				continue // Skip deleted items
 			}
 
+			if itemReq.ProductName == nil || itemReq.VariantName == nil || itemReq.Unit == nil || itemReq.Quantity == nil || itemReq.UnitPrice == nil {
+				return nil, errors.New("invoice item is missing required fields")
+			}
+
 			item := &models.InvoiceItem{
 				InvoiceID:    id,
 				ProductID:    itemReq.ProductID,
@@ -306,16 +381,27 @@ func (s *InvoiceService) UpdateInvoice(id int, req *models.UpdateInvoiceRequest,
 				CreatedAt:    time.Now(),
 				UpdatedAt:    time.Now(),
 			}
+			setItemDiscount(item, itemReq.DiscountAmount, itemReq.DiscountPercentage)
+			items = append(items, item)
+		}
 
-			err = s.invoiceRepo.CreateInvoiceItem(item)
+		if len(items) == 0 {
+			return nil, errors.New("invoice must have at least one item")
+		}
+
+		// Apply promotions running at the time the invoice was created
+		if s.promotionService != nil {
+			err = s.promotionService.ApplyPromotions(items, oldInvoice.CreatedAt)
 			if err != nil {
 				return nil, err
 			}
-
-			subtotal += item.TotalPrice
 		}
 
 		// Recalculate totals
+		subtotal, lineDiscounts, err := calculateItemTotals(items)
+		if err != nil {
+			return nil, err
+		}
 		invoice.Subtotal = subtotal
 
 		// Apply discount
@@ -325,14 +411,53 @@ func (s *InvoiceService) UpdateInvoice(id int, req *models.UpdateInvoiceRequest,
 			invoice.DiscountAmount = subtotal * (*req.DiscountPercentage / 100)
 		}
 
-		// Apply tax
-		if req.TaxAmount != nil {
-			invoice.TaxAmount = *req.TaxAmount
-		} else if req.TaxPercentage != nil {
-			invoice.TaxAmount = (subtotal - invoice.DiscountAmount) * (*req.TaxPercentage / 100)
+		// Enforce the manual discount cap of the user's role, line discounts included
+		if s.promotionService != nil {
+			err = s.promotionService.CheckDiscountCap(role, subtotal+lineDiscounts, invoice.DiscountAmount+lineDiscounts)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Apply tax at the rates in effect when the invoice was created. The pricing mode
+		// only changes together with the items, since it changes every line total.
+		if req.PriceMode != nil {
+			invoice.PriceMode = *req.PriceMode
+		}
+		if req.TaxPercentage != nil {
+			invoice.TaxPercentage = *req.TaxPercentage
+		}
+
+		// Without new tax input, keep the invoice's existing tax percentage or amount
+		taxAmount := req.TaxAmount
+		taxPercentage := req.TaxPercentage
+		if taxAmount == nil && taxPercentage == nil {
+			if invoice.TaxPercentage > 0 {
+				taxPercentage = &invoice.TaxPercentage
+			} else {
+				taxAmount = &oldInvoice.TaxAmount
+			}
+		}
+
+		invoice.TaxAmount, err = s.calculateTax(items, invoice.PriceMode, subtotal, invoice.DiscountAmount, taxAmount, taxPercentage, oldInvoice.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		invoice.TotalAmount = invoiceTotal(invoice.PriceMode, subtotal, invoice.DiscountAmount, invoice.TaxAmount)
+
+		// Replace existing items
+		err = s.invoiceRepo.DeleteInvoiceItemsByInvoiceID(id)
+		if err != nil {
+			return nil, err
 		}
 
-		invoice.TotalAmount = subtotal - invoice.DiscountAmount + invoice.TaxAmount
+		for _, item := range items {
+			err = s.invoiceRepo.CreateInvoiceItem(item)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Update paid amount if provided
@@ -385,8 +510,30 @@ func (s *InvoiceService) UpdateInvoice(id int, req *models.UpdateInvoiceRequest,
 	return updatedInvoice, nil
 }
 
-func (s *InvoiceService) DeleteInvoice(id int) error {
-	return s.invoiceRepo.DeleteInvoice(id)
+// DeleteInvoice cancels an invoice; money paid from customer deposits goes back to the deposits
+func (s *InvoiceService) DeleteInvoice(id int, cancelledBy int, cancelledByUsername string) error {
+	released, err := s.invoiceRepo.DeleteInvoice(id, cancelledBy, cancelledByUsername)
+	if err != nil {
+		return err
+	}
+
+	if s.auditLogService != nil && len(released) > 0 {
+		err := s.auditLogService.LogInvoiceChange(
+			id,
+			"deleted",
+			nil,
+			map[string]interface{}{"released_deposits": released},
+			&cancelledBy,
+			&cancelledByUsername,
+			nil,
+			nil,
+		)
+		if err != nil {
+			log.Printf("Failed to log released deposits: %v", err)
+		}
+	}
+
+	return nil
 }
 
 // InvoicePayment methods
@@ -401,12 +548,21 @@ func (s *InvoiceService) CreateInvoicePayment(invoiceID int, req *models.CreateI
 		return nil, errors.New("invoice not found")
 	}
 
+	// Validate payment method
+	if s.paymentMethodService != nil {
+		_, err = s.paymentMethodService.ValidatePayment(req.PaymentMethod, req.TransactionReference)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Create payment
 	payment := &models.InvoicePayment{
 		InvoiceID:     invoiceID,
 		Amount:        req.Amount,
 		PaymentMethod: req.PaymentMethod,
 		PaymentDate:   time.Now(),
+		PaymentType:   models.PaymentTypePayment,
 		Status:        "confirmed",
 		CreatedBy:     &createdBy,
 		CreatedAt:     time.Now(),
@@ -448,111 +604,355 @@ func (s *InvoiceService) CreateInvoicePayment(invoiceID int, req *models.CreateI
 	return payment, nil
 }
 
-func (s *InvoiceService) UpdateInvoicePayment(paymentID int, req *models.UpdateInvoicePaymentRequest, updatedBy int) (*models.InvoicePayment, error) {
-	// Get existing payment
-	payments, err := s.invoiceRepo.GetInvoicePaymentsByInvoiceID(0) // We need to find by payment ID
+// CorrectInvoicePayment corrects a payment by reversing it and recording a replacement
+// payment with the corrected values. The original payment is kept and marked as corrected.
+func (s *InvoiceService) CorrectInvoicePayment(paymentID int, req *models.UpdateInvoicePaymentRequest, correctedBy int, correctedByUsername string) (*models.PaymentCorrectionResult, error) {
+	original, err := s.getCorrectablePayment(paymentID)
 	if err != nil {
 		return nil, err
 	}
 
-	var existingPayment *models.InvoicePayment
-	for _, payment := range payments {
-		if payment.ID == paymentID {
-			existingPayment = payment
-			break
-		}
-	}
-
-	if existingPayment == nil {
-		return nil, errors.New("payment not found")
+	if original.PaymentMethod == models.PaymentMethodDeposit {
+		return nil, errors.New("payments from customer deposits can only be reversed")
 	}
 
-	// Store original amount for correction tracking
-	originalAmount := existingPayment.Amount
+	replacement := *original
+	replacement.ID = 0
+	replacement.TenderedAmount = nil
+	replacement.ChangeAmount = 0
+	replacement.CorrectionReason = nil
+	replacement.CorrectedBy = nil
+	replacement.CorrectedAt = nil
 
-	// Update payment fields
 	if req.Amount != nil {
-		existingPayment.Amount = *req.Amount
+		replacement.Amount = *req.Amount
 	}
 	if req.PaymentMethod != nil {
-		existingPayment.PaymentMethod = *req.PaymentMethod
+		replacement.PaymentMethod = *req.PaymentMethod
 	}
 	if req.PaymentDate != nil {
-		existingPayment.PaymentDate = *req.PaymentDate
+		replacement.PaymentDate = *req.PaymentDate
 	}
 	if req.TransactionReference != nil {
-		existingPayment.TransactionReference = req.TransactionReference
+		replacement.TransactionReference = req.TransactionReference
 	}
 	if req.Notes != nil {
-		existingPayment.Notes = req.Notes
+		replacement.Notes = req.Notes
 	}
-	if req.CorrectionReason != nil {
-		existingPayment.CorrectionReason = req.CorrectionReason
-		existingPayment.CorrectedBy = &updatedBy
-		correctedAt := time.Now()
-		existingPayment.CorrectedAt = &correctedAt
-		existingPayment.OriginalAmount = &originalAmount
+
+	if replacement.Amount == original.Amount &&
+		replacement.PaymentMethod == original.PaymentMethod &&
+		replacement.PaymentDate.Equal(original.PaymentDate) &&
+		req.TransactionReference == nil && req.Notes == nil {
+		return nil, errors.New("correction does not change the payment")
 	}
-	if req.Status != nil {
-		existingPayment.Status = *req.Status
+
+	// Validate payment method when it or the reference changes
+	if (req.PaymentMethod != nil || req.TransactionReference != nil) && s.paymentMethodService != nil {
+		_, err = s.paymentMethodService.ValidatePayment(replacement.PaymentMethod, replacement.TransactionReference)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	existingPayment.UpdatedAt = time.Now()
+	return s.recordPaymentCorrection(original, &replacement, req.CorrectionReason, correctedBy, correctedByUsername)
+}
 
-	err = s.invoiceRepo.UpdateInvoicePayment(existingPayment)
+// ReverseInvoicePayment cancels a payment with a reversal record instead of deleting it. A payment
+// from a customer deposit goes back to the deposit.
+func (s *InvoiceService) ReverseInvoicePayment(paymentID int, req *models.ReverseInvoicePaymentRequest, correctedBy int, correctedByUsername string) (*models.PaymentCorrectionResult, error) {
+	original, err := s.getCorrectablePayment(paymentID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update invoice paid amount if amount changed
-	if req.Amount != nil && *req.Amount != originalAmount {
-		invoice, err := s.invoiceRepo.GetInvoiceByID(existingPayment.InvoiceID)
-		if err != nil {
-			return nil, err
+	return s.recordPaymentCorrection(original, nil, req.CorrectionReason, correctedBy, correctedByUsername)
+}
+
+// GetPaymentCorrectionReport gets the payments corrected or reversed in [from, to), of invoices of
+// one branch when branchID > 0
+func (s *InvoiceService) GetPaymentCorrectionReport(from, to time.Time, branchID int) (*models.PaymentCorrectionReport, error) {
+	if !to.After(from) {
+		return nil, errors.New("report end date must be after its start date")
+	}
+
+	corrections, err := s.invoiceRepo.GetPaymentCorrections(from, to, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.PaymentCorrectionReport{
+		From:        from,
+		To:          to,
+		Corrections: corrections,
+	}
+	if report.Corrections == nil {
+		report.Corrections = []*models.PaymentCorrection{}
+	}
+
+	for _, correction := range corrections {
+		report.TotalReversed += correction.OriginalAmount
+		if correction.NewAmount != nil {
+			report.TotalReplaced += *correction.NewAmount
 		}
+	}
+
+	return report, nil
+}
+
+// Helper methods
+
+// getCorrectablePayment gets a payment that has not been reversed or corrected yet
+func (s *InvoiceService) getCorrectablePayment(paymentID int) (*models.InvoicePayment, error) {
+	payment, err := s.invoiceRepo.GetInvoicePaymentByID(paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment == nil {
+		return nil, errors.New("payment not found")
+	}
+
+	if payment.PaymentType == models.PaymentTypeReversal {
+		return nil, errors.New("reversal records cannot be corrected")
+	}
 
-		// Recalculate total paid amount
-		payments, err := s.invoiceRepo.GetInvoicePaymentsByInvoiceID(existingPayment.InvoiceID)
+	if payment.CorrectedAt != nil {
+		return nil, errors.New("payment has already been corrected")
+	}
+
+	return payment, nil
+}
+
+// recordPaymentCorrection marks the original payment as corrected and records its reversal
+// and optional replacement. The reversal is dated like the original payment so collections
+// of that day net to the corrected amount.
+func (s *InvoiceService) recordPaymentCorrection(original, replacement *models.InvoicePayment, reason string, correctedBy int, correctedByUsername string) (*models.PaymentCorrectionResult, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("correction reason is required")
+	}
+
+	oldPayment := *original
+	now := time.Now()
+	originalAmount := original.Amount
+
+	original.CorrectionReason = &reason
+	original.CorrectedBy = &correctedBy
+	original.CorrectedAt = &now
+	original.OriginalAmount = &originalAmount
+	original.UpdatedAt = now
+
+	reversal := &models.InvoicePayment{
+		InvoiceID:            original.InvoiceID,
+		Amount:               -originalAmount,
+		PaymentMethod:        original.PaymentMethod,
+		PaymentDate:          original.PaymentDate,
+		TransactionReference: original.TransactionReference,
+		Notes:                &reason,
+		OriginalAmount:       &originalAmount,
+		PaymentType:          models.PaymentTypeReversal,
+		CorrectsPaymentID:    &original.ID,
+		Status:               original.Status,
+		CreatedBy:            &correctedBy,
+		CreatedByUsername:    &correctedByUsername,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	if replacement != nil {
+		replacement.OriginalAmount = &originalAmount
+		replacement.PaymentType = models.PaymentTypePayment
+		replacement.CorrectsPaymentID = &original.ID
+		replacement.CreatedBy = &correctedBy
+		replacement.CreatedByUsername = &correctedByUsername
+		replacement.CreatedAt = now
+		replacement.UpdatedAt = now
+	}
+
+	if err := s.invoiceRepo.CorrectInvoicePayment(original, reversal, replacement); err != nil {
+		return nil, err
+	}
+
+	result := &models.PaymentCorrectionResult{
+		Original:    original,
+		Reversal:    reversal,
+		Replacement: replacement,
+	}
+
+	if s.auditLogService != nil {
+		userName := &correctedByUsername
+		err := s.auditLogService.LogInvoiceChange(
+			original.InvoiceID,
+			"updated",
+			map[string]interface{}{"payment": oldPayment},
+			result,
+			&correctedBy,
+			userName,
+			nil,
+			nil,
+		)
 		if err != nil {
-			return nil, err
+			log.Printf("Failed to log payment correction: %v", err)
 		}
+	}
+
+	return result, nil
+}
+
+// setItemDiscount sets the requested line discount of an item. A percentage is resolved to an
+// amount in calculateItemTotals once promotions are applied; an amount sent with it must match.
+func setItemDiscount(item *models.InvoiceItem, discountAmount, discountPercentage *float64) {
+	if discountPercentage != nil {
+		item.DiscountPercentage = *discountPercentage
+	}
+	if discountAmount != nil {
+		item.DiscountAmount = *discountAmount
+	}
+}
+
+// calculateItemTotals sets the total price of every item to its amount after the promotion
+// and line discounts, and returns the invoice subtotal and the sum of the line discounts
+func calculateItemTotals(items []*models.InvoiceItem) (float64, float64, error) {
+	subtotal := 0.0
+	lineDiscounts := 0.0
+
+	for _, item := range items {
+		base := item.Quantity*item.UnitPrice - item.PromotionDiscount
 
-		totalPaid := 0.0
-		for _, payment := range payments {
-			if payment.Status == "confirmed" {
-				totalPaid += payment.Amount
+		// The amount always follows the percentage, so an amount echoed back from an earlier
+		// version of the invoice does not survive a change of quantity or price
+		if item.DiscountPercentage > 0 {
+			amount := math.Round(base*item.DiscountPercentage) / 100
+			if item.DiscountAmount > 0 && math.Abs(item.DiscountAmount-amount) >= 0.01 {
+				return 0, 0, fmt.Errorf("discount amount of %s does not match its discount percentage; send only one of them", item.ProductName)
 			}
+			item.DiscountAmount = amount
 		}
 
-		invoice.PaidAmount = totalPaid
+		if item.DiscountAmount > base {
+			return 0, 0, fmt.Errorf("discount on %s exceeds the item total", item.ProductName)
+		}
 
-		if totalPaid >= invoice.TotalAmount {
-			invoice.PaymentStatus = "paid"
-		} else if totalPaid > 0 {
-			invoice.PaymentStatus = "partial"
-		} else {
-			invoice.PaymentStatus = "pending"
+		item.TotalPrice = base - item.DiscountAmount
+		subtotal += item.TotalPrice
+		lineDiscounts += item.DiscountAmount
+	}
+
+	return subtotal, lineDiscounts, nil
+}
+
+// calculateTax computes the per-line VAT of the items and returns the invoice tax amount.
+// An explicit tax amount is rejected when an item is taxed by its tax category; otherwise it is
+// spread over the lines.
+func (s *InvoiceService) calculateTax(items []*models.InvoiceItem, priceMode string, subtotal, discountAmount float64, taxAmount, taxPercentage *float64, at time.Time) (float64, error) {
+	if s.taxService == nil {
+		if taxAmount != nil {
+			return *taxAmount, nil
+		}
+		if taxPercentage != nil {
+			return (subtotal - discountAmount) * (*taxPercentage / 100), nil
 		}
+		return 0, nil
+	}
 
-		invoice.UpdatedAt = time.Now()
+	total, categorized, err := s.taxService.CalculateItemTaxes(items, priceMode, discountAmount, taxPercentage, at)
+	if err != nil {
+		return 0, err
+	}
 
-		err = s.invoiceRepo.UpdateInvoice(invoice)
-		if err != nil {
-			return nil, err
+	if taxAmount != nil {
+		// Items taxed by their tax category carry their own VAT, which an invoice amount would contradict
+		if categorized {
+			return 0, errors.New("tax_amount cannot be given when items are taxed by their tax category")
+		}
+		if err := s.taxService.AllocateInvoiceTax(items, priceMode, *taxAmount); err != nil {
+			return 0, err
 		}
+		return *taxAmount, nil
 	}
 
-	return existingPayment, nil
+	return total, nil
+}
+
+// invoiceTotal returns the amount due. With tax-inclusive prices the tax is already part of the subtotal.
+func invoiceTotal(priceMode string, subtotal, discountAmount, taxAmount float64) float64 {
+	if priceMode == models.PriceModeInclusive {
+		return subtotal - discountAmount
+	}
+	return subtotal - discountAmount + taxAmount
 }
 
-func (s *InvoiceService) DeleteInvoicePayment(paymentID int) error {
-	// Get payment to find invoice ID
-	// Note: This is a simplified approach. In a real implementation,
-	// you might want to add a GetPaymentByID method to the repository
-	return s.invoiceRepo.DeleteInvoicePayment(paymentID)
+// buildCheckoutPayments turns the payments tendered at checkout into invoice payments.
+// Non-cash payments may not exceed the invoice total. Cash tendered above the amount
+// still due is given back as change, taken from the last cash payments first; a cash
+// payment that is entirely given back is merged into the cash payment before it.
+func (s *InvoiceService) buildCheckoutPayments(tenders []models.CreateInvoiceTenderRequest, totalAmount float64, createdBy int) ([]*models.InvoicePayment, error) {
+	payments := make([]*models.InvoicePayment, 0, len(tenders))
+	isCash := make([]bool, 0, len(tenders))
+	nonCashTotal := 0.0
+	tenderedTotal := 0.0
+
+	for _, tender := range tenders {
+		cash := tender.PaymentMethod == models.PaymentMethodCash
+		if s.paymentMethodService != nil {
+			method, err := s.paymentMethodService.ValidatePayment(tender.PaymentMethod, tender.TransactionReference)
+			if err != nil {
+				return nil, err
+			}
+			cash = method.IsCash
+		}
+
+		if !cash {
+			nonCashTotal += tender.Amount
+		}
+		tenderedTotal += tender.Amount
+
+		payments = append(payments, &models.InvoicePayment{
+			Amount:               tender.Amount,
+			PaymentMethod:        tender.PaymentMethod,
+			PaymentDate:          time.Now(),
+			TransactionReference: tender.TransactionReference,
+			Notes:                tender.Notes,
+			PaymentType:          models.PaymentTypePayment,
+			Status:               "confirmed",
+			CreatedBy:            &createdBy,
+			CreatedAt:            time.Now(),
+			UpdatedAt:            time.Now(),
+		})
+		isCash = append(isCash, cash)
+	}
+
+	if nonCashTotal > totalAmount+0.5 {
+		return nil, fmt.Errorf("non-cash payments of %.0f exceed the invoice total of %.0f", nonCashTotal, totalAmount)
+	}
+
+	change := math.Round((tenderedTotal-totalAmount)*100) / 100
+	returned := 0.0 // cash of payments given back entirely
+	recorded := make([]*models.InvoicePayment, 0, len(payments))
+	for i := len(payments) - 1; i >= 0; i-- {
+		payment := payments[i]
+		if isCash[i] && change+returned > 0 {
+			tendered := payment.Amount + returned
+			given := math.Min(change, payment.Amount)
+			change -= given
+
+			payment.Amount = math.Round((payment.Amount-given)*100) / 100
+			if payment.Amount <= 0 {
+				returned = tendered
+				continue
+			}
+
+			payment.TenderedAmount = &tendered
+			payment.ChangeAmount = math.Round((given+returned)*100) / 100
+			returned = 0
+		}
+		recorded = append([]*models.InvoicePayment{payment}, recorded...)
+	}
+
+	return recorded, nil
 }
 
-// Helper methods
 func (s *InvoiceService) createInventoryLogForSale(variantID int, quantity float64, invoiceID int, createdBy int) error {
 	// This is a simplified implementation
 	// In a real system, you would need to:
@@ -577,8 +977,9 @@ func (s *InvoiceService) createInventoryLogForSale(variantID int, quantity float
 	return s.invoiceRepo.CreateInventoryLog(log)
 }
 
-func (s *InvoiceService) GetInvoiceSummary() (*models.InvoiceSummary, error) {
-	return s.invoiceRepo.GetInvoiceSummary()
+// GetInvoiceSummary gets invoice statistics, of one branch when branchID > 0
+func (s *InvoiceService) GetInvoiceSummary(branchID int) (*models.InvoiceSummary, error) {
+	return s.invoiceRepo.GetInvoiceSummary(branchID)
 }
 
 // GetInvoiceAuditLogs gets audit logs for a specific invoice
@@ -1,43 +1,80 @@
 package services
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
 
 	"steel-pos-backend/internal/config"
 	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/tokenstore"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Token types carried in the token_type claim
+const (
+	TokenTypeAccess    = "access"
+	TokenTypeRefresh   = "refresh"
+	TokenTypeTwoFactor = "two_factor"
+)
+
+// twoFactorChallengeExpiry is how long a user has to enter their two-factor code after the password
+const twoFactorChallengeExpiry = 5 * time.Minute
+
+// refreshReuseGrace is how long an exchanged refresh token may be presented again without
+// being treated as stolen, so that requests refreshing at the same moment do not log the user out
+const refreshReuseGrace = 10 * time.Second
+
+// ErrRefreshTokenExchanged is returned when a refresh token presented again within
+// refreshReuseGrace has a replacement that was exchanged in turn
+var ErrRefreshTokenExchanged = errors.New("refresh token has already been exchanged, use the newer token")
+
+// sessionTouchInterval limits how often the last seen time of a session is written
+const sessionTouchInterval = time.Minute
+
+// UserLoader gets the current record of a user, so that refreshed tokens carry their current role
+type UserLoader interface {
+	GetByID(id int) (*models.User, error)
+}
+
 type JWTService struct {
-	config *config.Config
+	config     *config.Config
+	tokenStore tokenstore.Store
+	users      UserLoader
 }
 
 type Claims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	TokenType string `json:"token_type,omitempty"`
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func NewJWTService(config *config.Config) *JWTService {
+func NewJWTService(config *config.Config, tokenStore tokenstore.Store, users UserLoader) *JWTService {
 	return &JWTService{
-		config: config,
+		config:     config,
+		tokenStore: tokenStore,
+		users:      users,
 	}
 }
 
-func (s *JWTService) GenerateAccessToken(user *models.User) (string, error) {
+func (s *JWTService) GenerateAccessToken(user *models.User, sessionID string) (string, error) {
 	expiration, err := time.ParseDuration(s.config.JWT.AccessTokenExpiry)
 	if err != nil {
 		return "", fmt.Errorf("invalid expiration time: %w", err)
 	}
 
 	claims := &Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
+		UserID:    user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		TokenType: TokenTypeAccess,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -51,28 +88,109 @@ func (s *JWTService) GenerateAccessToken(user *models.User) (string, error) {
 	return token.SignedString([]byte(s.config.JWT.Secret))
 }
 
-func (s *JWTService) GenerateRefreshToken(user *models.User) (string, error) {
-	// Refresh token có thời hạn dài hơn từ config
-	expiration, err := time.ParseDuration(s.config.JWT.RefreshTokenExpiry)
-	if err != nil {
-		return "", fmt.Errorf("invalid refresh token expiration time: %w", err)
-	}
-
+// GenerateChallengeToken issues the short-lived token that proves the password of a user was
+// checked, to be exchanged for a session once the second factor is passed. It has no session,
+// so it cannot be used as an access token.
+func (s *JWTService) GenerateChallengeToken(user *models.User) (string, int64, error) {
+	now := time.Now()
 	claims := &Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
+		UserID:    user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		TokenType: TokenTypeTwoFactor,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(twoFactorChallengeExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "steel-pos-backend",
 			Subject:   fmt.Sprintf("%d", user.ID),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.config.JWT.Secret))
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.config.JWT.Secret))
+	if err != nil {
+		return "", 0, err
+	}
+
+	return token, int64(twoFactorChallengeExpiry.Seconds()), nil
+}
+
+// ValidateChallengeToken validates a token issued by GenerateChallengeToken
+func (s *JWTService) ValidateChallengeToken(tokenString string) (*Claims, error) {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != TokenTypeTwoFactor {
+		return nil, errors.New("invalid two-factor challenge token")
+	}
+
+	return claims, nil
+}
+
+// StartSession records a new login of a user and issues its first access and refresh tokens
+func (s *JWTService) StartSession(user *models.User, deviceName *string, ipAddress, userAgent string) (accessToken, refreshToken string, err error) {
+	sessionID, err := newTokenID()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	err = s.tokenStore.CreateSession(&tokenstore.Session{
+		ID:         sessionID,
+		UserID:     user.ID,
+		DeviceName: deviceName,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	accessToken, err = s.GenerateAccessToken(user, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err = s.issueRefreshToken(user, tokenID, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// SwitchSessionUser makes another user the active user of a session, e.g. a cashier taking over
+// a shared counter, and issues tokens for them. Tokens issued to the session before stop working.
+func (s *JWTService) SwitchSessionUser(sessionID string, user *models.User) (accessToken, refreshToken string, err error) {
+	if err := s.tokenStore.SwitchUser(sessionID, user.ID, time.Now()); err != nil {
+		return "", "", err
+	}
+
+	accessToken, err = s.GenerateAccessToken(user, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err = s.issueRefreshToken(user, tokenID, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
 }
 
 func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
@@ -101,26 +219,87 @@ func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token claims")
 }
 
+// ValidateAccessToken validates a token presented to authenticate a request and checks that
+// its session has not been revoked. Refresh tokens are rejected so that they cannot stand in
+// for access tokens.
+func (s *JWTService) ValidateAccessToken(tokenString string) (*Claims, error) {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType == TokenTypeRefresh {
+		return nil, errors.New("invalid token: refresh token used as access token")
+	}
+
+	if claims.TokenType == TokenTypeTwoFactor {
+		return nil, errors.New("invalid token: two-factor authentication not completed")
+	}
+
+	if claims.SessionID == "" {
+		return nil, errors.New("invalid token: no session, please log in again")
+	}
+
+	session, err := s.tokenStore.GetSession(claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session == nil || session.CurrentUserID() != claims.UserID || session.RevokedAt != nil {
+		return nil, errors.New("invalid token: session has been revoked")
+	}
+
+	if now := time.Now(); now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		if err := s.tokenStore.TouchSession(session.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return claims, nil
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a new refresh token of
+// the same family. A refresh token can be exchanged once; presenting it again within
+// refreshReuseGrace returns the refresh token it was exchanged for, and presenting it after
+// that revokes the whole family, as the token has likely been stolen.
 func (s *JWTService) RefreshToken(refreshToken string) (*models.LoginResponse, error) {
-	claims, err := s.ValidateToken(refreshToken)
+	claims, stored, err := s.lookupRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tokens carry the current role, and a deactivated user cannot refresh
+	user, err := s.users.GetByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, errors.New("account is deactivated")
+	}
+
+	now := time.Now()
+	newID, err := newTokenID()
 	if err != nil {
 		return nil, err
 	}
 
-	// Tạo user object từ claims
-	user := &models.User{
-		ID:       claims.UserID,
-		Username: claims.Username,
-		Role:     claims.Role,
+	marked, err := s.tokenStore.MarkUsed(stored.ID, newID, now)
+	if err != nil {
+		return nil, err
 	}
 
-	// Generate new tokens
-	accessToken, err := s.GenerateAccessToken(user)
+	var newRefreshToken string
+	if marked {
+		newRefreshToken, err = s.issueRefreshToken(user, newID, stored.SessionID)
+	} else {
+		// Exchanged already, possibly a moment ago by a concurrent request, or revoked meanwhile
+		newRefreshToken, err = s.reissueReplacement(user, stored.ID, now)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	newRefreshToken, err := s.GenerateRefreshToken(user)
+	accessToken, err := s.GenerateAccessToken(user, stored.SessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -135,3 +314,180 @@ func (s *JWTService) RefreshToken(refreshToken string) (*models.LoginResponse, e
 		ExpiresIn:    int64(expiration.Seconds()),
 	}, nil
 }
+
+// RevokeRefreshToken revokes the session a refresh token was issued to
+func (s *JWTService) RevokeRefreshToken(refreshToken string) error {
+	_, stored, err := s.lookupRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	return s.tokenStore.RevokeSession(stored.SessionID, time.Now())
+}
+
+// GetUserSessions gets the active sessions of a user
+func (s *JWTService) GetUserSessions(userID int) ([]*tokenstore.Session, error) {
+	return s.tokenStore.GetUserSessions(userID)
+}
+
+// RevokeSession revokes a session of a user; its tokens are rejected from then on
+func (s *JWTService) RevokeSession(userID int, sessionID string) error {
+	session, err := s.tokenStore.GetSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if session == nil || (session.UserID != userID && session.CurrentUserID() != userID) {
+		return errors.New("session not found")
+	}
+
+	return s.tokenStore.RevokeSession(sessionID, time.Now())
+}
+
+// RevokeUserSessions revokes every session of a user
+func (s *JWTService) RevokeUserSessions(userID int) error {
+	return s.tokenStore.RevokeUser(userID, time.Now())
+}
+
+// RevokeOtherSessions revokes every session a user logged in to except the given one
+func (s *JWTService) RevokeOtherSessions(userID int, keepSessionID string) error {
+	sessions, err := s.tokenStore.GetUserSessions(userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, session := range sessions {
+		if session.ID == keepSessionID {
+			continue
+		}
+		if err := s.tokenStore.RevokeSession(session.ID, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteExpiredRefreshTokens removes expired refresh tokens from the store
+func (s *JWTService) DeleteExpiredRefreshTokens() (int64, error) {
+	return s.tokenStore.DeleteExpired(time.Now())
+}
+
+// Helper methods
+
+func (s *JWTService) issueRefreshToken(user *models.User, id, sessionID string) (string, error) {
+	// Refresh token có thời hạn dài hơn từ config
+	expiration, err := time.ParseDuration(s.config.JWT.RefreshTokenExpiry)
+	if err != nil {
+		return "", fmt.Errorf("invalid refresh token expiration time: %w", err)
+	}
+
+	now := time.Now()
+	token, err := s.signRefreshToken(user, id, sessionID, now, now.Add(expiration))
+	if err != nil {
+		return "", err
+	}
+
+	err = s.tokenStore.Save(&tokenstore.RefreshToken{
+		ID:        id,
+		SessionID: sessionID,
+		UserID:    user.ID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(expiration),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// reissueReplacement handles a refresh token presented after it was exchanged. Within
+// refreshReuseGrace it signs again the refresh token it was exchanged for, so that requests
+// refreshing at the same moment end up in the same family; later it revokes the session.
+func (s *JWTService) reissueReplacement(user *models.User, id string, now time.Time) (string, error) {
+	stored, err := s.tokenStore.Get(id)
+	if err != nil {
+		return "", err
+	}
+	if stored == nil || stored.RevokedAt != nil {
+		return "", errors.New("refresh token has been revoked")
+	}
+	if stored.UsedAt == nil || stored.ReplacedBy == nil || now.Sub(*stored.UsedAt) > refreshReuseGrace {
+		if err := s.tokenStore.RevokeSession(stored.SessionID, now); err != nil {
+			return "", err
+		}
+		return "", errors.New("refresh token reuse detected, please log in again")
+	}
+
+	replacement, err := s.tokenStore.Get(*stored.ReplacedBy)
+	if err != nil {
+		return "", err
+	}
+	if replacement != nil && replacement.RevokedAt != nil {
+		return "", errors.New("refresh token has been revoked")
+	}
+	if replacement == nil || replacement.UsedAt != nil {
+		// Not saved yet, or exchanged in turn: the client holds a newer token than this one
+		return "", ErrRefreshTokenExchanged
+	}
+
+	return s.signRefreshToken(user, replacement.ID, replacement.SessionID, replacement.IssuedAt, replacement.ExpiresAt)
+}
+
+// signRefreshToken signs the refresh token of a stored record
+func (s *JWTService) signRefreshToken(user *models.User, id, sessionID string, issuedAt, expiresAt time.Time) (string, error) {
+	claims := &Claims{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		TokenType: TokenTypeRefresh,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			NotBefore: jwt.NewNumericDate(issuedAt),
+			Issuer:    "steel-pos-backend",
+			Subject:   fmt.Sprintf("%d", user.ID),
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.config.JWT.Secret))
+}
+
+// lookupRefreshToken validates a refresh token and finds its record in the store
+func (s *JWTService) lookupRefreshToken(refreshToken string) (*Claims, *tokenstore.RefreshToken, error) {
+	claims, err := s.ValidateToken(refreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if claims.TokenType != TokenTypeRefresh || claims.ID == "" {
+		return nil, nil, errors.New("invalid refresh token")
+	}
+
+	stored, err := s.tokenStore.Get(claims.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if stored == nil || stored.UserID != claims.UserID {
+		return nil, nil, errors.New("refresh token not recognized")
+	}
+
+	if stored.RevokedAt != nil {
+		return nil, nil, errors.New("refresh token has been revoked")
+	}
+
+	return claims, stored, nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
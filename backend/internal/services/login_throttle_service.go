@@ -0,0 +1,236 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"steel-pos-backend/internal/config"
+	"steel-pos-backend/internal/loginattempt"
+	"steel-pos-backend/internal/models"
+)
+
+// LoginThrottledError is returned when a login is refused before the password is checked,
+// because of recent failures of the username or the IP address
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool // locked out after too many failures, rather than waiting between attempts
+}
+
+func (e *LoginThrottledError) Error() string {
+	wait := time.Duration(math.Ceil(e.RetryAfter.Seconds())) * time.Second
+	if e.Locked {
+		return fmt.Sprintf("too many failed logins, login locked, try again in %s", wait)
+	}
+	return fmt.Sprintf("too many failed logins, try again in %s", wait)
+}
+
+// LoginThrottleService slows down repeated failed logins with an exponential delay between
+// attempts and locks a username or IP address for a while after too many failures
+type LoginThrottleService struct {
+	config          config.LoginConfig
+	store           loginattempt.Store
+	auditLogService AuditLogService
+}
+
+func NewLoginThrottleService(config *config.Config, store loginattempt.Store, auditLogService AuditLogService) *LoginThrottleService {
+	return &LoginThrottleService{
+		config:          config.Login,
+		store:           store,
+		auditLogService: auditLogService,
+	}
+}
+
+// Check refuses a login attempt while the username or the IP address is locked, or while the
+// delay after its last failure has not passed. An attempt it lets through counts against the
+// limits until RecordFailure or RecordSuccess, so that concurrent attempts cannot get past them.
+func (s *LoginThrottleService) Check(username, ipAddress string) error {
+	userPolicy, ipPolicy, err := s.policies()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	userKey := loginattempt.UserKey(username)
+	refusal, err := s.store.Begin(userKey, now, userPolicy)
+	if err != nil {
+		return err
+	}
+
+	if refusal == nil {
+		refusal, err = s.store.Begin(loginattempt.IPKey(ipAddress), now, ipPolicy)
+		if err == nil && refusal == nil {
+			return nil
+		}
+
+		// The attempt is not made, so it does not count against the username
+		if endErr := s.store.End(userKey); endErr != nil {
+			log.Printf("Failed to end login attempt for %s: %v", username, endErr)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return &LoginThrottledError{RetryAfter: refusal.RetryAfter, Locked: refusal.Locked}
+}
+
+// RecordFailure counts a failed login for the username and the IP address, locking either once
+// it reaches its limit. user is nil when the username does not exist.
+func (s *LoginThrottleService) RecordFailure(username, ipAddress, userAgent string, user *models.User) {
+	userPolicy, ipPolicy, err := s.policies()
+	if err != nil {
+		log.Printf("Invalid login throttle configuration: %v", err)
+		return
+	}
+
+	userID := 0
+	if user != nil {
+		userID = user.ID
+	}
+
+	now := time.Now()
+
+	userAttempts, err := s.store.RecordFailure(loginattempt.UserKey(username), now, userPolicy)
+	if err != nil {
+		log.Printf("Failed to record failed login for %s: %v", username, err)
+		return
+	}
+
+	s.logLoginEvent(userID, "login_failed", fmt.Sprintf("Failed login for %s (%d in a row)", username, userAttempts.Failures),
+		map[string]interface{}{"username": username, "failures": userAttempts.Failures}, ipAddress, userAgent)
+
+	if userAttempts.Failures >= userPolicy.MaxFailures {
+		lockedUntil := *userAttempts.LockedUntil
+		s.logLoginEvent(userID, "locked", fmt.Sprintf("Locked login for %s until %s after %d failures", username, lockedUntil.Format(time.RFC3339), userAttempts.Failures),
+			map[string]interface{}{"username": username, "failures": userAttempts.Failures, "locked_until": lockedUntil}, ipAddress, userAgent)
+	}
+
+	ipAttempts, err := s.store.RecordFailure(loginattempt.IPKey(ipAddress), now, ipPolicy)
+	if err != nil {
+		log.Printf("Failed to record failed login from %s: %v", ipAddress, err)
+		return
+	}
+
+	if ipAttempts.Failures >= ipPolicy.MaxFailures {
+		lockedUntil := *ipAttempts.LockedUntil
+		s.logLoginEvent(0, "locked", fmt.Sprintf("Locked logins from %s until %s after %d failures", ipAddress, lockedUntil.Format(time.RFC3339), ipAttempts.Failures),
+			map[string]interface{}{"ip_address": ipAddress, "failures": ipAttempts.Failures, "locked_until": lockedUntil}, ipAddress, userAgent)
+	}
+}
+
+// RecordSuccess clears the failures of a username after it logged in. Failures of the IP address
+// are kept, so that one valid account does not reset guessing at others; only the attempt
+// started by Check is ended. ipAddress is empty when no attempt was started.
+func (s *LoginThrottleService) RecordSuccess(username, ipAddress string) error {
+	if err := s.store.Reset(loginattempt.UserKey(username)); err != nil {
+		return err
+	}
+
+	if ipAddress == "" {
+		return nil
+	}
+	return s.store.End(loginattempt.IPKey(ipAddress))
+}
+
+// UnlockUser clears the failures and lock of a user
+func (s *LoginThrottleService) UnlockUser(user *models.User, unlockedBy int, unlockedByName string) error {
+	if err := s.store.Reset(loginattempt.UserKey(user.Username)); err != nil {
+		return err
+	}
+
+	s.logUnlock(user.ID, fmt.Sprintf("Unlocked login for %s", user.Username),
+		map[string]interface{}{"username": user.Username}, unlockedBy, unlockedByName)
+	return nil
+}
+
+// UnlockIP clears the failures and lock of an IP address
+func (s *LoginThrottleService) UnlockIP(ipAddress string, unlockedBy int, unlockedByName string) error {
+	if err := s.store.Reset(loginattempt.IPKey(ipAddress)); err != nil {
+		return err
+	}
+
+	s.logUnlock(0, fmt.Sprintf("Unlocked logins from %s", ipAddress),
+		map[string]interface{}{"ip_address": ipAddress}, unlockedBy, unlockedByName)
+	return nil
+}
+
+// DeleteExpired forgets failures and locks that no longer have any effect
+func (s *LoginThrottleService) DeleteExpired() (int64, error) {
+	lockout, err := time.ParseDuration(s.config.LockoutDuration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid login lockout duration: %w", err)
+	}
+
+	return s.store.DeleteExpired(time.Now().Add(-lockout))
+}
+
+// Helper methods
+
+// policies are the limits applied to the failures of a username and of an IP address
+func (s *LoginThrottleService) policies() (user, ip loginattempt.Policy, err error) {
+	lockout, err := time.ParseDuration(s.config.LockoutDuration)
+	if err != nil {
+		return user, ip, fmt.Errorf("invalid login lockout duration: %w", err)
+	}
+
+	base, err := time.ParseDuration(s.config.BackoffBase)
+	if err != nil {
+		return user, ip, fmt.Errorf("invalid login backoff base: %w", err)
+	}
+
+	max, err := time.ParseDuration(s.config.BackoffMax)
+	if err != nil {
+		return user, ip, fmt.Errorf("invalid login backoff max: %w", err)
+	}
+
+	user = loginattempt.Policy{Window: lockout, BackoffBase: base, BackoffMax: max, MaxFailures: s.config.MaxFailures}
+	ip = user
+	ip.MaxFailures = s.config.MaxIPFailures
+	return user, ip, nil
+}
+
+// logLoginEvent records a failed login or lockout against the user, or user 0 when the
+// username is unknown or the IP address was locked
+func (s *LoginThrottleService) logLoginEvent(userID int, action, summary string, data map[string]interface{}, ipAddress, userAgent string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	req := models.AuditLogCreateRequest{
+		EntityType:     "user",
+		EntityID:       userID,
+		Action:         action,
+		NewData:        data,
+		ChangesSummary: &summary,
+		IPAddress:      &ipAddress,
+		UserAgent:      &userAgent,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the login
+		log.Printf("Failed to create audit log for login: %v", err)
+	}
+}
+
+func (s *LoginThrottleService) logUnlock(userID int, summary string, data map[string]interface{}, unlockedBy int, unlockedByName string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	req := models.AuditLogCreateRequest{
+		EntityType:     "user",
+		EntityID:       userID,
+		Action:         "unlocked",
+		UserID:         &unlockedBy,
+		UserName:       &unlockedByName,
+		NewData:        data,
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the unlock
+		log.Printf("Failed to create audit log for login unlock: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"steel-pos-backend/internal/config"
+	"steel-pos-backend/internal/loginattempt"
+)
+
+func newTestLoginThrottle(maxFailures, maxIPFailures int) (*LoginThrottleService, *loginattempt.MemoryStore) {
+	store := loginattempt.NewMemoryStore()
+	cfg := &config.Config{Login: config.LoginConfig{
+		MaxFailures:     maxFailures,
+		MaxIPFailures:   maxIPFailures,
+		LockoutDuration: "15m",
+		BackoffBase:     "0s", // no delay between attempts, only the limits
+		BackoffMax:      "0s",
+	}}
+	return NewLoginThrottleService(cfg, store, nil), store
+}
+
+func TestLoginThrottleConcurrentAttempts(t *testing.T) {
+	throttle, _ := newTestLoginThrottle(5, 100)
+
+	// Guesses sent at once cannot all pass the check before the first of them fails
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	admitted := 0
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := throttle.Check("an", "10.0.0.1"); err == nil {
+				mu.Lock()
+				admitted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if admitted != 5 {
+		t.Fatalf("admitted %d attempts, want 5", admitted)
+	}
+
+	for i := 0; i < admitted; i++ {
+		throttle.RecordFailure("an", "10.0.0.1", "test", nil)
+	}
+
+	var throttled *LoginThrottledError
+	if err := throttle.Check("an", "10.0.0.1"); !errors.As(err, &throttled) || !throttled.Locked {
+		t.Fatalf("got %v, want the username locked", err)
+	}
+}
+
+func TestLoginThrottleIPLock(t *testing.T) {
+	throttle, store := newTestLoginThrottle(5, 2)
+
+	for _, username := range []string{"an", "binh"} {
+		if err := throttle.Check(username, "10.0.0.1"); err != nil {
+			t.Fatalf("Check %s: %v", username, err)
+		}
+		throttle.RecordFailure(username, "10.0.0.1", "test", nil)
+	}
+
+	var throttled *LoginThrottledError
+	if err := throttle.Check("cuong", "10.0.0.1"); !errors.As(err, &throttled) || !throttled.Locked {
+		t.Fatalf("got %v, want the IP address locked", err)
+	}
+
+	// The refused attempt does not count against the username
+	if attempts, _ := store.Get(loginattempt.UserKey("cuong")); attempts != nil && len(attempts.Pending) != 0 {
+		t.Fatalf("refused attempt left %d attempts in progress for the username", len(attempts.Pending))
+	}
+
+	// Other addresses are not affected
+	if err := throttle.Check("cuong", "10.0.0.2"); err != nil {
+		t.Fatalf("Check from another address: %v", err)
+	}
+}
+
+func TestLoginThrottleSuccess(t *testing.T) {
+	throttle, store := newTestLoginThrottle(5, 100)
+
+	if err := throttle.Check("an", "10.0.0.1"); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	throttle.RecordFailure("an", "10.0.0.1", "test", nil)
+
+	if err := throttle.Check("an", "10.0.0.1"); err != nil {
+		t.Fatalf("Check after one failure: %v", err)
+	}
+	if err := throttle.RecordSuccess("an", "10.0.0.1"); err != nil {
+		t.Fatalf("RecordSuccess: %v", err)
+	}
+
+	if attempts, _ := store.Get(loginattempt.UserKey("an")); attempts != nil {
+		t.Fatalf("failures of the username kept after login: %+v", attempts)
+	}
+
+	// Failures of the address are kept, the attempt that succeeded is not
+	attempts, _ := store.Get(loginattempt.IPKey("10.0.0.1"))
+	if attempts == nil || attempts.Failures != 1 || len(attempts.Pending) != 0 {
+		t.Fatalf("got %+v, want 1 failure and no attempt in progress for the address", attempts)
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/repository"
+)
+
+type PaymentMethodService struct {
+	paymentMethodRepo *repository.PaymentMethodRepository
+}
+
+func NewPaymentMethodService(paymentMethodRepo *repository.PaymentMethodRepository) *PaymentMethodService {
+	return &PaymentMethodService{
+		paymentMethodRepo: paymentMethodRepo,
+	}
+}
+
+// PaymentMethod methods
+func (s *PaymentMethodService) CreatePaymentMethod(req *models.CreatePaymentMethodRequest, createdBy int, createdByName string) (*models.PaymentMethod, error) {
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	for _, r := range code {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_') {
+			return nil, errors.New("payment method code may only contain letters, digits and underscores")
+		}
+	}
+
+	existing, err := s.paymentMethodRepo.GetByCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, fmt.Errorf("payment method %s already exists", code)
+	}
+
+	method := &models.PaymentMethod{
+		Code:              code,
+		Name:              strings.TrimSpace(req.Name),
+		Description:       req.Description,
+		RequiresReference: req.RequiresReference,
+		IsCash:            req.IsCash,
+		IsActive:          true,
+		SortOrder:         req.SortOrder,
+		CreatedBy:         &createdBy,
+		CreatedByName:     &createdByName,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+
+	if err := s.paymentMethodRepo.Create(method); err != nil {
+		return nil, err
+	}
+
+	return method, nil
+}
+
+func (s *PaymentMethodService) GetPaymentMethod(id int) (*models.PaymentMethod, error) {
+	method, err := s.paymentMethodRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == nil {
+		return nil, errors.New("payment method not found")
+	}
+
+	return method, nil
+}
+
+func (s *PaymentMethodService) GetPaymentMethods(activeOnly bool) ([]*models.PaymentMethod, error) {
+	return s.paymentMethodRepo.GetAll(activeOnly)
+}
+
+// UpdatePaymentMethod updates a payment method. Methods already used by payments are
+// deactivated rather than deleted.
+func (s *PaymentMethodService) UpdatePaymentMethod(id int, req *models.UpdatePaymentMethodRequest) (*models.PaymentMethod, error) {
+	method, err := s.GetPaymentMethod(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		method.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		method.Description = req.Description
+	}
+	if req.RequiresReference != nil {
+		method.RequiresReference = *req.RequiresReference
+	}
+	if req.IsCash != nil {
+		method.IsCash = *req.IsCash
+	}
+	if req.IsActive != nil {
+		method.IsActive = *req.IsActive
+	}
+	if req.SortOrder != nil {
+		method.SortOrder = *req.SortOrder
+	}
+
+	method.UpdatedAt = time.Now()
+
+	if err := s.paymentMethodRepo.Update(method); err != nil {
+		return nil, err
+	}
+
+	return method, nil
+}
+
+// ValidatePayment checks that a payment uses an active payment method and carries the
+// transaction reference the method requires, and returns the method
+func (s *PaymentMethodService) ValidatePayment(code string, transactionReference *string) (*models.PaymentMethod, error) {
+	method, err := s.paymentMethodRepo.GetByCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == nil {
+		return nil, fmt.Errorf("unknown payment method %q", code)
+	}
+
+	if !method.IsActive {
+		return nil, fmt.Errorf("payment method %s is no longer accepted", method.Name)
+	}
+
+	if method.RequiresReference && (transactionReference == nil || strings.TrimSpace(*transactionReference) == "") {
+		return nil, fmt.Errorf("payments by %s require a transaction reference", method.Name)
+	}
+
+	return method, nil
+}
+
+// GetCollectionReport gets the payments collected per payment method in [from, to)
+func (s *PaymentMethodService) GetCollectionReport(from, to time.Time) (*models.PaymentCollectionReport, error) {
+	if !to.After(from) {
+		return nil, errors.New("report end date must be after its start date")
+	}
+
+	methods, err := s.paymentMethodRepo.GetCollections(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.PaymentCollectionReport{
+		From:    from,
+		To:      to,
+		Methods: methods,
+	}
+	if report.Methods == nil {
+		report.Methods = []*models.PaymentMethodTotal{}
+	}
+
+	for _, method := range methods {
+		report.TotalCount += method.Count
+		report.TotalAmount += method.Amount
+	}
+
+	return report, nil
+}
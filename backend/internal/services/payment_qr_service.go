@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"math"
+
+	"steel-pos-backend/internal/config"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/vietqr"
+)
+
+type PaymentQRService struct {
+	storeSettingsService *StoreSettingsService
+	cfg                  config.VietQRConfig
+}
+
+func NewPaymentQRService(storeSettingsService *StoreSettingsService, cfg config.VietQRConfig) *PaymentQRService {
+	return &PaymentQRService{
+		storeSettingsService: storeSettingsService,
+		cfg:                  cfg,
+	}
+}
+
+// IsConfigured reports whether a bank account is set up to receive transfers
+func (s *PaymentQRService) IsConfigured() bool {
+	account, err := s.bankAccount()
+	return err == nil && account != nil
+}
+
+// GetInvoicePaymentQR builds the VietQR code for the amount still due on an invoice,
+// referenced by the invoice code
+func (s *PaymentQRService) GetInvoicePaymentQR(invoice *models.Invoice) (*models.PaymentQR, error) {
+	account, err := s.bankAccount()
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, errors.New("VietQR bank account is not configured")
+	}
+
+	if invoice.Status == "cancelled" {
+		return nil, errors.New("invoice is cancelled")
+	}
+
+	amount := math.Round(invoice.TotalAmount - invoice.PaidAmount)
+	if amount <= 0 {
+		return nil, errors.New("invoice is already paid")
+	}
+
+	reference := vietqr.Reference(invoice.InvoiceCode)
+	payload, err := vietqr.Payload(vietqr.Transfer{
+		BankBIN:       account.BankBIN,
+		AccountNumber: account.AccountNumber,
+		Amount:        int64(amount),
+		Reference:     reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PaymentQR{
+		InvoiceID:     invoice.ID,
+		InvoiceCode:   invoice.InvoiceCode,
+		BankBIN:       account.BankBIN,
+		BankName:      account.BankName,
+		AccountNumber: account.AccountNumber,
+		AccountName:   account.AccountName,
+		Amount:        amount,
+		Reference:     reference,
+		Payload:       payload,
+	}, nil
+}
+
+// GetInvoicePaymentQRImage renders the VietQR code of an invoice as a PNG image
+func (s *PaymentQRService) GetInvoicePaymentQRImage(invoice *models.Invoice, size int) ([]byte, error) {
+	qr, err := s.GetInvoicePaymentQR(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	return vietqr.PNG(qr.Payload, size)
+}
+
+// bankAccount gets the account VietQR codes pay into, nil when there is none
+func (s *PaymentQRService) bankAccount() (*config.VietQRConfig, error) {
+	settings, err := s.storeSettingsService.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	return vietQRAccount(settings.BankAccounts, s.cfg), nil
+}
+
+// vietQRAccount picks the first store bank account with a bank BIN, so the code pays into an
+// account printed on the invoice. An account without a BIN takes the configured one when the
+// account numbers match. The configured account is only used when the store has no bank accounts.
+func vietQRAccount(accounts []*models.StoreBankAccount, cfg config.VietQRConfig) *config.VietQRConfig {
+	for _, account := range accounts {
+		bin := stringValue(account.BankBIN)
+		if bin == "" && account.AccountNumber == cfg.AccountNumber {
+			bin = cfg.BankBIN
+		}
+		if bin != "" {
+			return &config.VietQRConfig{
+				BankBIN:       bin,
+				BankName:      account.BankName,
+				AccountNumber: account.AccountNumber,
+				AccountName:   account.AccountName,
+			}
+		}
+	}
+
+	if len(accounts) > 0 || cfg.BankBIN == "" || cfg.AccountNumber == "" {
+		return nil
+	}
+	return &cfg
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+
+	"steel-pos-backend/internal/config"
+	"steel-pos-backend/internal/models"
+)
+
+func TestVietQRAccount(t *testing.T) {
+	cfg := config.VietQRConfig{BankBIN: "970436", BankName: "Vietcombank", AccountNumber: "0011001234567", AccountName: "NGUYEN VAN A"}
+
+	tests := []struct {
+		name       string
+		accounts   []*models.StoreBankAccount
+		wantBIN    string // empty when there is no account
+		wantNumber string
+	}{
+		{
+			name: "first store account with a BIN",
+			accounts: []*models.StoreBankAccount{
+				{BankName: "Agribank", AccountNumber: "3700205123456", AccountName: "DAI LY KIEN PHUOC"},
+				{BankBIN: stringPtr("970422"), BankName: "MB Bank", AccountNumber: "0972851015", AccountName: "DAI LY KIEN PHUOC"},
+			},
+			wantBIN:    "970422",
+			wantNumber: "0972851015",
+		},
+		{
+			name: "store account takes the configured BIN of the same account",
+			accounts: []*models.StoreBankAccount{
+				{BankName: "Vietcombank", AccountNumber: "0011001234567", AccountName: "NGUYEN VAN A"},
+			},
+			wantBIN:    "970436",
+			wantNumber: "0011001234567",
+		},
+		{
+			name: "store accounts without a BIN",
+			accounts: []*models.StoreBankAccount{
+				{BankName: "Agribank", AccountNumber: "3700205123456", AccountName: "DAI LY KIEN PHUOC"},
+			},
+		},
+		{
+			name:       "configured account when the store has none",
+			wantBIN:    "970436",
+			wantNumber: "0011001234567",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := vietQRAccount(tt.accounts, cfg)
+			if tt.wantBIN == "" {
+				if account != nil {
+					t.Fatalf("got %+v, want no account", account)
+				}
+				return
+			}
+			if account == nil || account.BankBIN != tt.wantBIN || account.AccountNumber != tt.wantNumber {
+				t.Fatalf("got %+v, want BIN %s account %s", account, tt.wantBIN, tt.wantNumber)
+			}
+		})
+	}
+}
+
+func TestVietQRAccountNotConfigured(t *testing.T) {
+	if account := vietQRAccount(nil, config.VietQRConfig{AccountNumber: "0011001234567"}); account != nil {
+		t.Fatalf("got %+v, want no account without a BIN", account)
+	}
+}
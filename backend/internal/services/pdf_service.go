@@ -3,27 +3,63 @@ package services
 import (
 	"bytes"
 	"fmt"
+	"image/png"
 	"strconv"
+	"strings"
 	"time"
 
 	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/vietqr"
 
 	"github.com/jung-kurt/gofpdf"
 )
 
-type PDFService struct{}
+type PDFService struct {
+	paymentQRService     *PaymentQRService
+	storeSettingsService *StoreSettingsService
+	receiptService       *ReceiptService
+}
 
-func NewPDFService() *PDFService {
-	return &PDFService{}
+func NewPDFService(paymentQRService *PaymentQRService, storeSettingsService *StoreSettingsService, receiptService *ReceiptService) *PDFService {
+	return &PDFService{
+		paymentQRService:     paymentQRService,
+		storeSettingsService: storeSettingsService,
+		receiptService:       receiptService,
+	}
 }
 
 func (s *PDFService) FormatCurrency(amount float64) string {
 	return fmt.Sprintf("%.0f VNĐ", amount)
 }
 
-// GenerateInvoicePDF generates a PDF for the given invoice
-func (s *PDFService) GenerateInvoicePDF(invoice *models.Invoice) ([]byte, error) {
-	pdf := gofpdf.New("P", "mm", "A4", "")
+// GenerateInvoicePDF generates a PDF for the given invoice with a print template,
+// or with the default template of the store when template is empty
+func (s *PDFService) GenerateInvoicePDF(invoice *models.Invoice, template string) ([]byte, error) {
+	settings, err := s.storeSettingsService.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	if template == "" {
+		template = settings.DefaultInvoiceTemplate
+	}
+
+	switch template {
+	case models.InvoiceTemplateA4:
+		return s.generatePageInvoicePDF(invoice, settings, "A4", 1)
+	case models.InvoiceTemplateA5:
+		return s.generatePageInvoicePDF(invoice, settings, "A5", 0.8)
+	case models.InvoiceTemplateReceipt:
+		return s.generateReceiptInvoicePDF(invoice)
+	default:
+		return nil, fmt.Errorf("unknown invoice template %q", template)
+	}
+}
+
+// generatePageInvoicePDF lays an invoice out on a sheet of paper. Column widths follow the
+// page width; fonts and spacing are multiplied by scale.
+func (s *PDFService) generatePageInvoicePDF(invoice *models.Invoice, settings *models.StoreSettings, pageSize string, scale float64) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", pageSize, "")
 
 	// Add Noto Sans font that supports Vietnamese
 	pdf.AddUTF8Font("NotoSans", "", "fonts/NotoSans-Regular.ttf")
@@ -32,90 +68,97 @@ func (s *PDFService) GenerateInvoicePDF(invoice *models.Invoice) ([]byte, error)
 	pdf.AddPage()
 	pdf.SetAutoPageBreak(true, 0)
 
-	// Set font - using Noto Sans that supports Vietnamese
-	pdf.SetFont("NotoSans", "B", 22)
-
-	pdf.SetTextColor(0, 0, 0)
-	pdf.CellFormat(0, 12, "ĐẠI LÝ SẮT THÉP KIÊN PHƯỚC", "", 0, "C", false, 0, "")
-	pdf.Ln(10)
+	pageWidth, pageHeight := pdf.GetPageSize()
+	fullWidth := pageWidth - 20 // 10mm margins
+	halfWidth := fullWidth / 2
 
-	// pdf.SetFont("NotoSans", "", 14)
-	// pdf.SetTextColor(100, 100, 100)
-	// pdf.CellFormat(0, 6, "Lorem ipsum dolor sit amet, consectetur adipiscing elit. Sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.", "", 0, "C", false, 0, "")
-
-	pdf.SetFont("NotoSans", "", 12)
-	pdf.CellFormat(0, 6, "Địa chỉ: Trường Sơn Đức Thọ Hà Tĩnh", "", 0, "C", false, 0, "")
-	pdf.Ln(6)
-	pdf.CellFormat(0, 6, "Điện thoại: 0972851015 - 0974498918", "", 0, "C", false, 0, "")
-	pdf.Ln(8)
+	s.addStoreHeader(pdf, settings, fullWidth, scale)
 
 	// Invoice title with better styling
-	pdf.SetFont("NotoSans", "B", 22)
+	pdf.SetFont("NotoSans", "B", 22*scale)
 	pdf.SetTextColor(0, 0, 0)
-	pdf.CellFormat(0, 12, "HOÁ ĐƠN BÁN HÀNG", "", 0, "C", false, 0, "")
-	pdf.Ln(15)
+	pdf.CellFormat(0, 12*scale, "HOÁ ĐƠN BÁN HÀNG", "", 0, "C", false, 0, "")
+	pdf.Ln(15 * scale)
 
-	pdf.SetFont("NotoSans", "", 12)
+	pdf.SetFont("NotoSans", "", 12*scale)
 
 	// Customer info in two columns (50% each)
-	pdf.Cell(95, 6, fmt.Sprintf("Tên khách hàng: %s", invoice.CustomerName))
-	pdf.Cell(95, 6, fmt.Sprintf("Số điện thoại: %s", invoice.CustomerPhone))
-	pdf.Ln(6)
+	pdf.Cell(halfWidth, 6*scale, fmt.Sprintf("Tên khách hàng: %s", invoice.CustomerName))
+	pdf.Cell(halfWidth, 6*scale, fmt.Sprintf("Số điện thoại: %s", invoice.CustomerPhone))
+	pdf.Ln(6 * scale)
 
 	if invoice.CustomerAddress != nil && *invoice.CustomerAddress != "" {
-		pdf.Cell(40, 6, fmt.Sprintf("Địa chỉ: %s", *invoice.CustomerAddress))
-		pdf.Ln(6)
+		pdf.Cell(40, 6*scale, fmt.Sprintf("Địa chỉ: %s", *invoice.CustomerAddress))
+		pdf.Ln(6 * scale)
 	}
 
-	pdf.Cell(95, 6, fmt.Sprintf("Mã hoá đơn: %s", invoice.InvoiceCode))
-	pdf.Cell(95, 6, fmt.Sprintf("Ngày tạo đơn: %s", invoice.CreatedAt.Format("02/01/2006")))
-	pdf.Ln(6)
+	pdf.Cell(halfWidth, 6*scale, fmt.Sprintf("Mã hoá đơn: %s", invoice.InvoiceCode))
+	pdf.Cell(halfWidth, 6*scale, fmt.Sprintf("Ngày tạo đơn: %s", invoice.CreatedAt.Format("02/01/2006")))
+	pdf.Ln(6 * scale)
 
-	pdf.SetFont("NotoSans", "B", 10)
+	pdf.SetFont("NotoSans", "B", 10*scale)
 	pdf.SetTextColor(0, 0, 0)
-	pdf.Cell(0, 6, "Ghi chú:")
-	pdf.Ln(6)
+	pdf.Cell(0, 6*scale, "Ghi chú:")
+	pdf.Ln(6 * scale)
 	hasNotes := false
 	if invoice.Notes != nil && *invoice.Notes != "" {
-		pdf.SetFont("NotoSans", "", 10)
+		pdf.SetFont("NotoSans", "", 10*scale)
 		pdf.SetTextColor(100, 100, 100)
-		pdf.Cell(0, 6, *invoice.Notes)
-		pdf.Ln(10)
+		pdf.Cell(0, 6*scale, *invoice.Notes)
+		pdf.Ln(10 * scale)
 		hasNotes = true
 	}
 	if hasNotes {
-		pdf.Ln(12)
+		pdf.Ln(12 * scale)
 	} else {
-		pdf.Ln(6)
+		pdf.Ln(6 * scale)
 	}
 
 	// Table header with better colors - centered
-	pdf.SetFont("NotoSans", "B", 10)
+	pdf.SetFont("NotoSans", "B", 10*scale)
 	pdf.SetFillColor(52, 144, 220)  // Blue header
 	pdf.SetTextColor(255, 255, 255) // White text
 
-	// Column widths - first column takes remaining space
+	// Show a discount column only when some item has a promotion or line discount
+	hasLineDiscounts := false
+	for _, item := range invoice.Items {
+		if item.PromotionDiscount > 0 || item.DiscountAmount > 0 {
+			hasLineDiscounts = true
+			break
+		}
+	}
+
+	// Column widths for the 190mm of an A4 page, narrowed on smaller paper -
+	// first column takes remaining space
 	w2 := 40.0 // Variant
 	w3 := 20.0 // Quantity
 	w4 := 30.0 // Unit price
 	w5 := 30.0 // Total
+	wd := 0.0  // Discount
+	if hasLineDiscounts {
+		w2 = 30.0
+		wd = 25.0
+	}
+	ratio := fullWidth / 190
+	w2, w3, w4, w5, wd = w2*ratio, w3*ratio, w4*ratio, w5*ratio, wd*ratio
 
-	// Full width minus margins (210mm - 20mm margins = 190mm)
-	fullWidth := 190.0
-	w1 := fullWidth - w2 - w3 - w4 - w5 // Product name (remaining space)
+	w1 := fullWidth - w2 - w3 - w4 - wd - w5 // Product name (remaining space)
 
 	// Start from left margin (10mm)
 	startX := 10.0
 	pdf.SetX(startX)
 
-	pdf.CellFormat(w1, 10, "Sản phẩm", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(w2, 10, "Phân loại", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(w3, 10, "Số lượng", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(w4, 10, "Đơn giá", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(w5, 10, "Thành tiền", "1", 1, "C", true, 0, "")
+	pdf.CellFormat(w1, 10*scale, "Sản phẩm", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(w2, 10*scale, "Phân loại", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(w3, 10*scale, "Số lượng", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(w4, 10*scale, "Đơn giá", "1", 0, "C", true, 0, "")
+	if hasLineDiscounts {
+		pdf.CellFormat(wd, 10*scale, "Giảm giá", "1", 0, "C", true, 0, "")
+	}
+	pdf.CellFormat(w5, 10*scale, "Thành tiền", "1", 1, "C", true, 0, "")
 
 	// Table content with alternating row colors
-	pdf.SetFont("NotoSans", "", 9)
+	pdf.SetFont("NotoSans", "", 9*scale)
 	pdf.SetTextColor(0, 0, 0)
 
 	for _, item := range invoice.Items {
@@ -123,93 +166,448 @@ func (s *PDFService) GenerateInvoicePDF(invoice *models.Invoice) ([]byte, error)
 		pdf.SetX(startX) // Reset X position for each row
 
 		// Product name (with word wrap)
-		pdf.CellFormat(w1, 10, item.ProductName, "1", 0, "L", true, 0, "")
-		pdf.CellFormat(w2, 10, item.VariantName, "1", 0, "L", true, 0, "")
-		pdf.CellFormat(w3, 10, strconv.Itoa(int(item.Quantity)), "1", 0, "C", true, 0, "")
-		pdf.CellFormat(w4, 10, s.FormatCurrency(item.UnitPrice), "1", 0, "R", true, 0, "")
-		pdf.CellFormat(w5, 10, s.FormatCurrency(item.TotalPrice), "1", 1, "R", true, 0, "")
+		pdf.CellFormat(w1, 10*scale, item.ProductName, "1", 0, "L", true, 0, "")
+		pdf.CellFormat(w2, 10*scale, item.VariantName, "1", 0, "L", true, 0, "")
+		pdf.CellFormat(w3, 10*scale, strconv.Itoa(int(item.Quantity)), "1", 0, "C", true, 0, "")
+		pdf.CellFormat(w4, 10*scale, s.FormatCurrency(item.UnitPrice), "1", 0, "R", true, 0, "")
+		if hasLineDiscounts {
+			lineDiscount := ""
+			if item.PromotionDiscount+item.DiscountAmount > 0 {
+				lineDiscount = "-" + s.FormatCurrency(item.PromotionDiscount+item.DiscountAmount)
+			}
+			pdf.CellFormat(wd, 10*scale, lineDiscount, "1", 0, "R", true, 0, "")
+		}
+		pdf.CellFormat(w5, 10*scale, s.FormatCurrency(item.TotalPrice), "1", 1, "R", true, 0, "")
 	}
 
 	// Total row - single cell spanning all columns
-	pdf.SetFont("NotoSans", "B", 10)
+	pdf.SetFont("NotoSans", "B", 10*scale)
 	pdf.SetTextColor(0, 0, 0)
 	pdf.SetX(startX) // Reset X position for total row
 
 	// Calculate total width of all columns
-	totalWidth := w1 + w2 + w3 + w4 + w5
-	pdf.CellFormat(totalWidth, 10, fmt.Sprintf("Thành tiền: %s", s.FormatCurrency(invoice.TotalAmount)), "1", 1, "R", true, 0, "")
+	totalWidth := w1 + w2 + w3 + w4 + wd + w5
+	pdf.CellFormat(totalWidth, 10*scale, fmt.Sprintf("Thành tiền: %s", s.FormatCurrency(invoice.TotalAmount)), "1", 1, "R", true, 0, "")
 
-	pdf.Ln(8)
+	pdf.Ln(8 * scale)
 
 	// Summary section with better styling
-	pdf.SetFont("NotoSans", "", 11)
+	pdf.SetFont("NotoSans", "", 11*scale)
 	pdf.SetTextColor(0, 0, 0)
 
 	// Summary table without borders - full width
-	pdf.SetFont("NotoSans", "", 11)
+	pdf.SetFont("NotoSans", "", 11*scale)
 	pdf.SetTextColor(0, 0, 0)
 
 	// Use same full width as product table
-	summaryW1 := w1                // First column takes remaining space
-	summaryW2 := w2 + w3 + w4 + w5 // Second column takes fixed width columns
+	summaryW1 := w1                     // First column takes remaining space
+	summaryW2 := w2 + w3 + w4 + wd + w5 // Second column takes fixed width columns
 
 	pdf.SetX(startX)
 
 	// Discount row
 	if invoice.DiscountAmount > 0 {
-		pdf.CellFormat(summaryW1, 6, "Giảm giá:", "", 0, "L", false, 0, "")
-		pdf.CellFormat(summaryW2, 6, "-"+s.FormatCurrency(invoice.DiscountAmount), "", 1, "R", false, 0, "")
+		pdf.CellFormat(summaryW1, 6*scale, "Giảm giá:", "", 0, "L", false, 0, "")
+		pdf.CellFormat(summaryW2, 6*scale, "-"+s.FormatCurrency(invoice.DiscountAmount), "", 1, "R", false, 0, "")
+		pdf.SetX(startX) // Reset X position for next row
+	}
+
+	// VAT rows, one per rate
+	vatShown := false
+	for _, line := range invoice.VATSummary {
+		if line.TaxAmount <= 0 {
+			continue
+		}
+		label := fmt.Sprintf("Thuế GTGT %s%%:", strconv.FormatFloat(line.TaxRate, 'f', -1, 64))
+		if invoice.PriceMode == models.PriceModeInclusive {
+			label = fmt.Sprintf("Thuế GTGT %s%% (đã gồm trong giá):", strconv.FormatFloat(line.TaxRate, 'f', -1, 64))
+		}
+		pdf.CellFormat(summaryW1, 6*scale, label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(summaryW2, 6*scale, s.FormatCurrency(line.TaxAmount), "", 1, "R", false, 0, "")
+		pdf.SetX(startX) // Reset X position for next row
+		vatShown = true
+	}
+	if !vatShown && invoice.TaxAmount > 0 {
+		pdf.CellFormat(summaryW1, 6*scale, "Thuế:", "", 0, "L", false, 0, "")
+		pdf.CellFormat(summaryW2, 6*scale, s.FormatCurrency(invoice.TaxAmount), "", 1, "R", false, 0, "")
 		pdf.SetX(startX) // Reset X position for next row
 	}
 
 	// Total row with highlight
-	pdf.SetFont("NotoSans", "B", 14)
+	pdf.SetFont("NotoSans", "B", 14*scale)
 	pdf.SetTextColor(220, 38, 38) // Red color for total
-	pdf.CellFormat(summaryW1, 8, "TỔNG CỘNG:", "", 0, "L", false, 0, "")
-	pdf.CellFormat(summaryW2, 8, s.FormatCurrency(invoice.TotalAmount), "", 1, "R", false, 0, "")
+	pdf.CellFormat(summaryW1, 8*scale, "TỔNG CỘNG:", "", 0, "L", false, 0, "")
+	pdf.CellFormat(summaryW2, 8*scale, s.FormatCurrency(invoice.TotalAmount), "", 1, "R", false, 0, "")
 	pdf.SetX(startX) // Reset X position for next row
 
 	// Paid amount row
 	if invoice.PaidAmount > 0 {
-		pdf.SetFont("NotoSans", "", 11)
+		pdf.SetFont("NotoSans", "", 11*scale)
 		pdf.SetTextColor(0, 0, 0)
-		pdf.CellFormat(summaryW1, 6, "Đã thanh toán:", "", 0, "L", false, 0, "")
-		pdf.CellFormat(summaryW2, 6, s.FormatCurrency(invoice.PaidAmount), "", 1, "R", false, 0, "")
+		pdf.CellFormat(summaryW1, 6*scale, "Đã thanh toán:", "", 0, "L", false, 0, "")
+		pdf.CellFormat(summaryW2, 6*scale, s.FormatCurrency(invoice.PaidAmount), "", 1, "R", false, 0, "")
 		pdf.SetX(startX) // Reset X position for next row
 	}
 
 	// Remaining amount row
 	if invoice.PaymentStatus == "partial" {
 		remaining := invoice.TotalAmount - invoice.PaidAmount
-		pdf.SetFont("NotoSans", "B", 11)
+		pdf.SetFont("NotoSans", "B", 11*scale)
 		pdf.SetTextColor(220, 38, 38) // Red color for remaining
-		pdf.CellFormat(summaryW1, 6, "Còn lại:", "", 0, "L", false, 0, "")
-		pdf.CellFormat(summaryW2, 6, s.FormatCurrency(remaining), "", 1, "R", false, 0, "")
+		pdf.CellFormat(summaryW1, 6*scale, "Còn lại:", "", 0, "L", false, 0, "")
+		pdf.CellFormat(summaryW2, 6*scale, s.FormatCurrency(remaining), "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(15 * scale)
+
+	// Signatures sit near the bottom of the page, on a new page when the invoice is too long
+	signatureY := pageHeight - 67*scale
+	if pdf.GetY() > signatureY {
+		pdf.AddPage()
 	}
-	pdf.Ln(15)
 
-	pdf.SetY(230) // Position near bottom of A4 page
+	// VietQR code for paying the remainder by bank transfer
+	s.addPaymentQR(pdf, invoice, startX, signatureY, scale)
+
+	pdf.SetY(signatureY)
 
-	pdf.SetFont("NotoSans", "", 12)
+	pdf.SetFont("NotoSans", "", 12*scale)
 	pdf.SetTextColor(0, 0, 0)
 
 	// Date row with space between
-	pdf.CellFormat(0, 6, fmt.Sprintf("Ngày %s, Tháng %s, Năm %s", time.Now().Format("02"), time.Now().Format("01"), time.Now().Format("2006")), "", 0, "R", false, 0, "")
+	pdf.CellFormat(0, 6*scale, fmt.Sprintf("Ngày %s, Tháng %s, Năm %s", time.Now().Format("02"), time.Now().Format("01"), time.Now().Format("2006")), "", 0, "R", false, 0, "")
 
-	pdf.Ln(6)
+	pdf.Ln(6 * scale)
 
 	// Signature section with space between
 	pdf.SetTextColor(100, 100, 100)
-	pdf.SetFont("NotoSans", "B", 13)
-	pdf.CellFormat(95, 6, "Khách hàng", "", 0, "C", false, 0, "")
-	pdf.CellFormat(95, 6, "Người bán", "", 0, "C", false, 0, "")
+	pdf.SetFont("NotoSans", "B", 13*scale)
+	pdf.CellFormat(halfWidth, 6*scale, "Khách hàng", "", 0, "C", false, 0, "")
+	pdf.CellFormat(halfWidth, 6*scale, "Người bán", "", 0, "C", false, 0, "")
+
+	// Footer text of the store at the bottom of the page
+	if settings.FooterText != nil {
+		pdf.SetFont("NotoSans", "", 10*scale)
+		pdf.SetXY(10, pageHeight-20*scale)
+		pdf.MultiCell(fullWidth, 5*scale, *settings.FooterText, "", "C", false)
+	}
+
+	// Generate PDF bytes
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// addPaymentQR draws the VietQR code of an unpaid invoice above the signature section.
+// It is left out when no bank account is configured or the page has no room for it.
+func (s *PDFService) addPaymentQR(pdf *gofpdf.Fpdf, invoice *models.Invoice, x, signatureY, scale float64) {
+	qrSize := 35 * scale
+
+	if s.paymentQRService == nil || !s.paymentQRService.IsConfigured() {
+		return
+	}
+
+	y := pdf.GetY()
+	if y+qrSize > signatureY {
+		return
+	}
+
+	qr, err := s.paymentQRService.GetInvoicePaymentQR(invoice)
+	if err != nil {
+		// Paid and cancelled invoices have no QR code
+		return
+	}
+
+	png, err := vietqr.PNG(qr.Payload, 512)
+	if err != nil {
+		return
+	}
+
+	imageName := "vietqr-" + invoice.InvoiceCode
+	pdf.RegisterImageOptionsReader(imageName, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+	pdf.ImageOptions(imageName, x, y, qrSize, qrSize, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+	// Transfer details next to the code
+	textX := x + qrSize + 5
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("NotoSans", "B", 11*scale)
+	pdf.SetXY(textX, y+3*scale)
+	pdf.CellFormat(0, 6*scale, "Quét mã để chuyển khoản", "", 1, "L", false, 0, "")
+
+	pdf.SetFont("NotoSans", "", 10*scale)
+	lines := []string{
+		fmt.Sprintf("Ngân hàng: %s", qr.BankName),
+		fmt.Sprintf("Số tài khoản: %s", qr.AccountNumber),
+		fmt.Sprintf("Chủ tài khoản: %s", qr.AccountName),
+		fmt.Sprintf("Số tiền: %s", s.FormatCurrency(qr.Amount)),
+		fmt.Sprintf("Nội dung: %s", qr.Reference),
+	}
+	for _, line := range lines {
+		pdf.SetX(textX)
+		pdf.CellFormat(0, 5*scale, line, "", 1, "L", false, 0, "")
+	}
+
+	pdf.SetY(y + qrSize + 5)
+}
+
+// addStoreHeader draws the store profile at the top of an invoice, with the logo on the left
+func (s *PDFService) addStoreHeader(pdf *gofpdf.Fpdf, settings *models.StoreSettings, fullWidth, scale float64) {
+	left, top := 10.0, pdf.GetY()
+	textX, textWidth := left, fullWidth
+
+	if settings.HasLogo {
+		logo, err := s.storeSettingsService.GetLogo()
+		if err == nil && logo != nil {
+			imageType := strings.TrimPrefix(logo.ContentType, "image/")
+			info := pdf.RegisterImageOptionsReader("store-logo", gofpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(logo.Data))
+			if info != nil && pdf.Ok() {
+				logoHeight := 22 * scale
+				logoWidth := logoHeight * info.Width() / info.Height()
+				pdf.ImageOptions("store-logo", left, top, logoWidth, logoHeight, false, gofpdf.ImageOptions{ImageType: imageType}, 0, "")
+
+				// Keep the text centered on the page, clear of the logo
+				textX = left + logoWidth + 2
+				textWidth = fullWidth - 2*(logoWidth+2)
+			}
+			// A logo that cannot be read is left out rather than failing the invoice
+			pdf.ClearError()
+		}
+	}
+
+	line := func(text string) {
+		pdf.SetX(textX)
+		pdf.MultiCell(textWidth, 6*scale, text, "", "C", false)
+	}
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("NotoSans", "B", 22*scale)
+	pdf.SetX(textX)
+	pdf.MultiCell(textWidth, 12*scale, settings.Name, "", "C", false)
+
+	pdf.SetFont("NotoSans", "", 12*scale)
+	if settings.Address != nil {
+		line("Địa chỉ: " + *settings.Address)
+	}
+	if len(settings.Phones) > 0 {
+		line("Điện thoại: " + strings.Join(settings.Phones, " - "))
+	}
+	if settings.TaxCode != nil {
+		line("Mã số thuế: " + *settings.TaxCode)
+	}
+	for _, account := range settings.BankAccounts {
+		text := fmt.Sprintf("STK: %s - %s - %s", account.AccountNumber, account.BankName, account.AccountName)
+		if account.Branch != nil && *account.Branch != "" {
+			text += " (" + *account.Branch + ")"
+		}
+		line(text)
+	}
+
+	if pdf.GetY() < top+22*scale {
+		pdf.SetY(top + 22*scale)
+	}
+	pdf.Ln(2 * scale)
+}
+
+// generateReceiptInvoicePDF prints an invoice on an 80mm roll, the same receipt the thermal
+// printer gets, as a single page as long as the receipt
+func (s *PDFService) generateReceiptInvoicePDF(invoice *models.Invoice) ([]byte, error) {
+	const paperWidth = 80.0
+	const margin = 4.0
+
+	img, err := s.receiptService.RenderInvoiceReceipt(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	var receipt bytes.Buffer
+	if err := png.Encode(&receipt, img); err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	imageWidth := paperWidth - 2*margin
+	imageHeight := imageWidth * float64(bounds.Dy()) / float64(bounds.Dx())
+
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: paperWidth, Ht: imageHeight + 2*margin},
+	})
+	pdf.AddPage()
+
+	imageName := "receipt-" + invoice.InvoiceCode
+	pdf.RegisterImageOptionsReader(imageName, gofpdf.ImageOptions{ImageType: "PNG"}, &receipt)
+	pdf.ImageOptions(imageName, margin, margin, imageWidth, imageHeight, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+	// Generate PDF bytes
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GenerateZReportPDF generates a PDF for a shift or end-of-day Z report
+func (s *PDFService) GenerateZReportPDF(report *models.ZReport) ([]byte, error) {
+	settings, err := s.storeSettingsService.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+
+	// Add Noto Sans font that supports Vietnamese
+	pdf.AddUTF8Font("NotoSans", "", "fonts/NotoSans-Regular.ttf")
+	pdf.AddUTF8Font("NotoSans", "B", "fonts/NotoSans-Bold.ttf")
+
+	pdf.AddPage()
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.SetTextColor(0, 0, 0)
+
+	pdf.SetFont("NotoSans", "B", 18)
+	pdf.CellFormat(0, 10, settings.Name, "", 1, "C", false, 0, "")
+
+	pdf.SetFont("NotoSans", "B", 16)
+	pdf.CellFormat(0, 10, report.Title, "", 1, "C", false, 0, "")
+
+	pdf.SetFont("NotoSans", "", 11)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Từ %s đến %s", report.From.Format("15:04 02/01/2006"), report.To.Format("15:04 02/01/2006")), "", 1, "C", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("In lúc %s", report.GeneratedAt.Format("15:04 02/01/2006")), "", 1, "C", false, 0, "")
 	pdf.Ln(6)
 
-	pdf.Ln(20)
+	labelW := 120.0
+	valueW := 70.0
+	row := func(label, value string) {
+		pdf.CellFormat(labelW, 7, label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(valueW, 7, value, "", 1, "R", false, 0, "")
+	}
+	section := func(title string) {
+		pdf.Ln(3)
+		pdf.SetFont("NotoSans", "B", 12)
+		pdf.CellFormat(labelW+valueW, 8, title, "B", 1, "L", false, 0, "")
+		pdf.SetFont("NotoSans", "", 11)
+	}
+
+	// Sales
+	section("Doanh số")
+	row("Số hoá đơn:", strconv.Itoa(report.Sales.InvoiceCount))
+	if report.Sales.CancelledCount > 0 {
+		row("Số hoá đơn đã huỷ:", strconv.Itoa(report.Sales.CancelledCount))
+	}
+	row("Tạm tính:", s.FormatCurrency(report.Sales.Subtotal))
+	if report.Sales.DiscountAmount > 0 {
+		row("Giảm giá:", "-"+s.FormatCurrency(report.Sales.DiscountAmount))
+	}
+	if report.Sales.TaxAmount > 0 {
+		row("Thuế:", s.FormatCurrency(report.Sales.TaxAmount))
+	}
+	pdf.SetFont("NotoSans", "B", 11)
+	row("Tổng doanh số:", s.FormatCurrency(report.Sales.TotalAmount))
+	pdf.SetFont("NotoSans", "", 11)
+
+	// Payments
+	section("Thanh toán theo phương thức")
+	totalPayments := 0.0
+	for _, payment := range report.PaymentsByMethod {
+		row(fmt.Sprintf("%s (%d):", payment.Name, payment.Count), s.FormatCurrency(payment.Amount))
+		totalPayments += payment.Amount
+	}
+	pdf.SetFont("NotoSans", "B", 11)
+	row("Tổng thu:", s.FormatCurrency(totalPayments))
+	pdf.SetFont("NotoSans", "", 11)
+
+	// Cash drawer
+	section("Két tiền mặt")
+	row("Tiền đầu ca:", s.FormatCurrency(report.OpeningFloat))
+	row("Thu tiền mặt:", s.FormatCurrency(report.CashPayments))
+	row("Nộp thêm vào két:", s.FormatCurrency(report.CashIn))
+	row("Rút ra khỏi két:", "-"+s.FormatCurrency(report.CashOut))
+	pdf.SetFont("NotoSans", "B", 11)
+	row("Tiền mặt dự kiến:", s.FormatCurrency(report.ExpectedCash))
+	if report.CountedCash != nil {
+		row("Tiền mặt thực đếm:", s.FormatCurrency(*report.CountedCash))
+	}
+	if report.CashDifference != nil {
+		if *report.CashDifference < 0 {
+			pdf.SetTextColor(220, 38, 38) // Red when cash is short
+		}
+		row("Chênh lệch:", s.FormatCurrency(*report.CashDifference))
+		pdf.SetTextColor(0, 0, 0)
+	}
+	pdf.SetFont("NotoSans", "", 11)
+
+	// Shifts
+	if len(report.Shifts) > 0 {
+		section("Ca làm việc")
+		pdf.SetFont("NotoSans", "B", 10)
+		pdf.CellFormat(15, 8, "Ca", "1", 0, "C", false, 0, "")
+		pdf.CellFormat(45, 8, "Thu ngân", "1", 0, "C", false, 0, "")
+		pdf.CellFormat(40, 8, "Thời gian", "1", 0, "C", false, 0, "")
+		pdf.CellFormat(30, 8, "Dự kiến", "1", 0, "C", false, 0, "")
+		pdf.CellFormat(30, 8, "Thực đếm", "1", 0, "C", false, 0, "")
+		pdf.CellFormat(30, 8, "Chênh lệch", "1", 1, "C", false, 0, "")
+
+		pdf.SetFont("NotoSans", "", 9)
+		for _, shift := range report.Shifts {
+			period := shift.OpenedAt.Format("15:04") + " - "
+			expected, counted, difference := "", "", ""
+			if shift.ClosedAt != nil {
+				period += shift.ClosedAt.Format("15:04")
+			} else {
+				period += "đang mở"
+			}
+			if shift.ExpectedCash != nil {
+				expected = s.FormatCurrency(*shift.ExpectedCash)
+			}
+			if shift.CountedCash != nil {
+				counted = s.FormatCurrency(*shift.CountedCash)
+			}
+			if shift.CashDifference != nil {
+				difference = s.FormatCurrency(*shift.CashDifference)
+			}
+
+			pdf.CellFormat(15, 8, strconv.Itoa(shift.ID), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(45, 8, shift.CashierName, "1", 0, "L", false, 0, "")
+			pdf.CellFormat(40, 8, period, "1", 0, "C", false, 0, "")
+			pdf.CellFormat(30, 8, expected, "1", 0, "R", false, 0, "")
+			pdf.CellFormat(30, 8, counted, "1", 0, "R", false, 0, "")
+			pdf.CellFormat(30, 8, difference, "1", 1, "R", false, 0, "")
+		}
+	}
+
+	// Cash movements
+	var movements []*models.CashMovement
+	for _, shift := range report.Shifts {
+		movements = append(movements, shift.Movements...)
+	}
+	if len(movements) > 0 {
+		section("Thu chi ngoài bán hàng")
+		pdf.SetFont("NotoSans", "", 9)
+		for _, movement := range movements {
+			amount := s.FormatCurrency(movement.Amount)
+			if movement.MovementType == models.CashMovementOut {
+				amount = "-" + amount
+			}
+			pdf.CellFormat(25, 7, movement.CreatedAt.Format("15:04 02/01"), "", 0, "L", false, 0, "")
+			pdf.CellFormat(labelW-25, 7, movement.Reason, "", 0, "L", false, 0, "")
+			pdf.CellFormat(valueW, 7, amount, "", 1, "R", false, 0, "")
+		}
+	}
+
+	// Signatures
+	pdf.Ln(12)
+	pdf.SetFont("NotoSans", "B", 12)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.CellFormat(95, 6, "Thu ngân", "", 0, "C", false, 0, "")
+	pdf.CellFormat(95, 6, "Quản lý", "", 1, "C", false, 0, "")
 
 	// Generate PDF bytes
 	var buf bytes.Buffer
-	err := pdf.Output(&buf)
+	err = pdf.Output(&buf)
 	if err != nil {
 		return nil, err
 	}
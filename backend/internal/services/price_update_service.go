@@ -0,0 +1,257 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/repository"
+)
+
+type PriceUpdateService struct {
+	priceUpdateRepo *repository.PriceUpdateRepository
+	auditLogService AuditLogService
+}
+
+func NewPriceUpdateService(priceUpdateRepo *repository.PriceUpdateRepository, auditLogService AuditLogService) *PriceUpdateService {
+	return &PriceUpdateService{
+		priceUpdateRepo: priceUpdateRepo,
+		auditLogService: auditLogService,
+	}
+}
+
+// PreviewPriceUpdate computes the new price of every matching variant without saving anything
+func (s *PriceUpdateService) PreviewPriceUpdate(req *models.BulkPriceUpdateRequest) (*models.BulkPriceUpdatePreview, error) {
+	items, err := s.computeItems(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BulkPriceUpdatePreview{
+		Items: items,
+		Total: len(items),
+	}, nil
+}
+
+// ApplyPriceUpdate applies a bulk price update as one batch and records it in the audit log
+func (s *PriceUpdateService) ApplyPriceUpdate(req *models.BulkPriceUpdateRequest, createdBy int, createdByName string) (*models.PriceUpdateBatch, error) {
+	items, err := s.computeItems(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only variants whose price actually changes are part of the batch
+	var changed []*models.PriceUpdateItem
+	for _, item := range items {
+		if item.NewPrice != item.OldPrice {
+			changed = append(changed, item)
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil, errors.New("no variant prices would change")
+	}
+
+	filters, err := filterToJSONB(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &models.PriceUpdateBatch{
+		AdjustmentType:  req.AdjustmentType,
+		AdjustmentValue: req.AdjustmentValue,
+		RoundTo:         req.RoundTo,
+		Filters:         filters,
+		Reason:          req.Reason,
+		VariantCount:    len(changed),
+		Status:          models.PriceUpdateBatchStatusApplied,
+		CreatedBy:       &createdBy,
+		CreatedByName:   &createdByName,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	err = s.priceUpdateRepo.ApplyBatch(batch, changed)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf("Bulk price update (%s %.2f) applied to %d variant(s)", batch.AdjustmentType, batch.AdjustmentValue, batch.VariantCount)
+	s.logBatchChange(batch, "created", nil, summary, createdBy, createdByName)
+
+	return s.GetPriceUpdateBatch(batch.ID)
+}
+
+// RollbackPriceUpdate restores the prices changed by a batch
+func (s *PriceUpdateService) RollbackPriceUpdate(batchID int, rolledBackBy int, rolledBackByName string) (*models.PriceUpdateRollbackResult, error) {
+	batch, err := s.priceUpdateRepo.GetBatchByID(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if batch == nil {
+		return nil, errors.New("price update batch not found")
+	}
+
+	if batch.Status != models.PriceUpdateBatchStatusApplied {
+		return nil, errors.New("price update batch has already been rolled back")
+	}
+
+	restored, skipped, err := s.priceUpdateRepo.RollbackBatch(batch, rolledBackBy, rolledBackByName)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedBatch, err := s.GetPriceUpdateBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf("Rolled back price update batch: %d restored, %d skipped", restored, len(skipped))
+	s.logBatchChange(updatedBatch, "updated", batch, summary, rolledBackBy, rolledBackByName)
+
+	return &models.PriceUpdateRollbackResult{
+		Batch:      updatedBatch,
+		Restored:   restored,
+		SkippedIDs: skipped,
+	}, nil
+}
+
+// GetPriceUpdateBatch gets a price update batch with its price changes
+func (s *PriceUpdateService) GetPriceUpdateBatch(id int) (*models.PriceUpdateBatch, error) {
+	batch, err := s.priceUpdateRepo.GetBatchByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if batch == nil {
+		return nil, errors.New("price update batch not found")
+	}
+
+	changes, err := s.priceUpdateRepo.GetBatchChanges(id)
+	if err != nil {
+		return nil, err
+	}
+	batch.Changes = changes
+
+	return batch, nil
+}
+
+// GetPriceUpdateBatches gets price update batches with pagination
+func (s *PriceUpdateService) GetPriceUpdateBatches(page, limit int) (*models.PriceUpdateBatchListResponse, error) {
+	offset := (page - 1) * limit
+
+	batches, err := s.priceUpdateRepo.GetBatches(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.priceUpdateRepo.CountBatches()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PriceUpdateBatchListResponse{
+		Batches: batches,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	}, nil
+}
+
+// Helper methods
+func (s *PriceUpdateService) computeItems(req *models.BulkPriceUpdateRequest) ([]*models.PriceUpdateItem, error) {
+	if req.Filter.IsEmpty() {
+		return nil, errors.New("at least one filter is required for a bulk price update")
+	}
+
+	items, err := s.priceUpdateRepo.FindVariants(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("no variants match the filter")
+	}
+
+	for _, item := range items {
+		newPrice := item.OldPrice
+		switch req.AdjustmentType {
+		case models.PriceAdjustmentPercentage:
+			newPrice = item.OldPrice * (1 + req.AdjustmentValue/100)
+		case models.PriceAdjustmentFixed:
+			newPrice = item.OldPrice + req.AdjustmentValue
+		default:
+			return nil, errors.New("invalid adjustment type")
+		}
+
+		if req.RoundTo > 0 {
+			newPrice = math.Round(newPrice/req.RoundTo) * req.RoundTo
+		}
+		newPrice = math.Round(newPrice*100) / 100
+
+		if newPrice < 0 {
+			return nil, fmt.Errorf("new price of variant %s would be negative", item.SKU)
+		}
+
+		item.NewPrice = newPrice
+	}
+
+	return items, nil
+}
+
+func (s *PriceUpdateService) logBatchChange(batch *models.PriceUpdateBatch, action string, oldBatch *models.PriceUpdateBatch, summary string, userID int, userName string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	req := models.AuditLogCreateRequest{
+		EntityType:     "price_update_batch",
+		EntityID:       batch.ID,
+		Action:         action,
+		UserID:         &userID,
+		UserName:       &userName,
+		OldData:        batchToMap(oldBatch),
+		NewData:        batchToMap(batch),
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the price update
+		log.Printf("Failed to create audit log for price update batch %d: %v", batch.ID, err)
+	}
+}
+
+func batchToMap(batch *models.PriceUpdateBatch) map[string]interface{} {
+	if batch == nil {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"adjustment_type":  batch.AdjustmentType,
+		"adjustment_value": batch.AdjustmentValue,
+		"round_to":         batch.RoundTo,
+		"filters":          map[string]interface{}(batch.Filters),
+		"reason":           batch.Reason,
+		"variant_count":    batch.VariantCount,
+		"status":           batch.Status,
+	}
+}
+
+func filterToJSONB(filter models.PriceUpdateFilter) (models.JSONB, error) {
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.JSONB
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
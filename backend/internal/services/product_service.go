@@ -9,21 +9,34 @@ import (
 )
 
 type ProductService struct {
-	productRepo *repository.ProductRepository
+	productRepo      *repository.ProductRepository
+	priceHistoryRepo *repository.PriceHistoryRepository
+	branchRepo       *repository.BranchRepository
 }
 
-func NewProductService(productRepo *repository.ProductRepository) *ProductService {
+func NewProductService(productRepo *repository.ProductRepository, priceHistoryRepo *repository.PriceHistoryRepository, branchRepo *repository.BranchRepository) *ProductService {
 	return &ProductService{
-		productRepo: productRepo,
+		productRepo:      productRepo,
+		priceHistoryRepo: priceHistoryRepo,
+		branchRepo:       branchRepo,
 	}
 }
 
 // Product methods
-func (s *ProductService) CreateProduct(req *models.CreateProductRequest, createdBy int, createdByName string) (*models.Product, error) {
+
+// CreateProduct creates a product with its variants; the stock of the variants is put at the branch
+func (s *ProductService) CreateProduct(req *models.CreateProductRequest, createdBy int, createdByName string, branchID int) (*models.Product, error) {
+	for _, variantReq := range req.Variants {
+		if variantReq.Stock != 0 && branchID == 0 {
+			return nil, errBranchRequiredForStock
+		}
+	}
+
 	// Create product
 	product := &models.Product{
 		Name:          req.Name,
 		CategoryID:    req.CategoryID,
+		TaxCategoryID: req.TaxCategoryID,
 		Unit:          req.Unit,
 		Notes:         req.Notes,
 		IsActive:      true,
@@ -59,6 +72,12 @@ func (s *ProductService) CreateProduct(req *models.CreateProductRequest, created
 			if err != nil {
 				return nil, err
 			}
+
+			if variant.Stock != 0 {
+				if err := s.setBranchStock(branchID, variant, variant.Stock); err != nil {
+					return nil, err
+				}
+			}
 		}
 	}
 
@@ -106,7 +125,8 @@ func (s *ProductService) GetAllProducts(page, limit int, search string) (*models
 	}, nil
 }
 
-func (s *ProductService) UpdateProduct(id int, req *models.UpdateProductRequest, updatedBy int) (*models.Product, error) {
+// UpdateProduct updates a product and its variants; stock given for a variant is its stock at the branch
+func (s *ProductService) UpdateProduct(id int, req *models.UpdateProductRequest, updatedBy int, updatedByName string, branchID int) (*models.Product, error) {
 	product, err := s.productRepo.GetByID(id)
 	if err != nil {
 		return nil, err
@@ -123,6 +143,9 @@ func (s *ProductService) UpdateProduct(id int, req *models.UpdateProductRequest,
 	if req.CategoryID != nil {
 		product.CategoryID = req.CategoryID
 	}
+	if req.TaxCategoryID != nil {
+		product.TaxCategoryID = req.TaxCategoryID
+	}
 	if req.Unit != "" {
 		product.Unit = req.Unit
 	}
@@ -160,6 +183,7 @@ func (s *ProductService) UpdateProduct(id int, req *models.UpdateProductRequest,
 				if existingVariant == nil {
 					return nil, errors.New("variant not found")
 				}
+				oldPrice := existingVariant.Price
 
 				// Update fields if provided
 				if variantReq.Name != "" {
@@ -168,9 +192,6 @@ func (s *ProductService) UpdateProduct(id int, req *models.UpdateProductRequest,
 				if variantReq.SKU != "" {
 					existingVariant.SKU = variantReq.SKU
 				}
-				if variantReq.Stock != nil {
-					existingVariant.Stock = *variantReq.Stock
-				}
 				if variantReq.Price != nil {
 					existingVariant.Price = *variantReq.Price
 				}
@@ -187,6 +208,17 @@ func (s *ProductService) UpdateProduct(id int, req *models.UpdateProductRequest,
 				if err != nil {
 					return nil, err
 				}
+
+				if variantReq.Stock != nil {
+					if err := s.setBranchStock(branchID, existingVariant, *variantReq.Stock); err != nil {
+						return nil, err
+					}
+				}
+
+				err = s.recordPriceChange(existingVariant, oldPrice, variantReq.PriceReason, models.PriceChangeSourceManual, nil, &updatedBy, &updatedByName)
+				if err != nil {
+					return nil, err
+				}
 			} else {
 				// Create new variant
 				variant := &models.ProductVariant{
@@ -206,6 +238,12 @@ func (s *ProductService) UpdateProduct(id int, req *models.UpdateProductRequest,
 				if err != nil {
 					return nil, err
 				}
+
+				if variant.Stock != 0 {
+					if err := s.setBranchStock(branchID, variant, variant.Stock); err != nil {
+						return nil, err
+					}
+				}
 			}
 		}
 	}
@@ -219,7 +257,13 @@ func (s *ProductService) DeleteProduct(id int) error {
 }
 
 // ProductVariant methods
-func (s *ProductService) CreateVariant(productID int, req *models.CreateProductVariantRequest, createdBy int) (*models.ProductVariant, error) {
+
+// CreateVariant creates a variant of a product; its stock is put at the branch
+func (s *ProductService) CreateVariant(productID int, req *models.CreateProductVariantRequest, createdBy int, branchID int) (*models.ProductVariant, error) {
+	if req.Stock != 0 && branchID == 0 {
+		return nil, errBranchRequiredForStock
+	}
+
 	// Check if product exists
 	product, err := s.productRepo.GetByID(productID)
 	if err != nil {
@@ -248,6 +292,12 @@ func (s *ProductService) CreateVariant(productID int, req *models.CreateProductV
 		return nil, err
 	}
 
+	if variant.Stock != 0 {
+		if err := s.setBranchStock(branchID, variant, variant.Stock); err != nil {
+			return nil, err
+		}
+	}
+
 	return variant, nil
 }
 
@@ -278,7 +328,8 @@ func (s *ProductService) GetVariantsByProductID(productID int) ([]*models.Produc
 	return s.productRepo.GetVariantsByProductID(productID)
 }
 
-func (s *ProductService) UpdateVariant(id int, req *models.UpdateProductVariantRequest, updatedBy int) (*models.ProductVariant, error) {
+// UpdateVariant updates a variant; stock given is its stock at the branch
+func (s *ProductService) UpdateVariant(id int, req *models.UpdateProductVariantRequest, updatedBy int, updatedByName string, branchID int) (*models.ProductVariant, error) {
 	variant, err := s.productRepo.GetVariantByID(id)
 	if err != nil {
 		return nil, err
@@ -287,6 +338,7 @@ func (s *ProductService) UpdateVariant(id int, req *models.UpdateProductVariantR
 	if variant == nil {
 		return nil, errors.New("variant not found")
 	}
+	oldPrice := variant.Price
 
 	// Update fields if provided
 	if req.Name != "" {
@@ -295,9 +347,6 @@ func (s *ProductService) UpdateVariant(id int, req *models.UpdateProductVariantR
 	if req.SKU != "" {
 		variant.SKU = req.SKU
 	}
-	if req.Stock != nil {
-		variant.Stock = *req.Stock
-	}
 	if req.Price != nil {
 		variant.Price = *req.Price
 	}
@@ -315,6 +364,17 @@ func (s *ProductService) UpdateVariant(id int, req *models.UpdateProductVariantR
 		return nil, err
 	}
 
+	if req.Stock != nil {
+		if err := s.setBranchStock(branchID, variant, *req.Stock); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.recordPriceChange(variant, oldPrice, req.PriceReason, models.PriceChangeSourceManual, nil, &updatedBy, &updatedByName)
+	if err != nil {
+		return nil, err
+	}
+
 	return variant, nil
 }
 
@@ -322,8 +382,20 @@ func (s *ProductService) DeleteVariant(id int) error {
 	return s.productRepo.DeleteVariant(id)
 }
 
-func (s *ProductService) UpdateStock(variantID int, quantity int) error {
-	return s.productRepo.UpdateStock(variantID, quantity)
+// UpdateStock adds quantity (negative to remove) to the stock of a variant at a branch
+func (s *ProductService) UpdateStock(variantID int, branchID int, quantity int) error {
+	if branchID == 0 {
+		return errBranchRequiredForStock
+	}
+	return s.branchRepo.AdjustStock(branchID, variantID, quantity)
+}
+
+// GetVariantBranchStock gets the stock of a variant at each branch
+func (s *ProductService) GetVariantBranchStock(variantID int) ([]*models.BranchStock, error) {
+	if _, err := s.GetVariantByID(variantID); err != nil {
+		return nil, err
+	}
+	return s.branchRepo.GetVariantStock(variantID)
 }
 
 // SearchProductsHybrid searches products using hybrid approach (ILIKE + full-text search)
@@ -430,3 +502,170 @@ func (s *ProductService) SearchProductsForImportOrder(query string, limit int) (
 
 	return results, nil
 }
+
+// Price history methods
+
+// GetVariantPriceHistory gets the price change history of a variant, newest first
+func (s *ProductService) GetVariantPriceHistory(variantID int, page, limit int) (*models.VariantPriceHistoryListResponse, error) {
+	variant, err := s.productRepo.GetVariantByID(variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if variant == nil {
+		return nil, errors.New("variant not found")
+	}
+
+	offset := (page - 1) * limit
+
+	history, err := s.priceHistoryRepo.GetByVariantID(variantID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.priceHistoryRepo.CountByVariantID(variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.VariantPriceHistoryListResponse{
+		History: history,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	}, nil
+}
+
+// SchedulePriceChange schedules a variant price change that takes effect at the given date
+func (s *ProductService) SchedulePriceChange(variantID int, req *models.CreateScheduledPriceChangeRequest, createdBy int, createdByName string) (*models.ScheduledPriceChange, error) {
+	variant, err := s.productRepo.GetVariantByID(variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if variant == nil {
+		return nil, errors.New("variant not found")
+	}
+
+	if !req.EffectiveAt.After(time.Now()) {
+		return nil, errors.New("effective_at must be in the future")
+	}
+
+	change := &models.ScheduledPriceChange{
+		VariantID:     variantID,
+		NewPrice:      req.NewPrice,
+		EffectiveAt:   req.EffectiveAt,
+		Reason:        req.Reason,
+		Status:        models.ScheduledPriceStatusPending,
+		CreatedBy:     &createdBy,
+		CreatedByName: &createdByName,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	err = s.priceHistoryRepo.CreateScheduledChange(change)
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// GetScheduledPriceChanges gets scheduled price changes of a variant, optionally filtered by status
+func (s *ProductService) GetScheduledPriceChanges(variantID int, status string) ([]*models.ScheduledPriceChange, error) {
+	return s.priceHistoryRepo.GetScheduledChangesByVariantID(variantID, status)
+}
+
+// CancelScheduledPriceChange cancels a pending scheduled price change
+func (s *ProductService) CancelScheduledPriceChange(variantID, changeID int) error {
+	change, err := s.priceHistoryRepo.GetScheduledChangeByID(changeID)
+	if err != nil {
+		return err
+	}
+
+	if change == nil || change.VariantID != variantID {
+		return errors.New("scheduled price change not found")
+	}
+
+	if change.Status != models.ScheduledPriceStatusPending {
+		return errors.New("only pending scheduled price changes can be cancelled")
+	}
+
+	return s.priceHistoryRepo.UpdateScheduledChangeStatus(changeID, models.ScheduledPriceStatusCancelled, nil)
+}
+
+// ApplyDueScheduledPriceChanges applies every pending scheduled price change whose
+// effective date has passed and returns the number of changes applied
+func (s *ProductService) ApplyDueScheduledPriceChanges() (int, error) {
+	now := time.Now()
+
+	changes, err := s.priceHistoryRepo.GetDueScheduledChanges(now)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, change := range changes {
+		ok, err := s.priceHistoryRepo.ApplyScheduledChange(change.ID, now)
+		if err != nil {
+			return applied, err
+		}
+
+		if ok {
+			applied++
+		}
+	}
+
+	return applied, nil
+}
+
+// errBranchRequiredForStock is returned when stock is set by a user without a branch
+var errBranchRequiredForStock = errors.New("stock can only be set at a branch")
+
+// setBranchStock sets the stock of a variant at a branch and refreshes its total stock, which is
+// the sum over all branches
+func (s *ProductService) setBranchStock(branchID int, variant *models.ProductVariant, stock int) error {
+	if branchID == 0 {
+		return errBranchRequiredForStock
+	}
+
+	if stock < 0 {
+		return errors.New("stock cannot be negative")
+	}
+
+	if err := s.branchRepo.SetStock(branchID, variant.ID, stock); err != nil {
+		return err
+	}
+
+	updated, err := s.productRepo.GetVariantByID(variant.ID)
+	if err != nil {
+		return err
+	}
+	if updated != nil {
+		variant.Stock = updated.Stock
+	}
+
+	return nil
+}
+
+// recordPriceChange writes a price history entry when the variant price differs from oldPrice
+func (s *ProductService) recordPriceChange(variant *models.ProductVariant, oldPrice float64, reason *string, source string, scheduledChangeID *int, changedBy *int, changedByName *string) error {
+	if variant.Price == oldPrice {
+		return nil
+	}
+
+	history := &models.VariantPriceHistory{
+		VariantID:         variant.ID,
+		ProductID:         variant.ProductID,
+		OldPrice:          oldPrice,
+		NewPrice:          variant.Price,
+		Reason:            reason,
+		Source:            source,
+		ScheduledChangeID: scheduledChangeID,
+		ChangedBy:         changedBy,
+		ChangedByName:     changedByName,
+		ChangedAt:         time.Now(),
+	}
+
+	return s.priceHistoryRepo.Create(history)
+}
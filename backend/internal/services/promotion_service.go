@@ -0,0 +1,463 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/repository"
+)
+
+type PromotionService struct {
+	promotionRepo   *repository.PromotionRepository
+	roleService     *RoleService
+	auditLogService AuditLogService
+}
+
+func NewPromotionService(promotionRepo *repository.PromotionRepository, roleService *RoleService, auditLogService AuditLogService) *PromotionService {
+	return &PromotionService{
+		promotionRepo:   promotionRepo,
+		roleService:     roleService,
+		auditLogService: auditLogService,
+	}
+}
+
+// Promotion methods
+func (s *PromotionService) CreatePromotion(req *models.CreatePromotionRequest, createdBy int, createdByName string) (*models.Promotion, error) {
+	promotion := &models.Promotion{
+		Name:               strings.TrimSpace(req.Name),
+		Description:        req.Description,
+		PromotionType:      req.PromotionType,
+		CategoryID:         req.CategoryID,
+		ProductID:          req.ProductID,
+		VariantID:          req.VariantID,
+		DiscountPercentage: req.DiscountPercentage,
+		BuyQuantity:        req.BuyQuantity,
+		GetQuantity:        req.GetQuantity,
+		MinQuantity:        req.MinQuantity,
+		Unit:               req.Unit,
+		StartsAt:           req.StartsAt,
+		EndsAt:             req.EndsAt,
+		IsActive:           true,
+		CreatedBy:          &createdBy,
+		CreatedByName:      &createdByName,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+
+	if err := validatePromotion(promotion); err != nil {
+		return nil, err
+	}
+
+	if err := s.promotionRepo.Create(promotion); err != nil {
+		return nil, err
+	}
+
+	s.logPromotionChange(promotion, "created", nil, createdBy, createdByName)
+
+	return promotion, nil
+}
+
+func (s *PromotionService) GetPromotion(id int) (*models.Promotion, error) {
+	promotion, err := s.promotionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if promotion == nil {
+		return nil, errors.New("promotion not found")
+	}
+
+	return promotion, nil
+}
+
+func (s *PromotionService) GetPromotions(page, limit int, activeOnly bool) (*models.PromotionListResponse, error) {
+	offset := (page - 1) * limit
+
+	promotions, err := s.promotionRepo.GetAll(limit, offset, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.promotionRepo.Count(activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PromotionListResponse{
+		Promotions: promotions,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+	}, nil
+}
+
+func (s *PromotionService) UpdatePromotion(id int, req *models.UpdatePromotionRequest, updatedBy int, updatedByName string) (*models.Promotion, error) {
+	oldPromotion, err := s.GetPromotion(id)
+	if err != nil {
+		return nil, err
+	}
+
+	promotion := *oldPromotion
+
+	updatePromotionFields(&promotion, req)
+
+	if err := validatePromotion(&promotion); err != nil {
+		return nil, err
+	}
+
+	promotion.UpdatedAt = time.Now()
+
+	if err := s.promotionRepo.Update(&promotion); err != nil {
+		return nil, err
+	}
+
+	s.logPromotionChange(&promotion, "updated", oldPromotion, updatedBy, updatedByName)
+
+	return &promotion, nil
+}
+
+func (s *PromotionService) DeletePromotion(id int, deletedBy int, deletedByName string) error {
+	promotion, err := s.GetPromotion(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.promotionRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.logPromotionChange(promotion, "deleted", promotion, deletedBy, deletedByName)
+
+	return nil
+}
+
+// ApplyPromotions evaluates the running promotions against the invoice items and applies the
+// best promotion to each item. Promotions do not stack: an item gets at most one promotion.
+// Each item's TotalPrice is set to its gross amount minus the promotion discount.
+func (s *PromotionService) ApplyPromotions(items []*models.InvoiceItem, at time.Time) error {
+	for _, item := range items {
+		item.PromotionID = nil
+		item.PromotionName = nil
+		item.PromotionDiscount = 0
+		item.TotalPrice = item.Quantity * item.UnitPrice
+	}
+
+	promotions, err := s.promotionRepo.GetRunningPromotions(at)
+	if err != nil {
+		return err
+	}
+
+	if len(promotions) == 0 {
+		return nil
+	}
+
+	var productIDs []int
+	for _, item := range items {
+		if item.ProductID != nil {
+			productIDs = append(productIDs, *item.ProductID)
+		}
+	}
+
+	categories, err := s.promotionRepo.GetProductCategories(productIDs)
+	if err != nil {
+		return err
+	}
+
+	applyPromotions(promotions, items, categories)
+
+	return nil
+}
+
+// applyPromotions gives each item the best of the promotions matching it and sets its TotalPrice.
+// categories maps product IDs to their category ID.
+func applyPromotions(promotions []*models.Promotion, items []*models.InvoiceItem, categories map[int]int) {
+	for _, promotion := range promotions {
+		// Volume promotions created before the unit was required would sum quantities across units
+		if promotion.PromotionType == models.PromotionTypeVolume && (promotion.Unit == nil || strings.TrimSpace(*promotion.Unit) == "") {
+			continue
+		}
+
+		var matched []*models.InvoiceItem
+		totalQuantity := 0.0
+		for _, item := range items {
+			if promotionMatchesItem(promotion, item, categories) {
+				matched = append(matched, item)
+				totalQuantity += item.Quantity
+			}
+		}
+
+		// Volume promotions only apply once the quantity in scope reaches the minimum
+		if promotion.PromotionType == models.PromotionTypeVolume && totalQuantity < promotion.MinQuantity {
+			continue
+		}
+
+		for _, item := range matched {
+			discount := promotionDiscount(promotion, item)
+			if discount > item.PromotionDiscount {
+				promotionID := promotion.ID
+				promotionName := promotion.Name
+				item.PromotionID = &promotionID
+				item.PromotionName = &promotionName
+				item.PromotionDiscount = discount
+			}
+		}
+	}
+
+	for _, item := range items {
+		item.TotalPrice = item.Quantity*item.UnitPrice - item.PromotionDiscount
+	}
+}
+
+// CheckDiscountCap verifies that a manual invoice discount does not exceed the cap of the role.
+// Roles without a configured cap or with the invoice.discount.override permission are not limited.
+func (s *PromotionService) CheckDiscountCap(role string, subtotal, discountAmount float64) error {
+	if discountAmount <= 0 {
+		return nil
+	}
+
+	override, err := s.roleService.HasPermission(role, models.PermissionInvoiceDiscountOverride)
+	if err != nil {
+		return err
+	}
+
+	if override {
+		return nil
+	}
+
+	roleCap, err := s.promotionRepo.GetRoleCap(role)
+	if err != nil {
+		return err
+	}
+
+	if roleCap == nil {
+		return nil
+	}
+
+	if subtotal <= 0 {
+		return errors.New("discount is not allowed on an invoice without a subtotal")
+	}
+
+	discountPercentage := discountAmount / subtotal * 100
+	if discountPercentage > roleCap.MaxDiscountPercentage+0.005 {
+		return fmt.Errorf("discount of %.2f%% exceeds the %.2f%% limit for role %s", discountPercentage, roleCap.MaxDiscountPercentage, role)
+	}
+
+	return nil
+}
+
+// Discount role cap methods
+func (s *PromotionService) GetDiscountRoleCaps() ([]*models.DiscountRoleCap, error) {
+	return s.promotionRepo.GetRoleCaps()
+}
+
+func (s *PromotionService) SetDiscountRoleCap(role string, req *models.UpdateDiscountRoleCapRequest, updatedBy int, updatedByName string) (*models.DiscountRoleCap, error) {
+	exists, err := s.roleService.RoleExists(role)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, errors.New("invalid role")
+	}
+
+	roleCap := &models.DiscountRoleCap{
+		Role:                  role,
+		MaxDiscountPercentage: req.MaxDiscountPercentage,
+		UpdatedAt:             time.Now(),
+		UpdatedBy:             &updatedBy,
+		UpdatedByName:         &updatedByName,
+	}
+
+	if err := s.promotionRepo.UpsertRoleCap(roleCap); err != nil {
+		return nil, err
+	}
+
+	return roleCap, nil
+}
+
+// Helper methods
+func (s *PromotionService) logPromotionChange(promotion *models.Promotion, action string, oldPromotion *models.Promotion, userID int, userName string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	summary := fmt.Sprintf("Promotion %q %s", promotion.Name, action)
+	req := models.AuditLogCreateRequest{
+		EntityType:     "promotion",
+		EntityID:       promotion.ID,
+		Action:         action,
+		UserID:         &userID,
+		UserName:       &userName,
+		OldData:        promotionToMap(oldPromotion),
+		NewData:        promotionToMap(promotion),
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the promotion change
+		log.Printf("Failed to create audit log for promotion %d: %v", promotion.ID, err)
+	}
+}
+
+// updatePromotionFields applies the fields set in req to promotion
+func updatePromotionFields(promotion *models.Promotion, req *models.UpdatePromotionRequest) {
+	if req.Name != nil {
+		promotion.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		promotion.Description = req.Description
+	}
+	if req.ClearScope {
+		promotion.CategoryID = nil
+		promotion.ProductID = nil
+		promotion.VariantID = nil
+	}
+	if req.CategoryID != nil {
+		promotion.CategoryID = req.CategoryID
+	}
+	if req.ProductID != nil {
+		promotion.ProductID = req.ProductID
+	}
+	if req.VariantID != nil {
+		promotion.VariantID = req.VariantID
+	}
+	if req.DiscountPercentage != nil {
+		promotion.DiscountPercentage = *req.DiscountPercentage
+	}
+	if req.BuyQuantity != nil {
+		promotion.BuyQuantity = *req.BuyQuantity
+	}
+	if req.GetQuantity != nil {
+		promotion.GetQuantity = *req.GetQuantity
+	}
+	if req.MinQuantity != nil {
+		promotion.MinQuantity = *req.MinQuantity
+	}
+	if req.Unit != nil {
+		promotion.Unit = req.Unit
+	}
+	if req.ClearStartsAt {
+		promotion.StartsAt = nil
+	}
+	if req.StartsAt != nil {
+		promotion.StartsAt = req.StartsAt
+	}
+	if req.ClearEndsAt {
+		promotion.EndsAt = nil
+	}
+	if req.EndsAt != nil {
+		promotion.EndsAt = req.EndsAt
+	}
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+}
+
+func validatePromotion(promotion *models.Promotion) error {
+	if promotion.Name == "" {
+		return errors.New("promotion name is required")
+	}
+
+	switch promotion.PromotionType {
+	case models.PromotionTypePercentage:
+		if promotion.DiscountPercentage <= 0 {
+			return errors.New("discount percentage must be greater than 0")
+		}
+	case models.PromotionTypeBuyXGetY:
+		if promotion.BuyQuantity <= 0 || promotion.GetQuantity <= 0 {
+			return errors.New("buy quantity and get quantity must be greater than 0")
+		}
+	case models.PromotionTypeVolume:
+		if promotion.DiscountPercentage <= 0 {
+			return errors.New("discount percentage must be greater than 0")
+		}
+		if promotion.MinQuantity <= 0 {
+			return errors.New("minimum quantity must be greater than 0")
+		}
+		if promotion.Unit == nil || strings.TrimSpace(*promotion.Unit) == "" {
+			return errors.New("unit is required for volume promotions")
+		}
+	default:
+		return errors.New("invalid promotion type")
+	}
+
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return errors.New("promotion end time must be after its start time")
+	}
+
+	return nil
+}
+
+func promotionMatchesItem(promotion *models.Promotion, item *models.InvoiceItem, categories map[int]int) bool {
+	if promotion.VariantID != nil && (item.VariantID == nil || *item.VariantID != *promotion.VariantID) {
+		return false
+	}
+
+	if promotion.ProductID != nil && (item.ProductID == nil || *item.ProductID != *promotion.ProductID) {
+		return false
+	}
+
+	if promotion.CategoryID != nil {
+		if item.ProductID == nil {
+			return false
+		}
+		categoryID, ok := categories[*item.ProductID]
+		if !ok || categoryID != *promotion.CategoryID {
+			return false
+		}
+	}
+
+	if promotion.Unit != nil && *promotion.Unit != "" && !strings.EqualFold(strings.TrimSpace(item.Unit), strings.TrimSpace(*promotion.Unit)) {
+		return false
+	}
+
+	return true
+}
+
+func promotionDiscount(promotion *models.Promotion, item *models.InvoiceItem) float64 {
+	gross := item.Quantity * item.UnitPrice
+	discount := 0.0
+
+	switch promotion.PromotionType {
+	case models.PromotionTypePercentage, models.PromotionTypeVolume:
+		discount = gross * promotion.DiscountPercentage / 100
+	case models.PromotionTypeBuyXGetY:
+		// Every full set of (buy + get) units contains `get` free units
+		sets := math.Floor(item.Quantity / (promotion.BuyQuantity + promotion.GetQuantity))
+		discount = sets * promotion.GetQuantity * item.UnitPrice
+	}
+
+	discount = math.Round(discount*100) / 100
+	if discount > gross {
+		discount = gross
+	}
+
+	return discount
+}
+
+func promotionToMap(promotion *models.Promotion) map[string]interface{} {
+	if promotion == nil {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"name":                promotion.Name,
+		"promotion_type":      promotion.PromotionType,
+		"category_id":         promotion.CategoryID,
+		"product_id":          promotion.ProductID,
+		"variant_id":          promotion.VariantID,
+		"discount_percentage": promotion.DiscountPercentage,
+		"buy_quantity":        promotion.BuyQuantity,
+		"get_quantity":        promotion.GetQuantity,
+		"min_quantity":        promotion.MinQuantity,
+		"unit":                promotion.Unit,
+		"starts_at":           promotion.StartsAt,
+		"ends_at":             promotion.EndsAt,
+		"is_active":           promotion.IsActive,
+	}
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"steel-pos-backend/internal/models"
+)
+
+func intPtr(v int) *int { return &v }
+
+func stringPtr(v string) *string { return &v }
+
+func TestValidatePromotion(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name      string
+		promotion models.Promotion
+		wantErr   string
+	}{
+		{
+			name:      "percentage",
+			promotion: models.Promotion{Name: "Spring", PromotionType: models.PromotionTypePercentage, DiscountPercentage: 5},
+		},
+		{
+			name:      "percentage without discount",
+			promotion: models.Promotion{Name: "Spring", PromotionType: models.PromotionTypePercentage},
+			wantErr:   "discount percentage must be greater than 0",
+		},
+		{
+			name:      "buy x get y without get quantity",
+			promotion: models.Promotion{Name: "Bundle", PromotionType: models.PromotionTypeBuyXGetY, BuyQuantity: 10},
+			wantErr:   "buy quantity and get quantity must be greater than 0",
+		},
+		{
+			name:      "volume with unit",
+			promotion: models.Promotion{Name: "Tonnage", PromotionType: models.PromotionTypeVolume, DiscountPercentage: 3, MinQuantity: 1000, Unit: stringPtr("kg")},
+		},
+		{
+			name:      "volume without unit",
+			promotion: models.Promotion{Name: "Tonnage", PromotionType: models.PromotionTypeVolume, DiscountPercentage: 3, MinQuantity: 1000},
+			wantErr:   "unit is required for volume promotions",
+		},
+		{
+			name:      "volume with blank unit",
+			promotion: models.Promotion{Name: "Tonnage", PromotionType: models.PromotionTypeVolume, DiscountPercentage: 3, MinQuantity: 1000, Unit: stringPtr("  ")},
+			wantErr:   "unit is required for volume promotions",
+		},
+		{
+			name:      "end before start",
+			promotion: models.Promotion{Name: "Spring", PromotionType: models.PromotionTypePercentage, DiscountPercentage: 5, StartsAt: &end, EndsAt: &start},
+			wantErr:   "promotion end time must be after its start time",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePromotion(&tt.promotion)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("got %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyPromotionsVolumeMixedUnits(t *testing.T) {
+	promotion := &models.Promotion{
+		ID:                 1,
+		Name:               "1 tonne of steel",
+		PromotionType:      models.PromotionTypeVolume,
+		CategoryID:         intPtr(7),
+		DiscountPercentage: 2,
+		MinQuantity:        1000,
+		Unit:               stringPtr("kg"),
+	}
+	categories := map[int]int{1: 7, 2: 7}
+
+	newItems := func() []*models.InvoiceItem {
+		return []*models.InvoiceItem{
+			{ProductID: intPtr(1), Unit: "kg", Quantity: 600, UnitPrice: 20000},
+			{ProductID: intPtr(2), Unit: "cây", Quantity: 500, UnitPrice: 150000},
+		}
+	}
+
+	// 600 kg and 500 bars are not 1100 kg
+	items := newItems()
+	applyPromotions([]*models.Promotion{promotion}, items, categories)
+	for _, item := range items {
+		if item.PromotionID != nil {
+			t.Fatalf("%s item got promotion below the minimum weight", item.Unit)
+		}
+		if item.TotalPrice != item.Quantity*item.UnitPrice {
+			t.Fatalf("%s item total %v, want %v", item.Unit, item.TotalPrice, item.Quantity*item.UnitPrice)
+		}
+	}
+
+	// Reaching the minimum in kg discounts the kg line only
+	items = newItems()
+	items[0].Quantity = 1000
+	applyPromotions([]*models.Promotion{promotion}, items, categories)
+	if items[0].PromotionID == nil || items[0].PromotionDiscount != 400000 {
+		t.Fatalf("kg item discount %v, want 400000", items[0].PromotionDiscount)
+	}
+	if items[0].TotalPrice != 19600000 {
+		t.Fatalf("kg item total %v, want 19600000", items[0].TotalPrice)
+	}
+	if items[1].PromotionID != nil {
+		t.Fatal("bar item got the kg volume promotion")
+	}
+
+	// A volume promotion saved without a unit is never applied
+	legacy := *promotion
+	legacy.Unit = nil
+	items = newItems()
+	items[0].Quantity = 1000
+	applyPromotions([]*models.Promotion{&legacy}, items, categories)
+	for _, item := range items {
+		if item.PromotionID != nil {
+			t.Fatalf("%s item got a volume promotion without unit", item.Unit)
+		}
+	}
+}
+
+func TestApplyPromotionsBestPromotionWins(t *testing.T) {
+	promotions := []*models.Promotion{
+		{ID: 1, Name: "5% off", PromotionType: models.PromotionTypePercentage, DiscountPercentage: 5},
+		{ID: 2, Name: "Buy 10 get 1", PromotionType: models.PromotionTypeBuyXGetY, BuyQuantity: 10, GetQuantity: 1, ProductID: intPtr(1)},
+	}
+	items := []*models.InvoiceItem{
+		{ProductID: intPtr(1), Unit: "cây", Quantity: 22, UnitPrice: 100000},
+		{ProductID: intPtr(2), Unit: "cây", Quantity: 22, UnitPrice: 100000},
+	}
+
+	applyPromotions(promotions, items, map[int]int{})
+
+	// 22 bars hold two sets of 10+1, so 2 bars are free: 200000 beats 5% = 110000
+	if items[0].PromotionID == nil || *items[0].PromotionID != 2 || items[0].PromotionDiscount != 200000 {
+		t.Fatalf("item 1 got promotion %v discount %v, want 2 and 200000", items[0].PromotionID, items[0].PromotionDiscount)
+	}
+	if items[1].PromotionID == nil || *items[1].PromotionID != 1 || items[1].PromotionDiscount != 110000 {
+		t.Fatalf("item 2 got promotion %v discount %v, want 1 and 110000", items[1].PromotionID, items[1].PromotionDiscount)
+	}
+}
+
+func TestUpdatePromotionFieldsClears(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	promotion := models.Promotion{
+		Name:       "Spring",
+		CategoryID: intPtr(7),
+		ProductID:  intPtr(1),
+		VariantID:  intPtr(3),
+		StartsAt:   &start,
+		EndsAt:     &end,
+	}
+
+	// Omitted fields stay as they are
+	updatePromotionFields(&promotion, &models.UpdatePromotionRequest{Name: stringPtr("Spring sale")})
+	if promotion.Name != "Spring sale" || promotion.CategoryID == nil || promotion.StartsAt == nil || promotion.EndsAt == nil {
+		t.Fatalf("unrelated fields changed: %+v", promotion)
+	}
+
+	updatePromotionFields(&promotion, &models.UpdatePromotionRequest{ClearScope: true, ClearStartsAt: true, ClearEndsAt: true})
+	if promotion.CategoryID != nil || promotion.ProductID != nil || promotion.VariantID != nil {
+		t.Fatalf("scope not cleared: %v %v %v", promotion.CategoryID, promotion.ProductID, promotion.VariantID)
+	}
+	if promotion.StartsAt != nil || promotion.EndsAt != nil {
+		t.Fatalf("dates not cleared: %v %v", promotion.StartsAt, promotion.EndsAt)
+	}
+
+	// A value sent with its clear flag replaces the old one
+	updatePromotionFields(&promotion, &models.UpdatePromotionRequest{ClearScope: true, ProductID: intPtr(5)})
+	if promotion.ProductID == nil || *promotion.ProductID != 5 || promotion.CategoryID != nil {
+		t.Fatalf("scope after clear with product: %v %v", promotion.CategoryID, promotion.ProductID)
+	}
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"steel-pos-backend/internal/config"
+	"steel-pos-backend/internal/escpos"
+	"steel-pos-backend/internal/models"
+)
+
+type ReceiptService struct {
+	cfg                  config.ReceiptConfig
+	paymentMethodService *PaymentMethodService
+	storeSettingsService *StoreSettingsService
+	printer              *escpos.Printer
+
+	// Fonts are loaded on the first receipt
+	rendererOnce sync.Once
+	renderer     *escpos.Renderer
+	rendererErr  error
+}
+
+func NewReceiptService(cfg config.ReceiptConfig, paymentMethodService *PaymentMethodService, storeSettingsService *StoreSettingsService) *ReceiptService {
+	return &ReceiptService{
+		cfg:                  cfg,
+		paymentMethodService: paymentMethodService,
+		storeSettingsService: storeSettingsService,
+		printer:              escpos.NewPrinter(cfg.PrinterAddress, time.Duration(cfg.PrinterTimeout)*time.Second),
+	}
+}
+
+// IsPrinterConfigured reports whether receipts can be sent to a counter printer
+func (s *ReceiptService) IsPrinterConfigured() bool {
+	return s.cfg.PrinterAddress != ""
+}
+
+// RenderInvoiceReceipt draws the receipt of an invoice as a bitmap as wide as the paper
+func (s *ReceiptService) RenderInvoiceReceipt(invoice *models.Invoice) (image.Image, error) {
+	s.rendererOnce.Do(func() {
+		s.renderer, s.rendererErr = escpos.NewRenderer("fonts/NotoSans-Regular.ttf", "fonts/NotoSans-Bold.ttf", s.cfg.PaperWidth)
+	})
+	if s.rendererErr != nil {
+		return nil, s.rendererErr
+	}
+
+	settings, err := s.storeSettingsService.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.renderer.Render(s.receiptLines(invoice, settings)), nil
+}
+
+// GenerateInvoiceReceipt renders an invoice as an 80mm ESC/POS receipt
+func (s *ReceiptService) GenerateInvoiceReceipt(invoice *models.Invoice) ([]byte, error) {
+	img, err := s.RenderInvoiceReceipt(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := escpos.NewBuilder()
+	receipt.Image(img)
+	receipt.Feed(3)
+	receipt.Cut()
+
+	return receipt.Bytes(), nil
+}
+
+// PrintInvoiceReceipt sends the receipt of an invoice to the counter printer
+func (s *ReceiptService) PrintInvoiceReceipt(invoice *models.Invoice) error {
+	if !s.IsPrinterConfigured() {
+		return errors.New("receipt printer is not configured")
+	}
+
+	data, err := s.GenerateInvoiceReceipt(invoice)
+	if err != nil {
+		return err
+	}
+
+	return s.printer.Print(data)
+}
+
+// Helper methods
+
+func (s *ReceiptService) receiptLines(invoice *models.Invoice, settings *models.StoreSettings) []escpos.Line {
+	separator := escpos.Line{Separator: true}
+
+	// Store header
+	lines := []escpos.Line{{Text: settings.Name, Align: escpos.AlignCenter, Bold: true, Large: true}}
+	if settings.Address != nil {
+		lines = append(lines, escpos.Line{Text: *settings.Address, Align: escpos.AlignCenter})
+	}
+	if len(settings.Phones) > 0 {
+		lines = append(lines, escpos.Line{Text: "ĐT: " + strings.Join(settings.Phones, " - "), Align: escpos.AlignCenter})
+	}
+	if settings.TaxCode != nil {
+		lines = append(lines, escpos.Line{Text: "MST: " + *settings.TaxCode, Align: escpos.AlignCenter})
+	}
+
+	lines = append(lines,
+		separator,
+		escpos.Line{Text: "HOÁ ĐƠN BÁN HÀNG", Align: escpos.AlignCenter, Bold: true, Large: true},
+		escpos.Line{Text: "Số: " + invoice.InvoiceCode, Right: invoice.CreatedAt.Format("02/01/2006 15:04")},
+		escpos.Line{Text: "Khách hàng: " + invoice.CustomerName},
+		escpos.Line{Text: "Điện thoại: " + invoice.CustomerPhone},
+	)
+	if invoice.CreatedByUsername != nil && *invoice.CreatedByUsername != "" {
+		lines = append(lines, escpos.Line{Text: "Thu ngân: " + *invoice.CreatedByUsername})
+	}
+	lines = append(lines, separator)
+
+	// Items
+	for _, item := range invoice.Items {
+		name := item.ProductName
+		if item.VariantName != "" {
+			name += " - " + item.VariantName
+		}
+		lines = append(lines,
+			escpos.Line{Text: name, Bold: true},
+			escpos.Line{
+				Text:  fmt.Sprintf("%s x %s", strconv.FormatFloat(item.Quantity, 'f', -1, 64), formatReceiptAmount(item.UnitPrice)),
+				Right: formatReceiptAmount(item.TotalPrice),
+			},
+		)
+		if discount := item.PromotionDiscount + item.DiscountAmount; discount > 0 {
+			lines = append(lines, escpos.Line{Text: "Giảm giá", Right: "-" + formatReceiptAmount(discount)})
+		}
+	}
+	lines = append(lines, separator)
+
+	// Totals
+	lines = append(lines, escpos.Line{Text: "Tạm tính", Right: formatReceiptAmount(invoice.Subtotal)})
+	if invoice.DiscountAmount > 0 {
+		lines = append(lines, escpos.Line{Text: "Giảm giá", Right: "-" + formatReceiptAmount(invoice.DiscountAmount)})
+	}
+	vatShown := false
+	for _, line := range invoice.VATSummary {
+		if line.TaxAmount <= 0 {
+			continue
+		}
+		label := fmt.Sprintf("Thuế GTGT %s%%", strconv.FormatFloat(line.TaxRate, 'f', -1, 64))
+		if invoice.PriceMode == models.PriceModeInclusive {
+			label += " (đã gồm)"
+		}
+		lines = append(lines, escpos.Line{Text: label, Right: formatReceiptAmount(line.TaxAmount)})
+		vatShown = true
+	}
+	if !vatShown && invoice.TaxAmount > 0 {
+		lines = append(lines, escpos.Line{Text: "Thuế", Right: formatReceiptAmount(invoice.TaxAmount)})
+	}
+	lines = append(lines, escpos.Line{Text: "TỔNG CỘNG", Right: formatReceiptAmount(invoice.TotalAmount), Bold: true, Large: true})
+
+	// Payments, without those replaced by a correction
+	methodNames := s.paymentMethodNames()
+	paymentsShown := false
+	for _, payment := range invoice.Payments {
+		if payment.Status != "confirmed" || payment.PaymentType == models.PaymentTypeReversal || payment.CorrectedAt != nil {
+			continue
+		}
+		if !paymentsShown {
+			lines = append(lines, separator)
+			paymentsShown = true
+		}
+
+		name := payment.PaymentMethod
+		if methodName, ok := methodNames[payment.PaymentMethod]; ok {
+			name = methodName
+		}
+
+		amount := payment.Amount
+		if payment.TenderedAmount != nil {
+			amount = *payment.TenderedAmount
+		}
+		lines = append(lines, escpos.Line{Text: name, Right: formatReceiptAmount(amount)})
+	}
+	if invoice.ChangeAmount > 0 {
+		lines = append(lines, escpos.Line{Text: "Tiền thừa trả khách", Right: formatReceiptAmount(invoice.ChangeAmount)})
+	}
+	if due := invoice.TotalAmount - invoice.PaidAmount; due > 0.5 && invoice.Status != "cancelled" {
+		lines = append(lines, escpos.Line{Text: "Còn lại", Right: formatReceiptAmount(due), Bold: true})
+	}
+
+	if invoice.Status == "cancelled" {
+		lines = append(lines, separator, escpos.Line{Text: "ĐÃ HUỶ", Align: escpos.AlignCenter, Bold: true, Large: true})
+	}
+
+	if settings.FooterText != nil {
+		lines = append(lines, separator)
+		for _, text := range strings.Split(*settings.FooterText, "\n") {
+			lines = append(lines, escpos.Line{Text: text, Align: escpos.AlignCenter})
+		}
+	}
+
+	return lines
+}
+
+// paymentMethodNames maps payment method codes to the names printed on receipts
+func (s *ReceiptService) paymentMethodNames() map[string]string {
+	names := map[string]string{}
+	if s.paymentMethodService == nil {
+		return names
+	}
+
+	methods, err := s.paymentMethodService.GetPaymentMethods(false)
+	if err != nil {
+		// Receipts fall back to the method codes
+		return names
+	}
+
+	for _, method := range methods {
+		names[method.Code] = method.Name
+	}
+	return names
+}
+
+// formatReceiptAmount formats an amount in dong with dots between thousands, e.g. 1.250.000
+func formatReceiptAmount(amount float64) string {
+	digits := strconv.FormatInt(int64(math.Abs(math.Round(amount))), 10)
+
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if math.Round(amount) < 0 {
+		return "-" + grouped.String()
+	}
+	return grouped.String()
+}
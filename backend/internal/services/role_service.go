@@ -0,0 +1,320 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/repository"
+)
+
+// rolePermissionsTTL is how long role permissions are cached before being read from the database
+// again; changes made through this service take effect immediately
+const rolePermissionsTTL = time.Minute
+
+type RoleService struct {
+	roleRepo        *repository.RoleRepository
+	auditLogService AuditLogService
+
+	mu          sync.RWMutex
+	permissions map[string][]string
+	loadedAt    time.Time
+}
+
+func NewRoleService(roleRepo *repository.RoleRepository, auditLogService AuditLogService) *RoleService {
+	return &RoleService{
+		roleRepo:        roleRepo,
+		auditLogService: auditLogService,
+	}
+}
+
+// GetPermissionCatalog gets every permission that can be granted to roles
+func (s *RoleService) GetPermissionCatalog() []models.Permission {
+	return models.AllPermissions
+}
+
+// GetRolePermissions gets the permissions granted to a role. The admin role has every permission;
+// unknown roles have none.
+func (s *RoleService) GetRolePermissions(role string) ([]string, error) {
+	if role == string(models.RoleAdmin) {
+		permissions := make([]string, len(models.AllPermissions))
+		for i, permission := range models.AllPermissions {
+			permissions[i] = permission.Code
+		}
+		return permissions, nil
+	}
+
+	s.mu.RLock()
+	permissions, loaded := s.permissions, time.Since(s.loadedAt) < rolePermissionsTTL
+	s.mu.RUnlock()
+
+	if !loaded || permissions == nil {
+		var err error
+		permissions, err = s.reloadPermissions()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return permissions[role], nil
+}
+
+// HasPermission reports whether a role has been granted a permission
+func (s *RoleService) HasPermission(role, permission string) (bool, error) {
+	permissions, err := s.GetRolePermissions(role)
+	if err != nil {
+		return false, err
+	}
+
+	for _, granted := range permissions {
+		if granted == permission {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// RoleExists reports whether a role with the name exists
+func (s *RoleService) RoleExists(name string) (bool, error) {
+	role, err := s.roleRepo.GetByName(name)
+	if err != nil {
+		return false, err
+	}
+	return role != nil, nil
+}
+
+// Role methods
+func (s *RoleService) GetRoles() ([]*models.Role, error) {
+	roles, err := s.roleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range roles {
+		s.fillSystemPermissions(role)
+	}
+
+	return roles, nil
+}
+
+func (s *RoleService) GetRole(name string) (*models.Role, error) {
+	role, err := s.roleRepo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		return nil, errors.New("role not found")
+	}
+
+	s.fillSystemPermissions(role)
+	return role, nil
+}
+
+func (s *RoleService) CreateRole(req *models.CreateRoleRequest, userID int, userName string) (*models.Role, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if name == "" {
+		return nil, errors.New("role name is required")
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_') {
+			return nil, errors.New("role name may only contain letters, digits and underscores")
+		}
+	}
+
+	existing, err := s.roleRepo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, fmt.Errorf("role %s already exists", name)
+	}
+
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Name:             name,
+		Description:      trimmedOrNil(req.Description),
+		RequireTwoFactor: req.RequireTwoFactor,
+		Permissions:      permissions,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+
+	if err := s.roleRepo.Create(role); err != nil {
+		return nil, err
+	}
+
+	s.invalidatePermissions()
+	s.logRoleChange(role.ID, "created", fmt.Sprintf("Created role %s", role.Name), nil, roleData(role), userID, userName)
+
+	return role, nil
+}
+
+// UpdateRole updates the description, two-factor policy and permissions of a role. The permissions
+// of the admin role cannot be changed.
+func (s *RoleService) UpdateRole(name string, req *models.UpdateRoleRequest, userID int, userName string) (*models.Role, error) {
+	role, err := s.GetRole(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if role.IsSystem && req.Permissions != nil {
+		return nil, fmt.Errorf("permissions of role %s cannot be changed", role.Name)
+	}
+
+	oldData := roleData(role)
+
+	if req.Description != nil {
+		role.Description = trimmedOrNil(*req.Description)
+	}
+
+	if req.RequireTwoFactor != nil {
+		role.RequireTwoFactor = *req.RequireTwoFactor
+	}
+
+	if req.Permissions != nil {
+		role.Permissions, err = normalizePermissions(*req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	role.UpdatedAt = time.Now()
+
+	if err := s.roleRepo.Update(role); err != nil {
+		return nil, err
+	}
+
+	s.invalidatePermissions()
+	s.logRoleChange(role.ID, "updated", fmt.Sprintf("Updated role %s", role.Name), oldData, roleData(role), userID, userName)
+
+	return role, nil
+}
+
+// RequiresTwoFactor reports whether users of a role must log in with two-factor authentication
+func (s *RoleService) RequiresTwoFactor(name string) (bool, error) {
+	role, err := s.roleRepo.GetByName(name)
+	if err != nil {
+		return false, err
+	}
+	return role != nil && role.RequireTwoFactor, nil
+}
+
+// DeleteRole deletes a role that no user is assigned to. The admin role cannot be deleted.
+func (s *RoleService) DeleteRole(name string, userID int, userName string) error {
+	role, err := s.GetRole(name)
+	if err != nil {
+		return err
+	}
+
+	if role.IsSystem {
+		return fmt.Errorf("role %s cannot be deleted", role.Name)
+	}
+
+	users, err := s.roleRepo.CountUsers(role.Name)
+	if err != nil {
+		return err
+	}
+
+	if users > 0 {
+		return fmt.Errorf("role %s is assigned to %d users", role.Name, users)
+	}
+
+	if err := s.roleRepo.Delete(role.ID); err != nil {
+		return err
+	}
+
+	s.invalidatePermissions()
+	s.logRoleChange(role.ID, "deleted", fmt.Sprintf("Deleted role %s", role.Name), roleData(role), nil, userID, userName)
+
+	return nil
+}
+
+// Helper methods
+
+func (s *RoleService) reloadPermissions() (map[string][]string, error) {
+	permissions, err := s.roleRepo.GetPermissionsByRole()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.permissions = permissions
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return permissions, nil
+}
+
+func (s *RoleService) invalidatePermissions() {
+	s.mu.Lock()
+	s.permissions = nil
+	s.mu.Unlock()
+}
+
+func (s *RoleService) fillSystemPermissions(role *models.Role) {
+	if role.Name == string(models.RoleAdmin) {
+		role.Permissions, _ = s.GetRolePermissions(role.Name)
+	}
+}
+
+func (s *RoleService) logRoleChange(roleID int, action, summary string, oldData, newData map[string]interface{}, userID int, userName string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	req := models.AuditLogCreateRequest{
+		EntityType:     "role",
+		EntityID:       roleID,
+		Action:         action,
+		UserID:         &userID,
+		UserName:       &userName,
+		OldData:        oldData,
+		NewData:        newData,
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the role change
+		log.Printf("Failed to create audit log for role %d: %v", roleID, err)
+	}
+}
+
+// normalizePermissions validates permission codes against the catalog and removes duplicates
+func normalizePermissions(codes []string) ([]string, error) {
+	seen := make(map[string]bool)
+	permissions := []string{}
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if !models.IsValidPermission(code) {
+			return nil, fmt.Errorf("unknown permission %s", code)
+		}
+		if !seen[code] {
+			seen[code] = true
+			permissions = append(permissions, code)
+		}
+	}
+
+	sort.Strings(permissions)
+	return permissions, nil
+}
+
+func roleData(role *models.Role) map[string]interface{} {
+	return map[string]interface{}{
+		"name":               role.Name,
+		"description":        role.Description,
+		"require_two_factor": role.RequireTwoFactor,
+		"permissions":        role.Permissions,
+	}
+}
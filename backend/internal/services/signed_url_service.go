@@ -0,0 +1,171 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"steel-pos-backend/internal/config"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/tokenstore"
+)
+
+// SignedURLActionPrint is the only action signed URLs are issued for; it is part of the signed
+// payload so a signature cannot be replayed for anything other than fetching a printable document
+const SignedURLActionPrint = "print"
+
+// Query parameters appended to a signed URL
+const (
+	signedURLExpiresParam   = "expires"
+	signedURLSessionParam   = "sid"
+	signedURLUserParam      = "uid"
+	signedURLSignatureParam = "signature"
+)
+
+// printableRoutes are the documents a signed URL may be issued for. Each of them must also be
+// registered behind AuthMiddleware.AuthenticateSignedURL
+var printableRoutes = []*regexp.Regexp{
+	regexp.MustCompile(`^/api/invoices/\d+/(print|pdf|receipt|payment-qr\.png)$`),
+	regexp.MustCompile(`^/api/cash-shifts/\d+/z-report/pdf$`),
+	regexp.MustCompile(`^/api/cash-shifts/z-report/daily/pdf$`),
+	regexp.MustCompile(`^/api/store-settings/logo$`),
+}
+
+type SignedURLService struct {
+	config     *config.Config
+	tokenStore tokenstore.Store
+	users      UserLoader
+}
+
+func NewSignedURLService(config *config.Config, tokenStore tokenstore.Store, users UserLoader) *SignedURLService {
+	return &SignedURLService{
+		config:     config,
+		tokenStore: tokenStore,
+		users:      users,
+	}
+}
+
+// SignURL signs a printable document path for the given user and login session. The signature
+// covers the action, the path with its query string, the user, the session and the expiry time,
+// so the URL is only good for that one document, stops working once it expires and dies with the
+// session or when another user takes the session over
+func (s *SignedURLService) SignURL(rawPath string, sessionID string, userID int) (*models.SignedURL, error) {
+	if sessionID == "" {
+		return nil, errors.New("no session, please log in again")
+	}
+
+	target, err := url.Parse(rawPath)
+	if err != nil || target.Scheme != "" || target.Host != "" {
+		return nil, errors.New("path must be a relative URL such as /api/invoices/1/pdf")
+	}
+
+	if !isPrintablePath(target.Path) {
+		return nil, fmt.Errorf("signed URLs are not available for %s", target.Path)
+	}
+
+	expiry, err := time.ParseDuration(s.config.JWT.SignedURLExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signed URL expiry: %w", err)
+	}
+
+	query := target.Query()
+	for _, param := range []string{signedURLExpiresParam, signedURLSessionParam, signedURLUserParam, signedURLSignatureParam} {
+		query.Del(param)
+	}
+
+	expiresAt := time.Now().Add(expiry).Truncate(time.Second)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	query.Set(signedURLExpiresParam, expires)
+	query.Set(signedURLSessionParam, sessionID)
+	query.Set(signedURLUserParam, strconv.Itoa(userID))
+	query.Set(signedURLSignatureParam, s.sign(target.Path, query))
+
+	return &models.SignedURL{
+		URL:       target.Path + "?" + query.Encode(),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// IsSignedRequest reports whether the query string carries a signature
+func (s *SignedURLService) IsSignedRequest(query url.Values) bool {
+	return query.Get(signedURLSignatureParam) != ""
+}
+
+// VerifyRequest checks the signature and expiry of a signed URL and returns the user and the
+// session it was issued for
+func (s *SignedURLService) VerifyRequest(path string, query url.Values) (*models.User, string, error) {
+	signature := query.Get(signedURLSignatureParam)
+	if signature == "" {
+		return nil, "", errors.New("signature is required")
+	}
+
+	if !isPrintablePath(path) {
+		return nil, "", errors.New("signed URLs are not accepted for this resource")
+	}
+
+	expected := s.sign(path, query)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, "", errors.New("invalid signature")
+	}
+
+	expires, err := strconv.ParseInt(query.Get(signedURLExpiresParam), 10, 64)
+	if err != nil {
+		return nil, "", errors.New("invalid expiry")
+	}
+	if time.Now().Unix() > expires {
+		return nil, "", errors.New("signed URL expired")
+	}
+
+	sessionID := query.Get(signedURLSessionParam)
+	session, err := s.tokenStore.GetSession(sessionID)
+	if err != nil {
+		return nil, "", err
+	}
+	if session == nil || session.RevokedAt != nil {
+		return nil, "", errors.New("session has been revoked")
+	}
+
+	// The URL acts for the user who signed it, not whoever is working on the session now
+	userID, err := strconv.Atoi(query.Get(signedURLUserParam))
+	if err != nil || userID != session.CurrentUserID() {
+		return nil, "", errors.New("signed URL was issued to another user of the session")
+	}
+
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return user, sessionID, nil
+}
+
+// sign computes the hex HMAC-SHA256 of the action, path and query string without the signature
+func (s *SignedURLService) sign(path string, query url.Values) string {
+	signed := url.Values{}
+	for key, values := range query {
+		if key != signedURLSignatureParam {
+			signed[key] = values
+		}
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.config.JWT.Secret))
+	mac.Write([]byte(strings.Join([]string{SignedURLActionPrint, path, signed.Encode()}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func isPrintablePath(path string) bool {
+	for _, route := range printableRoutes {
+		if route.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
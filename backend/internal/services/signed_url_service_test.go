@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"steel-pos-backend/internal/config"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/tokenstore"
+)
+
+// testUsers stands in for the user repository
+type testUsers map[int]*models.User
+
+func (u testUsers) GetByID(id int) (*models.User, error) {
+	user, ok := u[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+func newTestSignedURLService(t *testing.T, expiry string) (*SignedURLService, *tokenstore.MemoryStore) {
+	t.Helper()
+
+	store := tokenstore.NewMemoryStore()
+	now := time.Now()
+	if err := store.CreateSession(&tokenstore.Session{ID: "session-1", UserID: 1, CreatedAt: now, LastSeenAt: now}); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	cfg := &config.Config{JWT: config.JWTConfig{Secret: "test-secret", SignedURLExpiry: expiry}}
+	users := testUsers{
+		1: {ID: 1, Username: "cashier", Role: "user", IsActive: true},
+		2: {ID: 2, Username: "manager", Role: "manager", IsActive: true},
+	}
+	return NewSignedURLService(cfg, store, users), store
+}
+
+// signTestURL signs a path for user 1 on session-1 and splits the result into path and query
+func signTestURL(t *testing.T, service *SignedURLService, path string) (string, url.Values) {
+	t.Helper()
+
+	signed, err := service.SignURL(path, "session-1", 1)
+	if err != nil {
+		t.Fatalf("SignURL: %v", err)
+	}
+
+	parsed, err := url.Parse(signed.URL)
+	if err != nil {
+		t.Fatalf("parse signed URL: %v", err)
+	}
+	return parsed.Path, parsed.Query()
+}
+
+func TestSignedURLRoundTrip(t *testing.T) {
+	service, _ := newTestSignedURLService(t, "5m")
+	path, query := signTestURL(t, service, "/api/invoices/12/pdf?template=a5")
+
+	if query.Get("template") != "a5" {
+		t.Fatalf("query %v lost the template parameter", query)
+	}
+
+	user, sessionID, err := service.VerifyRequest(path, query)
+	if err != nil {
+		t.Fatalf("VerifyRequest: %v", err)
+	}
+	if user.ID != 1 || sessionID != "session-1" {
+		t.Fatalf("got user %d on %s, want user 1 on session-1", user.ID, sessionID)
+	}
+}
+
+func TestSignURLRejectsPaths(t *testing.T) {
+	service, _ := newTestSignedURLService(t, "5m")
+
+	tests := map[string]string{
+		"/api/users":                      "signed URLs are not available",
+		"https://evil.example/api/x":      "must be a relative URL",
+		"/api/invoices/12/pdf/../../keys": "signed URLs are not available",
+	}
+
+	for path, wantErr := range tests {
+		if _, err := service.SignURL(path, "session-1", 1); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("SignURL(%q) = %v, want %q", path, err, wantErr)
+		}
+	}
+
+	if _, err := service.SignURL("/api/invoices/12/pdf", "", 1); err == nil {
+		t.Error("URL signed without a session")
+	}
+}
+
+func TestVerifySignedURLTampered(t *testing.T) {
+	service, _ := newTestSignedURLService(t, "5m")
+
+	tests := []struct {
+		name   string
+		tamper func(path string, query url.Values) string
+	}{
+		{"other document", func(path string, query url.Values) string { return "/api/invoices/13/pdf" }},
+		{"other user", func(path string, query url.Values) string { query.Set("uid", "2"); return path }},
+		{"other session", func(path string, query url.Values) string { query.Set("sid", "session-2"); return path }},
+		{"later expiry", func(path string, query url.Values) string { query.Set("expires", "9999999999"); return path }},
+		{"added parameter", func(path string, query url.Values) string { query.Set("template", "a4"); return path }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, query := signTestURL(t, service, "/api/invoices/12/pdf")
+			path = tt.tamper(path, query)
+
+			if _, _, err := service.VerifyRequest(path, query); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+				t.Fatalf("got %v, want invalid signature", err)
+			}
+		})
+	}
+}
+
+func TestVerifySignedURLExpired(t *testing.T) {
+	service, _ := newTestSignedURLService(t, "-1s")
+	path, query := signTestURL(t, service, "/api/invoices/12/pdf")
+
+	if _, _, err := service.VerifyRequest(path, query); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("got %v, want signed URL expired", err)
+	}
+}
+
+func TestVerifySignedURLRevokedSession(t *testing.T) {
+	service, store := newTestSignedURLService(t, "5m")
+	path, query := signTestURL(t, service, "/api/invoices/12/pdf")
+
+	if err := store.RevokeSession("session-1", time.Now()); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+
+	if _, _, err := service.VerifyRequest(path, query); err == nil || !strings.Contains(err.Error(), "revoked") {
+		t.Fatalf("got %v, want session has been revoked", err)
+	}
+}
+
+func TestVerifySignedURLAfterUserSwitch(t *testing.T) {
+	service, store := newTestSignedURLService(t, "5m")
+	path, query := signTestURL(t, service, "/api/invoices/12/pdf")
+
+	// Another user takes over the counter; the URL of the first user must not act as them
+	if err := store.SwitchUser("session-1", 2, time.Now()); err != nil {
+		t.Fatalf("SwitchUser: %v", err)
+	}
+
+	if _, _, err := service.VerifyRequest(path, query); err == nil || !strings.Contains(err.Error(), "another user") {
+		t.Fatalf("got %v, want issued to another user", err)
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"strings"
+
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/repository"
+)
+
+type StoreSettingsService struct {
+	storeSettingsRepo *repository.StoreSettingsRepository
+	auditLogService   AuditLogService
+}
+
+func NewStoreSettingsService(storeSettingsRepo *repository.StoreSettingsRepository, auditLogService AuditLogService) *StoreSettingsService {
+	return &StoreSettingsService{
+		storeSettingsRepo: storeSettingsRepo,
+		auditLogService:   auditLogService,
+	}
+}
+
+// GetSettings gets the store profile
+func (s *StoreSettingsService) GetSettings() (*models.StoreSettings, error) {
+	return s.storeSettingsRepo.Get()
+}
+
+// UpdateSettings updates the store profile
+func (s *StoreSettingsService) UpdateSettings(req *models.UpdateStoreSettingsRequest, userID int, userName string) (*models.StoreSettings, error) {
+	settings, err := s.storeSettingsRepo.Get()
+	if err != nil {
+		return nil, err
+	}
+	oldData := storeSettingsData(settings)
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("store name is required")
+		}
+		settings.Name = name
+	}
+	if req.Address != nil {
+		settings.Address = trimmedOrNil(*req.Address)
+	}
+	if req.Phones != nil {
+		settings.Phones = []string{}
+		for _, phone := range *req.Phones {
+			if phone = strings.TrimSpace(phone); phone != "" {
+				settings.Phones = append(settings.Phones, phone)
+			}
+		}
+	}
+	if req.TaxCode != nil {
+		settings.TaxCode = trimmedOrNil(*req.TaxCode)
+	}
+	if req.FooterText != nil {
+		settings.FooterText = trimmedOrNil(*req.FooterText)
+	}
+	if req.DefaultInvoiceTemplate != nil {
+		settings.DefaultInvoiceTemplate = *req.DefaultInvoiceTemplate
+	}
+	if req.BankAccounts != nil {
+		settings.BankAccounts = []*models.StoreBankAccount{}
+		for _, account := range *req.BankAccounts {
+			settings.BankAccounts = append(settings.BankAccounts, &models.StoreBankAccount{
+				BankBIN:       account.BankBIN,
+				BankName:      strings.TrimSpace(account.BankName),
+				AccountNumber: strings.TrimSpace(account.AccountNumber),
+				AccountName:   strings.TrimSpace(account.AccountName),
+				Branch:        account.Branch,
+			})
+		}
+	}
+
+	settings.UpdatedBy = &userID
+	settings.UpdatedByName = &userName
+
+	if err := s.storeSettingsRepo.Update(settings, req.BankAccounts != nil); err != nil {
+		return nil, err
+	}
+
+	s.logSettingsChange("Updated store settings", oldData, storeSettingsData(settings), userID, userName)
+
+	return settings, nil
+}
+
+// GetLogo gets the logo image of the store, nil when none is uploaded
+func (s *StoreSettingsService) GetLogo() (*models.StoreLogo, error) {
+	return s.storeSettingsRepo.GetLogo()
+}
+
+// UploadLogo replaces the logo of the store with a PNG or JPEG image
+func (s *StoreSettingsService) UploadLogo(data []byte, userID int, userName string) error {
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "png" && format != "jpeg") {
+		return errors.New("logo must be a PNG or JPEG image")
+	}
+
+	logo := &models.StoreLogo{
+		Data:        data,
+		ContentType: "image/" + format,
+	}
+	if err := s.storeSettingsRepo.SetLogo(logo, userID, userName); err != nil {
+		return err
+	}
+
+	s.logSettingsChange("Uploaded store logo", nil, nil, userID, userName)
+
+	return nil
+}
+
+// DeleteLogo removes the logo of the store
+func (s *StoreSettingsService) DeleteLogo(userID int, userName string) error {
+	if err := s.storeSettingsRepo.SetLogo(nil, userID, userName); err != nil {
+		return err
+	}
+
+	s.logSettingsChange("Removed store logo", nil, nil, userID, userName)
+
+	return nil
+}
+
+// Helper methods
+
+func (s *StoreSettingsService) logSettingsChange(summary string, oldData, newData map[string]interface{}, userID int, userName string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	req := models.AuditLogCreateRequest{
+		EntityType:     "store_settings",
+		EntityID:       1,
+		Action:         "updated",
+		UserID:         &userID,
+		UserName:       &userName,
+		OldData:        oldData,
+		NewData:        newData,
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the settings update
+		log.Printf("Failed to create audit log for store settings: %v", err)
+	}
+}
+
+func storeSettingsData(settings *models.StoreSettings) map[string]interface{} {
+	return map[string]interface{}{
+		"name":                     settings.Name,
+		"address":                  settings.Address,
+		"phones":                   settings.Phones,
+		"tax_code":                 settings.TaxCode,
+		"footer_text":              settings.FooterText,
+		"default_invoice_template": settings.DefaultInvoiceTemplate,
+		"bank_accounts":            settings.BankAccounts,
+	}
+}
+
+func trimmedOrNil(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
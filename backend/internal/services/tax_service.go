@@ -0,0 +1,317 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/repository"
+)
+
+type TaxService struct {
+	taxRepo *repository.TaxRepository
+}
+
+func NewTaxService(taxRepo *repository.TaxRepository) *TaxService {
+	return &TaxService{
+		taxRepo: taxRepo,
+	}
+}
+
+// TaxCategory methods
+func (s *TaxService) CreateTaxCategory(req *models.CreateTaxCategoryRequest, createdBy int, createdByName string) (*models.TaxCategory, error) {
+	category := &models.TaxCategory{
+		Code:          strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:          strings.TrimSpace(req.Name),
+		Description:   req.Description,
+		IsActive:      true,
+		CreatedBy:     &createdBy,
+		CreatedByName: &createdByName,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := s.taxRepo.CreateCategory(category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// GetTaxCategory gets a tax category with its rate history
+func (s *TaxService) GetTaxCategory(id int) (*models.TaxCategory, error) {
+	category, err := s.taxRepo.GetCategoryByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if category == nil {
+		return nil, errors.New("tax category not found")
+	}
+
+	rates, err := s.taxRepo.GetRatesByCategoryID(id)
+	if err != nil {
+		return nil, err
+	}
+	category.Rates = rates
+	category.CurrentRate = rateAt(rates, time.Now())
+
+	return category, nil
+}
+
+// GetTaxCategories gets all tax categories with the rate currently in effect
+func (s *TaxService) GetTaxCategories(activeOnly bool) ([]*models.TaxCategory, error) {
+	categories, err := s.taxRepo.GetCategories(activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, category := range categories {
+		rates, err := s.taxRepo.GetRatesByCategoryID(category.ID)
+		if err != nil {
+			return nil, err
+		}
+		category.CurrentRate = rateAt(rates, now)
+	}
+
+	return categories, nil
+}
+
+func (s *TaxService) UpdateTaxCategory(id int, req *models.UpdateTaxCategoryRequest) (*models.TaxCategory, error) {
+	category, err := s.taxRepo.GetCategoryByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if category == nil {
+		return nil, errors.New("tax category not found")
+	}
+
+	if req.Name != nil {
+		category.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		category.Description = req.Description
+	}
+	if req.IsActive != nil {
+		category.IsActive = *req.IsActive
+	}
+
+	category.UpdatedAt = time.Now()
+
+	if err := s.taxRepo.UpdateCategory(category); err != nil {
+		return nil, err
+	}
+
+	return s.GetTaxCategory(id)
+}
+
+// AddTaxRate adds an effective-dated rate to a tax category
+func (s *TaxService) AddTaxRate(categoryID int, req *models.CreateTaxRateRequest, createdBy int, createdByName string) (*models.TaxCategory, error) {
+	category, err := s.taxRepo.GetCategoryByID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if category == nil {
+		return nil, errors.New("tax category not found")
+	}
+
+	if req.EffectiveTo != nil && !req.EffectiveTo.After(req.EffectiveFrom) {
+		return nil, errors.New("effective_to must be after effective_from")
+	}
+
+	rates, err := s.taxRepo.GetRatesByCategoryID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if overlapping := overlappingRate(rates, req.EffectiveFrom, req.EffectiveTo); overlapping != nil {
+		return nil, fmt.Errorf("rate overlaps the %v%% rate effective from %s", overlapping.Rate, overlapping.EffectiveFrom.Format("2006-01-02"))
+	}
+
+	rate := &models.TaxRate{
+		TaxCategoryID:  categoryID,
+		Rate:           req.Rate,
+		EffectiveFrom:  req.EffectiveFrom,
+		EffectiveTo:    req.EffectiveTo,
+		LegalReference: req.LegalReference,
+		CreatedBy:      &createdBy,
+		CreatedByName:  &createdByName,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := s.taxRepo.CreateRate(rate); err != nil {
+		return nil, err
+	}
+
+	return s.GetTaxCategory(categoryID)
+}
+
+// CalculateItemTaxes sets the tax of every invoice item and returns the invoice tax total.
+// Items whose product has a tax category are taxed at the category rate in effect at the
+// given time; other items use the fallback rate. The invoice discount is allocated to the
+// items in proportion to their totals before tax is computed. The returned flag reports
+// whether any item was taxed by its tax category.
+func (s *TaxService) CalculateItemTaxes(items []*models.InvoiceItem, priceMode string, invoiceDiscount float64, fallbackRate *float64, at time.Time) (float64, bool, error) {
+	var productIDs []int
+	subtotal := 0.0
+	for _, item := range items {
+		if item.ProductID != nil {
+			productIDs = append(productIDs, *item.ProductID)
+		}
+		subtotal += item.TotalPrice
+	}
+
+	productRates, err := s.taxRepo.GetProductTaxRates(productIDs, at)
+	if err != nil {
+		return 0, false, err
+	}
+
+	discountRatio := 1.0
+	if subtotal > 0 {
+		discountRatio = 1 - invoiceDiscount/subtotal
+	}
+
+	taxTotal := 0.0
+	categorized := false
+	for _, item := range items {
+		item.TaxCategoryID = nil
+		item.TaxRate = 0
+		item.AllocatedTaxAmount = nil
+		if fallbackRate != nil {
+			item.TaxRate = *fallbackRate
+		}
+
+		if item.ProductID != nil {
+			if productRate, ok := productRates[*item.ProductID]; ok {
+				if productRate.Rate == nil {
+					return 0, false, fmt.Errorf("no VAT rate in effect for %s", item.ProductName)
+				}
+				categoryID := productRate.TaxCategoryID
+				item.TaxCategoryID = &categoryID
+				item.TaxRate = *productRate.Rate
+				categorized = true
+			}
+		}
+
+		amount := item.TotalPrice * discountRatio
+		if priceMode == models.PriceModeInclusive {
+			item.TaxableAmount = roundCurrency(amount / (1 + item.TaxRate/100))
+			item.TaxAmount = roundCurrency(amount - item.TaxableAmount)
+		} else {
+			item.TaxableAmount = roundCurrency(amount)
+			item.TaxAmount = roundCurrency(amount * item.TaxRate / 100)
+		}
+
+		taxTotal += item.TaxAmount
+	}
+
+	return roundCurrency(taxTotal), categorized, nil
+}
+
+// AllocateInvoiceTax spreads an invoice tax amount given explicitly over the items already taxed
+// by CalculateItemTaxes, in proportion to their amounts, so that the line shares add up to it.
+// The items keep the rate they were taxed at; their share is stored as AllocatedTaxAmount.
+func (s *TaxService) AllocateInvoiceTax(items []*models.InvoiceItem, priceMode string, taxAmount float64) error {
+	if taxAmount < 0 {
+		return errors.New("tax amount cannot be negative")
+	}
+
+	// Amount of each line after the invoice discount, tax included in inclusive mode
+	amounts := make([]float64, len(items))
+	total := 0.0
+	for i, item := range items {
+		amounts[i] = item.TaxableAmount
+		if priceMode == models.PriceModeInclusive {
+			amounts[i] += item.TaxAmount
+		}
+		total += amounts[i]
+	}
+
+	if total <= 0 {
+		if taxAmount > 0 {
+			return errors.New("tax amount given for an invoice with nothing to tax")
+		}
+		return nil
+	}
+
+	taxable := total
+	if priceMode == models.PriceModeInclusive {
+		taxable = total - taxAmount
+	}
+	if taxAmount > taxable {
+		return errors.New("tax amount exceeds the taxable amount of the invoice")
+	}
+
+	allocated := 0.0
+	for i, item := range items {
+		share := roundCurrency(taxAmount * amounts[i] / total)
+		if i == len(items)-1 {
+			share = roundCurrency(taxAmount - allocated)
+		}
+		allocated += share
+
+		item.AllocatedTaxAmount = &share
+		if priceMode == models.PriceModeInclusive {
+			item.TaxableAmount = roundCurrency(amounts[i] - share)
+		}
+	}
+
+	return nil
+}
+
+// GetVATReport gets the VAT collected per rate for invoices created in [from, to)
+func (s *TaxService) GetVATReport(from, to time.Time) (*models.VATReport, error) {
+	if !to.After(from) {
+		return nil, errors.New("report end date must be after its start date")
+	}
+
+	lines, invoiceCount, err := s.taxRepo.GetVATSummary(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.VATReport{
+		From:         from,
+		To:           to,
+		InvoiceCount: invoiceCount,
+		Lines:        lines,
+	}
+
+	for _, line := range lines {
+		report.TotalTaxable += line.TaxableAmount
+		report.TotalTax += line.TaxAmount
+	}
+
+	return report, nil
+}
+
+// Helper methods
+func rateAt(rates []*models.TaxRate, at time.Time) *models.TaxRate {
+	for _, rate := range rates {
+		if !rate.EffectiveFrom.After(at) && (rate.EffectiveTo == nil || rate.EffectiveTo.After(at)) {
+			return rate
+		}
+	}
+	return nil
+}
+
+// overlappingRate returns a rate whose effective period overlaps [from, to), a nil to being open-ended
+func overlappingRate(rates []*models.TaxRate, from time.Time, to *time.Time) *models.TaxRate {
+	for _, rate := range rates {
+		startsBeforeEnd := to == nil || rate.EffectiveFrom.Before(*to)
+		endsAfterStart := rate.EffectiveTo == nil || rate.EffectiveTo.After(from)
+		if startsBeforeEnd && endsAfterStart {
+			return rate
+		}
+	}
+	return nil
+}
+
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
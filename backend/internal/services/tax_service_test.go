@@ -0,0 +1,177 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"steel-pos-backend/internal/models"
+)
+
+func TestAllocateInvoiceTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		priceMode string
+		items     []*models.InvoiceItem
+		taxAmount float64
+		// Expected share and taxable amount of each item
+		wantShares  []float64
+		wantTaxable []float64
+		wantErr     string
+	}{
+		{
+			name:      "exclusive",
+			priceMode: models.PriceModeExclusive,
+			items: []*models.InvoiceItem{
+				{TaxRate: 0, TaxableAmount: 1000000},
+				{TaxRate: 0, TaxableAmount: 2000000},
+			},
+			taxAmount:   296100,
+			wantShares:  []float64{98700, 197400},
+			wantTaxable: []float64{1000000, 2000000},
+		},
+		{
+			name:      "last line takes the rounding",
+			priceMode: models.PriceModeExclusive,
+			items: []*models.InvoiceItem{
+				{TaxableAmount: 100},
+				{TaxableAmount: 100},
+				{TaxableAmount: 100},
+			},
+			taxAmount:   10,
+			wantShares:  []float64{3.33, 3.33, 3.34},
+			wantTaxable: []float64{100, 100, 100},
+		},
+		{
+			name:      "inclusive",
+			priceMode: models.PriceModeInclusive,
+			items: []*models.InvoiceItem{
+				{TaxRate: 10, TaxableAmount: 1000000, TaxAmount: 100000},
+				{TaxRate: 10, TaxableAmount: 2000000, TaxAmount: 200000},
+			},
+			taxAmount:   330000,
+			wantShares:  []float64{110000, 220000},
+			wantTaxable: []float64{990000, 1980000},
+		},
+		{
+			name:      "negative",
+			priceMode: models.PriceModeExclusive,
+			items:     []*models.InvoiceItem{{TaxableAmount: 1000}},
+			taxAmount: -1,
+			wantErr:   "tax amount cannot be negative",
+		},
+		{
+			name:      "nothing to tax",
+			priceMode: models.PriceModeExclusive,
+			items:     []*models.InvoiceItem{{TaxableAmount: 0}},
+			taxAmount: 100,
+			wantErr:   "nothing to tax",
+		},
+		{
+			name:      "more than the taxable amount",
+			priceMode: models.PriceModeInclusive,
+			items:     []*models.InvoiceItem{{TaxableAmount: 1000}},
+			taxAmount: 600,
+			wantErr:   "exceeds the taxable amount",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates := make([]float64, len(tt.items))
+			taxes := make([]float64, len(tt.items))
+			for i, item := range tt.items {
+				rates[i] = item.TaxRate
+				taxes[i] = item.TaxAmount
+			}
+
+			err := (&TaxService{}).AllocateInvoiceTax(tt.items, tt.priceMode, tt.taxAmount)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AllocateInvoiceTax: %v", err)
+			}
+
+			allocated := 0.0
+			for i, item := range tt.items {
+				if item.AllocatedTaxAmount == nil || *item.AllocatedTaxAmount != tt.wantShares[i] {
+					t.Fatalf("item %d share %v, want %v", i, item.AllocatedTaxAmount, tt.wantShares[i])
+				}
+				if item.TaxableAmount != tt.wantTaxable[i] {
+					t.Fatalf("item %d taxable %v, want %v", i, item.TaxableAmount, tt.wantTaxable[i])
+				}
+				// The line keeps the rate and tax it was computed with
+				if item.TaxRate != rates[i] || item.TaxAmount != taxes[i] {
+					t.Fatalf("item %d rate %v tax %v changed from %v %v", i, item.TaxRate, item.TaxAmount, rates[i], taxes[i])
+				}
+				allocated += *item.AllocatedTaxAmount
+			}
+			if math.Abs(allocated-tt.taxAmount) > 0.001 {
+				t.Fatalf("shares add up to %v, want %v", allocated, tt.taxAmount)
+			}
+		})
+	}
+}
+
+func TestSummarizeVATWithAllocatedTax(t *testing.T) {
+	share := 98700.0
+	items := []*models.InvoiceItem{
+		{TaxRate: 0, TaxableAmount: 1000000, TaxAmount: 0, AllocatedTaxAmount: &share},
+		{TaxRate: 10, TaxableAmount: 500000, TaxAmount: 50000},
+	}
+
+	summary := models.SummarizeVAT(items)
+	if len(summary) != 2 {
+		t.Fatalf("got %d VAT lines, want 2", len(summary))
+	}
+	if summary[0].TaxRate != 0 || summary[0].TaxAmount != 98700 {
+		t.Fatalf("0%% line: rate %v tax %v, want 0 and 98700", summary[0].TaxRate, summary[0].TaxAmount)
+	}
+	if summary[1].TaxRate != 10 || summary[1].TaxAmount != 50000 {
+		t.Fatalf("10%% line: rate %v tax %v, want 10 and 50000", summary[1].TaxRate, summary[1].TaxAmount)
+	}
+}
+
+func TestOverlappingRate(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	timePtr := func(v time.Time) *time.Time { return &v }
+
+	// 10% until the 10th, 8% from the 10th to the 20th
+	rates := []*models.TaxRate{
+		{Rate: 8, EffectiveFrom: day(10), EffectiveTo: timePtr(day(20))},
+		{Rate: 10, EffectiveFrom: day(1), EffectiveTo: timePtr(day(10))},
+	}
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       *time.Time
+		wantRate float64 // 0 when no rate overlaps
+	}{
+		{name: "starts where the last one ends", from: day(20)},
+		{name: "open-ended inside a period", from: day(15), wantRate: 8},
+		{name: "ends where the first one starts", from: day(1).AddDate(0, -1, 0), to: timePtr(day(1))},
+		{name: "ends inside the first period", from: day(1).AddDate(0, -1, 0), to: timePtr(day(2)), wantRate: 10},
+		{name: "covers both", from: day(5), to: timePtr(day(25)), wantRate: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := overlappingRate(rates, tt.from, tt.to)
+			if tt.wantRate == 0 {
+				if got != nil {
+					t.Fatalf("overlaps the %v%% rate, want none", got.Rate)
+				}
+				return
+			}
+			if got == nil || got.Rate != tt.wantRate {
+				t.Fatalf("got %v, want the %v%% rate", got, tt.wantRate)
+			}
+		})
+	}
+}
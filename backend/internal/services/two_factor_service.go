@@ -0,0 +1,331 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/repository"
+	"steel-pos-backend/internal/totp"
+)
+
+// defaultTwoFactorIssuer names the account in authenticator apps when the store has no name
+const defaultTwoFactorIssuer = "Steel POS"
+
+// Recovery codes are shown as two groups of five characters, e.g. 7KQ2M-X9TRA
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+)
+
+// twoFactorQRSize is the size in pixels of the setup QR code
+const twoFactorQRSize = 256
+
+type TwoFactorService struct {
+	twoFactorRepo        *repository.TwoFactorRepository
+	roleService          *RoleService
+	storeSettingsService *StoreSettingsService
+	auditLogService      AuditLogService
+}
+
+func NewTwoFactorService(twoFactorRepo *repository.TwoFactorRepository, roleService *RoleService, storeSettingsService *StoreSettingsService, auditLogService AuditLogService) *TwoFactorService {
+	return &TwoFactorService{
+		twoFactorRepo:        twoFactorRepo,
+		roleService:          roleService,
+		storeSettingsService: storeSettingsService,
+		auditLogService:      auditLogService,
+	}
+}
+
+// GetStatus tells whether a user has two-factor authentication on and whether their role requires it
+func (s *TwoFactorService) GetStatus(user *models.User) (*models.TwoFactorStatus, error) {
+	required, err := s.roleService.RequiresTwoFactor(user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.TwoFactorStatus{Required: required}
+
+	twoFactor, err := s.twoFactorRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if twoFactor == nil || twoFactor.EnabledAt == nil {
+		return status, nil
+	}
+
+	status.Enabled = true
+	status.EnabledAt = twoFactor.EnabledAt
+	status.RecoveryCodesLeft, err = s.twoFactorRepo.CountUnusedRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// IsEnabled reports whether a user has confirmed two-factor setup
+func (s *TwoFactorService) IsEnabled(userID int) (bool, error) {
+	twoFactor, err := s.twoFactorRepo.GetByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+	return twoFactor != nil && twoFactor.EnabledAt != nil, nil
+}
+
+// BeginSetup generates a new secret for a user who has not enabled two-factor authentication yet.
+// It only takes effect once EnableTwoFactor confirms a code generated from it.
+func (s *TwoFactorService) BeginSetup(user *models.User) (*models.TwoFactorSetup, error) {
+	enabled, err := s.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.SavePendingSecret(user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	uri := totp.ProvisioningURI(s.issuer(), user.Username, secret)
+	png, err := totp.PNG(uri, twoFactorQRSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code: %w", err)
+	}
+
+	return &models.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: uri,
+		QRCode:          "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// EnableTwoFactor confirms the pending secret of a user with a code from their authenticator app
+// and returns the recovery codes, which are not shown again
+func (s *TwoFactorService) EnableTwoFactor(user *models.User, code string) ([]string, error) {
+	twoFactor, err := s.twoFactorRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if twoFactor == nil {
+		return nil, errors.New("two-factor setup has not been started")
+	}
+
+	if twoFactor.EnabledAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now(), twoFactor.LastUsedStep)
+	if !ok {
+		return nil, errors.New("invalid two-factor code")
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.Enable(user.ID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	s.logTwoFactorChange(user.ID, "updated", fmt.Sprintf("Enabled two-factor authentication for %s", user.Username),
+		map[string]interface{}{"two_factor": true}, user.ID, user.FullName)
+
+	return codes, nil
+}
+
+// Verify checks a code from the authenticator app, or a recovery code when code is empty. Each
+// authenticator code and each recovery code is accepted only once.
+func (s *TwoFactorService) Verify(user *models.User, code, recoveryCode string) error {
+	twoFactor, err := s.twoFactorRepo.GetByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+
+	if twoFactor == nil || twoFactor.EnabledAt == nil {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if code != "" {
+		step, ok := totp.Validate(twoFactor.Secret, code, time.Now(), twoFactor.LastUsedStep)
+		if ok {
+			ok, err = s.twoFactorRepo.UseStep(user.ID, step)
+			if err != nil {
+				return err
+			}
+		}
+		if !ok {
+			return errors.New("invalid two-factor code")
+		}
+		return nil
+	}
+
+	if recoveryCode != "" {
+		used, err := s.twoFactorRepo.UseRecoveryCode(user.ID, hashRecoveryCode(recoveryCode))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return errors.New("invalid recovery code")
+		}
+
+		s.logTwoFactorChange(user.ID, "updated", fmt.Sprintf("Used a recovery code for %s", user.Username),
+			map[string]interface{}{"recovery_code_used": true}, user.ID, user.FullName)
+		return nil
+	}
+
+	return errors.New("two-factor code is required")
+}
+
+// VerifyAny checks a code that may be either from the authenticator app or a recovery code
+func (s *TwoFactorService) VerifyAny(user *models.User, code string) error {
+	if len(strings.TrimSpace(code)) == totp.Digits {
+		return s.Verify(user, code, "")
+	}
+	return s.Verify(user, "", code)
+}
+
+// DisableTwoFactor turns off two-factor authentication of a user whose role does not require it
+func (s *TwoFactorService) DisableTwoFactor(user *models.User, code string) error {
+	required, err := s.roleService.RequiresTwoFactor(user.Role)
+	if err != nil {
+		return err
+	}
+
+	if required {
+		return fmt.Errorf("two-factor authentication is required for role %s", user.Role)
+	}
+
+	if err := s.VerifyAny(user, code); err != nil {
+		return err
+	}
+
+	if err := s.twoFactorRepo.Delete(user.ID); err != nil {
+		return err
+	}
+
+	s.logTwoFactorChange(user.ID, "updated", fmt.Sprintf("Disabled two-factor authentication for %s", user.Username),
+		map[string]interface{}{"two_factor": false}, user.ID, user.FullName)
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after checking a code from their
+// authenticator app
+func (s *TwoFactorService) RegenerateRecoveryCodes(user *models.User, code string) ([]string, error) {
+	if err := s.Verify(user, code, ""); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	s.logTwoFactorChange(user.ID, "updated", fmt.Sprintf("Regenerated recovery codes for %s", user.Username),
+		map[string]interface{}{"recovery_codes_regenerated": true}, user.ID, user.FullName)
+
+	return codes, nil
+}
+
+// ResetTwoFactor removes the two-factor enrollment of a user who lost their authenticator app and
+// recovery codes. If their role requires two-factor authentication, they set it up again at their
+// next login.
+func (s *TwoFactorService) ResetTwoFactor(user *models.User, resetBy int, resetByName string) error {
+	if err := s.twoFactorRepo.Delete(user.ID); err != nil {
+		return err
+	}
+
+	s.logTwoFactorChange(user.ID, "updated", fmt.Sprintf("Reset two-factor authentication for %s", user.Username),
+		map[string]interface{}{"two_factor": false}, resetBy, resetByName)
+
+	return nil
+}
+
+// Helper methods
+
+// issuer is the store name shown next to the account in authenticator apps
+func (s *TwoFactorService) issuer() string {
+	if s.storeSettingsService != nil {
+		if settings, err := s.storeSettingsService.GetSettings(); err == nil && strings.TrimSpace(settings.Name) != "" {
+			return strings.TrimSpace(settings.Name)
+		}
+	}
+	return defaultTwoFactorIssuer
+}
+
+func (s *TwoFactorService) logTwoFactorChange(targetUserID int, action, summary string, data map[string]interface{}, userID int, userName string) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	req := models.AuditLogCreateRequest{
+		EntityType:     "user",
+		EntityID:       targetUserID,
+		Action:         action,
+		UserID:         &userID,
+		UserName:       &userName,
+		NewData:        data,
+		ChangesSummary: &summary,
+	}
+
+	if _, err := s.auditLogService.CreateAuditLog(req); err != nil {
+		// Log error but don't fail the two-factor change
+		log.Printf("Failed to create audit log for two-factor change of user %d: %v", targetUserID, err)
+	}
+}
+
+// generateRecoveryCodes returns new recovery codes and the hashes to store for them
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		var code strings.Builder
+		for j, v := range b {
+			if j == 5 {
+				code.WriteByte('-')
+			}
+			code.WriteByte(recoveryCodeAlphabet[int(v)%len(recoveryCodeAlphabet)])
+		}
+
+		codes[i] = code.String()
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code ignoring case, spaces and dashes, so it can be typed
+// loosely
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
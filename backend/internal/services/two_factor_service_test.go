@@ -0,0 +1,44 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes: %v", err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d", len(codes), len(hashes), recoveryCodeCount)
+	}
+
+	format := regexp.MustCompile(`^[` + recoveryCodeAlphabet + `]{5}-[` + recoveryCodeAlphabet + `]{5}$`)
+	seen := map[string]bool{}
+	for i, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("code %q is not two groups of 5 characters", code)
+		}
+		if hashes[i] != hashRecoveryCode(code) {
+			t.Errorf("hash of code %d does not match", i)
+		}
+		if seen[code] {
+			t.Errorf("code %q generated twice", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCodeTypedLoosely(t *testing.T) {
+	want := hashRecoveryCode("ABCDE-FGHJK")
+	for _, typed := range []string{"abcde-fghjk", "ABCDEFGHJK", " abcde fghjk "} {
+		if got := hashRecoveryCode(typed); got != want {
+			t.Errorf("hash of %q differs from ABCDE-FGHJK", typed)
+		}
+	}
+
+	if hashRecoveryCode("ABCDE-FGHJL") == want {
+		t.Error("different codes hash the same")
+	}
+}
@@ -0,0 +1,207 @@
+package tokenstore
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps sessions and refresh tokens in memory. They are lost on restart, so it
+// is meant for tests and single-instance development setups.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+	tokens   map[string]*RefreshToken
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: map[string]*Session{},
+		tokens:   map[string]*RefreshToken{},
+	}
+}
+
+func (s *MemoryStore) CreateSession(session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *session
+	s.sessions[session.ID] = &saved
+	return nil
+}
+
+func (s *MemoryStore) GetSession(id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return s.withExpiry(session), nil
+}
+
+func (s *MemoryStore) GetUserSessions(userID int) ([]*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	sessions := []*Session{}
+	for _, session := range s.sessions {
+		if session.UserID != userID || session.RevokedAt != nil {
+			continue
+		}
+		if found := s.withExpiry(session); found.ExpiresAt.After(now) {
+			sessions = append(sessions, found)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+func (s *MemoryStore) TouchSession(id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[id]; ok {
+		session.LastSeenAt = at
+	}
+	return nil
+}
+
+func (s *MemoryStore) RevokeSession(id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[id]; ok && session.RevokedAt == nil {
+		session.RevokedAt = &at
+	}
+	for _, token := range s.tokens {
+		if token.SessionID == id && token.RevokedAt == nil {
+			token.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) RevokeUser(userID int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revoked := make(map[string]bool)
+	for _, session := range s.sessions {
+		if session.UserID == userID || session.CurrentUserID() == userID {
+			revoked[session.ID] = true
+			if session.RevokedAt == nil {
+				session.RevokedAt = &at
+			}
+		}
+	}
+	for _, token := range s.tokens {
+		if (token.UserID == userID || revoked[token.SessionID]) && token.RevokedAt == nil {
+			token.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) SwitchUser(id string, userID int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return errors.New("session has been revoked")
+	}
+
+	if userID == session.UserID {
+		session.ActiveUserID = nil
+	} else {
+		session.ActiveUserID = &userID
+	}
+	session.LastSeenAt = at
+
+	for _, token := range s.tokens {
+		if token.SessionID == id && token.RevokedAt == nil {
+			token.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Save(token *RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *token
+	s.tokens[token.ID] = &saved
+	return nil
+}
+
+func (s *MemoryStore) Get(id string) (*RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[id]
+	if !ok || !token.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+
+	found := *token
+	return &found, nil
+}
+
+func (s *MemoryStore) MarkUsed(id, replacedBy string, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[id]
+	if !ok || token.UsedAt != nil || token.RevokedAt != nil {
+		return false, nil
+	}
+
+	token.UsedAt = &at
+	token.ReplacedBy = &replacedBy
+	return true, nil
+}
+
+func (s *MemoryStore) DeleteExpired(before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed int64
+	live := map[string]bool{}
+	for id, token := range s.tokens {
+		if !token.ExpiresAt.After(before) {
+			delete(s.tokens, id)
+			removed++
+			continue
+		}
+		live[token.SessionID] = true
+	}
+
+	// Sessions are created just before their first token, so recent ones are left alone
+	for id, session := range s.sessions {
+		if !live[id] && session.CreatedAt.Before(before.Add(-time.Hour)) {
+			delete(s.sessions, id)
+		}
+	}
+	return removed, nil
+}
+
+// withExpiry copies a session with the expiry of its newest refresh token. The caller holds the lock.
+func (s *MemoryStore) withExpiry(session *Session) *Session {
+	found := *session
+	found.ExpiresAt = session.CreatedAt
+	for _, token := range s.tokens {
+		if token.SessionID == session.ID && token.ExpiresAt.After(found.ExpiresAt) {
+			found.ExpiresAt = token.ExpiresAt
+		}
+	}
+	return &found
+}
@@ -0,0 +1,290 @@
+package tokenstore_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"steel-pos-backend/internal/config"
+	"steel-pos-backend/internal/models"
+	"steel-pos-backend/internal/services"
+	"steel-pos-backend/internal/tokenstore"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testUsers stands in for the user repository
+type testUsers map[int]*models.User
+
+func (u testUsers) GetByID(id int) (*models.User, error) {
+	user, ok := u[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	found := *user
+	return &found, nil
+}
+
+func newTestJWTService(t *testing.T) (*services.JWTService, *tokenstore.MemoryStore) {
+	jwtService, store, _ := newTestJWTServiceWithUsers(t)
+	return jwtService, store
+}
+
+func newTestJWTServiceWithUsers(t *testing.T) (*services.JWTService, *tokenstore.MemoryStore, testUsers) {
+	t.Helper()
+
+	store := tokenstore.NewMemoryStore()
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			Secret:             "test-secret",
+			AccessTokenExpiry:  "15m",
+			RefreshTokenExpiry: "24h",
+		},
+	}
+	users := testUsers{1: {ID: 1, Username: "cashier", Role: "user", IsActive: true}}
+	return services.NewJWTService(cfg, store, users), store, users
+}
+
+func startTestSession(t *testing.T, jwtService *services.JWTService) (string, string) {
+	t.Helper()
+
+	user := &models.User{ID: 1, Username: "cashier", Role: "user"}
+	accessToken, refreshToken, err := jwtService.StartSession(user, nil, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	return accessToken, refreshToken
+}
+
+// ageExchange moves the time a refresh token was exchanged back by d, as if d had passed since
+func ageExchange(t *testing.T, store *tokenstore.MemoryStore, refreshToken string, d time.Duration) {
+	t.Helper()
+
+	claims := &jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(refreshToken, claims); err != nil {
+		t.Fatalf("parse refresh token: %v", err)
+	}
+
+	stored, err := store.Get(claims.ID)
+	if err != nil || stored == nil || stored.UsedAt == nil {
+		t.Fatalf("refresh token %s not exchanged: %v", claims.ID, err)
+	}
+
+	usedAt := stored.UsedAt.Add(-d)
+	stored.UsedAt = &usedAt
+	if err := store.Save(stored); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	jwtService, _ := newTestJWTService(t)
+	_, refreshToken := startTestSession(t, jwtService)
+
+	first, err := jwtService.RefreshToken(refreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if first.RefreshToken == refreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+	if _, err := jwtService.ValidateAccessToken(first.AccessToken); err != nil {
+		t.Fatalf("new access token rejected: %v", err)
+	}
+
+	second, err := jwtService.RefreshToken(first.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken with rotated token: %v", err)
+	}
+	if _, err := jwtService.ValidateAccessToken(second.AccessToken); err != nil {
+		t.Fatalf("access token of second rotation rejected: %v", err)
+	}
+}
+
+func TestRefreshReuseWithinGraceWindow(t *testing.T) {
+	jwtService, _ := newTestJWTService(t)
+	_, refreshToken := startTestSession(t, jwtService)
+
+	first, err := jwtService.RefreshToken(refreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+
+	// A concurrent request presenting the same token a moment later is not treated as theft,
+	// and gets the refresh token the first request was given
+	again, err := jwtService.RefreshToken(refreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken within grace window: %v", err)
+	}
+	if again.RefreshToken != first.RefreshToken {
+		t.Fatal("refresh token presented again within grace window was exchanged for another token")
+	}
+	if _, err := jwtService.ValidateAccessToken(again.AccessToken); err != nil {
+		t.Fatalf("access token issued within grace window rejected: %v", err)
+	}
+
+	// Both requests continue the same family
+	if _, err := jwtService.RefreshToken(first.RefreshToken); err != nil {
+		t.Fatalf("RefreshToken with replacement: %v", err)
+	}
+}
+
+func TestRefreshReuseAfterReplacementExchanged(t *testing.T) {
+	jwtService, _ := newTestJWTService(t)
+	_, refreshToken := startTestSession(t, jwtService)
+
+	first, err := jwtService.RefreshToken(refreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if _, err := jwtService.RefreshToken(first.RefreshToken); err != nil {
+		t.Fatalf("RefreshToken with replacement: %v", err)
+	}
+
+	_, err = jwtService.RefreshToken(refreshToken)
+	if !errors.Is(err, services.ErrRefreshTokenExchanged) {
+		t.Fatalf("got %v, want %v", err, services.ErrRefreshTokenExchanged)
+	}
+}
+
+func TestRefreshUsesCurrentRole(t *testing.T) {
+	jwtService, _, users := newTestJWTServiceWithUsers(t)
+	_, refreshToken := startTestSession(t, jwtService)
+
+	users[1].Role = "manager"
+
+	refreshed, err := jwtService.RefreshToken(refreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	claims, err := jwtService.ValidateAccessToken(refreshed.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+	if claims.Role != "manager" || refreshed.User.Role != "manager" {
+		t.Fatalf("got role %q, want manager", claims.Role)
+	}
+}
+
+func TestRefreshRejectsDeactivatedUser(t *testing.T) {
+	jwtService, _, users := newTestJWTServiceWithUsers(t)
+	_, refreshToken := startTestSession(t, jwtService)
+
+	users[1].IsActive = false
+
+	if _, err := jwtService.RefreshToken(refreshToken); err == nil || !strings.Contains(err.Error(), "deactivated") {
+		t.Fatalf("got %v, want account is deactivated", err)
+	}
+
+	delete(users, 1)
+
+	if _, err := jwtService.RefreshToken(refreshToken); err == nil {
+		t.Fatal("refresh token of a deleted user accepted")
+	}
+}
+
+func TestRefreshReuseRevokesSession(t *testing.T) {
+	jwtService, store := newTestJWTService(t)
+	accessToken, refreshToken := startTestSession(t, jwtService)
+
+	rotated, err := jwtService.RefreshToken(refreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+
+	ageExchange(t, store, refreshToken, 11*time.Second)
+
+	_, err = jwtService.RefreshToken(refreshToken)
+	if err == nil || !strings.Contains(err.Error(), "reuse detected") {
+		t.Fatalf("reused refresh token: got %v, want reuse detected", err)
+	}
+
+	// The whole session is revoked, the legitimate holder included
+	if _, err := jwtService.RefreshToken(rotated.RefreshToken); err == nil {
+		t.Fatal("rotated refresh token still accepted after reuse")
+	}
+	if _, err := jwtService.ValidateAccessToken(accessToken); err == nil {
+		t.Fatal("access token still accepted after reuse")
+	}
+	if _, err := jwtService.ValidateAccessToken(rotated.AccessToken); err == nil {
+		t.Fatal("rotated access token still accepted after reuse")
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	jwtService, _ := newTestJWTService(t)
+	accessToken, refreshToken := startTestSession(t, jwtService)
+
+	if err := jwtService.RevokeRefreshToken(refreshToken); err != nil {
+		t.Fatalf("RevokeRefreshToken: %v", err)
+	}
+
+	if _, err := jwtService.ValidateAccessToken(accessToken); err == nil {
+		t.Fatal("access token still accepted after logout")
+	}
+	if _, err := jwtService.RefreshToken(refreshToken); err == nil {
+		t.Fatal("refresh token still accepted after logout")
+	}
+}
+
+func TestTwoFactorChallengeToken(t *testing.T) {
+	jwtService, _ := newTestJWTService(t)
+	user := &models.User{ID: 1, Username: "cashier", Role: "user"}
+
+	challenge, expiresIn, err := jwtService.GenerateChallengeToken(user)
+	if err != nil {
+		t.Fatalf("GenerateChallengeToken: %v", err)
+	}
+	if expiresIn != 300 {
+		t.Fatalf("challenge expires in %ds, want 300", expiresIn)
+	}
+
+	claims, err := jwtService.ValidateChallengeToken(challenge)
+	if err != nil || claims.UserID != 1 {
+		t.Fatalf("ValidateChallengeToken: %v", err)
+	}
+
+	// The password alone does not give access
+	if _, err := jwtService.ValidateAccessToken(challenge); err == nil || !strings.Contains(err.Error(), "two-factor") {
+		t.Fatalf("challenge token used as access token: got %v", err)
+	}
+
+	// Nor can a session token skip the second factor
+	accessToken, refreshToken := startTestSession(t, jwtService)
+	for _, token := range []string{accessToken, refreshToken} {
+		if _, err := jwtService.ValidateChallengeToken(token); err == nil {
+			t.Fatal("session token accepted as two-factor challenge token")
+		}
+	}
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	jwtService, _ := newTestJWTService(t)
+	currentAccess, currentRefresh := startTestSession(t, jwtService)
+	otherAccess, otherRefresh := startTestSession(t, jwtService)
+
+	current, err := jwtService.ValidateAccessToken(currentAccess)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+
+	if err := jwtService.RevokeOtherSessions(1, current.SessionID); err != nil {
+		t.Fatalf("RevokeOtherSessions: %v", err)
+	}
+
+	if _, err := jwtService.ValidateAccessToken(otherAccess); err == nil {
+		t.Fatal("access token of another session still accepted")
+	}
+	if _, err := jwtService.RefreshToken(otherRefresh); err == nil {
+		t.Fatal("refresh token of another session still accepted")
+	}
+
+	// The session the password was changed in stays logged in
+	if _, err := jwtService.ValidateAccessToken(currentAccess); err != nil {
+		t.Fatalf("current access token rejected: %v", err)
+	}
+	if _, err := jwtService.RefreshToken(currentRefresh); err != nil {
+		t.Fatalf("current refresh token rejected: %v", err)
+	}
+}
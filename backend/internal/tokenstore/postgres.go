@@ -0,0 +1,260 @@
+package tokenstore
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PostgresStore keeps sessions in the user_sessions table and refresh tokens in the
+// refresh_tokens table
+type PostgresStore struct {
+	db *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+const sessionColumns = `
+	s.id, s.user_id, s.active_user_id, s.device_name, s.ip_address, s.user_agent, s.created_at, s.last_seen_at,
+	COALESCE(MAX(t.expires_at), s.created_at), s.revoked_at`
+
+func (s *PostgresStore) CreateSession(session *Session) error {
+	_, err := s.db.Exec(`
+		INSERT INTO user_sessions (id, user_id, device_name, ip_address, user_agent, created_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, session.ID, session.UserID, session.DeviceName, session.IPAddress, session.UserAgent, session.CreatedAt, session.LastSeenAt)
+
+	return err
+}
+
+func (s *PostgresStore) GetSession(id string) (*Session, error) {
+	session, err := scanSession(s.db.QueryRow(`
+		SELECT `+sessionColumns+`
+		FROM user_sessions s
+		LEFT JOIN refresh_tokens t ON t.session_id = s.id
+		WHERE s.id = $1
+		GROUP BY s.id
+	`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *PostgresStore) GetUserSessions(userID int) ([]*Session, error) {
+	rows, err := s.db.Query(`
+		SELECT `+sessionColumns+`
+		FROM user_sessions s
+		JOIN refresh_tokens t ON t.session_id = s.id
+		WHERE s.user_id = $1 AND s.revoked_at IS NULL
+		GROUP BY s.id
+		HAVING MAX(t.expires_at) > CURRENT_TIMESTAMP
+		ORDER BY s.last_seen_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (s *PostgresStore) TouchSession(id string, at time.Time) error {
+	_, err := s.db.Exec(`UPDATE user_sessions SET last_seen_at = $2 WHERE id = $1`, id, at)
+	return err
+}
+
+func (s *PostgresStore) RevokeSession(id string, at time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE user_sessions SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`, id, at); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = $2 WHERE session_id = $1 AND revoked_at IS NULL`, id, at); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *PostgresStore) RevokeUser(userID int, at time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE user_sessions SET revoked_at = $2 WHERE (user_id = $1 OR active_user_id = $1) AND revoked_at IS NULL`, userID, at); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE refresh_tokens SET revoked_at = $2
+		WHERE (user_id = $1 OR session_id IN (SELECT id FROM user_sessions WHERE user_id = $1 OR active_user_id = $1))
+			AND revoked_at IS NULL
+	`, userID, at)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *PostgresStore) SwitchUser(id string, userID int, at time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The user who logged in switching back is stored as NULL
+	result, err := tx.Exec(`
+		UPDATE user_sessions SET active_user_id = NULLIF($2, user_id), last_seen_at = $3
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id, userID, at)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("session has been revoked")
+	}
+
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = $2 WHERE session_id = $1 AND revoked_at IS NULL`, id, at); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *PostgresStore) Save(token *RefreshToken) error {
+	_, err := s.db.Exec(`
+		INSERT INTO refresh_tokens (id, session_id, user_id, issued_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, token.ID, token.SessionID, token.UserID, token.IssuedAt, token.ExpiresAt)
+
+	return err
+}
+
+func (s *PostgresStore) Get(id string) (*RefreshToken, error) {
+	token := &RefreshToken{}
+	err := s.db.QueryRow(`
+		SELECT id, session_id, user_id, issued_at, expires_at, used_at, replaced_by, revoked_at
+		FROM refresh_tokens
+		WHERE id = $1 AND expires_at > CURRENT_TIMESTAMP
+	`, id).Scan(
+		&token.ID,
+		&token.SessionID,
+		&token.UserID,
+		&token.IssuedAt,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.ReplacedBy,
+		&token.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (s *PostgresStore) MarkUsed(id, replacedBy string, at time.Time) (bool, error) {
+	result, err := s.db.Exec(`
+		UPDATE refresh_tokens
+		SET used_at = $2, replaced_by = $3
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL
+	`, id, at, replacedBy)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+func (s *PostgresStore) DeleteExpired(before time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM refresh_tokens WHERE expires_at <= $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	// Sessions are created just before their first token, so recent ones are left alone
+	_, err = s.db.Exec(`
+		DELETE FROM user_sessions s
+		WHERE s.created_at <= $1::timestamptz - INTERVAL '1 hour'
+		  AND NOT EXISTS (SELECT 1 FROM refresh_tokens t WHERE t.session_id = s.id)
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
+
+// Helper methods
+
+type sessionScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSession(scanner sessionScanner) (*Session, error) {
+	session := &Session{}
+	var ipAddress, userAgent sql.NullString
+	err := scanner.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.ActiveUserID,
+		&session.DeviceName,
+		&ipAddress,
+		&userAgent,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	session.IPAddress = ipAddress.String
+	session.UserAgent = userAgent.String
+
+	return session, nil
+}
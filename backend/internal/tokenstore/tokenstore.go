@@ -0,0 +1,91 @@
+// Package tokenstore keeps track of login sessions and the refresh tokens issued to them, so
+// that tokens can be rotated on use and sessions revoked. Presenting a refresh token that was
+// already exchanged revokes its whole session.
+package tokenstore
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"steel-pos-backend/internal/config"
+)
+
+// Session is a login of a user on a device. Access and refresh tokens carry its ID.
+type Session struct {
+	ID           string
+	UserID       int  // user who logged in
+	ActiveUserID *int // user switched to with a PIN, nil while the user who logged in is active
+	DeviceName   *string
+	IPAddress    string
+	UserAgent    string
+	CreatedAt    time.Time
+	LastSeenAt   time.Time
+	ExpiresAt    time.Time // expiry of its newest refresh token
+	RevokedAt    *time.Time
+}
+
+// CurrentUserID is the user working on the session, whom its tokens are issued to
+func (s *Session) CurrentUserID() int {
+	if s.ActiveUserID != nil {
+		return *s.ActiveUserID
+	}
+	return s.UserID
+}
+
+// RefreshToken is the server-side record of an issued refresh token
+type RefreshToken struct {
+	ID         string // jti claim of the token
+	SessionID  string
+	UserID     int
+	IssuedAt   time.Time
+	ExpiresAt  time.Time
+	UsedAt     *time.Time // when it was exchanged for a new token
+	ReplacedBy *string
+	RevokedAt  *time.Time
+}
+
+// Store persists sessions and refresh tokens
+type Store interface {
+	// CreateSession records a new login
+	CreateSession(session *Session) error
+	// GetSession gets a session by ID, nil when it is unknown
+	GetSession(id string) (*Session, error)
+	// GetUserSessions gets the sessions of a user that are neither revoked nor expired,
+	// most recently seen first
+	GetUserSessions(userID int) ([]*Session, error)
+	// TouchSession records that a session was used
+	TouchSession(id string, at time.Time) error
+	// RevokeSession revokes a session and every refresh token issued to it
+	RevokeSession(id string, at time.Time) error
+	// RevokeUser revokes every session and refresh token of a user, including sessions the
+	// user is the active user of
+	RevokeUser(userID int, at time.Time) error
+	// SwitchUser makes another user the active user of a session and revokes the refresh
+	// tokens issued to the session so far
+	SwitchUser(id string, userID int, at time.Time) error
+
+	// Save records a newly issued refresh token
+	Save(token *RefreshToken) error
+	// Get gets a refresh token by ID, nil when it is unknown or expired
+	Get(id string) (*RefreshToken, error)
+	// MarkUsed records that a token was exchanged for replacedBy. It reports false when the
+	// token was already used or revoked, so that two exchanges of one token cannot both succeed.
+	MarkUsed(id, replacedBy string, at time.Time) (bool, error)
+
+	// DeleteExpired removes expired refresh tokens, and sessions left without any, and
+	// returns how many tokens were removed
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+// NewStore creates the store selected in the configuration
+func NewStore(cfg config.JWTConfig, db *sql.DB) (Store, error) {
+	switch cfg.RefreshTokenStore {
+	case "", "postgres":
+		return NewPostgresStore(db), nil
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown refresh token store %q", cfg.RefreshTokenStore)
+	}
+}
@@ -0,0 +1,120 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors, "12345678901234567890", in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B, the last 6 of the 8 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Fatal("invalid secret accepted")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	at := time.Unix(1111111111, 0)
+	step := Step(at)
+	code := func(step int64) string {
+		c, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name         string
+		code         string
+		lastUsedStep int64
+		wantStep     int64 // 0 when the code is rejected
+	}{
+		{"current step", code(step), 0, step},
+		{"previous step for clock drift", code(step - 1), 0, step - 1},
+		{"next step for clock drift", code(step + 1), 0, step + 1},
+		{"typed with spaces", code(step)[:3] + " " + code(step)[3:], 0, step},
+		{"two steps ago", code(step - 2), 0, 0},
+		{"already used", code(step), step, 0},
+		{"next step after the current one was used", code(step + 1), step, step + 1},
+		{"too short", code(step)[:5], 0, 0},
+		{"wrong code", "000000", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Validate(rfcSecret, tt.code, at, tt.lastUsedStep)
+			if tt.wantStep == 0 {
+				if ok {
+					t.Fatalf("code accepted at step %d, want rejected", got)
+				}
+				return
+			}
+			if !ok || got != tt.wantStep {
+				t.Fatalf("got step %d %v, want %d", got, ok, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	if len(secret) != 32 {
+		t.Fatalf("secret %q has %d characters, want 32", secret, len(secret))
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Fatalf("generated secret is not usable: %v", err)
+	}
+
+	other, _ := GenerateSecret()
+	if other == secret {
+		t.Fatal("two generated secrets are equal")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	got := ProvisioningURI("Sắt Thép Kiên Phước", "cashier", rfcSecret)
+
+	want := "otpauth://totp/S%E1%BA%AFt%20Th%C3%A9p%20Ki%C3%AAn%20Ph%C6%B0%E1%BB%9Bc:cashier?"
+	if !strings.HasPrefix(got, want) {
+		t.Fatalf("got %s, want prefix %s", got, want)
+	}
+	for _, param := range []string{"secret=" + rfcSecret, "digits=6", "period=30", "algorithm=SHA1", "issuer=S%E1%BA%AFt%20Th%C3%A9p"} {
+		if !strings.Contains(got, param) {
+			t.Errorf("%s is missing %s", got, param)
+		}
+	}
+	if strings.Contains(got, "+") {
+		t.Errorf("%s encodes spaces as +", got)
+	}
+}
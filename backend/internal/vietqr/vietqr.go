@@ -0,0 +1,114 @@
+// Package vietqr builds VietQR payment codes, the NAPAS profile of the EMVCo merchant
+// presented QR format that Vietnamese banking apps scan to prefill a transfer.
+package vietqr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	napasGUID       = "A000000727"
+	serviceTransfer = "QRIBFTTA" // transfer to a bank account
+	currencyVND     = "704"
+	countryVN       = "VN"
+
+	// maxReferenceLength is the longest purpose of transaction banks accept
+	maxReferenceLength = 25
+)
+
+// Transfer describes a bank transfer to encode
+type Transfer struct {
+	BankBIN       string
+	AccountNumber string
+	Amount        int64 // in dong; 0 lets the payer enter the amount
+	Reference     string
+}
+
+// Payload returns the VietQR string for a transfer
+func Payload(transfer Transfer) (string, error) {
+	if transfer.BankBIN == "" || transfer.AccountNumber == "" {
+		return "", errors.New("bank BIN and account number are required")
+	}
+
+	if transfer.Amount < 0 {
+		return "", errors.New("amount must not be negative")
+	}
+
+	beneficiary := field("00", transfer.BankBIN) + field("01", transfer.AccountNumber)
+	merchantAccount := field("00", napasGUID) + field("01", beneficiary) + field("02", serviceTransfer)
+
+	// Static codes can be paid repeatedly, dynamic codes carry the amount of one payment
+	initiation := "11"
+	if transfer.Amount > 0 {
+		initiation = "12"
+	}
+
+	var b strings.Builder
+	b.WriteString(field("00", "01"))
+	b.WriteString(field("01", initiation))
+	b.WriteString(field("38", merchantAccount))
+	b.WriteString(field("53", currencyVND))
+	if transfer.Amount > 0 {
+		b.WriteString(field("54", strconv.FormatInt(transfer.Amount, 10)))
+	}
+	b.WriteString(field("58", countryVN))
+	if transfer.Reference != "" {
+		b.WriteString(field("62", field("08", transfer.Reference)))
+	}
+
+	// The checksum covers the payload up to and including its own tag and length
+	b.WriteString("6304")
+	payload := b.String()
+
+	return payload + fmt.Sprintf("%04X", crc16(payload)), nil
+}
+
+// PNG renders a payload as a QR code image of the given size in pixels
+func PNG(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
+
+// Reference turns an invoice code into a transfer reference. Banks drop punctuation from
+// transfer descriptions, so only letters and digits are kept, e.g. "INV-2024-001" becomes
+// "INV2024001".
+func Reference(invoiceCode string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(invoiceCode) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+
+	reference := b.String()
+	if len(reference) > maxReferenceLength {
+		reference = reference[:maxReferenceLength]
+	}
+	return reference
+}
+
+// Helper methods
+func field(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+// crc16 computes the CRC-16/CCITT-FALSE checksum required by EMVCo
+func crc16(data string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
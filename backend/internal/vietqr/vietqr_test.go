@@ -0,0 +1,108 @@
+package vietqr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCRC16(t *testing.T) {
+	tests := []struct {
+		data string
+		want uint16
+	}{
+		// Check value of CRC-16/CCITT-FALSE
+		{"123456789", 0x29B1},
+		{"", 0xFFFF},
+		{"A", 0xB915},
+	}
+
+	for _, tt := range tests {
+		if got := crc16(tt.data); got != tt.want {
+			t.Errorf("crc16(%q) = %04X, want %04X", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		transfer Transfer
+		want     string
+	}{
+		{
+			name: "dynamic with amount and reference",
+			transfer: Transfer{
+				BankBIN:       "970436",
+				AccountNumber: "0011001234567",
+				Amount:        1500000,
+				Reference:     "INV2024001",
+			},
+			want: "00020101021238570010A00000072701270006970436011300110012345670208QRIBFTTA" +
+				"5303704540715000005802VN62140810INV20240016304B518",
+		},
+		{
+			name: "static without amount",
+			transfer: Transfer{
+				BankBIN:       "970436",
+				AccountNumber: "0011001234567",
+			},
+			want: "00020101021138570010A00000072701270006970436011300110012345670208QRIBFTTA" +
+				"53037045802VN6304E8DB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Payload(tt.transfer)
+			if err != nil {
+				t.Fatalf("Payload: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got  %s\nwant %s", got, tt.want)
+			}
+
+			// The checksum covers everything before it
+			body, checksum := got[:len(got)-4], got[len(got)-4:]
+			if want := fmt.Sprintf("%04X", crc16(body)); checksum != want {
+				t.Fatalf("checksum %s, want %s", checksum, want)
+			}
+		})
+	}
+}
+
+func TestPayloadInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		transfer Transfer
+		wantErr  string
+	}{
+		{"without bank", Transfer{AccountNumber: "0011001234567"}, "bank BIN and account number are required"},
+		{"without account", Transfer{BankBIN: "970436"}, "bank BIN and account number are required"},
+		{"negative amount", Transfer{BankBIN: "970436", AccountNumber: "0011001234567", Amount: -1}, "amount must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Payload(tt.transfer)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReference(t *testing.T) {
+	tests := map[string]string{
+		"INV-2024-001":                    "INV2024001",
+		"hd 0012/24":                      "HD001224",
+		"Đơn-01":                          "N01",
+		"INV-2024-0000000000000000000001": "INV2024000000000000000000", // cut at 25 characters
+	}
+
+	for code, want := range tests {
+		if got := Reference(code); got != want {
+			t.Errorf("Reference(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
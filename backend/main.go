@@ -3,13 +3,17 @@ package main
 import (
 	"log"
 	"net/http"
+	"time"
 
 	"steel-pos-backend/internal/config"
+	"steel-pos-backend/internal/einvoice"
 	"steel-pos-backend/internal/handlers"
+	"steel-pos-backend/internal/loginattempt"
 	"steel-pos-backend/internal/middleware"
 	"steel-pos-backend/internal/repository"
 	"steel-pos-backend/internal/routes"
 	"steel-pos-backend/internal/services"
+	"steel-pos-backend/internal/tokenstore"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
@@ -32,20 +36,102 @@ func main() {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	productRepo := repository.NewProductRepository(db)
+	priceHistoryRepo := repository.NewPriceHistoryRepository(db)
 	importOrderRepo := repository.NewImportOrderRepository(db)
 	invoiceRepo := repository.NewInvoiceRepository(db)
 	customerRepo := repository.NewCustomerRepository(db)
 	auditLogRepo := repository.NewAuditLogRepository(sqlxDB)
+	priceUpdateRepo := repository.NewPriceUpdateRepository(db)
+	promotionRepo := repository.NewPromotionRepository(db)
+	taxRepo := repository.NewTaxRepository(db)
+	einvoiceRepo := repository.NewEInvoiceRepository(db)
+	bankStatementRepo := repository.NewBankStatementRepository(db)
+	cashShiftRepo := repository.NewCashShiftRepository(db)
+	paymentMethodRepo := repository.NewPaymentMethodRepository(db)
+	customerDepositRepo := repository.NewCustomerDepositRepository(db)
+	storeSettingsRepo := repository.NewStoreSettingsRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	branchRepo := repository.NewBranchRepository(db)
+
+	refreshTokenStore, err := tokenstore.NewStore(cfg.JWT, db)
+	if err != nil {
+		log.Fatalf("Failed to initialize refresh token store: %v", err)
+	}
+
+	loginAttemptStore, err := loginattempt.NewStore(cfg.Login)
+	if err != nil {
+		log.Fatalf("Failed to initialize login attempt store: %v", err)
+	}
 
 	// Initialize services
-	jwtService := services.NewJWTService(cfg)
-	authService := services.NewAuthService(userRepo, jwtService, cfg)
-	productService := services.NewProductService(productRepo)
+	jwtService := services.NewJWTService(cfg, refreshTokenStore, userRepo)
+	auditLogService := services.NewAuditLogService(auditLogRepo)
+	roleService := services.NewRoleService(roleRepo, auditLogService)
+	loginThrottleService := services.NewLoginThrottleService(cfg, loginAttemptStore, auditLogService)
+	storeSettingsService := services.NewStoreSettingsService(storeSettingsRepo, auditLogService)
+	twoFactorService := services.NewTwoFactorService(twoFactorRepo, roleService, storeSettingsService, auditLogService)
+	branchService := services.NewBranchService(branchRepo, userRepo, auditLogService)
+	authService := services.NewAuthService(userRepo, passwordResetRepo, jwtService, roleService, loginThrottleService, twoFactorService, branchService, auditLogService, cfg)
+	signedURLService := services.NewSignedURLService(cfg, refreshTokenStore, userRepo)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo, roleService, auditLogService)
+	productService := services.NewProductService(productRepo, priceHistoryRepo, branchRepo)
 	importOrderService := services.NewImportOrderService(importOrderRepo)
 	customerService := services.NewCustomerService(customerRepo)
-	auditLogService := services.NewAuditLogService(auditLogRepo)
-	invoiceService := services.NewInvoiceService(invoiceRepo, customerService, auditLogService)
-	pdfService := services.NewPDFService()
+	promotionService := services.NewPromotionService(promotionRepo, roleService, auditLogService)
+	taxService := services.NewTaxService(taxRepo)
+	paymentMethodService := services.NewPaymentMethodService(paymentMethodRepo)
+	customerDepositService := services.NewCustomerDepositService(customerDepositRepo, customerService, paymentMethodService, auditLogService)
+	invoiceService := services.NewInvoiceService(invoiceRepo, customerService, promotionService, taxService, paymentMethodService, customerDepositService, auditLogService)
+	paymentQRService := services.NewPaymentQRService(storeSettingsService, cfg.VietQR)
+	receiptService := services.NewReceiptService(cfg.Receipt, paymentMethodService, storeSettingsService)
+	pdfService := services.NewPDFService(paymentQRService, storeSettingsService, receiptService)
+	priceUpdateService := services.NewPriceUpdateService(priceUpdateRepo, auditLogService)
+	bankReconciliationService := services.NewBankReconciliationService(bankStatementRepo, invoiceService, auditLogService)
+	cashShiftService := services.NewCashShiftService(cashShiftRepo, auditLogService)
+
+	einvoiceProvider, err := einvoice.NewProvider(cfg.EInvoice)
+	if err != nil {
+		log.Fatalf("Failed to initialize e-invoice provider: %v", err)
+	}
+	einvoiceService := services.NewEInvoiceService(einvoiceRepo, invoiceService, customerService, storeSettingsService, einvoiceProvider, auditLogService, cfg.EInvoice)
+
+	// Apply scheduled price changes in the background
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			applied, err := productService.ApplyDueScheduledPriceChanges()
+			if err != nil {
+				log.Printf("Failed to apply scheduled price changes: %v", err)
+				continue
+			}
+			if applied > 0 {
+				log.Printf("Applied %d scheduled price change(s)", applied)
+			}
+		}
+	}()
+
+	// Remove expired refresh tokens and login attempts from their stores
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := jwtService.DeleteExpiredRefreshTokens(); err != nil {
+				log.Printf("Failed to delete expired refresh tokens: %v", err)
+			}
+			if _, err := loginThrottleService.DeleteExpired(); err != nil {
+				log.Printf("Failed to delete expired login attempts: %v", err)
+			}
+			if _, err := authService.DeleteExpiredPasswordResets(); err != nil {
+				log.Printf("Failed to delete expired password reset tokens: %v", err)
+			}
+		}
+	}()
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
@@ -53,20 +139,38 @@ func main() {
 	importOrderHandler := handlers.NewImportOrderHandler(importOrderService)
 	customerHandler := handlers.NewCustomerHandler(customerService)
 	auditLogHandler := handlers.NewAuditLogHandler(auditLogService)
-	invoiceHandler := handlers.NewInvoiceHandler(invoiceService, pdfService)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService, pdfService, paymentQRService, receiptService)
+	priceUpdateHandler := handlers.NewPriceUpdateHandler(priceUpdateService)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
+	taxHandler := handlers.NewTaxHandler(taxService)
+	einvoiceHandler := handlers.NewEInvoiceHandler(einvoiceService)
+	bankStatementHandler := handlers.NewBankStatementHandler(bankReconciliationService)
+	cashShiftHandler := handlers.NewCashShiftHandler(cashShiftService, pdfService)
+	paymentMethodHandler := handlers.NewPaymentMethodHandler(paymentMethodService)
+	customerDepositHandler := handlers.NewCustomerDepositHandler(customerDepositService)
+	storeSettingsHandler := handlers.NewStoreSettingsHandler(storeSettingsService)
+	signedURLHandler := handlers.NewSignedURLHandler(signedURLService)
+	roleHandler := handlers.NewRoleHandler(roleService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	branchHandler := handlers.NewBranchHandler(branchService)
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(jwtService)
+	authMiddleware := middleware.NewAuthMiddleware(jwtService, signedURLService, roleService, authService, apiKeyService, branchService)
 	tokenRefreshMiddleware := middleware.NewTokenRefreshMiddleware(jwtService)
 
 	// Setup Gin router
 	router := gin.Default()
 
+	// The client IP used for login throttling comes from X-Forwarded-For only behind these proxies
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+
 	// Add CORS middleware
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, X-Branch-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -85,7 +189,7 @@ func main() {
 	})
 
 	// Setup routes
-	routes.SetupAllRoutes(router, authHandler, productHandler, importOrderHandler, invoiceHandler, customerHandler, auditLogHandler, authMiddleware, tokenRefreshMiddleware)
+	routes.SetupAllRoutes(router, authHandler, productHandler, importOrderHandler, invoiceHandler, customerHandler, auditLogHandler, priceUpdateHandler, promotionHandler, taxHandler, einvoiceHandler, bankStatementHandler, cashShiftHandler, paymentMethodHandler, customerDepositHandler, storeSettingsHandler, signedURLHandler, roleHandler, apiKeyHandler, branchHandler, authMiddleware, tokenRefreshMiddleware)
 
 	// Start server
 	log.Printf("Server starting on port %s", cfg.Server.Port)
@@ -0,0 +1,9 @@
+-- Migration: Drop variant price history and scheduled price changes
+-- Created: 2024-02-05
+
+-- Drop trigger first
+DROP TRIGGER IF EXISTS update_scheduled_price_changes_updated_at ON scheduled_price_changes;
+
+-- Drop tables
+DROP TABLE IF EXISTS scheduled_price_changes;
+DROP TABLE IF EXISTS variant_price_history;
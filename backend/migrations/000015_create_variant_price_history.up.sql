@@ -0,0 +1,55 @@
+-- Migration: Create variant price history and scheduled price changes
+-- Created: 2024-02-05
+-- Description: Track every variant price change and allow scheduling future price changes
+
+-- Create variant_price_history table
+CREATE TABLE variant_price_history (
+    id SERIAL PRIMARY KEY,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL,       -- Snapshot for reporting (no FK constraint)
+
+    -- Price change
+    old_price DECIMAL(15,2) NOT NULL,
+    new_price DECIMAL(15,2) NOT NULL CHECK (new_price >= 0),
+    reason TEXT,
+    source VARCHAR(20) NOT NULL DEFAULT 'manual'
+        CHECK (source IN ('manual', 'scheduled')),
+    scheduled_change_id INTEGER,       -- Set when applied from scheduled_price_changes
+
+    -- Who and when
+    changed_by INTEGER,                -- user_id who changed the price (no FK constraint)
+    changed_by_name VARCHAR(100),      -- Username of user who changed the price
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create scheduled_price_changes table
+CREATE TABLE scheduled_price_changes (
+    id SERIAL PRIMARY KEY,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    new_price DECIMAL(15,2) NOT NULL CHECK (new_price >= 0),
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    reason TEXT,
+
+    -- Status
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'applied', 'cancelled')),
+    applied_at TIMESTAMP WITH TIME ZONE,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,                -- user_id who created (no FK constraint)
+    created_by_name VARCHAR(100)       -- Username of user who created this record
+);
+
+-- Create indexes
+CREATE INDEX idx_variant_price_history_variant_id ON variant_price_history (variant_id);
+CREATE INDEX idx_variant_price_history_changed_at ON variant_price_history (changed_at);
+CREATE INDEX idx_scheduled_price_changes_variant_id ON scheduled_price_changes (variant_id);
+CREATE INDEX idx_scheduled_price_changes_due ON scheduled_price_changes (effective_at) WHERE status = 'pending';
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_scheduled_price_changes_updated_at
+    BEFORE UPDATE ON scheduled_price_changes
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
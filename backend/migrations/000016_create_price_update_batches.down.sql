@@ -0,0 +1,16 @@
+-- Migration: Drop price update batches
+-- Created: 2024-02-06
+
+-- Drop trigger first
+DROP TRIGGER IF EXISTS update_price_update_batches_updated_at ON price_update_batches;
+
+-- Remove batch link and restore price change sources
+DROP INDEX IF EXISTS idx_variant_price_history_batch_id;
+ALTER TABLE variant_price_history DROP COLUMN IF EXISTS batch_id;
+DELETE FROM variant_price_history WHERE source IN ('bulk', 'rollback');
+ALTER TABLE variant_price_history DROP CONSTRAINT IF EXISTS variant_price_history_source_check;
+ALTER TABLE variant_price_history ADD CONSTRAINT variant_price_history_source_check
+    CHECK (source IN ('manual', 'scheduled'));
+
+-- Drop table
+DROP TABLE IF EXISTS price_update_batches;
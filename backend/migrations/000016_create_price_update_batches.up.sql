@@ -0,0 +1,47 @@
+-- Migration: Create price update batches for bulk pricing
+-- Created: 2024-02-06
+-- Description: Group bulk variant price changes into one audited batch that can be rolled back
+
+-- Create price_update_batches table
+CREATE TABLE price_update_batches (
+    id SERIAL PRIMARY KEY,
+
+    -- Adjustment
+    adjustment_type VARCHAR(20) NOT NULL CHECK (adjustment_type IN ('percentage', 'fixed')),
+    adjustment_value DECIMAL(15,2) NOT NULL,
+    round_to DECIMAL(15,2) NOT NULL DEFAULT 0,
+    filters JSONB,                     -- Filters used to select the variants
+    reason TEXT,
+    variant_count INTEGER NOT NULL DEFAULT 0,
+
+    -- Status
+    status VARCHAR(20) NOT NULL DEFAULT 'applied'
+        CHECK (status IN ('applied', 'rolled_back')),
+    rolled_back_by INTEGER,
+    rolled_back_by_name VARCHAR(100),
+    rolled_back_at TIMESTAMP WITH TIME ZONE,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,                -- user_id who created (no FK constraint)
+    created_by_name VARCHAR(100)       -- Username of user who created this record
+);
+
+-- Link price history entries to their batch
+ALTER TABLE variant_price_history ADD COLUMN batch_id INTEGER REFERENCES price_update_batches(id);
+
+-- Allow bulk and rollback price change sources
+ALTER TABLE variant_price_history DROP CONSTRAINT IF EXISTS variant_price_history_source_check;
+ALTER TABLE variant_price_history ADD CONSTRAINT variant_price_history_source_check
+    CHECK (source IN ('manual', 'scheduled', 'bulk', 'rollback'));
+
+-- Create indexes
+CREATE INDEX idx_price_update_batches_created_at ON price_update_batches (created_at);
+CREATE INDEX idx_variant_price_history_batch_id ON variant_price_history (batch_id);
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_price_update_batches_updated_at
+    BEFORE UPDATE ON price_update_batches
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
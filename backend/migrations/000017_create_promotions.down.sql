@@ -0,0 +1,16 @@
+-- Migration: Drop promotions and discount role caps
+-- Created: 2024-02-08
+
+-- Drop triggers first
+DROP TRIGGER IF EXISTS update_discount_role_caps_updated_at ON discount_role_caps;
+DROP TRIGGER IF EXISTS update_promotions_updated_at ON promotions;
+
+-- Remove applied promotion from invoice items
+DROP INDEX IF EXISTS idx_invoice_items_promotion_id;
+ALTER TABLE invoice_items DROP COLUMN IF EXISTS promotion_discount;
+ALTER TABLE invoice_items DROP COLUMN IF EXISTS promotion_name;
+ALTER TABLE invoice_items DROP COLUMN IF EXISTS promotion_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS discount_role_caps;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,69 @@
+-- Migration: Create promotions and discount role caps
+-- Created: 2024-02-08
+-- Description: Configurable promotions evaluated when building invoices, and per-role manual discount caps
+
+-- Create promotions table
+CREATE TABLE promotions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    promotion_type VARCHAR(20) NOT NULL
+        CHECK (promotion_type IN ('percentage', 'buy_x_get_y', 'volume')),
+
+    -- Scope (all NULL = applies to every item)
+    category_id INTEGER REFERENCES product_categories(id),
+    product_id INTEGER,                -- No FK constraint to keep promotions when products are deleted
+    variant_id INTEGER,                -- No FK constraint to keep promotions when variants are deleted
+
+    -- Rule parameters
+    discount_percentage DECIMAL(5,2) NOT NULL DEFAULT 0
+        CHECK (discount_percentage >= 0 AND discount_percentage <= 100),
+    buy_quantity DECIMAL(10,3) NOT NULL DEFAULT 0 CHECK (buy_quantity >= 0),
+    get_quantity DECIMAL(10,3) NOT NULL DEFAULT 0 CHECK (get_quantity >= 0),
+    min_quantity DECIMAL(10,3) NOT NULL DEFAULT 0 CHECK (min_quantity >= 0),
+    unit VARCHAR(50),                  -- Volume promotions only count items in this unit, e.g. 'kg'
+
+    -- Campaign window
+    starts_at TIMESTAMP WITH TIME ZONE,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    is_active BOOLEAN DEFAULT true,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,                -- user_id who created (no FK constraint)
+    created_by_name VARCHAR(100),      -- Username of user who created this record
+
+    CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at)
+);
+
+-- Create discount_role_caps table
+CREATE TABLE discount_role_caps (
+    role VARCHAR(20) PRIMARY KEY CHECK (role IN ('admin', 'manager', 'accountant', 'user')),
+    max_discount_percentage DECIMAL(5,2) NOT NULL
+        CHECK (max_discount_percentage >= 0 AND max_discount_percentage <= 100),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_by INTEGER,
+    updated_by_name VARCHAR(100)
+);
+
+-- Record the applied promotion per invoice item
+ALTER TABLE invoice_items ADD COLUMN promotion_id INTEGER;
+ALTER TABLE invoice_items ADD COLUMN promotion_name VARCHAR(255);
+ALTER TABLE invoice_items ADD COLUMN promotion_discount DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+-- Create indexes
+CREATE INDEX idx_promotions_is_active ON promotions (is_active);
+CREATE INDEX idx_promotions_category_id ON promotions (category_id);
+CREATE INDEX idx_invoice_items_promotion_id ON invoice_items (promotion_id);
+
+-- Create triggers for updated_at
+CREATE TRIGGER update_promotions_updated_at
+    BEFORE UPDATE ON promotions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_discount_role_caps_updated_at
+    BEFORE UPDATE ON discount_role_caps
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
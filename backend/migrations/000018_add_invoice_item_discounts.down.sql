@@ -0,0 +1,5 @@
+-- Migration: Remove line-level discounts from invoice items
+-- Created: 2024-02-09
+
+ALTER TABLE invoice_items DROP COLUMN IF EXISTS discount_percentage;
+ALTER TABLE invoice_items DROP COLUMN IF EXISTS discount_amount;
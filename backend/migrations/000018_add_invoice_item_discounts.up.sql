@@ -0,0 +1,8 @@
+-- Migration: Add line-level discounts to invoice items
+-- Created: 2024-02-09
+-- Description: Discount amount/percentage per invoice item, applied after promotions
+
+ALTER TABLE invoice_items ADD COLUMN discount_amount DECIMAL(15,2) NOT NULL DEFAULT 0
+    CHECK (discount_amount >= 0);
+ALTER TABLE invoice_items ADD COLUMN discount_percentage DECIMAL(5,2) NOT NULL DEFAULT 0
+    CHECK (discount_percentage >= 0 AND discount_percentage <= 100);
@@ -0,0 +1,22 @@
+-- Migration: Drop tax categories and effective-dated VAT rates
+-- Created: 2024-02-10
+
+-- Drop trigger first
+DROP TRIGGER IF EXISTS update_tax_categories_updated_at ON tax_categories;
+
+-- Remove per-line tax from invoice items
+ALTER TABLE invoice_items DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE invoice_items DROP COLUMN IF EXISTS taxable_amount;
+ALTER TABLE invoice_items DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE invoice_items DROP COLUMN IF EXISTS tax_category_id;
+
+-- Remove pricing mode from invoices
+ALTER TABLE invoices DROP COLUMN IF EXISTS price_mode;
+
+-- Remove tax category from products
+DROP INDEX IF EXISTS idx_products_tax_category_id;
+ALTER TABLE products DROP COLUMN IF EXISTS tax_category_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS tax_rates;
+DROP TABLE IF EXISTS tax_categories;
@@ -0,0 +1,58 @@
+-- Migration: Create tax categories and effective-dated VAT rates
+-- Created: 2024-02-10
+-- Description: Per-product tax categories, VAT rates by effective date and per-line tax on invoice items
+
+-- Create tax_categories table
+CREATE TABLE tax_categories (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,   -- e.g. 'VAT10', 'VAT8', 'VAT5', 'KCT'
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    is_active BOOLEAN DEFAULT true,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,                 -- user_id who created (no FK constraint)
+    created_by_name VARCHAR(100)        -- Username of user who created this record
+);
+
+-- Create tax_rates table
+CREATE TABLE tax_rates (
+    id SERIAL PRIMARY KEY,
+    tax_category_id INTEGER NOT NULL REFERENCES tax_categories(id) ON DELETE CASCADE,
+    rate DECIMAL(5,2) NOT NULL CHECK (rate >= 0 AND rate <= 100),
+    effective_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    effective_to TIMESTAMP WITH TIME ZONE,     -- NULL = open-ended
+    legal_reference VARCHAR(255),              -- Decree introducing the rate, e.g. 'Nghị định 94/2023/NĐ-CP'
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,
+    created_by_name VARCHAR(100),
+
+    CHECK (effective_to IS NULL OR effective_to > effective_from)
+);
+
+-- Tax category per product
+ALTER TABLE products ADD COLUMN tax_category_id INTEGER REFERENCES tax_categories(id);
+
+-- Pricing mode per invoice
+ALTER TABLE invoices ADD COLUMN price_mode VARCHAR(20) NOT NULL DEFAULT 'exclusive'
+    CHECK (price_mode IN ('exclusive', 'inclusive'));
+
+-- Per-line tax snapshot on invoice items
+ALTER TABLE invoice_items ADD COLUMN tax_category_id INTEGER;   -- No FK constraint, snapshot data
+ALTER TABLE invoice_items ADD COLUMN tax_rate DECIMAL(5,2) NOT NULL DEFAULT 0;
+ALTER TABLE invoice_items ADD COLUMN taxable_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE invoice_items ADD COLUMN tax_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+-- Create indexes
+CREATE INDEX idx_tax_rates_category_effective ON tax_rates (tax_category_id, effective_from);
+CREATE INDEX idx_products_tax_category_id ON products (tax_category_id);
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_tax_categories_updated_at
+    BEFORE UPDATE ON tax_categories
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,12 @@
+-- Migration: Drop e-invoices
+-- Created: 2024-02-11
+
+-- Drop trigger first
+DROP TRIGGER IF EXISTS update_einvoices_updated_at ON einvoices;
+
+-- Drop tables
+DROP TABLE IF EXISTS einvoices;
+DROP SEQUENCE IF EXISTS einvoice_number_seq;
+
+-- Remove buyer tax code from customers
+ALTER TABLE customers DROP COLUMN IF EXISTS tax_code;
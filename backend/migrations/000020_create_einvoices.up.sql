@@ -0,0 +1,47 @@
+-- Migration: Create e-invoices
+-- Created: 2024-02-11
+-- Description: Buyer tax codes and electronic invoices rendered from confirmed invoices
+
+-- Buyer tax code (Mã số thuế) on customers
+ALTER TABLE customers ADD COLUMN tax_code VARCHAR(20);
+
+-- E-invoice numbers are sequential within the seller's series
+CREATE SEQUENCE einvoice_number_seq START 1;
+
+-- Create einvoices table
+CREATE TABLE einvoices (
+    id SERIAL PRIMARY KEY,
+    invoice_id INTEGER NOT NULL UNIQUE REFERENCES invoices(id),
+    template_code VARCHAR(11) NOT NULL,    -- Ký hiệu mẫu số, e.g. '1'
+    series VARCHAR(9) NOT NULL,            -- Ký hiệu hóa đơn, e.g. 'C24TKP'
+    invoice_number INTEGER NOT NULL,
+    issue_date DATE NOT NULL,
+    buyer_tax_code VARCHAR(20),
+    xml_content TEXT NOT NULL,
+
+    -- Submission
+    status VARCHAR(20) NOT NULL DEFAULT 'validated'
+        CHECK (status IN ('validated', 'submitted', 'failed')),
+    provider VARCHAR(50),
+    provider_reference VARCHAR(255),
+    lookup_code VARCHAR(100),              -- Mã tra cứu returned by the provider
+    error_message TEXT,
+    submitted_at TIMESTAMP WITH TIME ZONE,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,                    -- user_id who created (no FK constraint)
+    created_by_name VARCHAR(100),          -- Username of user who created this record
+
+    UNIQUE (template_code, series, invoice_number)
+);
+
+-- Create indexes
+CREATE INDEX idx_einvoices_status ON einvoices (status);
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_einvoices_updated_at
+    BEFORE UPDATE ON einvoices
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
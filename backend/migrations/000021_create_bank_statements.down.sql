@@ -0,0 +1,9 @@
+-- Migration: Drop bank statements
+-- Created: 2024-02-12
+
+-- Drop trigger first
+DROP TRIGGER IF EXISTS update_bank_transactions_updated_at ON bank_transactions;
+
+-- Drop tables
+DROP TABLE IF EXISTS bank_transactions;
+DROP TABLE IF EXISTS bank_statements;
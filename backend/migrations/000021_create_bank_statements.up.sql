@@ -0,0 +1,58 @@
+-- Migration: Create bank statements
+-- Created: 2024-02-12
+-- Description: Imported bank statements and their incoming transfers, matched to invoices for reconciliation
+
+-- Create bank_statements table
+CREATE TABLE bank_statements (
+    id SERIAL PRIMARY KEY,
+    file_name VARCHAR(255) NOT NULL,
+    transaction_count INTEGER NOT NULL DEFAULT 0,   -- incoming transfers imported
+    skipped_count INTEGER NOT NULL DEFAULT 0,       -- debits and transfers already imported
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,                             -- user_id who uploaded (no FK constraint)
+    created_by_name VARCHAR(100)                    -- Username of user who uploaded the statement
+);
+
+-- Create bank_transactions table
+CREATE TABLE bank_transactions (
+    id SERIAL PRIMARY KEY,
+    statement_id INTEGER NOT NULL REFERENCES bank_statements(id) ON DELETE CASCADE,
+    transaction_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    description TEXT,
+    bank_reference VARCHAR(100),                    -- Số tham chiếu / số bút toán from the bank
+    counterparty_name VARCHAR(255),
+    counterparty_account VARCHAR(50),
+
+    -- Reconciliation
+    status VARCHAR(20) NOT NULL DEFAULT 'unmatched'
+        CHECK (status IN ('unmatched', 'suggested', 'confirmed', 'ignored')),
+    invoice_id INTEGER REFERENCES invoices(id),
+    match_method VARCHAR(20)
+        CHECK (match_method IN ('invoice_code', 'amount_customer', 'manual')),
+    payment_id INTEGER REFERENCES invoice_payments(id),
+    confirmed_by INTEGER,
+    confirmed_by_name VARCHAR(100),
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_bank_transactions_statement_id ON bank_transactions (statement_id);
+CREATE INDEX idx_bank_transactions_status ON bank_transactions (status);
+CREATE INDEX idx_bank_transactions_invoice_id ON bank_transactions (invoice_id);
+
+-- The same transfer appears again when overlapping statements are uploaded
+CREATE UNIQUE INDEX idx_bank_transactions_bank_reference ON bank_transactions (bank_reference)
+    WHERE bank_reference IS NOT NULL;
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_bank_transactions_updated_at
+    BEFORE UPDATE ON bank_transactions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
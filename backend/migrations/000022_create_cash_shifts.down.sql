@@ -0,0 +1,12 @@
+-- Migration: Drop cash shifts
+-- Created: 2024-02-13
+
+-- Drop trigger first
+DROP TRIGGER IF EXISTS update_cash_shifts_updated_at ON cash_shifts;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_invoice_payments_created_by_created_at;
+
+-- Drop tables
+DROP TABLE IF EXISTS cash_movements;
+DROP TABLE IF EXISTS cash_shifts;
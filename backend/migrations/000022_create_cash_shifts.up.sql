@@ -0,0 +1,60 @@
+-- Migration: Create cash shifts
+-- Created: 2024-02-13
+-- Description: Cashier shifts with opening float, cash-in/cash-out entries and counted cash at close
+
+-- Create cash_shifts table
+CREATE TABLE cash_shifts (
+    id SERIAL PRIMARY KEY,
+    cashier_id INTEGER NOT NULL,                 -- user_id of the cashier (no FK constraint)
+    cashier_name VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'closed')),
+
+    -- Opening
+    opening_float DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (opening_float >= 0),
+    opening_notes TEXT,
+    opened_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Closing
+    expected_cash DECIMAL(15,2),                 -- opening float + cash payments + cash in - cash out
+    counted_cash DECIMAL(15,2),
+    cash_difference DECIMAL(15,2),               -- counted - expected, negative when cash is short
+    closing_notes TEXT,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    closed_by INTEGER,
+    closed_by_name VARCHAR(100),
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create cash_movements table
+CREATE TABLE cash_movements (
+    id SERIAL PRIMARY KEY,
+    shift_id INTEGER NOT NULL REFERENCES cash_shifts(id) ON DELETE CASCADE,
+    movement_type VARCHAR(20) NOT NULL
+        CHECK (movement_type IN ('cash_in', 'cash_out')),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    reason VARCHAR(255) NOT NULL,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,
+    created_by_name VARCHAR(100)
+);
+
+-- Create indexes
+CREATE INDEX idx_cash_shifts_cashier_id ON cash_shifts (cashier_id);
+CREATE INDEX idx_cash_shifts_opened_at ON cash_shifts (opened_at);
+CREATE INDEX idx_cash_movements_shift_id ON cash_movements (shift_id);
+CREATE INDEX idx_invoice_payments_created_by_created_at ON invoice_payments (created_by, created_at);
+
+-- A cashier has at most one open shift
+CREATE UNIQUE INDEX idx_cash_shifts_open_cashier ON cash_shifts (cashier_id) WHERE status = 'open';
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_cash_shifts_updated_at
+    BEFORE UPDATE ON cash_shifts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,15 @@
+-- Migration: Drop payment methods
+-- Created: 2024-02-14
+
+-- Restore the fixed payment methods of invoice payments
+ALTER TABLE invoice_payments DROP CONSTRAINT IF EXISTS fk_invoice_payments_payment_method;
+UPDATE invoice_payments SET payment_method = 'bank_transfer'
+    WHERE payment_method NOT IN ('cash', 'card', 'bank_transfer', 'credit');
+ALTER TABLE invoice_payments ADD CONSTRAINT invoice_payments_payment_method_check
+    CHECK (payment_method IN ('cash', 'card', 'bank_transfer', 'credit'));
+
+-- Drop trigger first
+DROP TRIGGER IF EXISTS update_payment_methods_updated_at ON payment_methods;
+
+-- Drop table
+DROP TABLE IF EXISTS payment_methods;
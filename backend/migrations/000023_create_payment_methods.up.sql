@@ -0,0 +1,40 @@
+-- Migration: Create payment methods
+-- Created: 2024-02-14
+-- Description: Managed list of payment methods replacing the fixed payment_method values of invoice payments
+
+-- Create payment_methods table
+CREATE TABLE payment_methods (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,           -- stored on invoice_payments.payment_method
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    requires_reference BOOLEAN NOT NULL DEFAULT false,  -- payments must carry a transaction reference
+    is_cash BOOLEAN NOT NULL DEFAULT false,     -- payments go into the cash drawer
+    is_active BOOLEAN DEFAULT true,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,                         -- user_id who created (no FK constraint)
+    created_by_name VARCHAR(100)                -- Username of user who created this record
+);
+
+-- Default payment methods
+INSERT INTO payment_methods (code, name, requires_reference, is_cash, sort_order) VALUES
+    ('cash', 'Tiền mặt', false, true, 1),
+    ('bank_transfer', 'Chuyển khoản', true, false, 2),
+    ('card', 'Thẻ', true, false, 3),
+    ('e_wallet', 'Ví điện tử', true, false, 4),
+    ('credit', 'Công nợ', false, false, 5);
+
+-- Payment methods of invoice payments now come from the registry
+ALTER TABLE invoice_payments DROP CONSTRAINT IF EXISTS invoice_payments_payment_method_check;
+ALTER TABLE invoice_payments ADD CONSTRAINT fk_invoice_payments_payment_method
+    FOREIGN KEY (payment_method) REFERENCES payment_methods(code);
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_payment_methods_updated_at
+    BEFORE UPDATE ON payment_methods
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
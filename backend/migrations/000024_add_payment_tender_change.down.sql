@@ -0,0 +1,5 @@
+-- Migration: Remove tendered amount and change from invoice payments
+-- Created: 2024-02-15
+
+ALTER TABLE invoice_payments DROP COLUMN IF EXISTS change_amount;
+ALTER TABLE invoice_payments DROP COLUMN IF EXISTS tendered_amount;
@@ -0,0 +1,7 @@
+-- Migration: Add tendered amount and change to invoice payments
+-- Created: 2024-02-15
+-- Description: Split tender at checkout; cash tendered above the amount due is given back as change
+
+ALTER TABLE invoice_payments ADD COLUMN tendered_amount DECIMAL(15,2);   -- amount handed over, NULL when equal to amount
+ALTER TABLE invoice_payments ADD COLUMN change_amount DECIMAL(15,2) NOT NULL DEFAULT 0
+    CHECK (change_amount >= 0);
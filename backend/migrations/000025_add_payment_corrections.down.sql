@@ -0,0 +1,15 @@
+-- Migration: Remove payment corrections
+-- Created: 2024-02-16
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_invoice_payments_corrected_at;
+DROP INDEX IF EXISTS idx_invoice_payments_corrects_payment_id;
+
+-- Reversals cannot be represented without a payment type
+DELETE FROM invoice_payments WHERE payment_type = 'reversal';
+
+ALTER TABLE invoice_payments DROP CONSTRAINT IF EXISTS invoice_payments_amount_sign_check;
+ALTER TABLE invoice_payments ADD CONSTRAINT invoice_payments_amount_check CHECK (amount > 0);
+
+ALTER TABLE invoice_payments DROP COLUMN IF EXISTS corrects_payment_id;
+ALTER TABLE invoice_payments DROP COLUMN IF EXISTS payment_type;
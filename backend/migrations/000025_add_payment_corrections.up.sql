@@ -0,0 +1,17 @@
+-- Migration: Add payment corrections
+-- Created: 2024-02-16
+-- Description: Payments are corrected by a reversal record and an optional replacement instead of being overwritten or deleted
+
+-- Reversals carry the negative amount and the date of the payment they reverse, so sums of
+-- confirmed payments stay correct for the day the payment was made
+ALTER TABLE invoice_payments ADD COLUMN payment_type VARCHAR(20) NOT NULL DEFAULT 'payment'
+    CHECK (payment_type IN ('payment', 'reversal'));
+ALTER TABLE invoice_payments ADD COLUMN corrects_payment_id INTEGER REFERENCES invoice_payments(id);
+
+ALTER TABLE invoice_payments DROP CONSTRAINT IF EXISTS invoice_payments_amount_check;
+ALTER TABLE invoice_payments ADD CONSTRAINT invoice_payments_amount_sign_check
+    CHECK ((payment_type = 'payment' AND amount > 0) OR (payment_type = 'reversal' AND amount < 0));
+
+-- Create indexes
+CREATE INDEX idx_invoice_payments_corrects_payment_id ON invoice_payments (corrects_payment_id);
+CREATE INDEX idx_invoice_payments_corrected_at ON invoice_payments (corrected_at);
@@ -0,0 +1,13 @@
+-- Migration: Drop customer deposits
+-- Created: 2024-02-17
+
+-- Drop trigger first
+DROP TRIGGER IF EXISTS update_customer_deposits_updated_at ON customer_deposits;
+
+-- Drop tables
+DROP TABLE IF EXISTS customer_deposit_movements;
+DROP TABLE IF EXISTS customer_deposits;
+
+-- Payments made from deposits are kept as cash payments
+UPDATE invoice_payments SET payment_method = 'cash' WHERE payment_method = 'deposit';
+DELETE FROM payment_methods WHERE code = 'deposit';
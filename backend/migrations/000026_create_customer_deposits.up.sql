@@ -0,0 +1,69 @@
+-- Migration: Create customer deposits
+-- Created: 2024-02-17
+-- Description: Customer advance payments held as credit, applied to invoices or refunded
+
+-- Create customer_deposits table
+CREATE TABLE customer_deposits (
+    id SERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id),
+
+    -- Money received
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    remaining_amount DECIMAL(15,2) NOT NULL,    -- amount not yet applied or refunded
+    payment_method VARCHAR(20) NOT NULL REFERENCES payment_methods(code),
+    transaction_reference VARCHAR(255),
+    notes TEXT,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,                         -- user_id who created (no FK constraint)
+    created_by_name VARCHAR(100),               -- Username of user who created this record
+
+    CONSTRAINT customer_deposits_remaining_check CHECK (remaining_amount >= 0 AND remaining_amount <= amount)
+);
+
+-- Create customer_deposit_movements table
+CREATE TABLE customer_deposit_movements (
+    id SERIAL PRIMARY KEY,
+    deposit_id INTEGER NOT NULL REFERENCES customer_deposits(id),
+    movement_type VARCHAR(20) NOT NULL
+        CHECK (movement_type IN ('applied', 'refunded')),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+
+    -- Applied to an invoice
+    invoice_id INTEGER REFERENCES invoices(id),
+    invoice_payment_id INTEGER REFERENCES invoice_payments(id),
+
+    -- Paid back to the customer
+    payment_method VARCHAR(20) REFERENCES payment_methods(code),
+    transaction_reference VARCHAR(255),
+    reason TEXT,
+
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_by INTEGER,
+    created_by_name VARCHAR(100),
+
+    CONSTRAINT customer_deposit_movements_target_check CHECK (
+        (movement_type = 'applied' AND invoice_id IS NOT NULL AND invoice_payment_id IS NOT NULL) OR
+        (movement_type = 'refunded' AND payment_method IS NOT NULL)
+    )
+);
+
+-- Invoice payments made from a deposit. The method is inactive so it cannot be picked at
+-- checkout; deposits are applied from the customer's deposit balance.
+INSERT INTO payment_methods (code, name, description, requires_reference, is_cash, is_active, sort_order) VALUES
+    ('deposit', 'Tiền đặt cọc', 'Trừ vào tiền khách đặt cọc trước', false, false, false, 6);
+
+-- Create indexes
+CREATE INDEX idx_customer_deposits_customer_id ON customer_deposits (customer_id);
+CREATE INDEX idx_customer_deposits_created_by_created_at ON customer_deposits (created_by, created_at);
+CREATE INDEX idx_customer_deposit_movements_deposit_id ON customer_deposit_movements (deposit_id);
+CREATE INDEX idx_customer_deposit_movements_invoice_id ON customer_deposit_movements (invoice_id);
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_customer_deposits_updated_at
+    BEFORE UPDATE ON customer_deposits
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,9 @@
+-- Migration: Drop store settings
+-- Created: 2024-02-18
+
+-- Drop trigger first
+DROP TRIGGER IF EXISTS update_store_settings_updated_at ON store_settings;
+
+-- Drop tables
+DROP TABLE IF EXISTS store_bank_accounts;
+DROP TABLE IF EXISTS store_settings;
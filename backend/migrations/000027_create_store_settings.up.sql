@@ -0,0 +1,42 @@
+-- Migration: Create store settings
+-- Created: 2024-02-18
+-- Description: Store profile printed on invoices and receipts, replacing the hard-coded store header
+
+-- Create store_settings table (a single row)
+CREATE TABLE store_settings (
+    id INTEGER PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    name VARCHAR(200) NOT NULL,
+    address TEXT,
+    phones TEXT[] NOT NULL DEFAULT '{}',
+    tax_code VARCHAR(20),
+    footer_text TEXT,                           -- printed at the bottom of invoices and receipts
+    logo BYTEA,                                 -- PNG or JPEG image
+    logo_content_type VARCHAR(20),
+    default_invoice_template VARCHAR(20) NOT NULL DEFAULT 'a4'
+        CHECK (default_invoice_template IN ('a4', 'a5', 'receipt')),
+
+    -- Timestamps
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_by INTEGER,                         -- user_id who last updated (no FK constraint)
+    updated_by_name VARCHAR(100)
+);
+
+-- Create store_bank_accounts table
+CREATE TABLE store_bank_accounts (
+    id SERIAL PRIMARY KEY,
+    bank_name VARCHAR(100) NOT NULL,
+    account_number VARCHAR(30) NOT NULL,
+    account_name VARCHAR(100) NOT NULL,
+    branch VARCHAR(100),
+    sort_order INTEGER NOT NULL DEFAULT 0
+);
+
+-- The store header printed so far
+INSERT INTO store_settings (id, name, address, phones, footer_text) VALUES
+    (1, 'ĐẠI LÝ SẮT THÉP KIÊN PHƯỚC', 'Trường Sơn Đức Thọ Hà Tĩnh', ARRAY['0972851015', '0974498918'], 'Cảm ơn quý khách!');
+
+-- Create trigger for updated_at
+CREATE TRIGGER update_store_settings_updated_at
+    BEFORE UPDATE ON store_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
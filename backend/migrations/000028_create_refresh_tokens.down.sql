@@ -0,0 +1,5 @@
+-- Migration: Drop refresh tokens
+-- Created: 2024-02-19
+
+-- Drop table
+DROP TABLE IF EXISTS refresh_tokens;
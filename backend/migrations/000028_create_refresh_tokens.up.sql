@@ -0,0 +1,20 @@
+-- Migration: Create refresh tokens
+-- Created: 2024-02-19
+-- Description: Server-side record of issued refresh tokens for rotation, reuse detection and logout
+
+-- Create refresh_tokens table
+CREATE TABLE refresh_tokens (
+    id VARCHAR(64) PRIMARY KEY,                 -- jti claim of the token
+    family_id VARCHAR(64) NOT NULL,             -- shared by all tokens rotated from the same login
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issued_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,           -- exchanged for the token in replaced_by
+    replaced_by VARCHAR(64),
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
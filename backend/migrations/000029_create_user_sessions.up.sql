@@ -0,0 +1,25 @@
+-- Migration: Create user sessions
+-- Created: 2024-02-20
+-- Description: Login sessions with device details; refresh token families become sessions that can be revoked
+
+-- Create user_sessions table
+CREATE TABLE user_sessions (
+    id VARCHAR(64) PRIMARY KEY,                 -- sid claim of access and refresh tokens
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_name VARCHAR(100),                   -- name given by the client at login, e.g. "Quầy 1"
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes
+CREATE INDEX idx_user_sessions_user_id ON user_sessions(user_id);
+
+-- Refresh tokens issued before sessions existed cannot be tied to one; their users log in again
+DELETE FROM refresh_tokens;
+ALTER TABLE refresh_tokens RENAME COLUMN family_id TO session_id;
+ALTER INDEX idx_refresh_tokens_family_id RENAME TO idx_refresh_tokens_session_id;
+ALTER TABLE refresh_tokens ADD CONSTRAINT fk_refresh_tokens_session
+    FOREIGN KEY (session_id) REFERENCES user_sessions(id) ON DELETE CASCADE;
@@ -0,0 +1,20 @@
+-- Migration: Drop roles and role permissions
+-- Created: 2024-02-21
+
+-- Users and discount caps go back to the fixed role list
+ALTER TABLE discount_role_caps DROP CONSTRAINT IF EXISTS fk_discount_role_caps_role;
+DELETE FROM discount_role_caps WHERE role NOT IN ('admin', 'manager', 'accountant', 'user');
+ALTER TABLE discount_role_caps ADD CONSTRAINT discount_role_caps_role_check
+    CHECK (role IN ('admin', 'manager', 'accountant', 'user'));
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_role;
+UPDATE users SET role = 'user' WHERE role NOT IN ('admin', 'manager', 'accountant', 'user');
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('admin', 'manager', 'accountant', 'user'));
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_roles_updated_at ON roles;
+
+-- Drop tables
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,61 @@
+-- Migration: Create roles and role permissions
+-- Created: 2024-02-21
+-- Description: Roles become editable bundles of permissions; users and discount caps reference them
+
+-- Create roles table
+CREATE TABLE roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(20) NOT NULL UNIQUE,
+    description TEXT,
+    is_system BOOLEAN NOT NULL DEFAULT false,   -- admin always has every permission and cannot be changed
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create role_permissions table
+CREATE TABLE role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission VARCHAR(50) NOT NULL,            -- permission code, e.g. invoice.create
+    PRIMARY KEY (role_id, permission)
+);
+
+-- Create triggers for updated_at
+CREATE TRIGGER update_roles_updated_at
+    BEFORE UPDATE ON roles
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Seed the existing roles with the access they had through the fixed role checks
+INSERT INTO roles (name, description, is_system) VALUES
+    ('admin', 'Quản trị viên, có mọi quyền', true),
+    ('manager', 'Quản lý cửa hàng', false),
+    ('accountant', 'Kế toán', false),
+    ('user', 'Nhân viên', false);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+CROSS JOIN unnest(ARRAY[
+    'user.view', 'user.update', 'product.manage', 'price.edit', 'import.create', 'import.approve',
+    'invoice.create', 'invoice.update', 'invoice.delete', 'invoice.export', 'payment.create',
+    'payment.report', 'payment_method.manage', 'customer.manage', 'deposit.receive',
+    'promotion.manage', 'tax.manage', 'tax.report', 'einvoice.issue', 'bank.reconcile', 'shift.manage'
+]) AS p(permission)
+WHERE r.name = 'manager';
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission
+FROM roles r
+CROSS JOIN unnest(ARRAY[
+    'payment.correct', 'payment.report', 'deposit.refund', 'tax.report', 'einvoice.issue', 'bank.reconcile'
+]) AS p(permission)
+WHERE r.name = 'accountant';
+
+-- Users and discount caps reference roles instead of a fixed list
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT fk_users_role
+    FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+
+ALTER TABLE discount_role_caps DROP CONSTRAINT IF EXISTS discount_role_caps_role_check;
+ALTER TABLE discount_role_caps ADD CONSTRAINT fk_discount_role_caps_role
+    FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE;
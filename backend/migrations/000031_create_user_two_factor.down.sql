@@ -0,0 +1,8 @@
+-- Migration: Drop user two-factor authentication
+-- Created: 2024-02-22
+
+ALTER TABLE roles DROP COLUMN IF EXISTS require_two_factor;
+
+-- Drop tables
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS user_two_factor;
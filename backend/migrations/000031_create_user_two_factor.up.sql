@@ -0,0 +1,27 @@
+-- Migration: Create user two-factor authentication
+-- Created: 2024-02-22
+-- Description: TOTP secrets and recovery codes per user, and roles that must use two-factor authentication
+
+-- Create user_two_factor table
+CREATE TABLE user_two_factor (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,                -- base32 TOTP secret
+    enabled_at TIMESTAMP WITH TIME ZONE,        -- NULL until the first code is confirmed
+    last_used_step BIGINT NOT NULL DEFAULT 0,   -- time step of the last accepted code, codes cannot be reused
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create user_recovery_codes table
+CREATE TABLE user_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,             -- SHA-256 of the code
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
+
+-- Roles whose users must set up two-factor authentication before they can log in
+ALTER TABLE roles ADD COLUMN require_two_factor BOOLEAN NOT NULL DEFAULT false;
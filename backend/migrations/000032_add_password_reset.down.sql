@@ -0,0 +1,8 @@
+-- Migration: Drop password reset
+-- Created: 2024-02-23
+
+-- Drop tables
+DROP TABLE IF EXISTS password_reset_tokens;
+
+ALTER TABLE users DROP COLUMN IF EXISTS password_changed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS must_change_password;
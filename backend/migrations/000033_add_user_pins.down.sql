@@ -0,0 +1,5 @@
+-- Migration: Drop user PINs
+-- Created: 2024-02-24
+
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS active_user_id;
+ALTER TABLE users DROP COLUMN IF EXISTS pin_hash;
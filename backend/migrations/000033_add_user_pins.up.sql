@@ -0,0 +1,9 @@
+-- Migration: Add user PINs
+-- Created: 2024-02-24
+-- Description: Short PINs that let staff switch the active user of a shared terminal session
+
+-- bcrypt hash of the PIN, NULL when the user has none
+ALTER TABLE users ADD COLUMN pin_hash VARCHAR(255);
+
+-- User switched to with a PIN, NULL while the user who logged in is active
+ALTER TABLE user_sessions ADD COLUMN active_user_id INTEGER REFERENCES users(id);
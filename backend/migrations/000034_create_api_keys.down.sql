@@ -0,0 +1,8 @@
+-- Migration: Drop API keys
+-- Created: 2024-02-25
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_api_keys_updated_at ON api_keys;
+
+-- Drop tables
+DROP TABLE IF EXISTS api_keys;
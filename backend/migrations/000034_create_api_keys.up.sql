@@ -0,0 +1,29 @@
+-- Migration: Create API keys
+-- Created: 2024-02-25
+-- Description: Admin-managed API keys for integrations and scripts, acting as a user with a subset of their permissions
+
+-- Create api_keys table
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,            -- start of the key, shown to tell keys apart
+    key_hash VARCHAR(64) NOT NULL UNIQUE,       -- SHA-256 of the key, the key itself is not stored
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,  -- user the key acts as
+    permissions TEXT[] NOT NULL DEFAULT '{}',   -- permission codes, limited to those of the user's role
+    expires_at TIMESTAMP WITH TIME ZONE,        -- NULL for keys that do not expire
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id);
+
+-- Create triggers for updated_at
+CREATE TRIGGER update_api_keys_updated_at
+    BEFORE UPDATE ON api_keys
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
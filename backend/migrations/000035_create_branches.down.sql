@@ -0,0 +1,84 @@
+-- Migration: Drop branches
+-- Created: 2024-02-26
+
+-- Restore import approval to the stock of the variant
+CREATE OR REPLACE FUNCTION update_inventory_on_import_approval(
+    p_import_order_id INTEGER,
+    p_approved_by INTEGER
+)
+RETURNS VOID AS $$
+DECLARE
+    v_item RECORD;
+    v_previous_stock INTEGER;
+    v_new_stock INTEGER;
+BEGIN
+    -- Loop through all items in the import order
+    FOR v_item IN
+        SELECT
+            ioi.product_variant_id,
+            ioi.quantity,
+            ioi.product_name,
+            ioi.variant_name
+        FROM import_order_items ioi
+        WHERE ioi.import_order_id = p_import_order_id
+    LOOP
+        -- Get current stock
+        SELECT stock INTO v_previous_stock
+        FROM product_variants
+        WHERE id = v_item.product_variant_id;
+
+        -- Calculate new stock
+        v_new_stock := COALESCE(v_previous_stock, 0) + v_item.quantity;
+
+        -- Update product variant stock
+        UPDATE product_variants
+        SET
+            stock = v_new_stock,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE id = v_item.product_variant_id;
+
+        -- Insert inventory history record
+        INSERT INTO inventory_history (
+            product_variant_id,
+            type,
+            quantity,
+            previous_stock,
+            new_stock,
+            reference_id,
+            reference_type,
+            notes,
+            created_by
+        ) VALUES (
+            v_item.product_variant_id,
+            'import',
+            v_item.quantity,
+            COALESCE(v_previous_stock, 0),
+            v_new_stock,
+            p_import_order_id,
+            'import_order',
+            'Import order approval - ' || v_item.product_name || ' ' || v_item.variant_name,
+            p_approved_by
+        );
+
+    END LOOP;
+
+END;
+$$ LANGUAGE plpgsql;
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_branches_updated_at ON branches;
+DROP TRIGGER IF EXISTS trigger_sync_variant_stock ON branch_stock;
+
+-- Drop functions
+DROP FUNCTION IF EXISTS sync_variant_stock();
+
+-- Drop columns
+ALTER TABLE inventory_history DROP COLUMN IF EXISTS branch_id;
+ALTER TABLE cash_shifts DROP COLUMN IF EXISTS branch_id;
+ALTER TABLE import_orders DROP COLUMN IF EXISTS branch_id;
+ALTER TABLE invoices DROP COLUMN IF EXISTS branch_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS branch_stock;
+DROP TABLE IF EXISTS user_branches;
+DROP TABLE IF EXISTS branches;
@@ -0,0 +1,167 @@
+-- Migration: Create branches
+-- Created: 2024-02-26
+-- Description: Branches (stores) with their own stock, invoices, import orders and cash shifts, and the branches each user works at
+
+-- Create branches table
+CREATE TABLE branches (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    name VARCHAR(200) NOT NULL,
+    address TEXT,
+    phone VARCHAR(20),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The existing store becomes the main branch
+INSERT INTO branches (code, name) VALUES ('MAIN', 'Cửa hàng chính');
+
+-- Create user_branches table (users with branch.manage work at every branch)
+CREATE TABLE user_branches (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    branch_id INTEGER NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, branch_id)
+);
+
+INSERT INTO user_branches (user_id, branch_id)
+SELECT u.id, b.id FROM users u, branches b WHERE b.code = 'MAIN';
+
+-- Create branch_stock table; product_variants.stock is the sum over all branches
+CREATE TABLE branch_stock (
+    branch_id INTEGER NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (branch_id, variant_id)
+);
+
+INSERT INTO branch_stock (branch_id, variant_id, stock)
+SELECT b.id, pv.id, pv.stock
+FROM product_variants pv, branches b
+WHERE b.code = 'MAIN' AND pv.stock > 0;
+
+-- Scope documents to the branch they were made at
+ALTER TABLE invoices ADD COLUMN branch_id INTEGER REFERENCES branches(id);
+ALTER TABLE import_orders ADD COLUMN branch_id INTEGER REFERENCES branches(id);
+ALTER TABLE cash_shifts ADD COLUMN branch_id INTEGER REFERENCES branches(id);
+ALTER TABLE inventory_history ADD COLUMN branch_id INTEGER REFERENCES branches(id);
+
+UPDATE invoices SET branch_id = (SELECT id FROM branches WHERE code = 'MAIN');
+UPDATE import_orders SET branch_id = (SELECT id FROM branches WHERE code = 'MAIN');
+UPDATE cash_shifts SET branch_id = (SELECT id FROM branches WHERE code = 'MAIN');
+UPDATE inventory_history SET branch_id = (SELECT id FROM branches WHERE code = 'MAIN');
+
+ALTER TABLE invoices ALTER COLUMN branch_id SET NOT NULL;
+ALTER TABLE import_orders ALTER COLUMN branch_id SET NOT NULL;
+ALTER TABLE cash_shifts ALTER COLUMN branch_id SET NOT NULL;
+
+-- Create indexes
+CREATE INDEX idx_user_branches_branch_id ON user_branches(branch_id);
+CREATE INDEX idx_branch_stock_variant_id ON branch_stock(variant_id);
+CREATE INDEX idx_invoices_branch_id ON invoices(branch_id);
+CREATE INDEX idx_import_orders_branch_id ON import_orders(branch_id);
+CREATE INDEX idx_cash_shifts_branch_id ON cash_shifts(branch_id);
+
+-- Keep the total stock of a variant equal to the sum of its branch stock
+CREATE OR REPLACE FUNCTION sync_variant_stock()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_variant_id INTEGER;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        v_variant_id := OLD.variant_id;
+    ELSE
+        v_variant_id := NEW.variant_id;
+    END IF;
+
+    UPDATE product_variants
+    SET
+        stock = (SELECT COALESCE(SUM(stock), 0) FROM branch_stock WHERE variant_id = v_variant_id),
+        updated_at = CURRENT_TIMESTAMP
+    WHERE id = v_variant_id;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_sync_variant_stock
+    AFTER INSERT OR UPDATE OR DELETE ON branch_stock
+    FOR EACH ROW
+    EXECUTE FUNCTION sync_variant_stock();
+
+-- Create triggers for updated_at
+CREATE TRIGGER update_branches_updated_at
+    BEFORE UPDATE ON branches
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Approved import orders add stock to the branch of the order
+CREATE OR REPLACE FUNCTION update_inventory_on_import_approval(
+    p_import_order_id INTEGER,
+    p_approved_by INTEGER
+)
+RETURNS VOID AS $$
+DECLARE
+    v_item RECORD;
+    v_branch_id INTEGER;
+    v_previous_stock INTEGER;
+    v_new_stock INTEGER;
+BEGIN
+    SELECT branch_id INTO v_branch_id
+    FROM import_orders
+    WHERE id = p_import_order_id;
+
+    -- Loop through all items in the import order
+    FOR v_item IN
+        SELECT
+            ioi.product_variant_id,
+            ioi.quantity,
+            ioi.product_name,
+            ioi.variant_name
+        FROM import_order_items ioi
+        WHERE ioi.import_order_id = p_import_order_id
+    LOOP
+        -- Get current stock of the branch
+        SELECT stock INTO v_previous_stock
+        FROM branch_stock
+        WHERE branch_id = v_branch_id AND variant_id = v_item.product_variant_id;
+
+        -- Calculate new stock
+        v_new_stock := COALESCE(v_previous_stock, 0) + v_item.quantity;
+
+        -- Update branch stock; the variant total follows through trigger_sync_variant_stock
+        INSERT INTO branch_stock (branch_id, variant_id, stock, updated_at)
+        VALUES (v_branch_id, v_item.product_variant_id, v_new_stock, CURRENT_TIMESTAMP)
+        ON CONFLICT (branch_id, variant_id)
+        DO UPDATE SET stock = EXCLUDED.stock, updated_at = EXCLUDED.updated_at;
+
+        -- Insert inventory history record
+        INSERT INTO inventory_history (
+            product_variant_id,
+            branch_id,
+            type,
+            quantity,
+            previous_stock,
+            new_stock,
+            reference_id,
+            reference_type,
+            notes,
+            created_by
+        ) VALUES (
+            v_item.product_variant_id,
+            v_branch_id,
+            'import',
+            v_item.quantity,
+            COALESCE(v_previous_stock, 0),
+            v_new_stock,
+            p_import_order_id,
+            'import_order',
+            'Import order approval - ' || v_item.product_name || ' ' || v_item.variant_name,
+            p_approved_by
+        );
+
+    END LOOP;
+
+END;
+$$ LANGUAGE plpgsql;
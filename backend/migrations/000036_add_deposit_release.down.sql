@@ -0,0 +1,30 @@
+-- Migration: Drop deposit release
+-- Created: 2024-02-27
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_customer_deposit_movements_released_payment;
+
+-- Released money is applied again, as far as it has not been refunded since
+UPDATE customer_deposits d
+SET remaining_amount = GREATEST(d.remaining_amount - m.amount, 0)
+FROM (
+    SELECT deposit_id, SUM(amount) AS amount
+    FROM customer_deposit_movements
+    WHERE movement_type = 'released'
+    GROUP BY deposit_id
+) m
+WHERE d.id = m.deposit_id;
+
+DELETE FROM customer_deposit_movements WHERE movement_type = 'released';
+
+-- Restore constraints
+ALTER TABLE customer_deposit_movements DROP CONSTRAINT customer_deposit_movements_movement_type_check;
+ALTER TABLE customer_deposit_movements DROP CONSTRAINT customer_deposit_movements_target_check;
+
+ALTER TABLE customer_deposit_movements ADD CONSTRAINT customer_deposit_movements_movement_type_check
+    CHECK (movement_type IN ('applied', 'refunded'));
+
+ALTER TABLE customer_deposit_movements ADD CONSTRAINT customer_deposit_movements_target_check CHECK (
+    (movement_type = 'applied' AND invoice_id IS NOT NULL AND invoice_payment_id IS NOT NULL) OR
+    (movement_type = 'refunded' AND payment_method IS NOT NULL)
+);
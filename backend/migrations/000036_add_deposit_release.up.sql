@@ -0,0 +1,19 @@
+-- Migration: Add deposit release
+-- Created: 2024-02-27
+-- Description: Deposit money applied to an invoice goes back to the deposit when the invoice is cancelled or the payment is reversed
+
+-- Allow released movements, which reference the deposit payment they give back
+ALTER TABLE customer_deposit_movements DROP CONSTRAINT customer_deposit_movements_movement_type_check;
+ALTER TABLE customer_deposit_movements DROP CONSTRAINT customer_deposit_movements_target_check;
+
+ALTER TABLE customer_deposit_movements ADD CONSTRAINT customer_deposit_movements_movement_type_check
+    CHECK (movement_type IN ('applied', 'released', 'refunded'));
+
+ALTER TABLE customer_deposit_movements ADD CONSTRAINT customer_deposit_movements_target_check CHECK (
+    (movement_type IN ('applied', 'released') AND invoice_id IS NOT NULL AND invoice_payment_id IS NOT NULL) OR
+    (movement_type = 'refunded' AND payment_method IS NOT NULL)
+);
+
+-- A deposit payment is released at most once
+CREATE UNIQUE INDEX idx_customer_deposit_movements_released_payment
+    ON customer_deposit_movements (invoice_payment_id) WHERE movement_type = 'released';
@@ -0,0 +1,4 @@
+-- Migration: Drop allocated tax from invoice items
+-- Created: 2024-02-28
+
+ALTER TABLE invoice_items DROP COLUMN IF EXISTS allocated_tax_amount;
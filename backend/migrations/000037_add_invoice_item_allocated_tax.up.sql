@@ -0,0 +1,6 @@
+-- Migration: Add allocated tax to invoice items
+-- Created: 2024-02-28
+-- Description: An invoice tax amount given explicitly is spread over the items separately, the items keep the tax rate they are taxed at
+
+-- Share of the explicit invoice tax amount, NULL when the item is taxed at its own rate
+ALTER TABLE invoice_items ADD COLUMN allocated_tax_amount DECIMAL(15,2);
@@ -0,0 +1,9 @@
+-- Migration: Drop e-invoice number counters
+-- Created: 2024-02-29
+
+-- Restore the sequence after the highest number issued
+CREATE SEQUENCE einvoice_number_seq START 1;
+SELECT setval('einvoice_number_seq', MAX(invoice_number)) FROM einvoices HAVING MAX(invoice_number) IS NOT NULL;
+
+-- Drop tables
+DROP TABLE IF EXISTS einvoice_number_counters;
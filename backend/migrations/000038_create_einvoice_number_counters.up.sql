@@ -0,0 +1,21 @@
+-- Migration: Create e-invoice number counters
+-- Created: 2024-02-29
+-- Description: E-invoice numbers are allocated in the transaction that stores the e-invoice so that a failed issue leaves no gap
+
+-- Last number issued in each template and series. Unlike a sequence, taking a number
+-- is rolled back with the transaction that took it.
+CREATE TABLE einvoice_number_counters (
+    template_code VARCHAR(11) NOT NULL,
+    series VARCHAR(9) NOT NULL,
+    last_number INTEGER NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (template_code, series)
+);
+
+-- Continue from the e-invoices issued so far
+INSERT INTO einvoice_number_counters (template_code, series, last_number)
+SELECT template_code, series, MAX(invoice_number)
+FROM einvoices
+GROUP BY template_code, series;
+
+DROP SEQUENCE IF EXISTS einvoice_number_seq;
@@ -0,0 +1,10 @@
+-- Migration: Drop amount match method
+-- Created: 2024-03-01
+
+-- Candidates found by amount alone go back to unmatched
+UPDATE bank_transactions SET invoice_id = NULL, match_method = NULL
+WHERE match_method = 'amount' AND status = 'unmatched';
+
+ALTER TABLE bank_transactions DROP CONSTRAINT bank_transactions_match_method_check;
+ALTER TABLE bank_transactions ADD CONSTRAINT bank_transactions_match_method_check
+    CHECK (match_method IN ('invoice_code', 'amount_customer', 'manual'));
@@ -0,0 +1,7 @@
+-- Migration: Add amount match method
+-- Created: 2024-03-01
+-- Description: A transfer matching an invoice by its amount alone is kept as a candidate instead of a suggested match
+
+ALTER TABLE bank_transactions DROP CONSTRAINT bank_transactions_match_method_check;
+ALTER TABLE bank_transactions ADD CONSTRAINT bank_transactions_match_method_check
+    CHECK (match_method IN ('invoice_code', 'amount_customer', 'amount', 'manual'));
@@ -0,0 +1,4 @@
+-- Migration: Drop store bank account BIN
+-- Created: 2024-03-02
+
+ALTER TABLE store_bank_accounts DROP COLUMN bank_bin;
@@ -0,0 +1,5 @@
+-- Migration: Add store bank account BIN
+-- Created: 2024-03-02
+-- Description: Bank BIN of a store bank account, so VietQR codes pay into the account printed on invoices
+
+ALTER TABLE store_bank_accounts ADD COLUMN bank_bin VARCHAR(10);
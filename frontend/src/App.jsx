@@ -11,6 +11,8 @@ import { AuthProvider } from './contexts/AuthContext';
 import { UiProvider } from './contexts/UiContext';
 import ProtectedRoute from './components/ProtectedRoute';
 import Login from './pages/Login';
+import ChangePassword from './pages/ChangePassword';
+import ResetPassword from './pages/ResetPassword';
 import Dashboard from './pages/Dashboard';
 import InventoryRoute from './routes/InventoryRoute';
 import Sales from './pages/sales';
@@ -29,6 +31,8 @@ function App() {
             <Routes>
               {/* Public routes */}
               <Route path='/login' element={<Login />} />
+              <Route path='/reset-password' element={<ResetPassword />} />
+              <Route path='/change-password' element={<ChangePassword />} />
 
               {/* Protected routes group */}
               <Route path='/' element={<ProtectedRoute />}>
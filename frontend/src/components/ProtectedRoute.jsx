@@ -5,7 +5,7 @@ import MainLayout from "./organisms/MainLayout";
 import SplashScreen from "./SplashScreen";
 
 const ProtectedRoute = () => {
-  const { isLoading, isAuthenticated } = useAuth();
+  const { isLoading, isAuthenticated, user } = useAuth();
 
   // Hiển thị splash screen khi đang kiểm tra authentication
   if (isLoading) {
@@ -17,6 +17,11 @@ const ProtectedRoute = () => {
     return <Navigate to="/login" replace />;
   }
 
+  // Tài khoản vừa được đặt lại mật khẩu phải đổi mật khẩu trước
+  if (user?.must_change_password) {
+    return <Navigate to="/change-password" replace />;
+  }
+
   // Nếu đã đăng nhập, hiển thị children hoặc Outlet
   return (
     <MainLayout>
@@ -0,0 +1,29 @@
+import React from 'react';
+import { Text } from '@chakra-ui/react';
+import { useFetchApi } from '../../../hooks/useFetchApi';
+
+// Hiển thị chính sách mật khẩu của server dưới ô nhập mật khẩu mới
+const PasswordPolicyHint = () => {
+  const { data: policy } = useFetchApi(
+    ['auth', 'password-policy'],
+    '/auth/password-policy'
+  );
+
+  if (!policy) {
+    return null;
+  }
+
+  const rules = [`ít nhất ${policy.min_length} ký tự`];
+  if (policy.require_uppercase) rules.push('chữ hoa');
+  if (policy.require_lowercase) rules.push('chữ thường');
+  if (policy.require_digit) rules.push('chữ số');
+  if (policy.require_symbol) rules.push('ký tự đặc biệt');
+
+  return (
+    <Text fontSize='xs' color='gray.500'>
+      Mật khẩu cần có {rules.join(', ')} và không chứa tên đăng nhập.
+    </Text>
+  );
+};
+
+export default PasswordPolicyHint;
@@ -0,0 +1,176 @@
+import React, { useState } from "react";
+import {
+  Modal,
+  ModalOverlay,
+  ModalContent,
+  ModalHeader,
+  ModalFooter,
+  ModalBody,
+  ModalCloseButton,
+  Button,
+  FormControl,
+  FormLabel,
+  FormHelperText,
+  Input,
+  VStack,
+  useToast,
+} from "@chakra-ui/react";
+import { useAuth } from "../../../contexts/useAuthContext";
+import { fetchApi } from "../../../shared/services/api";
+
+const emptyForm = { password: "", pin: "", confirm_pin: "" };
+
+// Đặt hoặc xoá mã PIN dùng để chuyển nhanh sang tài khoản trên máy dùng chung
+const SetPinModal = ({ isOpen, onClose }) => {
+  const [formData, setFormData] = useState(emptyForm);
+  const [isSubmitting, setIsSubmitting] = useState(false);
+  const { user, checkAuthStatus } = useAuth();
+  const toast = useToast();
+
+  const handleInputChange = (e) => {
+    const { name, value } = e.target;
+    setFormData((prev) => ({
+      ...prev,
+      [name]: value,
+    }));
+  };
+
+  const handleClose = () => {
+    setFormData(emptyForm);
+    onClose();
+  };
+
+  const submit = async (request, successTitle) => {
+    setIsSubmitting(true);
+    try {
+      await fetchApi(request);
+      toast({
+        title: successTitle,
+        status: "success",
+        duration: 3000,
+        isClosable: true,
+      });
+      await checkAuthStatus();
+      handleClose();
+    } catch (error) {
+      toast({
+        title: "Lỗi",
+        description: error.message,
+        status: "error",
+        duration: 3000,
+        isClosable: true,
+      });
+    } finally {
+      setIsSubmitting(false);
+    }
+  };
+
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+
+    if (formData.pin !== formData.confirm_pin) {
+      toast({
+        title: "Lỗi",
+        description: "Mã PIN xác nhận không khớp",
+        status: "error",
+        duration: 3000,
+        isClosable: true,
+      });
+      return;
+    }
+
+    await submit(
+      {
+        method: "PUT",
+        url: "/auth/pin",
+        data: { password: formData.password, pin: formData.pin },
+      },
+      "Đã lưu mã PIN"
+    );
+  };
+
+  const handleRemove = () =>
+    submit({ method: "DELETE", url: "/auth/pin" }, "Đã xoá mã PIN");
+
+  return (
+    <Modal isOpen={isOpen} onClose={handleClose} size="sm">
+      <ModalOverlay />
+      <ModalContent as="form" onSubmit={handleSubmit}>
+        <ModalHeader>{user?.has_pin ? "Đổi mã PIN" : "Đặt mã PIN"}</ModalHeader>
+        <ModalCloseButton />
+        <ModalBody>
+          <VStack spacing={4} align="stretch">
+            <FormControl isRequired>
+              <FormLabel fontSize="sm">Mật khẩu hiện tại</FormLabel>
+              <Input
+                name="password"
+                type="password"
+                autoComplete="current-password"
+                value={formData.password}
+                onChange={handleInputChange}
+              />
+            </FormControl>
+            <FormControl isRequired>
+              <FormLabel fontSize="sm">Mã PIN mới</FormLabel>
+              <Input
+                name="pin"
+                type="password"
+                inputMode="numeric"
+                autoComplete="off"
+                maxLength={8}
+                value={formData.pin}
+                onChange={handleInputChange}
+              />
+              <FormHelperText>
+                4-8 chữ số, không dùng dãy dễ đoán như 1111 hoặc 1234
+              </FormHelperText>
+            </FormControl>
+            <FormControl isRequired>
+              <FormLabel fontSize="sm">Xác nhận mã PIN</FormLabel>
+              <Input
+                name="confirm_pin"
+                type="password"
+                inputMode="numeric"
+                autoComplete="off"
+                maxLength={8}
+                value={formData.confirm_pin}
+                onChange={handleInputChange}
+              />
+            </FormControl>
+          </VStack>
+        </ModalBody>
+        <ModalFooter>
+          {user?.has_pin && (
+            <Button
+              variant="ghost"
+              colorScheme="red"
+              mr="auto"
+              onClick={handleRemove}
+              isDisabled={isSubmitting}
+            >
+              Xoá PIN
+            </Button>
+          )}
+          <Button
+            variant="ghost"
+            mr={3}
+            onClick={handleClose}
+            isDisabled={isSubmitting}
+          >
+            Hủy
+          </Button>
+          <Button
+            type="submit"
+            colorScheme="blue"
+            isLoading={isSubmitting}
+            loadingText="Đang lưu..."
+          >
+            Lưu
+          </Button>
+        </ModalFooter>
+      </ModalContent>
+    </Modal>
+  );
+};
+
+export default SetPinModal;
@@ -0,0 +1,126 @@
+import React, { useState } from "react";
+import {
+  Modal,
+  ModalOverlay,
+  ModalContent,
+  ModalHeader,
+  ModalFooter,
+  ModalBody,
+  ModalCloseButton,
+  Button,
+  FormControl,
+  FormLabel,
+  Input,
+  VStack,
+  Text,
+  useToast,
+} from "@chakra-ui/react";
+import { useAuth } from "../../../contexts/useAuthContext";
+
+// Chuyển người thu ngân đang làm việc trên máy dùng chung bằng username và PIN,
+// hoá đơn và thanh toán sau đó được ghi cho người vừa chuyển sang
+const SwitchUserModal = ({ isOpen, onClose }) => {
+  const [formData, setFormData] = useState({ username: "", pin: "" });
+  const { user, switchUser, isSwitchingUser } = useAuth();
+  const toast = useToast();
+
+  const handleInputChange = (e) => {
+    const { name, value } = e.target;
+    setFormData((prev) => ({
+      ...prev,
+      [name]: value,
+    }));
+  };
+
+  const handleClose = () => {
+    setFormData({ username: "", pin: "" });
+    onClose();
+  };
+
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+
+    try {
+      const response = await switchUser(formData);
+
+      toast({
+        title: "Đã chuyển người dùng",
+        description: `Đang làm việc với tài khoản ${
+          response.data.user.full_name || response.data.user.username
+        }`,
+        status: "success",
+        duration: 3000,
+        isClosable: true,
+      });
+      handleClose();
+    } catch (error) {
+      setFormData((prev) => ({ ...prev, pin: "" }));
+      toast({
+        title: "Chuyển người dùng thất bại",
+        description: error.message,
+        status: "error",
+        duration: 3000,
+        isClosable: true,
+      });
+    }
+  };
+
+  return (
+    <Modal isOpen={isOpen} onClose={handleClose} size="sm">
+      <ModalOverlay />
+      <ModalContent as="form" onSubmit={handleSubmit}>
+        <ModalHeader>Chuyển người dùng</ModalHeader>
+        <ModalCloseButton />
+        <ModalBody>
+          <VStack spacing={4} align="stretch">
+            <Text fontSize="sm" color="gray.600">
+              Đang làm việc: <strong>{user?.full_name || user?.username}</strong>
+            </Text>
+            <FormControl isRequired>
+              <FormLabel fontSize="sm">Tên đăng nhập</FormLabel>
+              <Input
+                name="username"
+                autoComplete="off"
+                value={formData.username}
+                onChange={handleInputChange}
+                autoFocus
+              />
+            </FormControl>
+            <FormControl isRequired>
+              <FormLabel fontSize="sm">Mã PIN</FormLabel>
+              <Input
+                name="pin"
+                type="password"
+                inputMode="numeric"
+                autoComplete="off"
+                maxLength={8}
+                value={formData.pin}
+                onChange={handleInputChange}
+              />
+            </FormControl>
+          </VStack>
+        </ModalBody>
+        <ModalFooter>
+          <Button
+            variant="ghost"
+            mr={3}
+            onClick={handleClose}
+            isDisabled={isSwitchingUser}
+          >
+            Hủy
+          </Button>
+          <Button
+            type="submit"
+            colorScheme="blue"
+            isLoading={isSwitchingUser}
+            loadingText="Đang chuyển..."
+          >
+            Chuyển
+          </Button>
+        </ModalFooter>
+      </ModalContent>
+    </Modal>
+  );
+};
+
+export default SwitchUserModal;
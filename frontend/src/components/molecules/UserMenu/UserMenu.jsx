@@ -1,15 +1,19 @@
 import React from "react";
-import { Box, Button, Menu, MenuButton, MenuList, MenuItem, MenuDivider, HStack } from "@chakra-ui/react";
-import { ChevronDown, User, Settings, LogOut } from "lucide-react";
+import { Box, Button, Menu, MenuButton, MenuList, MenuItem, MenuDivider, HStack, useDisclosure } from "@chakra-ui/react";
+import { ChevronDown, User, Settings, KeyRound, Hash, Users, LogOut } from "lucide-react";
 import { useNavigate } from "react-router-dom";
 import { useAuth } from "../../../contexts/useAuthContext";
 import UserAvatar from "../../atoms/UserAvatar";
+import SwitchUserModal from "./SwitchUserModal";
+import SetPinModal from "./SetPinModal";
 import { useColorModeValue } from "@chakra-ui/react";
 
 const UserMenu = () => {
   const navigate = useNavigate();
   const { user, logout } = useAuth();
   const hoverBg = useColorModeValue("gray.50", "gray.700");
+  const switchUserModal = useDisclosure();
+  const setPinModal = useDisclosure();
 
   const handleLogout = async () => {
     try {
@@ -21,35 +25,58 @@ const UserMenu = () => {
   };
 
   return (
-    <Menu>
-      <MenuButton
-        as={Button}
-        variant="ghost"
-        w="full"
-        justifyContent="flex-start"
-        h="auto"
-        py={3}
-        px={4}
-        _hover={{ bg: hoverBg }}
-      >
-        <HStack spacing={3} w="full">
-          <UserAvatar user={user} />
-          <ChevronDown size={16} />
-        </HStack>
-      </MenuButton>
-      <MenuList>
-        <MenuItem icon={<User size={16} />}>Hồ sơ</MenuItem>
-        <MenuItem icon={<Settings size={16} />}>Cài đặt</MenuItem>
-        <MenuDivider />
-        <MenuItem
-          icon={<LogOut size={16} />}
-          onClick={handleLogout}
-          color="red.500"
+    <>
+      <Menu>
+        <MenuButton
+          as={Button}
+          variant="ghost"
+          w="full"
+          justifyContent="flex-start"
+          h="auto"
+          py={3}
+          px={4}
+          _hover={{ bg: hoverBg }}
         >
-          Đăng xuất
-        </MenuItem>
-      </MenuList>
-    </Menu>
+          <HStack spacing={3} w="full">
+            <UserAvatar user={user} />
+            <ChevronDown size={16} />
+          </HStack>
+        </MenuButton>
+        <MenuList>
+          <MenuItem icon={<User size={16} />}>Hồ sơ</MenuItem>
+          <MenuItem icon={<Settings size={16} />}>Cài đặt</MenuItem>
+          <MenuItem
+            icon={<KeyRound size={16} />}
+            onClick={() => navigate("/change-password")}
+          >
+            Đổi mật khẩu
+          </MenuItem>
+          <MenuItem icon={<Hash size={16} />} onClick={setPinModal.onOpen}>
+            {user?.has_pin ? "Đổi mã PIN" : "Đặt mã PIN"}
+          </MenuItem>
+          <MenuDivider />
+          <MenuItem
+            icon={<Users size={16} />}
+            onClick={switchUserModal.onOpen}
+          >
+            Chuyển người dùng
+          </MenuItem>
+          <MenuDivider />
+          <MenuItem
+            icon={<LogOut size={16} />}
+            onClick={handleLogout}
+            color="red.500"
+          >
+            Đăng xuất
+          </MenuItem>
+        </MenuList>
+      </Menu>
+      <SwitchUserModal
+        isOpen={switchUserModal.isOpen}
+        onClose={switchUserModal.onClose}
+      />
+      <SetPinModal isOpen={setPinModal.isOpen} onClose={setPinModal.onClose} />
+    </>
   );
 };
 
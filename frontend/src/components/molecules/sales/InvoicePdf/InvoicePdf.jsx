@@ -1,13 +1,16 @@
-import React, { useEffect, useCallback } from "react";
+import React, { useEffect, useCallback, useState } from "react";
 import {
   Box,
   VStack,
   Text,
+  Spinner,
   useToast,
 } from "@chakra-ui/react";
+import { getSignedUrl } from "../../../../shared/services/api";
 
 const InvoicePdf = ({ invoiceId, invoiceCode, onLoad, onError }) => {
   const toast = useToast();
+  const [pdfUrl, setPdfUrl] = useState(null);
 
   // Logging functions
   const logPDFEvent = useCallback((eventType, details = {}) => {
@@ -26,11 +29,26 @@ const InvoicePdf = ({ invoiceId, invoiceCode, onLoad, onError }) => {
     // Example: analytics.track('pdf_event', logData);
   }, [invoiceId, invoiceCode]);
 
-  // Get authenticated PDF URL
-  const getAuthenticatedPDFUrl = () => {
-    const token = localStorage.getItem('accessToken');
-    return `http://localhost:8080/api/invoices/${invoiceId}/pdf?token=${token}`;
-  };
+  // Get signed PDF URL (short-lived, no access token in the URL)
+  useEffect(() => {
+    let cancelled = false;
+    getSignedUrl(`/invoices/${invoiceId}/pdf`)
+      .then((url) => {
+        if (!cancelled) {
+          setPdfUrl(url);
+        }
+      })
+      .catch(() => {
+        if (!cancelled) {
+          handleIframeError();
+        }
+      });
+
+    return () => {
+      cancelled = true;
+    };
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [invoiceId]);
 
   // Log component mount/unmount
   useEffect(() => {
@@ -48,7 +66,7 @@ const InvoicePdf = ({ invoiceId, invoiceCode, onLoad, onError }) => {
   const handleIframeLoad = () => {
     logPDFEvent('pdf_viewer_loaded', {
       source: 'iframe',
-      pdfUrl: getAuthenticatedPDFUrl()
+      pdfUrl
     });
     
     if (onLoad) {
@@ -92,9 +110,14 @@ const InvoicePdf = ({ invoiceId, invoiceCode, onLoad, onError }) => {
         position="relative"
       >
         <Box position="relative" w="100%" h="100%">
+          {!pdfUrl ? (
+            <Box display="flex" alignItems="center" justifyContent="center" h="100%" minH="500px">
+              <Spinner size="lg" />
+            </Box>
+          ) : (
           <iframe
             id={`pdf-iframe-${invoiceId}`}
-            src={getAuthenticatedPDFUrl()}
+            src={pdfUrl}
             width="100%"
             height="100%"
             style={{
@@ -106,6 +129,7 @@ const InvoicePdf = ({ invoiceId, invoiceCode, onLoad, onError }) => {
             onLoad={handleIframeLoad}
             onError={handleIframeError}
           />
+          )}
         </Box>
       </Box>
 
@@ -7,16 +7,27 @@ export const useAuth = () => {
   const [user, setUser] = useState(null);
   const [isAuthenticated, setIsAuthenticated] = useState(false);
   const [isLoading, setIsLoading] = useState(true);
+  // Challenge trả về khi tài khoản cần xác thực 2 bước
+  const [twoFactorChallenge, setTwoFactorChallenge] = useState(null);
+
+  const startSession = (userData) => {
+    localStorage.setItem("accessToken", userData.access_token);
+    localStorage.setItem("refreshToken", userData.refresh_token);
+    localStorage.setItem("user", JSON.stringify(userData.user));
+
+    setTwoFactorChallenge(null);
+    setUser(userData.user);
+    setIsAuthenticated(true);
+  };
 
   const loginMutation = useCreateApi("/auth/login", {
     onSuccess: (data) => {
       const userData = data.data;
-      localStorage.setItem("accessToken", userData.access_token);
-      localStorage.setItem("refreshToken", userData.refresh_token);
-      localStorage.setItem("user", JSON.stringify(userData.user));
-
-      setUser(userData.user);
-      setIsAuthenticated(true);
+      if (userData.two_factor) {
+        setTwoFactorChallenge(userData.two_factor);
+        return;
+      }
+      startSession(userData);
     },
     onError: (error) => {
       setUser(null);
@@ -25,6 +36,25 @@ export const useAuth = () => {
     },
   });
 
+  // Bước 2: nhập mã từ ứng dụng xác thực hoặc mã khôi phục
+  const verifyTwoFactorMutation = useCreateApi("/auth/login/2fa", {
+    onSuccess: (data) => startSession(data.data),
+  });
+
+  // Cài đặt 2FA khi vai trò bắt buộc mà tài khoản chưa cài đặt. Sau khi bật,
+  // trang đăng nhập hiển thị mã khôi phục rồi mới gọi completeLogin
+  const setupTwoFactorMutation = useCreateApi("/auth/login/2fa/setup");
+  const enableTwoFactorMutation = useCreateApi("/auth/login/2fa/enable");
+
+  // Chuyển người thu ngân trên máy dùng chung bằng PIN, không cần đăng nhập lại.
+  // Tokens cũ của phiên bị thu hồi nên phải thay bằng tokens mới
+  const switchUserMutation = useCreateApi("/auth/switch-user", {
+    onSuccess: async (data) => {
+      startSession(data.data);
+      await checkAuthStatus();
+    },
+  });
+
   const whoAmIQuery = useFetchApi(
     apiUtils.createListQueryKey("auth", "whoami"),
     "/auth/whoami",
@@ -51,6 +81,7 @@ export const useAuth = () => {
     localStorage.removeItem("accessToken");
     localStorage.removeItem("refreshToken");
     localStorage.removeItem("user");
+    setTwoFactorChallenge(null);
     setUser(null);
     setIsAuthenticated(false);
     window.location.href = "/login";
@@ -99,8 +130,35 @@ export const useAuth = () => {
     // Actions
     login: loginMutation.mutate,
     logout,
+
+    // Xác thực 2 bước
+    twoFactorChallenge,
+    cancelTwoFactor: () => setTwoFactorChallenge(null),
+    verifyTwoFactor: (body) =>
+      verifyTwoFactorMutation.mutateAsync({
+        challenge_token: twoFactorChallenge?.challenge_token,
+        ...body,
+      }),
+    setupTwoFactor: () =>
+      setupTwoFactorMutation.mutateAsync({
+        challenge_token: twoFactorChallenge?.challenge_token,
+      }),
+    enableTwoFactor: (code) =>
+      enableTwoFactorMutation.mutateAsync({
+        challenge_token: twoFactorChallenge?.challenge_token,
+        code,
+      }),
+    completeLogin: startSession,
+    isTwoFactorLoading:
+      verifyTwoFactorMutation.isPending ||
+      setupTwoFactorMutation.isPending ||
+      enableTwoFactorMutation.isPending,
     checkAuthStatus,
 
+    // Chuyển user bằng PIN
+    switchUser: switchUserMutation.mutateAsync,
+    isSwitchingUser: switchUserMutation.isPending,
+
     // Mutation states
     isLoginLoading: loginMutation.isPending,
     loginError: loginMutation.error,
@@ -0,0 +1,201 @@
+import React, { useState } from 'react';
+import {
+  Box,
+  Button,
+  FormControl,
+  FormLabel,
+  Input,
+  VStack,
+  Heading,
+  Text,
+  useToast,
+  Container,
+  Flex,
+} from '@chakra-ui/react';
+import { Navigate, useNavigate } from 'react-router-dom';
+import { useAuth } from '../contexts/useAuthContext';
+import { fetchApi } from '../shared/services/api';
+import SplashScreen from '../components/SplashScreen';
+import PasswordPolicyHint from '../components/molecules/PasswordPolicyHint';
+
+const ChangePassword = () => {
+  const [formData, setFormData] = useState({
+    current_password: '',
+    new_password: '',
+    confirm_password: '',
+  });
+  const [isSubmitting, setIsSubmitting] = useState(false);
+  const toast = useToast();
+  const navigate = useNavigate();
+  const { user, isLoading, isAuthenticated, checkAuthStatus, logout } =
+    useAuth();
+
+  if (isLoading) {
+    return <SplashScreen />;
+  }
+
+  if (!isAuthenticated) {
+    return <Navigate to='/login' replace />;
+  }
+
+  const handleInputChange = e => {
+    const { name, value } = e.target;
+    setFormData(prev => ({
+      ...prev,
+      [name]: value,
+    }));
+  };
+
+  const handleSubmit = async e => {
+    e.preventDefault();
+
+    if (formData.new_password !== formData.confirm_password) {
+      toast({
+        title: 'Lỗi',
+        description: 'Mật khẩu xác nhận không khớp',
+        status: 'error',
+        duration: 3000,
+        isClosable: true,
+      });
+      return;
+    }
+
+    setIsSubmitting(true);
+    try {
+      await fetchApi({
+        method: 'POST',
+        url: '/auth/change-password',
+        data: {
+          current_password: formData.current_password,
+          new_password: formData.new_password,
+        },
+      });
+
+      toast({
+        title: 'Đã đổi mật khẩu',
+        status: 'success',
+        duration: 3000,
+        isClosable: true,
+      });
+
+      // Lấy lại thông tin user để bỏ cờ bắt buộc đổi mật khẩu
+      await checkAuthStatus();
+      navigate('/dashboard', { replace: true });
+    } catch (error) {
+      toast({
+        title: 'Đổi mật khẩu thất bại',
+        description: error.message,
+        status: 'error',
+        duration: 3000,
+        isClosable: true,
+      });
+    } finally {
+      setIsSubmitting(false);
+    }
+  };
+
+  return (
+    <Box h='100%' overflow={'auto'} bg='gray.50'>
+      <Container maxW='lg' py={{ base: '0', md: '24' }}>
+        <Flex direction='column' align='center' justify='center' minH='100vh'>
+          <Box
+            py='8'
+            px={{ base: '4', sm: '10' }}
+            bg='white'
+            boxShadow='md'
+            borderRadius='xl'
+            w='full'
+            maxW='md'
+          >
+            <VStack spacing='6'>
+              <VStack spacing='2'>
+                <Heading size='md' color='gray.900'>
+                  Đổi mật khẩu
+                </Heading>
+                {user?.must_change_password && (
+                  <Text color='gray.600' fontSize='sm' textAlign='center'>
+                    Bạn cần đặt mật khẩu mới trước khi tiếp tục sử dụng hệ
+                    thống.
+                  </Text>
+                )}
+              </VStack>
+
+              <Box as='form' onSubmit={handleSubmit} w='full'>
+                <VStack spacing='4'>
+                  <FormControl isRequired>
+                    <FormLabel htmlFor='current_password' fontSize='sm'>
+                      Mật khẩu hiện tại
+                    </FormLabel>
+                    <Input
+                      id='current_password'
+                      name='current_password'
+                      type='password'
+                      autoComplete='current-password'
+                      value={formData.current_password}
+                      onChange={handleInputChange}
+                    />
+                  </FormControl>
+
+                  <FormControl isRequired>
+                    <FormLabel htmlFor='new_password' fontSize='sm'>
+                      Mật khẩu mới
+                    </FormLabel>
+                    <Input
+                      id='new_password'
+                      name='new_password'
+                      type='password'
+                      autoComplete='new-password'
+                      value={formData.new_password}
+                      onChange={handleInputChange}
+                    />
+                    <PasswordPolicyHint />
+                  </FormControl>
+
+                  <FormControl isRequired>
+                    <FormLabel htmlFor='confirm_password' fontSize='sm'>
+                      Xác nhận mật khẩu mới
+                    </FormLabel>
+                    <Input
+                      id='confirm_password'
+                      name='confirm_password'
+                      type='password'
+                      autoComplete='new-password'
+                      value={formData.confirm_password}
+                      onChange={handleInputChange}
+                    />
+                  </FormControl>
+
+                  <Button
+                    type='submit'
+                    colorScheme='blue'
+                    w='full'
+                    isLoading={isSubmitting}
+                    loadingText='Đang lưu...'
+                  >
+                    Đổi mật khẩu
+                  </Button>
+
+                  {user?.must_change_password ? (
+                    <Button variant='link' size='sm' onClick={logout}>
+                      Đăng xuất
+                    </Button>
+                  ) : (
+                    <Button
+                      variant='link'
+                      size='sm'
+                      onClick={() => navigate(-1)}
+                    >
+                      Quay lại
+                    </Button>
+                  )}
+                </VStack>
+              </Box>
+            </VStack>
+          </Box>
+        </Flex>
+      </Container>
+    </Box>
+  );
+};
+
+export default ChangePassword;
@@ -5,6 +5,9 @@ import {
   FormControl,
   FormLabel,
   Input,
+  Image,
+  Code,
+  SimpleGrid,
   VStack,
   Heading,
   Text,
@@ -28,7 +31,33 @@ const Login = () => {
   const toast = useToast();
   const navigate = useNavigate();
   const location = useLocation();
-  const { login, isAuthenticated, isLoginLoading } = useAuth();
+  const {
+    login,
+    isAuthenticated,
+    isLoginLoading,
+    twoFactorChallenge,
+    cancelTwoFactor,
+    verifyTwoFactor,
+    setupTwoFactor,
+    enableTwoFactor,
+    completeLogin,
+    isTwoFactorLoading,
+  } = useAuth();
+
+  // Xác thực 2 bước
+  const [twoFactorCode, setTwoFactorCode] = useState('');
+  const [useRecoveryCode, setUseRecoveryCode] = useState(false);
+  const [twoFactorSetup, setTwoFactorSetup] = useState(null);
+  const [enabledLogin, setEnabledLogin] = useState(null);
+
+  // Vai trò bắt buộc 2FA mà tài khoản chưa cài đặt: lấy mã QR để quét
+  useEffect(() => {
+    if (twoFactorChallenge?.setup_required && !twoFactorSetup) {
+      setupTwoFactor()
+        .then(resp => setTwoFactorSetup(resp.data))
+        .catch(error => showError('Không thể cài đặt xác thực 2 bước', error));
+    }
+  }, [twoFactorChallenge]);
 
   // Nếu đã đăng nhập, chuyển hướng đến dashboard
   useEffect(() => {
@@ -46,6 +75,45 @@ const Login = () => {
     }));
   };
 
+  const showError = (title, error) => {
+    toast({
+      title,
+      description: error.message || 'Mã xác thực không đúng',
+      status: 'error',
+      duration: 3000,
+      isClosable: true,
+    });
+  };
+
+  const resetTwoFactor = () => {
+    setTwoFactorCode('');
+    setUseRecoveryCode(false);
+    setTwoFactorSetup(null);
+    cancelTwoFactor();
+  };
+
+  const handleTwoFactorSubmit = async e => {
+    e.preventDefault();
+
+    try {
+      if (twoFactorChallenge.setup_required) {
+        const resp = await enableTwoFactor(twoFactorCode);
+        // Giữ lại phiên đăng nhập đến khi người dùng lưu mã khôi phục
+        setEnabledLogin(resp.data);
+        return;
+      }
+
+      await verifyTwoFactor(
+        useRecoveryCode
+          ? { recovery_code: twoFactorCode }
+          : { code: twoFactorCode }
+      );
+    } catch (error) {
+      setTwoFactorCode('');
+      showError('Xác thực 2 bước thất bại', error);
+    }
+  };
+
   const handleSubmit = async e => {
     e.preventDefault();
 
@@ -116,49 +184,127 @@ const Login = () => {
                 </VStack>
               </VStack>
 
-              {/* Form đăng nhập */}
-              <Box as='form' onSubmit={handleSubmit} w='full'>
-                <VStack spacing={{ base: '2', md: '6' }}>
-                  <FormControl isRequired>
-                    <FormLabel
-                      htmlFor='username'
-                      fontSize={{ base: 'xs', md: 'sm' }}
-                      fontWeight='medium'
-                    >
-                      Tên đăng nhập
-                    </FormLabel>
-                    <Input
-                      id='username'
-                      name='username'
-                      type='text'
-                      value={formData.username}
-                      onChange={handleInputChange}
-                      placeholder='Nhập tên đăng nhập'
+              {/* Mã khôi phục sau khi cài đặt 2FA, chỉ hiển thị một lần */}
+              {enabledLogin && (
+                <VStack spacing='4' w='full'>
+                  <Text fontSize='sm' color='gray.700'>
+                    Lưu các mã khôi phục dưới đây ở nơi an toàn. Mỗi mã dùng
+                    được một lần khi không có ứng dụng xác thực.
+                  </Text>
+                  <SimpleGrid columns={2} spacing='2' w='full'>
+                    {enabledLogin.recovery_codes.map(code => (
+                      <Code key={code} textAlign='center' py='1'>
+                        {code}
+                      </Code>
+                    ))}
+                  </SimpleGrid>
+                  <Button
+                    colorScheme='blue'
+                    w='full'
+                    onClick={() => completeLogin(enabledLogin)}
+                  >
+                    Tôi đã lưu mã khôi phục
+                  </Button>
+                </VStack>
+              )}
+
+              {/* Bước 2: mã từ ứng dụng xác thực */}
+              {twoFactorChallenge && !enabledLogin && (
+                <Box as='form' onSubmit={handleTwoFactorSubmit} w='full'>
+                  <VStack spacing={{ base: '2', md: '6' }}>
+                    {twoFactorChallenge.setup_required && (
+                      <VStack spacing='2'>
+                        <Text fontSize='sm' color='gray.700'>
+                          Tài khoản của bạn bắt buộc xác thực 2 bước. Quét mã QR
+                          bằng ứng dụng xác thực rồi nhập mã 6 số.
+                        </Text>
+                        {twoFactorSetup && (
+                          <>
+                            <Image
+                              src={twoFactorSetup.qr_code}
+                              alt='Mã QR xác thực 2 bước'
+                              boxSize='200px'
+                            />
+                            <Code fontSize='xs'>{twoFactorSetup.secret}</Code>
+                          </>
+                        )}
+                      </VStack>
+                    )}
+
+                    <FormControl isRequired>
+                      <FormLabel
+                        htmlFor='twoFactorCode'
+                        fontSize={{ base: 'xs', md: 'sm' }}
+                        fontWeight='medium'
+                      >
+                        {useRecoveryCode ? 'Mã khôi phục' : 'Mã xác thực'}
+                      </FormLabel>
+                      <Input
+                        id='twoFactorCode'
+                        value={twoFactorCode}
+                        onChange={e => setTwoFactorCode(e.target.value)}
+                        placeholder={useRecoveryCode ? 'XXXXX-XXXXX' : '123456'}
+                        inputMode={useRecoveryCode ? 'text' : 'numeric'}
+                        autoComplete='one-time-code'
+                        autoFocus
+                        size={{ base: 'md', md: 'lg' }}
+                        bg='white'
+                      />
+                    </FormControl>
+
+                    <Button
+                      type='submit'
+                      colorScheme='blue'
                       size={{ base: 'md', md: 'lg' }}
-                      bg='white'
-                      border='1px'
-                      borderColor='gray.300'
-                      _hover={{ borderColor: 'gray.400' }}
-                      _focus={{ borderColor: 'blue.500', boxShadow: 'outline' }}
-                    />
-                  </FormControl>
-
-                  <FormControl isRequired>
-                    <FormLabel
-                      htmlFor='password'
-                      fontSize={{ base: 'xs', md: 'sm' }}
-                      fontWeight='medium'
+                      fontSize='md'
+                      w='full'
+                      isLoading={isTwoFactorLoading}
+                      loadingText='Đang xác thực...'
                     >
-                      Mật khẩu
-                    </FormLabel>
-                    <InputGroup size={{ base: 'md', md: 'lg' }}>
+                      Xác nhận
+                    </Button>
+
+                    {!twoFactorChallenge.setup_required && (
+                      <Button
+                        variant='link'
+                        size='sm'
+                        onClick={() => {
+                          setTwoFactorCode('');
+                          setUseRecoveryCode(!useRecoveryCode);
+                        }}
+                      >
+                        {useRecoveryCode
+                          ? 'Dùng mã từ ứng dụng xác thực'
+                          : 'Dùng mã khôi phục'}
+                      </Button>
+                    )}
+                    <Button variant='link' size='sm' onClick={resetTwoFactor}>
+                      Quay lại
+                    </Button>
+                  </VStack>
+                </Box>
+              )}
+
+              {/* Form đăng nhập */}
+              {!twoFactorChallenge && !enabledLogin && (
+                <Box as='form' onSubmit={handleSubmit} w='full'>
+                  <VStack spacing={{ base: '2', md: '6' }}>
+                    <FormControl isRequired>
+                      <FormLabel
+                        htmlFor='username'
+                        fontSize={{ base: 'xs', md: 'sm' }}
+                        fontWeight='medium'
+                      >
+                        Tên đăng nhập
+                      </FormLabel>
                       <Input
-                        id='password'
-                        name='password'
-                        type={showPassword ? 'text' : 'password'}
-                        value={formData.password}
+                        id='username'
+                        name='username'
+                        type='text'
+                        value={formData.username}
                         onChange={handleInputChange}
-                        placeholder='Nhập mật khẩu'
+                        placeholder='Nhập tên đăng nhập'
+                        size={{ base: 'md', md: 'lg' }}
                         bg='white'
                         border='1px'
                         borderColor='gray.300'
@@ -168,33 +314,70 @@ const Login = () => {
                           boxShadow: 'outline',
                         }}
                       />
-                      <InputRightElement>
-                        <IconButton
-                          aria-label={
-                            showPassword ? 'Ẩn mật khẩu' : 'Hiện mật khẩu'
-                          }
-                          icon={showPassword ? <EyeOff /> : <Eye />}
-                          onClick={() => setShowPassword(!showPassword)}
-                          variant='ghost'
-                          size={{ base: 'xs', md: 'sm' }}
+                    </FormControl>
+
+                    <FormControl isRequired>
+                      <FormLabel
+                        htmlFor='password'
+                        fontSize={{ base: 'xs', md: 'sm' }}
+                        fontWeight='medium'
+                      >
+                        Mật khẩu
+                      </FormLabel>
+                      <InputGroup size={{ base: 'md', md: 'lg' }}>
+                        <Input
+                          id='password'
+                          name='password'
+                          type={showPassword ? 'text' : 'password'}
+                          value={formData.password}
+                          onChange={handleInputChange}
+                          placeholder='Nhập mật khẩu'
+                          bg='white'
+                          border='1px'
+                          borderColor='gray.300'
+                          _hover={{ borderColor: 'gray.400' }}
+                          _focus={{
+                            borderColor: 'blue.500',
+                            boxShadow: 'outline',
+                          }}
                         />
-                      </InputRightElement>
-                    </InputGroup>
-                  </FormControl>
+                        <InputRightElement>
+                          <IconButton
+                            aria-label={
+                              showPassword ? 'Ẩn mật khẩu' : 'Hiện mật khẩu'
+                            }
+                            icon={showPassword ? <EyeOff /> : <Eye />}
+                            onClick={() => setShowPassword(!showPassword)}
+                            variant='ghost'
+                            size={{ base: 'xs', md: 'sm' }}
+                          />
+                        </InputRightElement>
+                      </InputGroup>
+                    </FormControl>
 
-                  <Button
-                    type='submit'
-                    colorScheme='blue'
-                    size={{ base: 'md', md: 'lg' }}
-                    fontSize='md'
-                    w='full'
-                    isLoading={isLoginLoading}
-                    loadingText='Đang đăng nhập...'
-                  >
-                    Đăng nhập
-                  </Button>
-                </VStack>
-              </Box>
+                    <Button
+                      type='submit'
+                      colorScheme='blue'
+                      size={{ base: 'md', md: 'lg' }}
+                      fontSize='md'
+                      w='full'
+                      isLoading={isLoginLoading}
+                      loadingText='Đang đăng nhập...'
+                    >
+                      Đăng nhập
+                    </Button>
+
+                    {/* Mã đặt lại mật khẩu do admin cấp khi quên mật khẩu */}
+                    <Button
+                      variant='link'
+                      size='sm'
+                      onClick={() => navigate('/reset-password')}
+                    >
+                      Quên mật khẩu?
+                    </Button>
+                  </VStack>
+                </Box>
+              )}
 
               {/* Thông tin demo */}
               <VStack
@@ -0,0 +1,178 @@
+import React, { useState } from 'react';
+import {
+  Box,
+  Button,
+  FormControl,
+  FormLabel,
+  Input,
+  VStack,
+  Heading,
+  Text,
+  useToast,
+  Container,
+  Flex,
+} from '@chakra-ui/react';
+import { useNavigate, useSearchParams } from 'react-router-dom';
+import { fetchApi } from '../shared/services/api';
+import PasswordPolicyHint from '../components/molecules/PasswordPolicyHint';
+
+// Đặt lại mật khẩu bằng token dùng một lần do admin cấp
+const ResetPassword = () => {
+  const [searchParams] = useSearchParams();
+  const [formData, setFormData] = useState({
+    token: searchParams.get('token') || '',
+    new_password: '',
+    confirm_password: '',
+  });
+  const [isSubmitting, setIsSubmitting] = useState(false);
+  const toast = useToast();
+  const navigate = useNavigate();
+
+  const handleInputChange = e => {
+    const { name, value } = e.target;
+    setFormData(prev => ({
+      ...prev,
+      [name]: value,
+    }));
+  };
+
+  const handleSubmit = async e => {
+    e.preventDefault();
+
+    if (formData.new_password !== formData.confirm_password) {
+      toast({
+        title: 'Lỗi',
+        description: 'Mật khẩu xác nhận không khớp',
+        status: 'error',
+        duration: 3000,
+        isClosable: true,
+      });
+      return;
+    }
+
+    setIsSubmitting(true);
+    try {
+      await fetchApi({
+        method: 'POST',
+        url: '/auth/reset-password',
+        data: {
+          token: formData.token,
+          new_password: formData.new_password,
+        },
+      });
+
+      toast({
+        title: 'Đã đặt lại mật khẩu',
+        description: 'Vui lòng đăng nhập bằng mật khẩu mới',
+        status: 'success',
+        duration: 3000,
+        isClosable: true,
+      });
+      navigate('/login', { replace: true });
+    } catch (error) {
+      toast({
+        title: 'Đặt lại mật khẩu thất bại',
+        description: error.message,
+        status: 'error',
+        duration: 3000,
+        isClosable: true,
+      });
+    } finally {
+      setIsSubmitting(false);
+    }
+  };
+
+  return (
+    <Box h='100%' overflow={'auto'} bg='gray.50'>
+      <Container maxW='lg' py={{ base: '0', md: '24' }}>
+        <Flex direction='column' align='center' justify='center' minH='100vh'>
+          <Box
+            py='8'
+            px={{ base: '4', sm: '10' }}
+            bg='white'
+            boxShadow='md'
+            borderRadius='xl'
+            w='full'
+            maxW='md'
+          >
+            <VStack spacing='6'>
+              <VStack spacing='2'>
+                <Heading size='md' color='gray.900'>
+                  Đặt lại mật khẩu
+                </Heading>
+                <Text color='gray.600' fontSize='sm' textAlign='center'>
+                  Nhập mã đặt lại mật khẩu do quản trị viên cung cấp.
+                </Text>
+              </VStack>
+
+              <Box as='form' onSubmit={handleSubmit} w='full'>
+                <VStack spacing='4'>
+                  <FormControl isRequired>
+                    <FormLabel htmlFor='token' fontSize='sm'>
+                      Mã đặt lại
+                    </FormLabel>
+                    <Input
+                      id='token'
+                      name='token'
+                      value={formData.token}
+                      onChange={handleInputChange}
+                    />
+                  </FormControl>
+
+                  <FormControl isRequired>
+                    <FormLabel htmlFor='new_password' fontSize='sm'>
+                      Mật khẩu mới
+                    </FormLabel>
+                    <Input
+                      id='new_password'
+                      name='new_password'
+                      type='password'
+                      autoComplete='new-password'
+                      value={formData.new_password}
+                      onChange={handleInputChange}
+                    />
+                    <PasswordPolicyHint />
+                  </FormControl>
+
+                  <FormControl isRequired>
+                    <FormLabel htmlFor='confirm_password' fontSize='sm'>
+                      Xác nhận mật khẩu mới
+                    </FormLabel>
+                    <Input
+                      id='confirm_password'
+                      name='confirm_password'
+                      type='password'
+                      autoComplete='new-password'
+                      value={formData.confirm_password}
+                      onChange={handleInputChange}
+                    />
+                  </FormControl>
+
+                  <Button
+                    type='submit'
+                    colorScheme='blue'
+                    w='full'
+                    isLoading={isSubmitting}
+                    loadingText='Đang lưu...'
+                  >
+                    Đặt lại mật khẩu
+                  </Button>
+
+                  <Button
+                    variant='link'
+                    size='sm'
+                    onClick={() => navigate('/login')}
+                  >
+                    Quay lại đăng nhập
+                  </Button>
+                </VStack>
+              </Box>
+            </VStack>
+          </Box>
+        </Flex>
+      </Container>
+    </Box>
+  );
+};
+
+export default ResetPassword;
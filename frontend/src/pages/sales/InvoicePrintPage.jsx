@@ -1,4 +1,4 @@
-import React, { useEffect, useCallback } from "react";
+import React, { useEffect, useCallback, useState } from "react";
 import { useParams, useNavigate } from "react-router-dom";
 import {
   Box,
@@ -17,11 +17,13 @@ import {
 import { Printer, Download, ArrowLeft } from "lucide-react";
 import { useFetchApi } from "../../hooks/useFetchApi";
 import Page from "../../components/organisms/Page/Page";
+import { getSignedUrl } from "../../shared/services/api";
 
 const InvoicePrintPage = () => {
   const { id } = useParams();
   const navigate = useNavigate();
   const toast = useToast();
+  const [pdfUrl, setPdfUrl] = useState(null);
 
   // Fetch invoice data for title
   const { data: invoiceData, error, isPending: isLoading } = useFetchApi(
@@ -125,16 +127,25 @@ const InvoicePrintPage = () => {
     logPrintEvent('print_button_clicked', {
       source: 'print_button'
     });
-    // Open PDF in new window for printing
-    const token = localStorage.getItem('accessToken');
-    const printUrl = `http://localhost:8080/api/invoices/${id}/pdf?token=${token}`;
-    const printWindow = window.open(printUrl, '_blank');
+    // Open the window right away so the popup blocker allows it, then load a fresh signed PDF URL
+    const printWindow = window.open('', '_blank');
     
     if (printWindow) {
+      getSignedUrl(`/invoices/${id}/pdf`)
+        .then((printUrl) => {
+          printWindow.location.href = printUrl;
+        })
+        .catch((error) => {
+          printWindow.close();
+          logPrintEvent('print_window_error', {
+            source: 'print_button',
+            error: error.message
+          });
+        });
+
       printWindow.onload = () => {
         logPrintEvent('print_window_opened', {
-          source: 'print_button',
-          printUrl: printUrl
+          source: 'print_button'
         });
         printWindow.print();
       };
@@ -207,11 +218,30 @@ const InvoicePrintPage = () => {
     }
   };
 
-  // Get authenticated PDF URL
-  const getAuthenticatedPDFUrl = () => {
-    const token = localStorage.getItem('accessToken');
-    return `http://localhost:8080/api/invoices/${id}/pdf?token=${token}`;
-  };
+  // Get signed PDF URL for the preview (short-lived, no access token in the URL)
+  useEffect(() => {
+    if (!id) {
+      return;
+    }
+
+    let cancelled = false;
+    getSignedUrl(`/invoices/${id}/pdf`)
+      .then((url) => {
+        if (!cancelled) {
+          setPdfUrl(url);
+        }
+      })
+      .catch((error) => {
+        logPDFEvent('pdf_viewer_error', {
+          source: 'signed_url',
+          error: error.message
+        });
+      });
+
+    return () => {
+      cancelled = true;
+    };
+  }, [id, logPDFEvent]);
 
   // Show not found state
   if (!isLoading && !error && !invoice.invoice_code) {
@@ -276,7 +306,7 @@ const InvoicePrintPage = () => {
                    <Box position="relative" w="100%" h="600px">
                      <iframe
                        id="pdf-iframe"
-                       src={getAuthenticatedPDFUrl()}
+                       src={pdfUrl || undefined}
                        width="100%"
                        height="100%"
                        style={{
@@ -288,7 +318,7 @@ const InvoicePrintPage = () => {
                        onLoad={() => {
                          logPDFEvent('pdf_viewer_loaded', {
                            source: 'iframe',
-                           pdfUrl: getAuthenticatedPDFUrl()
+                           pdfUrl
                          });
                        }}
                        onError={() => {
@@ -17,6 +17,11 @@ apiClient.interceptors.request.use(
     if (token) {
       config.headers.Authorization = `Bearer ${token}`;
     }
+    // Chi nhánh đang làm việc; backend dùng chi nhánh đầu tiên của user nếu không gửi
+    const branchId = localStorage.getItem("branchId");
+    if (branchId) {
+      config.headers["X-Branch-ID"] = branchId;
+    }
     return config;
   },
   (error) => {
@@ -67,6 +72,15 @@ apiClient.interceptors.response.use(
       }
     }
 
+    // Tài khoản bị bắt buộc đổi mật khẩu: chỉ cho vào trang đổi mật khẩu
+    if (
+      error.response?.status === 403 &&
+      error.response.data?.error === "Password change required" &&
+      window.location.pathname !== "/change-password"
+    ) {
+      window.location.href = "/change-password";
+    }
+
     return Promise.reject(error);
   }
 );
@@ -121,6 +135,16 @@ export const fetchApi = async ({
   }
 };
 
+// Lấy signed URL ngắn hạn để mở PDF/ảnh trong iframe hoặc tab mới mà không đưa access token vào URL
+export const getSignedUrl = async (path) => {
+  const response = await fetchApi({
+    method: "POST",
+    url: "/signed-urls",
+    data: { path: `/api${path}` },
+  });
+  return `${API_BASE_URL.replace(/\/api$/, "")}${response.data.data.url}`;
+};
+
 // Utility functions
 export const apiUtils = {
   // Tạo query key cho pagination